@@ -0,0 +1,132 @@
+// 告警 API 控制器
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AlertController 告警控制器
+type AlertController struct {
+	service *AlertService
+}
+
+// NewAlertController 创建告警控制器
+func NewAlertController() *AlertController {
+	return &AlertController{
+		service: NewAlertService(),
+	}
+}
+
+// ListAlerts 查询告警历史
+func (c *AlertController) ListAlerts(ctx *gin.Context) {
+	var params models.ParamAlertList
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.PageNum == 0 {
+		params.PageNum = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 20
+	}
+
+	result, err := c.service.ListAlerts(ctx, params)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAlertRule) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取告警历史失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// ListAlertRules 获取告警规则
+func (c *AlertController) ListAlertRules(ctx *gin.Context) {
+	result, err := c.service.ListAlertRules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取告警规则失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// SaveAlertRule 创建或更新告警规则
+func (c *AlertController) SaveAlertRule(ctx *gin.Context) {
+	var params AlertRuleRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Type == models.AlertIndexPEBelow && params.Threshold == 0 {
+		params.Threshold = models.DefaultAlertIndexPEPercentile
+	}
+	if params.Type == models.AlertStockPriceSpace && params.Direction == "" {
+		params.Direction = models.AlertDirectionBelow
+	}
+	if params.Name == "" {
+		params.Name = params.Type
+	}
+
+	result, err := c.service.SaveAlertRule(ctx, params)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAlertRule) || errors.Is(err, models.ErrAlertRuleNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("保存告警规则失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DeleteAlertRule 删除告警规则
+func (c *AlertController) DeleteAlertRule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", ErrInvalidParams))
+		return
+	}
+
+	if err := c.service.DeleteAlertRule(ctx, uint(id)); err != nil {
+		if errors.Is(err, models.ErrAlertRuleNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("删除告警规则失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(nil))
+}
+
+// EvaluateAlerts 立即评估告警规则
+func (c *AlertController) EvaluateAlerts(ctx *gin.Context) {
+	var params AlertEvaluateParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.EvaluateAlerts(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("评估告警规则失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
@@ -0,0 +1,77 @@
+// 告警 API 服务层
+package api
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// AlertService 告警服务
+type AlertService struct{}
+
+// NewAlertService 创建告警服务实例
+func NewAlertService() *AlertService {
+	return &AlertService{}
+}
+
+// ListAlerts 分页查询告警历史
+func (s *AlertService) ListAlerts(ctx context.Context, params models.ParamAlertList) (*AlertListResponse, error) {
+	params.UserID = auth.UserID(ctx)
+	alerts, total, err := models.ListAlerts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+	start := (params.PageNum - 1) * params.PageSize
+	return &AlertListResponse{
+		Alerts: alerts,
+		Pagination: PaginationResponse{
+			PageNum:    params.PageNum,
+			PageSize:   params.PageSize,
+			Total:      int(total),
+			TotalPages: totalPages,
+			StartIndex: start,
+			EndIndex:   start + len(alerts),
+		},
+	}, nil
+}
+
+// ListAlertRules 获取当前用户的全部告警规则
+func (s *AlertService) ListAlertRules(ctx context.Context) ([]models.AlertRuleDB, error) {
+	return models.ListUserAlertRules(ctx, auth.UserID(ctx))
+}
+
+// SaveAlertRule 创建或更新告警规则
+func (s *AlertService) SaveAlertRule(ctx context.Context, params AlertRuleRequest) (*models.AlertRuleDB, error) {
+	rule := params.AlertRuleDB
+	rule.UserID = auth.UserID(ctx)
+	rule.Enabled = params.Enabled == nil || *params.Enabled
+	if rule.ID > 0 {
+		existing, err := models.GetAlertRule(ctx, rule.UserID, rule.ID)
+		if err != nil {
+			return nil, err
+		}
+		rule.CreatedAt = existing.CreatedAt
+	}
+	if err := models.SaveAlertRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteAlertRule 删除告警规则
+func (s *AlertService) DeleteAlertRule(ctx context.Context, id uint) error {
+	return models.DeleteAlertRule(ctx, auth.UserID(ctx), id)
+}
+
+// EvaluateAlerts 立即评估告警规则
+func (s *AlertService) EvaluateAlerts(ctx context.Context, params AlertEvaluateParams) (*core.AlertEvaluation, error) {
+	return core.EvaluateAlerts(ctx, goutils.SplitStringFields(params.Types)...)
+}
@@ -0,0 +1,23 @@
+// 告警 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// AlertListResponse 告警历史响应
+type AlertListResponse struct {
+	Alerts     []models.AlertDB   `json:"alerts"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// AlertRuleRequest 创建或更新告警规则请求参数
+type AlertRuleRequest struct {
+	models.AlertRuleDB
+	// 是否启用，为空时启用
+	Enabled *bool `json:"enabled"`
+}
+
+// AlertEvaluateParams 手动评估告警规则请求参数
+type AlertEvaluateParams struct {
+	// 规则类型，逗号分隔，为空时评估全部启用规则
+	Types string `json:"types" form:"types"`
+}
@@ -0,0 +1,164 @@
+// 用户认证 API 控制器
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthController 用户认证控制器
+type AuthController struct {
+	service *AuthService
+}
+
+// NewAuthController 创建用户认证控制器
+func NewAuthController() *AuthController {
+	return &AuthController{
+		service: NewAuthService(),
+	}
+}
+
+// Register 注册用户
+func (c *AuthController) Register(ctx *gin.Context) {
+	var params AuthRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.Register(ctx, params)
+	if err != nil {
+		if errors.Is(err, auth.ErrAuthDisabled) || errors.Is(err, auth.ErrRegisterDisabled) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse(http.StatusForbidden, "未开放注册", err))
+			return
+		}
+		if errors.Is(err, models.ErrInvalidUser) || errors.Is(err, models.ErrUserExists) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("注册失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// Login 登录，返回 JWT
+func (c *AuthController) Login(ctx *gin.Context) {
+	var params AuthRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.Login(ctx, params)
+	if err != nil {
+		if errors.Is(err, auth.ErrAuthDisabled) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse(http.StatusForbidden, "未开启认证", err))
+			return
+		}
+		if err == ErrLoginFailed {
+			ctx.JSON(http.StatusUnauthorized, ErrorResponse(http.StatusUnauthorized, "登录失败", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("登录失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// Me 获取当前登录用户
+func (c *AuthController) Me(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, SuccessResponse(auth.CurrentUser(ctx)))
+}
+
+// ListAPITokens 获取当前用户的 API token
+func (c *AuthController) ListAPITokens(ctx *gin.Context) {
+	result, err := c.service.ListAPITokens(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取 API token 失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// CreateAPIToken 创建 API token，明文 token 只在创建时返回
+func (c *AuthController) CreateAPIToken(ctx *gin.Context) {
+	var params APITokenRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.CreateAPIToken(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("创建 API token 失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DeleteAPIToken 删除 API token
+func (c *AuthController) DeleteAPIToken(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", ErrInvalidParams))
+		return
+	}
+
+	if err := c.service.DeleteAPIToken(ctx, uint(id)); err != nil {
+		if errors.Is(err, models.ErrAPITokenNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("删除 API token 失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(nil))
+}
+
+// ListUsers 获取全部用户
+func (c *AuthController) ListUsers(ctx *gin.Context) {
+	result, err := c.service.ListUsers(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取用户列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// UpdateUserRole 修改用户角色
+func (c *AuthController) UpdateUserRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", ErrInvalidParams))
+		return
+	}
+	var params UserRoleRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.UpdateUserRole(ctx, uint(id), params.Role)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidUser) || errors.Is(err, models.ErrUserNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("修改用户角色失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
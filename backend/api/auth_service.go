@@ -0,0 +1,110 @@
+// 用户认证 API 服务层
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrLoginFailed 用户名或密码错误
+var ErrLoginFailed = errors.New("用户名或密码错误")
+
+// AuthService 用户认证服务
+type AuthService struct{}
+
+// NewAuthService 创建用户认证服务实例
+func NewAuthService() *AuthService {
+	return &AuthService{}
+}
+
+// Register 注册用户，未开启认证或未开放注册时不能注册
+func (s *AuthService) Register(ctx context.Context, params AuthRequest) (*models.UserDB, error) {
+	if !auth.Settings.Enabled {
+		return nil, auth.ErrAuthDisabled
+	}
+	if !auth.Settings.AllowRegister {
+		return nil, auth.ErrRegisterDisabled
+	}
+	hash, err := auth.HashPassword(params.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.UserDB{Username: params.Username, PasswordHash: hash, Role: auth.Settings.DefaultRole}
+	if err := models.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login 校验用户名密码并签发 JWT，未开启认证时不能登录
+func (s *AuthService) Login(ctx context.Context, params AuthRequest) (*LoginResponse, error) {
+	if !auth.Settings.Enabled {
+		return nil, auth.ErrAuthDisabled
+	}
+	user, err := models.GetUserByName(ctx, params.Username)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, ErrLoginFailed
+		}
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, params.Password) {
+		return nil, ErrLoginFailed
+	}
+	token, expiresAt, err := auth.IssueJWT(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.TouchUserLogin(ctx, user.ID); err != nil {
+		logrus.WithContext(ctx).Warnf("TouchUserLogin id:%d error:%v", user.ID, err)
+	}
+	return &LoginResponse{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// ListAPITokens 获取当前用户的 API token
+func (s *AuthService) ListAPITokens(ctx context.Context) ([]models.APITokenDB, error) {
+	return models.ListAPITokens(ctx, auth.UserID(ctx))
+}
+
+// CreateAPIToken 为当前用户创建 API token
+func (s *AuthService) CreateAPIToken(ctx context.Context, params APITokenRequest) (*APITokenResponse, error) {
+	plain, prefix, err := auth.GenerateAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	token := models.APITokenDB{
+		UserID:    auth.UserID(ctx),
+		Name:      params.Name,
+		TokenHash: auth.HashAPIToken(plain),
+		Prefix:    prefix,
+		CreatedAt: time.Now(),
+	}
+	if params.ExpiresDays > 0 {
+		expiresAt := token.CreatedAt.AddDate(0, 0, params.ExpiresDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := models.CreateAPIToken(ctx, &token); err != nil {
+		return nil, err
+	}
+	return &APITokenResponse{APITokenDB: token, Token: plain}, nil
+}
+
+// DeleteAPIToken 删除当前用户的 API token
+func (s *AuthService) DeleteAPIToken(ctx context.Context, id uint) error {
+	return models.DeleteAPIToken(ctx, auth.UserID(ctx), id)
+}
+
+// ListUsers 获取全部用户
+func (s *AuthService) ListUsers(ctx context.Context) ([]models.UserDB, error) {
+	return models.ListUsers(ctx)
+}
+
+// UpdateUserRole 修改用户角色
+func (s *AuthService) UpdateUserRole(ctx context.Context, id uint, role string) (*models.UserDB, error) {
+	return models.UpdateUserRole(ctx, id, role)
+}
@@ -0,0 +1,39 @@
+// 用户认证 API 请求参数和响应结构体定义
+package api
+
+import (
+	"time"
+
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// AuthRequest 注册和登录请求参数
+type AuthRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse 登录响应
+type LoginResponse struct {
+	Token     string         `json:"token"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	User      *models.UserDB `json:"user"`
+}
+
+// APITokenRequest 创建 API token 请求参数
+type APITokenRequest struct {
+	Name string `json:"name" binding:"required"`
+	// 有效天数，为 0 时永不过期
+	ExpiresDays int `json:"expires_days" binding:"min=0"`
+}
+
+// APITokenResponse 创建 API token 响应，明文 token 只返回一次
+type APITokenResponse struct {
+	models.APITokenDB
+	Token string `json:"token"`
+}
+
+// UserRoleRequest 修改用户角色请求参数
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
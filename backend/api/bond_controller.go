@@ -0,0 +1,116 @@
+// 债券收益率 API 控制器
+package api
+
+import (
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/datacenter/chinabond"
+	"github.com/gin-gonic/gin"
+)
+
+// BondController 债券收益率控制器
+type BondController struct {
+	service *BondService
+}
+
+// NewBondController 创建债券收益率控制器
+func NewBondController() *BondController {
+	return &BondController{
+		service: NewBondService(),
+	}
+}
+
+// GetBondCurves 获取全部收益率曲线名称
+func (c *BondController) GetBondCurves(ctx *gin.Context) {
+	result, err := c.service.GetBondCurves(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取收益率曲线列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetBondCurve 获取指定日期的收益率曲线
+func (c *BondController) GetBondCurve(ctx *gin.Context) {
+	var params BondCurveParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Name == "" {
+		params.Name = chinabond.CurveTreasury
+	}
+
+	result, err := c.service.GetBondCurve(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams || err == ErrDataNotFound {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取收益率曲线失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetBondYields 获取收益率时间序列
+func (c *BondController) GetBondYields(ctx *gin.Context) {
+	var params BondYieldsParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Name == "" {
+		params.Name = chinabond.CurveTreasury
+	}
+	if params.Tenor == 0 {
+		params.Tenor = 10
+	}
+
+	result, err := c.service.GetBondYields(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取收益率序列失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetBondSpread 获取曲线利差序列
+func (c *BondController) GetBondSpread(ctx *gin.Context) {
+	var params BondSpreadParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Base == "" {
+		params.Base = chinabond.CurveTreasury
+	}
+	if params.Tenor == 0 {
+		params.Tenor = 10
+	}
+
+	result, err := c.service.GetBondSpread(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取收益率利差失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
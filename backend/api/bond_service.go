@@ -0,0 +1,83 @@
+// 债券收益率 API 服务层
+package api
+
+import (
+	"context"
+	"sort"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// BondService 债券收益率服务
+type BondService struct{}
+
+// NewBondService 创建债券收益率服务实例
+func NewBondService() *BondService {
+	return &BondService{}
+}
+
+// GetBondCurves 获取全部收益率曲线名称
+func (s *BondService) GetBondCurves(ctx context.Context) ([]string, error) {
+	curves, err := datacenter.ChinaBond.QueryCurveList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range curves {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetBondCurve 获取指定日期的收益率曲线
+func (s *BondService) GetBondCurve(ctx context.Context, params BondCurveParams) (*BondCurveResponse, error) {
+	if params.Name == "" {
+		return nil, ErrInvalidParams
+	}
+	curve, err := models.GetBondYieldCurve(ctx, params.Name, params.Date)
+	if err == models.ErrBondYieldNotFound {
+		return nil, ErrDataNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BondCurveResponse{
+		YieldCurve:   curve,
+		RiskFreeRate: models.RiskFreeRate(ctx),
+	}, nil
+}
+
+// GetBondYields 获取指定曲线某期限的收益率时间序列
+func (s *BondService) GetBondYields(ctx context.Context, params BondYieldsParams) (*BondYieldsResponse, error) {
+	if params.Name == "" {
+		return nil, ErrInvalidParams
+	}
+	yields, err := models.LoadBondYieldSeries(ctx, params.Name, params.Tenor, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	return &BondYieldsResponse{
+		Name:   params.Name,
+		Tenor:  params.Tenor,
+		Yields: yields,
+	}, nil
+}
+
+// GetBondSpread 获取两条曲线同期限利差序列
+func (s *BondService) GetBondSpread(ctx context.Context, params BondSpreadParams) (*BondSpreadResponse, error) {
+	if params.Name == "" || params.Base == "" {
+		return nil, ErrInvalidParams
+	}
+	spreads, err := models.BondYieldSpread(ctx, params.Name, params.Base, params.Tenor, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	return &BondSpreadResponse{
+		Name:    params.Name,
+		Base:    params.Base,
+		Tenor:   params.Tenor,
+		Spreads: spreads,
+	}, nil
+}
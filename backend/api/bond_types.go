@@ -0,0 +1,63 @@
+// 债券收益率相关 API 请求参数和响应结构体定义
+package api
+
+import (
+	"github.com/axiaoxin-com/investool/datacenter/chinabond"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// BondCurveParams 收益率曲线请求参数
+type BondCurveParams struct {
+	// 曲线名称: 中债国债收益率曲线
+	Name string `json:"name" form:"name"`
+	// 日期: 2021-11-19，为空时返回最新曲线
+	Date string `json:"date" form:"date"`
+}
+
+// BondYieldsParams 收益率时间序列请求参数
+type BondYieldsParams struct {
+	// 曲线名称
+	Name string `json:"name"  form:"name"`
+	// 期限（年）
+	Tenor float64 `json:"tenor" form:"tenor" binding:"min=0"`
+	// 开始日期
+	Start string `json:"start" form:"start"`
+	// 结束日期
+	End string `json:"end"   form:"end"`
+}
+
+// BondYieldsResponse 收益率时间序列响应
+type BondYieldsResponse struct {
+	Name   string                  `json:"name"`
+	Tenor  float64                 `json:"tenor"`
+	Yields []models.BondYieldPoint `json:"yields"`
+}
+
+// BondSpreadParams 曲线利差请求参数
+type BondSpreadParams struct {
+	// 曲线名称
+	Name string `json:"name"  form:"name"`
+	// 基准曲线名称，默认国债
+	Base string `json:"base"  form:"base"`
+	// 期限（年）
+	Tenor float64 `json:"tenor" form:"tenor" binding:"min=0"`
+	// 开始日期
+	Start string `json:"start" form:"start"`
+	// 结束日期
+	End string `json:"end"   form:"end"`
+}
+
+// BondSpreadResponse 曲线利差响应
+type BondSpreadResponse struct {
+	Name    string                        `json:"name"`
+	Base    string                        `json:"base"`
+	Tenor   float64                       `json:"tenor"`
+	Spreads []models.BondYieldSpreadPoint `json:"spreads"`
+}
+
+// BondCurveResponse 收益率曲线响应
+type BondCurveResponse struct {
+	chinabond.YieldCurve
+	// 当前使用的无风险利率（%）
+	RiskFreeRate float64 `json:"risk_free_rate"`
+}
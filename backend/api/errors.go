@@ -8,7 +8,9 @@ var (
 	ErrFundCodeRequired  = errors.New("基金代码不能为空")
 	ErrFundCodesRequired = errors.New("基金代码列表不能为空")
 	ErrKeywordsRequired  = errors.New("关键词不能为空")
+	ErrStockCodeRequired = errors.New("股票代码不能为空")
 	ErrTooManyFunds      = errors.New("基金数量超过限制")
+	ErrTooManyStocks     = errors.New("股票数量超过限制")
 	ErrInvalidParams     = errors.New("参数无效")
 	ErrDataNotFound      = errors.New("数据不存在")
 	ErrInternalError     = errors.New("内部服务器错误")
@@ -2,8 +2,12 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -54,6 +58,14 @@ func (c *FundController) GetFundFilter(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
 		return
 	}
+	if err := c.service.ApplyFilterPreset(ctx, &params); err != nil {
+		if errors.Is(err, models.ErrScreenPresetNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("加载预设失败", err))
+		return
+	}
 
 	// 设置默认值
 	if params.ParamFundListFilter.MinScale == 0 {
@@ -90,6 +102,10 @@ func (c *FundController) GetFundFilter(ctx *gin.Context) {
 
 	result, err := c.service.GetFundFilter(ctx, params)
 	if err != nil {
+		if errors.Is(err, models.ErrWatchlistNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("基金筛选失败", err))
 		return
 	}
@@ -99,10 +115,51 @@ func (c *FundController) GetFundFilter(ctx *gin.Context) {
 
 // CheckFund 基金检测
 func (c *FundController) CheckFund(ctx *gin.Context) {
+	params, ok := c.bindFundCheckParams(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.service.CheckFund(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || errors.Is(err, models.ErrWatchlistNotFound) || errors.Is(err, models.ErrInvalidWatchlist) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("基金检测失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// CheckFundStream 基金检测，以 SSE 逐个返回查询到的基金和检测结果
+// 事件：start、progress、fund（Fund）、stock_check（FundStocksCheckEvent）、attribution（FundAttributionEvent）、done（FundCheckSummary）、error
+func (c *FundController) CheckFundStream(ctx *gin.Context) {
+	params, ok := c.bindFundCheckParams(ctx)
+	if !ok {
+		return
+	}
+
+	stream(ctx, "基金检测失败", func(sctx context.Context, emit emitFunc) (interface{}, error) {
+		return c.service.CheckFundStream(sctx, params, emit)
+	})
+}
+
+// bindFundCheckParams 绑定基金检测参数并设置默认值，失败时返回错误响应
+func (c *FundController) bindFundCheckParams(ctx *gin.Context) (FundCheckParams, bool) {
 	var params FundCheckParams
 	if err := ctx.ShouldBind(&params); err != nil {
 		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
-		return
+		return params, false
+	}
+	if err := c.service.ApplyCheckPreset(ctx, &params); err != nil {
+		if errors.Is(err, models.ErrScreenPresetNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return params, false
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("加载预设失败", err))
+		return params, false
 	}
 
 	// 设置默认值
@@ -136,14 +193,7 @@ func (c *FundController) CheckFund(ctx *gin.Context) {
 	if params.Max135AvgRetr == 0 {
 		params.Max135AvgRetr = 25.0
 	}
-
-	result, err := c.service.CheckFund(ctx, params)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("基金检测失败", err))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, SuccessResponse(result))
+	return params, true
 }
 
 // GetFundManagers 基金经理筛选
@@ -203,6 +253,24 @@ func (c *FundController) GetFundSimilarity(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, SuccessResponse(result))
 }
 
+// GetFundSimilarityStream 基金持仓相似度，以 SSE 逐个返回查询到的基金
+// 事件：start、progress、fund（Fund）、done（相似度结果）、error
+func (c *FundController) GetFundSimilarityStream(ctx *gin.Context) {
+	var params FundSimilarityParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	if params.Codes == "" {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", ErrFundCodesRequired))
+		return
+	}
+
+	stream(ctx, "基金相似度检测失败", func(sctx context.Context, emit emitFunc) (interface{}, error) {
+		return c.service.GetFundSimilarityStream(sctx, params, emit)
+	})
+}
+
 // QueryByStock 股票选基
 func (c *FundController) QueryByStock(ctx *gin.Context) {
 	var params QueryByStockParams
@@ -219,3 +287,226 @@ func (c *FundController) QueryByStock(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, SuccessResponse(result))
 }
+
+// GetFundHoldings 获取基金某报告期的持仓
+func (c *FundController) GetFundHoldings(ctx *gin.Context) {
+	var params FundHoldingsParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.GetFundHoldings(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || err == ErrDataNotFound {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取基金持仓失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetFundHoldingsDiff 对比基金两个报告期的持仓
+func (c *FundController) GetFundHoldingsDiff(ctx *gin.Context) {
+	var params FundHoldingsDiffParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.GetFundHoldingsDiff(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || errors.Is(err, models.ErrFundHoldingPeriodsNotEnough) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("对比基金持仓失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetSmartMoney 获取4433基金最新报告期加仓和减仓最多的股票
+func (c *FundController) GetSmartMoney(ctx *gin.Context) {
+	var params SmartMoneyParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+
+	result, err := c.service.GetSmartMoney(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取4433基金调仓汇总失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetFundStyle 获取基金风格箱历史及风格漂移度
+func (c *FundController) GetFundStyle(ctx *gin.Context) {
+	var params FundStyleParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.GetFundStyle(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || errors.Is(err, models.ErrFundHoldingPeriodsNotEnough) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取基金风格失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetFundAttribution 基金风格分析和行业归因
+func (c *FundController) GetFundAttribution(ctx *gin.Context) {
+	var params FundAttributionParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Days == 0 {
+		params.Days = 500
+	}
+	if params.Window == 0 {
+		params.Window = 120
+	}
+	if params.Step == 0 {
+		params.Step = 20
+	}
+
+	result, err := c.service.GetFundAttribution(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || errors.Is(err, models.ErrStyleReturnsNotEnough) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("基金归因失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// BacktestFunds 基金回测
+func (c *FundController) BacktestFunds(ctx *gin.Context) {
+	var params FundBacktestRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Rule == "" {
+		params.Rule = models.BacktestRule4433
+	}
+	if params.TopN == 0 {
+		params.TopN = 5
+	}
+	if params.Rebalance == "" {
+		params.Rebalance = models.RebalanceQuarterly
+	}
+	if params.InitialCash == 0 {
+		params.InitialCash = 100000
+	}
+	if params.Benchmark == "" {
+		params.Benchmark = "000300"
+	}
+	if params.Start == "" {
+		params.Start = time.Now().AddDate(-3, 0, 0).Format("2006-01-02")
+	}
+
+	result, err := c.service.BacktestFunds(ctx, params)
+	if err != nil {
+		if err == ErrFundCodesRequired || err == ErrTooManyFunds || errors.Is(err, models.ErrInvalidBacktestParams) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("基金回测失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// SimulateDCA 基金定投模拟
+func (c *FundController) SimulateDCA(ctx *gin.Context) {
+	var params FundDCARequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Amount == 0 {
+		params.Amount = 1000
+	}
+	if params.Frequency == "" {
+		params.Frequency = models.DCAMonthly
+	}
+	if params.Start == "" {
+		params.Start = time.Now().AddDate(-3, 0, 0).Format("2006-01-02")
+	}
+	params.FundDCAParams.ReinvestDividends = params.ReinvestDividends == nil || *params.ReinvestDividends
+
+	result, err := c.service.SimulateDCA(ctx, params)
+	if err != nil {
+		if err == ErrFundCodeRequired || errors.Is(err, models.ErrInvalidDCAParams) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("定投模拟失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// PlanAllocation 资产配置调仓计划
+func (c *FundController) PlanAllocation(ctx *gin.Context) {
+	var params FundAllocationRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Policy == "" {
+		params.Policy = models.RebalancePolicyThreshold
+	}
+	if params.Band == 0 {
+		params.Band = 5
+	}
+	if params.Frequency == "" {
+		params.Frequency = models.RebalanceQuarterly
+	}
+
+	result, err := c.service.PlanAllocation(ctx, params)
+	if err != nil {
+		if err == ErrFundCodesRequired || err == ErrTooManyFunds || errors.Is(err, models.ErrInvalidAllocationParams) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("生成调仓计划失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
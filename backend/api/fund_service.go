@@ -4,8 +4,10 @@ package api
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/auth"
 	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
 	"github.com/axiaoxin-com/investool/models"
@@ -65,10 +67,7 @@ func (s *FundService) GetFundIndex(ctx context.Context, params FundIndexParams)
 	}
 
 	// 转换为基金列表
-	fundList := make([]*models.Fund, len(fundDBs))
-	for i, fd := range fundDBs {
-		fundList[i] = fd.ToFund()
-	}
+	fundList := models.ToFunds(fundDBs)
 
 	// 获取所有基金类型
 	var fundTypes []string
@@ -142,6 +141,15 @@ func (s *FundService) GetFundFilter(ctx context.Context, params FundFilterParams
 	// 应用筛选条件
 	filter := params.ParamFundListFilter
 
+	// 自选列表筛选
+	if params.Watchlist != "" {
+		watchlist, err := models.GetWatchlist(ctx, auth.UserID(ctx), params.Watchlist)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("code IN ?", watchlist.Codes())
+	}
+
 	// 基金类型筛选
 	if len(filter.Types) > 0 {
 		query = query.Where("type IN ?", filter.Types)
@@ -192,6 +200,21 @@ func (s *FundService) GetFundFilter(ctx context.Context, params FundFilterParams
 		query = query.Where("(max_retracement->>'avg_135')::float <= ?", filter.Max135AvgRetr)
 	}
 
+	// 风格筛选（使用各基金最新报告期的风格）
+	if len(filter.StyleBoxes) > 0 || filter.MaxStyleDrift > 0 {
+		latest := models.DB.Model(&models.FundStyleDB{}).Select("fund_code, MAX(report_date)").Group("fund_code")
+		subQuery := models.DB.Model(&models.FundStyleDB{}).
+			Where("(fund_code, report_date) IN (?)", latest).
+			Select("fund_code")
+		if len(filter.StyleBoxes) > 0 {
+			subQuery = subQuery.Where("box IN ?", filter.StyleBoxes)
+		}
+		if filter.MaxStyleDrift > 0 {
+			subQuery = subQuery.Where("drift <= ?", filter.MaxStyleDrift)
+		}
+		query = query.Where("code IN (?)", subQuery)
+	}
+
 	// 基金类型额外过滤
 	if params.ParamFundIndex.Type != "" {
 		query = query.Where("type = ?", params.ParamFundIndex.Type)
@@ -211,10 +234,7 @@ func (s *FundService) GetFundFilter(ctx context.Context, params FundFilterParams
 	}
 
 	// 转换为基金列表
-	fundList := make([]*models.Fund, len(fundDBs))
-	for i, fd := range fundDBs {
-		fundList[i] = fd.ToFund()
-	}
+	fundList := models.ToFunds(fundDBs)
 
 	// 获取基金类型
 	var fundTypes []string
@@ -250,15 +270,103 @@ func (s *FundService) GetFundFilter(ctx context.Context, params FundFilterParams
 	}, nil
 }
 
-// CheckFund 基金检测
+// ApplyFilterPreset 使用预设中的基金筛选条件替换请求中的 filter
+func (s *FundService) ApplyFilterPreset(ctx context.Context, params *FundFilterParams) error {
+	if params.Preset == "" {
+		return nil
+	}
+	preset, err := core.GetPreset(ctx, params.Preset)
+	if err != nil {
+		return err
+	}
+	params.ParamFundListFilter = preset.FundFilter
+	return nil
+}
+
+// ApplyCheckPreset 使用预设中的基金筛选条件和股票检测条件替换请求中的检测参数
+func (s *FundService) ApplyCheckPreset(ctx context.Context, params *FundCheckParams) error {
+	if params.Preset == "" {
+		return nil
+	}
+	preset, err := core.GetPreset(ctx, params.Preset)
+	if err != nil {
+		return err
+	}
+	filter := preset.FundFilter
+	params.MinScale = filter.MinScale
+	params.MaxScale = filter.MaxScale
+	params.MinManagerYears = filter.MinManagerYears
+	params.Year1RankRatio = filter.Year1RankRatio
+	params.ThisYear235RankRatio = filter.ThisYear235RankRatio
+	params.Month6RankRatio = filter.Month6RankRatio
+	params.Month3RankRatio = filter.Month3RankRatio
+	params.Max135AvgStddev = filter.Max135AvgStddev
+	params.Min135AvgSharp = filter.Min135AvgSharp
+	params.Max135AvgRetr = filter.Max135AvgRetr
+	params.StockCheckerOptions = preset.CheckerOptions
+	return nil
+}
+
+// CheckFund 基金检测，相同参数的并发请求共享同一次检测结果
 func (s *FundService) CheckFund(ctx context.Context, params FundCheckParams) (*FundCheckResponse, error) {
+	v, err := coalesce(ctx, "fund_check", params, func(ctx context.Context) (interface{}, error) {
+		return s.checkFund(ctx, params, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*FundCheckResponse), nil
+}
+
+// CheckFundStream 基金检测，通过 emit 逐个推送查询到的基金、持仓个股检测结果、归因结果和进度，返回结果汇总
+func (s *FundService) CheckFundStream(ctx context.Context, params FundCheckParams, emit emitFunc) (*FundCheckSummary, error) {
+	start := time.Now()
+	response, err := s.checkFund(ctx, params, emit)
+	if err != nil {
+		return nil, err
+	}
+	summary := &FundCheckSummary{
+		Param:            params,
+		FundCount:        len(response.Funds),
+		StockCheckCount:  len(response.StockCheckResults),
+		AttributionCount: len(response.Attributions),
+		NotFound:         []string{},
+	}
+	codes, err := models.ExpandWatchlistCodes(ctx, auth.UserID(ctx), models.WatchlistKindFund, goutils.SplitStringFields(params.Code))
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]bool{}
+	for _, fund := range response.Funds {
+		found[fund.Code] = true
+	}
+	for _, code := range codes {
+		if !found[code] {
+			summary.NotFound = append(summary.NotFound, code)
+		}
+	}
+	summary.Latency = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// checkFund 基金检测，emit 不为 nil 时推送处理过程
+func (s *FundService) checkFund(ctx context.Context, params FundCheckParams, emit emitFunc) (*FundCheckResponse, error) {
 	if params.Code == "" {
 		return nil, ErrFundCodeRequired
 	}
 
-	codes := goutils.SplitStringFields(params.Code)
+	codes, err := models.ExpandWatchlistCodes(ctx, auth.UserID(ctx), models.WatchlistKindFund, goutils.SplitStringFields(params.Code))
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, ErrFundCodeRequired
+	}
+	emit.send(StreamEventStart, StreamProgress{Stage: "funds", Total: len(codes)})
 	searcher := core.NewSearcher(ctx)
-	fundsMap, err := searcher.SearchFunds(ctx, codes)
+	fundsMap, err := searcher.SearchFundsEach(ctx, codes, func(fund *models.Fund) {
+		emit.send("fund", fund)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -267,6 +375,7 @@ func (s *FundService) CheckFund(ctx context.Context, params FundCheckParams) (*F
 	for _, fund := range fundsMap {
 		funds = append(funds, fund)
 	}
+	emit.progress("funds", len(funds), len(codes))
 
 	response := &FundCheckResponse{
 		Funds: funds,
@@ -283,19 +392,23 @@ func (s *FundService) CheckFund(ctx context.Context, params FundCheckParams) (*F
 		checker := core.NewChecker(ctx, params.StockCheckerOptions)
 		var wg sync.WaitGroup
 		var mu sync.Mutex
+		done := 0
 
 		for _, fund := range funds {
 			wg.Add(1)
 			go func(fund *models.Fund) {
 				defer wg.Done()
 				checkResult, err := checker.CheckFundStocks(ctx, fund)
+				mu.Lock()
+				defer mu.Unlock()
+				done++
 				if err != nil {
 					logrus.WithContext(ctx).Errorf("CheckFundStocks code:%s err:%v", fund.Code, err)
-					return
+				} else {
+					stockCheckResults[fund.Code] = checkResult
+					emit.send("stock_check", FundStocksCheckEvent{Code: fund.Code, Result: checkResult})
 				}
-				mu.Lock()
-				stockCheckResults[fund.Code] = checkResult
-				mu.Unlock()
+				emit.progress("stocks", done, len(funds))
 			}(fund)
 		}
 		wg.Wait()
@@ -303,6 +416,50 @@ func (s *FundService) CheckFund(ctx context.Context, params FundCheckParams) (*F
 		response.StockCheckResults = stockCheckResults
 	}
 
+	// 如果需要计算风格分析和行业归因
+	if params.CheckAttribution {
+		if len(funds) > 50 {
+			return nil, ErrTooManyFunds
+		}
+
+		// 指数行情只获取一次，各基金共用
+		benchmarks, err := models.GetAttributionBenchmarks(ctx, 500)
+		if err != nil {
+			return nil, err
+		}
+		attributions := map[string]*models.FundAttribution{}
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		done := 0
+
+		// 限制并发，避免被接口限流
+		semaphore := make(chan struct{}, 4)
+		for _, fund := range funds {
+			wg.Add(1)
+			go func(fund *models.Fund) {
+				defer func() {
+					wg.Done()
+					<-semaphore
+				}()
+				semaphore <- struct{}{}
+				attribution, err := models.GetFundAttributionWithBenchmarks(ctx, fund.Code, 500, 120, 20, benchmarks)
+				mu.Lock()
+				defer mu.Unlock()
+				done++
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("GetFundAttribution code:%s err:%v", fund.Code, err)
+				} else {
+					attributions[fund.Code] = attribution
+					emit.send("attribution", FundAttributionEvent{Code: fund.Code, Attribution: attribution})
+				}
+				emit.progress("attribution", done, len(funds))
+			}(fund)
+		}
+		wg.Wait()
+
+		response.Attributions = attributions
+	}
+
 	return response, nil
 }
 
@@ -403,6 +560,27 @@ func (s *FundService) GetFundSimilarity(ctx context.Context, params FundSimilari
 	return result, nil
 }
 
+// GetFundSimilarityStream 基金持仓相似度，通过 emit 逐个推送查询到的基金和进度，返回相似度结果
+func (s *FundService) GetFundSimilarityStream(ctx context.Context, params FundSimilarityParams, emit emitFunc) ([]core.FundStocksSimilarity, error) {
+	if params.Codes == "" {
+		return nil, ErrFundCodesRequired
+	}
+
+	codeList := goutils.SplitStringFields(params.Codes)
+	emit.send(StreamEventStart, StreamProgress{Stage: "funds", Total: len(codeList)})
+	searcher := core.NewSearcher(ctx)
+	done := 0
+	funds, err := searcher.SearchFundsEach(ctx, codeList, func(fund *models.Fund) {
+		done++
+		emit.send("fund", fund)
+		emit.progress("funds", done, len(codeList))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return core.FundsStocksSimilarity(funds), nil
+}
+
 // QueryByStock 股票选基
 func (s *FundService) QueryByStock(ctx context.Context, params QueryByStockParams) (interface{}, error) {
 	if params.Keywords == "" {
@@ -417,3 +595,109 @@ func (s *FundService) QueryByStock(ctx context.Context, params QueryByStockParam
 		"message":     "功能开发中",
 	}, nil
 }
+
+// GetFundHoldings 获取基金某报告期的持仓
+func (s *FundService) GetFundHoldings(ctx context.Context, params FundHoldingsParams) (*FundHoldingsResponse, error) {
+	if params.Code == "" {
+		return nil, ErrFundCodeRequired
+	}
+	periods, err := models.LoadFundHoldingPeriods(ctx, params.Code)
+	if err != nil {
+		return nil, err
+	}
+	period := params.Period
+	if period == "" {
+		if len(periods) == 0 {
+			return nil, ErrDataNotFound
+		}
+		period = periods[0]
+	}
+	holdings, err := models.LoadFundHoldings(ctx, params.Code, period)
+	if err != nil {
+		return nil, err
+	}
+	return &FundHoldingsResponse{
+		Code:     params.Code,
+		Period:   period,
+		Periods:  periods,
+		Holdings: holdings,
+	}, nil
+}
+
+// GetFundHoldingsDiff 对比基金两个报告期的持仓
+func (s *FundService) GetFundHoldingsDiff(ctx context.Context, params FundHoldingsDiffParams) (*models.FundHoldingDiff, error) {
+	if params.Code == "" {
+		return nil, ErrFundCodeRequired
+	}
+	diff, err := models.GetFundHoldingDiff(ctx, params.Code, params.From, params.To)
+	if err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// GetSmartMoney 获取4433基金最新报告期的调仓汇总
+func (s *FundService) GetSmartMoney(ctx context.Context, params SmartMoneyParams) (*models.SmartMoneyReport, error) {
+	return models.QuerySmartMoney(ctx, params.Limit)
+}
+
+// GetFundStyle 获取基金各报告期的风格箱及风格漂移度
+func (s *FundService) GetFundStyle(ctx context.Context, params FundStyleParams) (*models.FundStyleHistory, error) {
+	if params.Code == "" {
+		return nil, ErrFundCodeRequired
+	}
+	if !params.Refresh {
+		history, err := models.LoadFundStyleHistory(ctx, params.Code)
+		if err != nil {
+			logrus.WithContext(ctx).Debug("GetFundStyle LoadFundStyleHistory err:" + err.Error())
+		} else if len(history.Styles) > 0 {
+			return &history, nil
+		}
+	}
+	history, err := models.GetFundStyleHistory(ctx, params.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// GetFundAttribution 基金风格分析和行业归因
+func (s *FundService) GetFundAttribution(ctx context.Context, params FundAttributionParams) (*models.FundAttribution, error) {
+	if params.Code == "" {
+		return nil, ErrFundCodeRequired
+	}
+	return models.GetFundAttribution(ctx, params.Code, params.Days, params.Window, params.Step)
+}
+
+// BacktestFunds 按选基规则定期调仓回测
+func (s *FundService) BacktestFunds(ctx context.Context, params FundBacktestRequest) (*models.FundBacktestResult, error) {
+	// 从接口获取净值时需要指定基金池并限制数量
+	if params.Fetch {
+		if len(params.Codes) == 0 {
+			return nil, ErrFundCodesRequired
+		}
+		if len(params.Codes) > 50 {
+			return nil, ErrTooManyFunds
+		}
+	}
+	return models.BacktestFunds(ctx, params.FundBacktestParams, params.Fetch)
+}
+
+// SimulateDCA 基金定投模拟
+func (s *FundService) SimulateDCA(ctx context.Context, params FundDCARequest) (*models.FundDCAResult, error) {
+	if params.Code == "" {
+		return nil, ErrFundCodeRequired
+	}
+	return models.SimulateFundDCA(ctx, params.FundDCAParams)
+}
+
+// PlanAllocation 生成资产配置调仓计划
+func (s *FundService) PlanAllocation(ctx context.Context, params FundAllocationRequest) (*models.AllocationPlan, error) {
+	if len(params.Targets) == 0 {
+		return nil, ErrFundCodesRequired
+	}
+	if len(params.Targets)+len(params.Holdings)+len(params.Candidates) > 50 {
+		return nil, ErrTooManyFunds
+	}
+	return models.BuildAllocationPlan(ctx, params.AllocationParams)
+}
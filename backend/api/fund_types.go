@@ -29,10 +29,15 @@ type FundIndexResponse struct {
 type FundFilterParams struct {
 	ParamFundListFilter models.ParamFundListFilter `json:"filter"`
 	ParamFundIndex      FundIndexParams            `json:"index"`
+	// 使用保存的预设中的基金筛选条件，指定后忽略 filter
+	Preset string `json:"preset" form:"preset"`
+	// 只在该自选列表的基金中筛选
+	Watchlist string `json:"watchlist" form:"watchlist"`
 }
 
 // FundCheckParams 基金检测请求参数
 type FundCheckParams struct {
+	// 基金代码，多个使用逗号分隔，watchlist:<name> 表示自选列表中的全部基金
 	Code                 string              `json:"fundcode" binding:"required"`
 	MinScale             float64             `json:"min_scale"`
 	MaxScale             float64             `json:"max_scale"`
@@ -45,7 +50,10 @@ type FundCheckParams struct {
 	Min135AvgSharp       float64             `json:"min_135_avg_sharp"`
 	Max135AvgRetr        float64             `json:"max_135_avg_retr"`
 	CheckStocks          bool                `json:"check_stocks"`
+	CheckAttribution     bool                `json:"check_attribution"`
 	StockCheckerOptions  core.CheckerOptions `json:"stock_checker_options"`
+	// 使用预设中的基金筛选条件和股票检测条件，指定后忽略对应参数
+	Preset string `json:"preset"`
 }
 
 // FundCheckResponse 基金检测响应
@@ -53,6 +61,31 @@ type FundCheckResponse struct {
 	Funds             []*models.Fund                        `json:"funds"`
 	Param             FundCheckParams                       `json:"param"`
 	StockCheckResults map[string]core.FundStocksCheckResult `json:"stock_check_results,omitempty"`
+	Attributions      map[string]*models.FundAttribution    `json:"attributions,omitempty"`
+}
+
+// FundStocksCheckEvent 基金检测流式响应中单只基金的持仓个股检测结果
+type FundStocksCheckEvent struct {
+	Code   string                     `json:"code"`
+	Result core.FundStocksCheckResult `json:"result"`
+}
+
+// FundAttributionEvent 基金检测流式响应中单只基金的风格分析和行业归因
+type FundAttributionEvent struct {
+	Code        string                  `json:"code"`
+	Attribution *models.FundAttribution `json:"attribution"`
+}
+
+// FundCheckSummary 基金检测流式响应的结果汇总
+type FundCheckSummary struct {
+	Param            FundCheckParams `json:"param"`
+	FundCount        int             `json:"fund_count"`
+	StockCheckCount  int             `json:"stock_check_count"`
+	AttributionCount int             `json:"attribution_count"`
+	// 未查询到的基金代码
+	NotFound []string `json:"not_found"`
+	// 耗时（毫秒）
+	Latency int64 `json:"latency"`
 }
 
 // FundManagerParams 基金经理筛选参数
@@ -89,3 +122,75 @@ type FundSimilarityParams struct {
 type QueryByStockParams struct {
 	Keywords string `json:"keywords" form:"keywords" binding:"required"`
 }
+
+// FundHoldingsParams 基金持仓请求参数
+type FundHoldingsParams struct {
+	// 基金代码
+	Code string `json:"code"   form:"code"`
+	// 报告期，为空时使用最新报告期
+	Period string `json:"period" form:"period"`
+}
+
+// FundHoldingsResponse 基金持仓响应
+type FundHoldingsResponse struct {
+	Code   string `json:"code"`
+	Period string `json:"period"`
+	// 已保存的全部报告期，按日期降序
+	Periods  []string             `json:"periods"`
+	Holdings []models.FundStockDB `json:"holdings"`
+}
+
+// FundHoldingsDiffParams 基金持仓对比请求参数
+type FundHoldingsDiffParams struct {
+	// 基金代码
+	Code string `json:"code" form:"code"`
+	// 起始报告期，为空时使用结束报告期的上一期
+	From string `json:"from" form:"from"`
+	// 结束报告期，为空时使用最新报告期
+	To string `json:"to"   form:"to"`
+}
+
+// SmartMoneyParams 4433基金调仓汇总请求参数
+type SmartMoneyParams struct {
+	// 加仓和减仓各返回的股票数
+	Limit int `json:"limit" form:"limit" binding:"min=0,max=200"`
+}
+
+// FundStyleParams 基金风格请求参数
+type FundStyleParams struct {
+	// 基金代码
+	Code string `json:"code"    form:"code"`
+	// 是否按最新行情重新计算，默认优先返回数据库中的结果
+	Refresh bool `json:"refresh" form:"refresh"`
+}
+
+// FundAttributionParams 基金归因请求参数
+type FundAttributionParams struct {
+	// 基金代码
+	Code string `json:"code"   form:"code"`
+	// 使用最近多少个交易日的净值
+	Days int `json:"days"   form:"days"   binding:"min=0,max=1250"`
+	// 滚动窗口交易日数
+	Window int `json:"window" form:"window" binding:"min=0,max=250"`
+	// 滚动步长交易日数
+	Step int `json:"step"   form:"step"   binding:"min=0,max=250"`
+}
+
+// FundBacktestRequest 基金回测请求参数
+type FundBacktestRequest struct {
+	models.FundBacktestParams
+	// 是否从接口获取基金净值，默认只使用数据库中已保存的净值
+	Fetch bool `json:"fetch"`
+}
+
+// FundDCARequest 基金定投模拟请求参数
+type FundDCARequest struct {
+	models.FundDCAParams
+	// 分红方式，默认红利再投资
+	ReinvestDividends *bool `json:"reinvest_dividends"`
+}
+
+// FundAllocationRequest 资产配置调仓计划请求参数
+type FundAllocationRequest struct {
+	models.AllocationParams
+}
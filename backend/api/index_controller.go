@@ -0,0 +1,168 @@
+// 指数 API 控制器
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// IndexController 指数控制器
+type IndexController struct {
+	service *IndexService
+}
+
+// NewIndexController 创建指数控制器
+func NewIndexController() *IndexController {
+	return &IndexController{
+		service: NewIndexService(),
+	}
+}
+
+// GetIndexFunds 获取跟踪指数的基金及跟踪质量
+func (c *IndexController) GetIndexFunds(ctx *gin.Context) {
+	var params IndexFundsParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Code = ctx.Param("code")
+
+	// 设置默认值
+	if params.Days == 0 {
+		params.Days = 250
+	}
+
+	result, err := c.service.GetIndexFunds(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取指数跟踪基金失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndexValuations 获取已保存的指数估值历史
+func (c *IndexController) GetIndexValuations(ctx *gin.Context) {
+	var params IndexValuationsParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Code = ctx.Param("code")
+
+	result, err := c.service.GetIndexValuations(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取指数估值历史失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndexSignal 获取指数定投信号
+func (c *IndexController) GetIndexSignal(ctx *gin.Context) {
+	var params IndexSignalParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Code = ctx.Param("code")
+
+	// 设置默认值
+	if params.Amount == 0 {
+		params.Amount = 1000
+	}
+
+	result, err := c.service.GetIndexSignal(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams || errors.Is(err, models.ErrInvalidSignalBands) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取指数定投信号失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndexConstituents 获取指数成分股及权重
+func (c *IndexController) GetIndexConstituents(ctx *gin.Context) {
+	var params IndexConstituentsParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Code = ctx.Param("code")
+
+	result, err := c.service.GetIndexConstituents(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取指数成分股失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DiffIndexConstituents 对比指数成分股快照
+func (c *IndexController) DiffIndexConstituents(ctx *gin.Context) {
+	var params IndexConstituentsDiffParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Code = ctx.Param("code")
+
+	result, err := c.service.DiffIndexConstituents(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("对比指数成分股失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// IndexConstituentsSet 多指数成分股集合运算
+func (c *IndexController) IndexConstituentsSet(ctx *gin.Context) {
+	var params IndexConstituentsSetParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Op == "" {
+		params.Op = models.IndexSetOpIntersect
+	}
+
+	result, err := c.service.IndexConstituentsSet(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams || errors.Is(err, models.ErrInvalidIndexSetOp) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("指数成分股集合运算失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
@@ -0,0 +1,142 @@
+// 指数 API 服务层
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// IndexService 指数服务
+type IndexService struct{}
+
+// NewIndexService 创建指数服务实例
+func NewIndexService() *IndexService {
+	return &IndexService{}
+}
+
+// GetIndexFunds 获取跟踪指数的全部基金及跟踪质量
+func (s *IndexService) GetIndexFunds(ctx context.Context, params IndexFundsParams) (*core.IndexFundsResult, error) {
+	if params.Code == "" {
+		return nil, ErrInvalidParams
+	}
+	searcher := core.NewSearcher(ctx)
+	return searcher.SearchIndexFunds(ctx, params.Code, params.Days)
+}
+
+// GetIndexValuations 获取已保存的指数估值历史
+func (s *IndexService) GetIndexValuations(ctx context.Context, params IndexValuationsParams) (*IndexValuationsResponse, error) {
+	if params.Code == "" {
+		return nil, ErrInvalidParams
+	}
+	rows, err := models.LoadIndexValuations(ctx, params.Code, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexValuationsResponse{
+		IndexCode:  params.Code,
+		Valuations: rows,
+	}, nil
+}
+
+// GetIndexSignal 按当前估值百分位给出定投信号，可选在估值历史上回测
+func (s *IndexService) GetIndexSignal(ctx context.Context, params IndexSignalParams) (*IndexSignalResponse, error) {
+	if params.Code == "" {
+		return nil, ErrInvalidParams
+	}
+	bands := params.Bands()
+	if err := bands.Validate(); err != nil {
+		return nil, err
+	}
+	v, err := models.QueryIndexValuation(ctx, params.Code)
+	if err != nil {
+		return nil, err
+	}
+	resp := &IndexSignalResponse{
+		Signal: models.NewIndexSignal(v, bands),
+		Bands:  bands,
+	}
+	if !params.Backtest {
+		return resp, nil
+	}
+	history, err := models.LoadIndexValuations(ctx, params.Code, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	result, err := models.BacktestIndexSignal(history, bands, params.Amount)
+	if err != nil {
+		return nil, err
+	}
+	resp.Backtest = &result
+	return resp, nil
+}
+
+// GetIndexConstituents 获取指数成分股及权重，指定日期时从快照读取
+func (s *IndexService) GetIndexConstituents(ctx context.Context, params IndexConstituentsParams) (*models.IndexConstituentSnapshot, error) {
+	if params.Code == "" {
+		return nil, ErrInvalidParams
+	}
+	snapshot, err := loadIndexConstituents(ctx, params.Code, params.Date)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// DiffIndexConstituents 对比指数两个快照的成分股变化
+func (s *IndexService) DiffIndexConstituents(ctx context.Context, params IndexConstituentsDiffParams) (*models.IndexConstituentDiff, error) {
+	if params.Code == "" || params.From == "" {
+		return nil, ErrInvalidParams
+	}
+	from, err := models.LoadIndexConstituents(ctx, params.Code, params.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := models.LoadIndexConstituents(ctx, params.Code, params.To)
+	if err != nil {
+		return nil, err
+	}
+	diff := models.DiffIndexConstituents(from, to)
+	return &diff, nil
+}
+
+// IndexConstituentsSet 对多个指数的成分股做集合运算
+func (s *IndexService) IndexConstituentsSet(ctx context.Context, params IndexConstituentsSetParams) (*IndexConstituentsSetResponse, error) {
+	codes := []string{}
+	for _, code := range strings.Split(params.Codes, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) < 2 {
+		return nil, ErrInvalidParams
+	}
+	snapshots := []models.IndexConstituentSnapshot{}
+	for _, code := range codes {
+		snapshot, err := loadIndexConstituents(ctx, code, params.Date)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	members, err := models.IndexConstituentSet(params.Op, snapshots)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexConstituentsSetResponse{
+		IndexCodes: codes,
+		Op:         params.Op,
+		Total:      len(members),
+		Members:    members,
+	}, nil
+}
+
+// loadIndexConstituents 日期为空时实时获取成分股，否则读取快照
+func loadIndexConstituents(ctx context.Context, code, date string) (models.IndexConstituentSnapshot, error) {
+	if date == "" {
+		return models.GetIndexConstituents(ctx, code)
+	}
+	return models.LoadIndexConstituents(ctx, code, date)
+}
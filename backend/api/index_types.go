@@ -0,0 +1,121 @@
+// 指数相关 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// IndexFundsParams 指数跟踪基金请求参数
+type IndexFundsParams struct {
+	// 指数代码: 000300，来自路径参数
+	Code string `json:"code" uri:"code"`
+	// 统计最近的交易日数
+	Days int `json:"days" form:"days" binding:"min=0,max=500"`
+}
+
+// IndexValuationsParams 指数估值历史请求参数
+type IndexValuationsParams struct {
+	// 指数代码: 000300，来自路径参数
+	Code string `json:"code" uri:"code"`
+	// 开始日期
+	Start string `json:"start" form:"start"`
+	// 结束日期
+	End string `json:"end"   form:"end"`
+}
+
+// IndexValuationsResponse 指数估值历史响应
+type IndexValuationsResponse struct {
+	IndexCode  string                    `json:"index_code"`
+	Valuations []models.IndexValuationDB `json:"valuations"`
+}
+
+// IndexSignalParams 指数定投信号请求参数
+type IndexSignalParams struct {
+	// 指数代码: 000300，来自路径参数
+	Code string `json:"code" uri:"code"`
+	// 百分位指标: pe/pb
+	Metric string `json:"metric" form:"metric"`
+	// 加倍定投的百分位上限
+	IncreaseBelow float64 `json:"increase_below" form:"increase_below"`
+	// 正常定投的百分位上限
+	NormalBelow float64 `json:"normal_below" form:"normal_below"`
+	// 暂停定投的百分位上限
+	PauseBelow float64 `json:"pause_below" form:"pause_below"`
+	// 加倍定投的金额倍数
+	IncreaseMultiple float64 `json:"increase_multiple" form:"increase_multiple"`
+	// 每次止盈卖出的持仓比例
+	TakeProfitRatio float64 `json:"take_profit_ratio" form:"take_profit_ratio"`
+	// 是否在已保存的估值历史上回测
+	Backtest bool `json:"backtest" form:"backtest"`
+	// 回测每期基础定投金额
+	Amount float64 `json:"amount" form:"amount"`
+	// 回测开始日期
+	Start string `json:"start" form:"start"`
+	// 回测结束日期
+	End string `json:"end"   form:"end"`
+}
+
+// Bands 转换为信号区间，未设置的字段使用默认值
+func (p IndexSignalParams) Bands() models.SignalBands {
+	b := models.DefaultSignalBands()
+	if p.Metric != "" {
+		b.Metric = p.Metric
+	}
+	if p.IncreaseBelow > 0 {
+		b.IncreaseBelow = p.IncreaseBelow
+	}
+	if p.NormalBelow > 0 {
+		b.NormalBelow = p.NormalBelow
+	}
+	if p.PauseBelow > 0 {
+		b.PauseBelow = p.PauseBelow
+	}
+	if p.IncreaseMultiple > 0 {
+		b.IncreaseMultiple = p.IncreaseMultiple
+	}
+	if p.TakeProfitRatio > 0 {
+		b.TakeProfitRatio = p.TakeProfitRatio
+	}
+	return b
+}
+
+// IndexSignalResponse 指数定投信号响应
+type IndexSignalResponse struct {
+	Signal   models.IndexSignal           `json:"signal"`
+	Bands    models.SignalBands           `json:"bands"`
+	Backtest *models.SignalBacktestResult `json:"backtest,omitempty"`
+}
+
+// IndexConstituentsParams 指数成分股请求参数
+type IndexConstituentsParams struct {
+	// 指数代码: 000300，来自路径参数
+	Code string `json:"code" uri:"code"`
+	// 快照日期，为空时实时获取并保存当日快照
+	Date string `json:"date" form:"date"`
+}
+
+// IndexConstituentsDiffParams 指数成分股快照对比请求参数
+type IndexConstituentsDiffParams struct {
+	// 指数代码: 000300，来自路径参数
+	Code string `json:"code" uri:"code"`
+	// 起始快照日期
+	From string `json:"from" form:"from"`
+	// 结束快照日期，为空时使用最新快照
+	To string `json:"to"   form:"to"`
+}
+
+// IndexConstituentsSetParams 多指数成分股集合运算请求参数
+type IndexConstituentsSetParams struct {
+	// 指数代码，逗号分隔: 000300,000905
+	Codes string `json:"codes" form:"codes"`
+	// 运算: union/intersect/difference
+	Op string `json:"op"    form:"op"`
+	// 快照日期，为空时实时获取
+	Date string `json:"date"  form:"date"`
+}
+
+// IndexConstituentsSetResponse 多指数成分股集合运算响应
+type IndexConstituentsSetResponse struct {
+	IndexCodes []string                `json:"index_codes"`
+	Op         string                  `json:"op"`
+	Total      int                     `json:"total"`
+	Members    []models.IndexSetMember `json:"members"`
+}
@@ -0,0 +1,96 @@
+// 行业 API 控制器
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IndustryController 行业控制器
+type IndustryController struct {
+	service *IndustryService
+}
+
+// NewIndustryController 创建行业控制器
+func NewIndustryController() *IndustryController {
+	return &IndustryController{
+		service: NewIndustryService(),
+	}
+}
+
+// GetIndustryBoards 获取全部行业板块当前行情及估值
+func (c *IndustryController) GetIndustryBoards(ctx *gin.Context) {
+	result, err := c.service.GetIndustryBoards(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取行业板块失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndustrySeries 获取行业每日行情及估值序列
+func (c *IndustryController) GetIndustrySeries(ctx *gin.Context) {
+	var params IndustrySeriesParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.GetIndustrySeries(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取行业序列失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndustryAllocation 获取基金行业配置
+func (c *IndustryController) GetIndustryAllocation(ctx *gin.Context) {
+	var params IndustryAllocationParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Periods == 0 {
+		params.Periods = 4
+	}
+
+	result, err := c.service.GetIndustryAllocation(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取基金行业配置失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetIndustryRotation 获取行业轮动报告
+func (c *IndustryController) GetIndustryRotation(ctx *gin.Context) {
+	var params IndustryRotationParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Window == 0 {
+		params.Window = 20
+	}
+
+	result, err := c.service.GetIndustryRotation(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取行业轮动报告失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
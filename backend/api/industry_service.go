@@ -0,0 +1,72 @@
+// 行业 API 服务层
+package api
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// IndustryService 行业服务
+type IndustryService struct{}
+
+// NewIndustryService 创建行业服务实例
+func NewIndustryService() *IndustryService {
+	return &IndustryService{}
+}
+
+// GetIndustryBoards 获取全部行业板块当前行情及估值
+func (s *IndustryService) GetIndustryBoards(ctx context.Context) ([]eastmoney.IndustryBoard, error) {
+	return datacenter.EastMoney.QueryIndustryBoards(ctx)
+}
+
+// GetIndustrySeries 获取已保存的行业每日行情及估值序列
+func (s *IndustryService) GetIndustrySeries(ctx context.Context, params IndustrySeriesParams) (*IndustrySeriesResponse, error) {
+	if params.Board == "" {
+		return nil, ErrInvalidParams
+	}
+	series, err := models.LoadIndustryDailies(ctx, params.Board, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	return &IndustrySeriesResponse{
+		Board:  params.Board,
+		Series: series,
+	}, nil
+}
+
+// GetIndustryAllocation 获取全部基金按报告期汇总的行业配置
+func (s *IndustryService) GetIndustryAllocation(ctx context.Context, params IndustryAllocationParams) (*IndustryAllocationResponse, error) {
+	allocs, err := models.LoadFundIndustryAllocation(ctx, params.Periods)
+	if err != nil {
+		return nil, err
+	}
+	changes := models.IndustryAllocationChanges(allocs)
+	if params.Industry != "" {
+		filtered := []models.IndustryAllocation{}
+		for _, a := range allocs {
+			if a.Industry == params.Industry {
+				filtered = append(filtered, a)
+			}
+		}
+		allocs = filtered
+		filteredChanges := []models.IndustryAllocationChange{}
+		for _, c := range changes {
+			if c.Industry == params.Industry {
+				filteredChanges = append(filteredChanges, c)
+			}
+		}
+		changes = filteredChanges
+	}
+	return &IndustryAllocationResponse{
+		Allocations: allocs,
+		Changes:     changes,
+	}, nil
+}
+
+// GetIndustryRotation 获取行业轮动报告
+func (s *IndustryService) GetIndustryRotation(ctx context.Context, params IndustryRotationParams) (*models.IndustryRotationReport, error) {
+	return models.QueryIndustryRotation(ctx, params.Window)
+}
@@ -0,0 +1,42 @@
+// 行业相关 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// IndustrySeriesParams 行业每日行情及估值序列请求参数
+type IndustrySeriesParams struct {
+	// 行业板块代码或名称: BK0477/酿酒行业
+	Board string `json:"board" form:"board"`
+	// 开始日期
+	Start string `json:"start" form:"start"`
+	// 结束日期
+	End string `json:"end"   form:"end"`
+}
+
+// IndustrySeriesResponse 行业每日行情及估值序列响应
+type IndustrySeriesResponse struct {
+	Board  string                   `json:"board"`
+	Series []models.IndustryDailyDB `json:"series"`
+}
+
+// IndustryAllocationParams 基金行业配置请求参数
+type IndustryAllocationParams struct {
+	// 最近的报告期数
+	Periods int `json:"periods" form:"periods" binding:"min=0,max=20"`
+	// 只返回该行业，为空返回全部
+	Industry string `json:"industry" form:"industry"`
+}
+
+// IndustryAllocationResponse 基金行业配置响应
+type IndustryAllocationResponse struct {
+	// 按报告期汇总的行业配置
+	Allocations []models.IndustryAllocation `json:"allocations"`
+	// 最近两个报告期的配置变化
+	Changes []models.IndustryAllocationChange `json:"changes"`
+}
+
+// IndustryRotationParams 行业轮动请求参数
+type IndustryRotationParams struct {
+	// 计算相对强弱的交易日窗口
+	Window int `json:"window" form:"window" binding:"min=0,max=250"`
+}
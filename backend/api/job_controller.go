@@ -0,0 +1,42 @@
+// 任务 API 控制器
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/cron"
+	"github.com/gin-gonic/gin"
+)
+
+// JobController 任务控制器
+type JobController struct {
+	service *JobService
+}
+
+// NewJobController 创建任务控制器
+func NewJobController() *JobController {
+	return &JobController{
+		service: NewJobService(),
+	}
+}
+
+// ListJobs 获取可手动触发的任务
+func (c *JobController) ListJobs(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, SuccessResponse(c.service.ListJobs(ctx)))
+}
+
+// TriggerJob 手动触发任务，任务在后台执行
+func (c *JobController) TriggerJob(ctx *gin.Context) {
+	result, err := c.service.TriggerJob(ctx, ctx.Param("name"))
+	if err != nil {
+		if errors.Is(err, cron.ErrJobNotFound) || errors.Is(err, cron.ErrJobRunning) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("触发任务失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
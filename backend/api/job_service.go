@@ -0,0 +1,33 @@
+// 任务 API 服务层
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/cron"
+	"github.com/sirupsen/logrus"
+)
+
+// JobService 任务服务
+type JobService struct{}
+
+// NewJobService 创建任务服务实例
+func NewJobService() *JobService {
+	return &JobService{}
+}
+
+// ListJobs 获取可手动触发的任务名称
+func (s *JobService) ListJobs(ctx context.Context) []string {
+	return cron.JobNames()
+}
+
+// TriggerJob 在后台执行同步任务或定时报告
+func (s *JobService) TriggerJob(ctx context.Context, name string) (*JobTriggerResponse, error) {
+	if err := cron.TriggerJob(name); err != nil {
+		return nil, err
+	}
+	logrus.WithContext(ctx).Infof("job %s triggered by user:%d", name, auth.UserID(ctx))
+	return &JobTriggerResponse{Job: name, StartedAt: time.Now()}, nil
+}
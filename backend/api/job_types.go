@@ -0,0 +1,10 @@
+// 任务 API 请求参数和响应结构体定义
+package api
+
+import "time"
+
+// JobTriggerResponse 触发任务响应
+type JobTriggerResponse struct {
+	Job       string    `json:"job"`
+	StartedAt time.Time `json:"started_at"`
+}
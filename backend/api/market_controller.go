@@ -0,0 +1,63 @@
+// 市场温度 API 控制器
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarketController 市场温度控制器
+type MarketController struct {
+	service *MarketService
+}
+
+// NewMarketController 创建市场温度控制器
+func NewMarketController() *MarketController {
+	return &MarketController{
+		service: NewMarketService(),
+	}
+}
+
+// GetMarketTemperature 获取市场温度
+func (c *MarketController) GetMarketTemperature(ctx *gin.Context) {
+	var params MarketTemperatureParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.GetMarketTemperature(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取市场温度失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetMarketTemperatureHistory 获取指数温度历史
+func (c *MarketController) GetMarketTemperatureHistory(ctx *gin.Context) {
+	var params MarketTemperatureHistoryParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.IndexCode == "" {
+		params.IndexCode = "000300"
+	}
+
+	result, err := c.service.GetMarketTemperatureHistory(ctx, params)
+	if err != nil {
+		if err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取指数温度历史失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
@@ -0,0 +1,46 @@
+// 市场温度 API 服务层
+package api
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// MarketService 市场温度服务
+type MarketService struct{}
+
+// NewMarketService 创建市场温度服务实例
+func NewMarketService() *MarketService {
+	return &MarketService{}
+}
+
+// GetMarketTemperature 获取市场温度，指定日期时从数据库读取，未指定日期时优先返回当天已计算的结果
+func (s *MarketService) GetMarketTemperature(ctx context.Context, params MarketTemperatureParams) (*models.MarketTemperature, error) {
+	var temp models.MarketTemperature
+	var err error
+	if params.Date != "" {
+		temp, err = models.LoadMarketTemperature(ctx, params.Date)
+	} else {
+		temp, err = models.GetMarketTemperature(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &temp, nil
+}
+
+// GetMarketTemperatureHistory 获取指数温度历史
+func (s *MarketService) GetMarketTemperatureHistory(ctx context.Context, params MarketTemperatureHistoryParams) (*MarketTemperatureHistoryResponse, error) {
+	if params.IndexCode == "" {
+		return nil, ErrInvalidParams
+	}
+	history, err := models.LoadIndexTemperatureHistory(ctx, params.IndexCode, params.Start, params.End)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketTemperatureHistoryResponse{
+		IndexCode: params.IndexCode,
+		History:   history,
+	}, nil
+}
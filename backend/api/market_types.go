@@ -0,0 +1,26 @@
+// 市场温度相关 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// MarketTemperatureParams 市场温度请求参数
+type MarketTemperatureParams struct {
+	// 日期: 2023-01-03，为空时返回当天已计算的结果，当天未计算时实时计算
+	Date string `json:"date" form:"date"`
+}
+
+// MarketTemperatureHistoryParams 指数温度历史请求参数
+type MarketTemperatureHistoryParams struct {
+	// 指数代码: 000300
+	IndexCode string `json:"index_code" form:"index_code"`
+	// 开始日期
+	Start string `json:"start"      form:"start"`
+	// 结束日期
+	End string `json:"end"        form:"end"`
+}
+
+// MarketTemperatureHistoryResponse 指数温度历史响应
+type MarketTemperatureHistoryResponse struct {
+	IndexCode string                    `json:"index_code"`
+	History   []models.IndexTemperature `json:"history"`
+}
@@ -0,0 +1,151 @@
+// 高成本接口的请求成本计算和相同请求合并
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// fundStocksCheckCost 检测基金持仓个股时每只基金的额外成本，对应最多 10 只持仓股的查询和检测
+	fundStocksCheckCost = 10
+	// fundAttributionCost 计算风格分析和行业归因时每只基金的额外成本
+	fundAttributionCost = 1
+	// stockSelectCost 选股需要获取筛选结果中全部股票的数据，按固定成本计算
+	stockSelectCost = 300
+	// indexFundsCost 指数跟踪基金需要获取全部跟踪基金的净值，按固定成本计算
+	indexFundsCost = 30
+	// fundStyleRefreshCost 重新计算基金风格需要获取全部持仓股的行情和估值
+	fundStyleRefreshCost = 10
+)
+
+// maxPeekBodyBytes 计算请求成本时读取的请求体大小上限
+const maxPeekBodyBytes = 1 << 20
+
+// peekJSON 解析 JSON 请求体并还原，后续 handler 仍可绑定参数
+// 请求体超过 maxPeekBodyBytes 时返回 413 并中止请求
+func peekJSON(c *gin.Context, obj interface{}) error {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPeekBodyBytes))
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse(http.StatusRequestEntityTooLarge, "请求体过大", err))
+		}
+		return err
+	}
+	return json.Unmarshal(body, obj)
+}
+
+// countCodes 统计请求中的代码数量，watchlist:<name> 展开为 kind 类型自选列表中的代码
+func countCodes(c *gin.Context, kind, codes string) int {
+	fields := goutils.SplitStringFields(codes)
+	expanded, err := models.ExpandWatchlistCodes(c, auth.UserID(c), kind, fields)
+	if err != nil {
+		return len(fields)
+	}
+	return len(expanded)
+}
+
+// CheckFundCost 基金检测的请求成本：每只基金成本为 1，检测持仓个股和计算归因时按基金数量增加成本
+// 请求体无法解析时成本为 1，由 handler 返回参数错误
+func CheckFundCost(c *gin.Context) int {
+	var params FundCheckParams
+	if err := peekJSON(c, &params); err != nil {
+		return 1
+	}
+	n := countCodes(c, models.WatchlistKindFund, params.Code)
+	cost := n
+	if params.CheckStocks {
+		cost += n * fundStocksCheckCost
+	}
+	if params.CheckAttribution {
+		cost += n * fundAttributionCost
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	return cost
+}
+
+// CheckStocksCost 股票检测的请求成本：每只股票成本为 1
+func CheckStocksCost(c *gin.Context) int {
+	var params StockCheckParams
+	if err := peekJSON(c, &params); err != nil {
+		return 1
+	}
+	if cost := countCodes(c, models.WatchlistKindStock, params.Keyword); cost > 1 {
+		return cost
+	}
+	return 1
+}
+
+// SelectStocksCost 选股的请求成本
+func SelectStocksCost(c *gin.Context) int {
+	return stockSelectCost
+}
+
+// IndexFundsCost 指数跟踪基金的请求成本
+func IndexFundsCost(c *gin.Context) int {
+	return indexFundsCost
+}
+
+// FundStyleCost 基金风格的请求成本：读取已保存结果成本为 1，重新计算时按固定成本计算
+func FundStyleCost(c *gin.Context) int {
+	var params FundStyleParams
+	if err := c.ShouldBindQuery(&params); err != nil || !params.Refresh {
+		return 1
+	}
+	return fundStyleRefreshCost
+}
+
+// checkGroup 合并相同的并发检测请求
+var checkGroup singleflight.Group
+
+// coalesceTimeout 合并执行的检测的超时时间，与 server 的 WriteTimeout 一致
+const coalesceTimeout = 10 * time.Minute
+
+// detachedContext 保留请求中的值但不随请求取消，用于多个请求共享的计算
+// 发起计算的请求会等待计算结束，执行期间 parent 中的值始终有效
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// coalesce 相同用户、相同参数的并发请求只执行一次 fn 并共享结果
+// fn 使用不随请求取消的 context 执行，第一个请求的客户端断开不影响其他等待的请求
+// 结果被多个请求共享，调用方不能修改返回值
+func coalesce(ctx context.Context, kind string, params interface{}, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	run := func() (interface{}, error) {
+		sharedCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, coalesceTimeout)
+		defer cancel()
+		return fn(sharedCtx)
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return fn(ctx)
+	}
+	key := fmt.Sprintf("%s:%d:%s", kind, auth.UserID(ctx), b)
+	v, err, shared := checkGroup.Do(key, run)
+	if shared {
+		logrus.WithContext(ctx).Debugf("coalesce %s request shared result", kind)
+	}
+	return v, err
+}
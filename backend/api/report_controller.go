@@ -0,0 +1,88 @@
+// 报告 API 控制器
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportController 报告控制器
+type ReportController struct {
+	service *ReportService
+}
+
+// NewReportController 创建报告控制器
+func NewReportController() *ReportController {
+	return &ReportController{
+		service: NewReportService(),
+	}
+}
+
+// ListReports 查询报告存档
+func (c *ReportController) ListReports(ctx *gin.Context) {
+	var params models.ParamReportList
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.PageNum == 0 {
+		params.PageNum = 1
+	}
+	if params.PageSize == 0 {
+		params.PageSize = 20
+	}
+
+	result, err := c.service.ListReports(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取报告列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DownloadReport 下载报告，默认下载 xlsx 文件，format=html 时返回 HTML 正文
+func (c *ReportController) DownloadReport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", ErrInvalidParams))
+		return
+	}
+	var params ReportDownloadParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Format == "" {
+		params.Format = core.ReportFormatXLSX
+	}
+
+	filename, contentType, data, err := c.service.GetReportFile(ctx, uint(id), params.Format)
+	if err != nil {
+		if errors.Is(err, models.ErrReportNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse(http.StatusNotFound, "报告不存在", err))
+			return
+		}
+		if errors.Is(err, ErrInvalidParams) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取报告失败", err))
+		return
+	}
+
+	if filename != "" {
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	ctx.Data(http.StatusOK, contentType, data)
+}
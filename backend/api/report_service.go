@@ -0,0 +1,63 @@
+// 报告 API 服务层
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// ReportService 报告服务
+type ReportService struct{}
+
+// NewReportService 创建报告服务实例
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// ListReports 分页查询报告存档
+func (s *ReportService) ListReports(ctx context.Context, params models.ParamReportList) (*ReportListResponse, error) {
+	reports, total, err := models.ListReports(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+	start := (params.PageNum - 1) * params.PageSize
+	return &ReportListResponse{
+		Reports: reports,
+		Pagination: PaginationResponse{
+			PageNum:    params.PageNum,
+			PageSize:   params.PageSize,
+			Total:      int(total),
+			TotalPages: totalPages,
+			StartIndex: start,
+			EndIndex:   start + len(reports),
+		},
+	}, nil
+}
+
+// GetReportFile 获取报告指定格式的文件名、Content-Type 和内容
+func (s *ReportService) GetReportFile(ctx context.Context, id uint, format string) (string, string, []byte, error) {
+	report, err := models.GetReport(ctx, id)
+	if err != nil {
+		return "", "", nil, err
+	}
+	switch format {
+	case core.ReportFormatXLSX:
+		if len(report.Content) == 0 {
+			return "", "", nil, fmt.Errorf("%w: report %d has no xlsx", ErrInvalidParams, id)
+		}
+		return report.Filename, core.XLSXContentType, report.Content, nil
+	case core.ReportFormatHTML:
+		if report.HTML == "" {
+			return "", "", nil, fmt.Errorf("%w: report %d has no html", ErrInvalidParams, id)
+		}
+		return "", "text/html; charset=utf-8", []byte(report.HTML), nil
+	}
+	return "", "", nil, fmt.Errorf("%w: format %s", ErrInvalidParams, format)
+}
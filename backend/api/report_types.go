@@ -0,0 +1,16 @@
+// 报告 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// ReportListResponse 报告列表响应
+type ReportListResponse struct {
+	Reports    []models.ReportDB  `json:"reports"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// ReportDownloadParams 报告下载请求参数
+type ReportDownloadParams struct {
+	// 下载格式：xlsx/html，为空时为 xlsx
+	Format string `json:"format" form:"format"`
+}
@@ -0,0 +1,193 @@
+// 股票 API 控制器
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// StockController 股票控制器
+type StockController struct {
+	service *StockService
+}
+
+// NewStockController 创建股票控制器
+func NewStockController() *StockController {
+	return &StockController{
+		service: NewStockService(),
+	}
+}
+
+// GetStockPrices 获取股票历史行情
+func (c *StockController) GetStockPrices(ctx *gin.Context) {
+	var params StockPricesParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Period == "" {
+		params.Period = "day"
+	}
+	if params.FQ == "" {
+		params.FQ = "forward"
+	}
+	if params.Limit == 0 {
+		params.Limit = 250
+	}
+
+	result, err := c.service.GetStockPrices(ctx, params)
+	if err != nil {
+		if err == ErrStockCodeRequired || err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取股票行情失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetStockVolatility 获取股票波动率及波动率锥
+func (c *StockController) GetStockVolatility(ctx *gin.Context) {
+	var params StockVolatilityParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Windows == "" {
+		params.Windows = "20,60,120,250"
+	}
+	if params.Limit == 0 {
+		params.Limit = 1250
+	}
+
+	result, err := c.service.GetStockVolatility(ctx, params)
+	if err != nil {
+		if err == ErrStockCodeRequired || err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取股票波动率失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetStockMoneyFlow 获取股票资金流向分析
+func (c *StockController) GetStockMoneyFlow(ctx *gin.Context) {
+	var params StockMoneyFlowParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Limit == 0 {
+		params.Limit = 60
+	}
+	if params.Windows == "" {
+		params.Windows = "5,20,60"
+	}
+	if params.DivergencePct == 0 {
+		params.DivergencePct = 5
+	}
+
+	result, err := c.service.GetStockMoneyFlow(ctx, params)
+	if err != nil {
+		if err == ErrStockCodeRequired || err == ErrInvalidParams {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取股票资金流向失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// RankStockMoneyFlow 股票主力资金净流入排行
+func (c *StockController) RankStockMoneyFlow(ctx *gin.Context) {
+	var params StockMoneyFlowRankParams
+	if err := ctx.ShouldBind(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Days == 0 {
+		params.Days = 5
+	}
+
+	result, err := c.service.RankStockMoneyFlow(ctx, params)
+	if err != nil {
+		if err == ErrStockCodeRequired || err == ErrTooManyStocks {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取主力资金净流入排行失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// CheckStocks 批量检测股票基本面
+func (c *StockController) CheckStocks(ctx *gin.Context) {
+	var params StockCheckParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.CheckStocks(ctx, params)
+	if err != nil {
+		if err == ErrKeywordsRequired || err == ErrTooManyStocks ||
+			errors.Is(err, models.ErrWatchlistNotFound) || errors.Is(err, models.ErrInvalidWatchlist) ||
+			errors.Is(err, models.ErrScreenPresetNotFound) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("股票检测失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// SelectStocksStream 按条件选股，以 SSE 返回处理进度和入选的股票
+// 事件：start、progress、stock（ExportorData）、done（StockSelectSummary）、error
+func (c *StockController) SelectStocksStream(ctx *gin.Context) {
+	var params StockSelectParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	stream(ctx, "选股失败", func(sctx context.Context, emit emitFunc) (interface{}, error) {
+		return c.service.SelectStocksStream(sctx, params, emit)
+	})
+}
+
+// CheckStocksStream 批量检测股票基本面，以 SSE 逐个返回检测结果
+// 事件：start、progress、stock（StockCheckItem）、done（StockCheckSummary）、error
+func (c *StockController) CheckStocksStream(ctx *gin.Context) {
+	var params StockCheckParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	stream(ctx, "股票检测失败", func(sctx context.Context, emit emitFunc) (interface{}, error) {
+		return c.service.CheckStocksStream(sctx, params, emit)
+	})
+}
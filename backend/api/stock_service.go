@@ -0,0 +1,346 @@
+// 股票 API 服务层
+package api
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/datacenter/zszx"
+	"github.com/axiaoxin-com/investool/indicators"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+)
+
+// StockService 股票服务
+type StockService struct{}
+
+// NewStockService 创建股票服务实例
+func NewStockService() *StockService {
+	return &StockService{}
+}
+
+// klinePeriods K 线周期参数映射
+var klinePeriods = map[string]eastmoney.KlineType{
+	"day":   eastmoney.KlineTypeDay,
+	"week":  eastmoney.KlineTypeWeek,
+	"month": eastmoney.KlineTypeMonth,
+}
+
+// klineFQs 复权类型参数映射
+var klineFQs = map[string]eastmoney.KlineFQ{
+	"none":     eastmoney.KlineFQNone,
+	"forward":  eastmoney.KlineFQForward,
+	"backward": eastmoney.KlineFQBackward,
+}
+
+// GetStockPrices 获取股票历史行情及技术指标
+func (s *StockService) GetStockPrices(ctx context.Context, params StockPricesParams) (*StockPricesResponse, error) {
+	if params.Code == "" {
+		return nil, ErrStockCodeRequired
+	}
+	klt, ok := klinePeriods[params.Period]
+	if !ok {
+		return nil, ErrInvalidParams
+	}
+	fqt, ok := klineFQs[params.FQ]
+	if !ok {
+		return nil, ErrInvalidParams
+	}
+	code := strings.ToUpper(params.Code)
+	klines, err := models.GetStockPrices(ctx, code, klt, fqt, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &StockPricesResponse{
+		Code:   code,
+		Period: params.Period,
+		FQ:     params.FQ,
+		Klines: klines,
+	}
+	if params.WithIndicators {
+		resp.Indicators = NewStockIndicators(klines)
+	}
+	return resp, nil
+}
+
+// NewStockIndicators 根据 K 线计算常用技术指标
+func NewStockIndicators(klines eastmoney.KlineList) *StockIndicators {
+	closes := klines.Closes()
+	dif, dea, macd := indicators.MACD(closes, 12, 26, 9)
+	mid, upper, lower := indicators.Bollinger(closes, 20, 2)
+	return &StockIndicators{
+		MA5:       indicators.MA(closes, 5),
+		MA20:      indicators.MA(closes, 20),
+		MA60:      indicators.MA(closes, 60),
+		MA250:     indicators.MA(closes, 250),
+		EMA12:     indicators.EMA(closes, 12),
+		EMA26:     indicators.EMA(closes, 26),
+		DIF:       dif,
+		DEA:       dea,
+		MACD:      macd,
+		RSI14:     indicators.RSI(closes, 14),
+		BollMid:   mid,
+		BollUpper: upper,
+		BollLower: lower,
+		ATR14:     indicators.ATR(klines.Highs(), klines.Lows(), closes, 14),
+		Drawdown:  indicators.DrawdownFromHigh(closes),
+	}
+}
+
+// parseWindows 解析逗号分隔的窗口参数，窗口不能小于 min
+func parseWindows(s string, min int) ([]int, error) {
+	windows := []int{}
+	for _, w := range goutils.SplitStringFields(s) {
+		if w == "" {
+			continue
+		}
+		i, err := strconv.Atoi(w)
+		if err != nil || i < min {
+			return nil, ErrInvalidParams
+		}
+		windows = append(windows, i)
+	}
+	return windows, nil
+}
+
+// GetStockVolatility 获取股票多窗口波动率及波动率锥
+func (s *StockService) GetStockVolatility(ctx context.Context, params StockVolatilityParams) (*StockVolatilityResponse, error) {
+	if params.Code == "" {
+		return nil, ErrStockCodeRequired
+	}
+	windows, err := parseWindows(params.Windows, 2)
+	if err != nil {
+		return nil, err
+	}
+	code := strings.ToUpper(params.Code)
+	klines, err := models.GetStockPrices(ctx, code, eastmoney.KlineTypeDay, eastmoney.KlineFQForward, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, ErrDataNotFound
+	}
+	opens, highs, lows, closes := klines.Opens(), klines.Highs(), klines.Lows(), klines.Closes()
+	resp := &StockVolatilityResponse{
+		Code:    code,
+		Date:    klines[len(klines)-1].Date,
+		Windows: []StockVolatilityWindow{},
+		Cone:    indicators.VolatilityCone(closes, windows),
+	}
+	for _, w := range windows {
+		if len(closes) <= w {
+			continue
+		}
+		resp.Windows = append(resp.Windows, StockVolatilityWindow{
+			Window:      w,
+			Realized:    indicators.Last(indicators.RealizedVolatility(closes, w)),
+			Parkinson:   indicators.Last(indicators.Parkinson(highs, lows, w)),
+			GarmanKlass: indicators.Last(indicators.GarmanKlass(opens, highs, lows, closes, w)),
+		})
+	}
+	return resp, nil
+}
+
+// GetStockMoneyFlow 获取股票资金流向明细、滚动净流入合计及资金股价背离
+func (s *StockService) GetStockMoneyFlow(ctx context.Context, params StockMoneyFlowParams) (*StockMoneyFlowResponse, error) {
+	if params.Code == "" {
+		return nil, ErrStockCodeRequired
+	}
+	windows, err := parseWindows(params.Windows, 1)
+	if err != nil {
+		return nil, err
+	}
+	code := strings.ToUpper(params.Code)
+	flows, err := models.GetStockMoneyFlows(ctx, code, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(flows) == 0 {
+		return nil, ErrDataNotFound
+	}
+	resp := &StockMoneyFlowResponse{
+		Code:        code,
+		Sums:        flows.RollingSums(windows),
+		Divergences: []zszx.MoneyFlowDivergenceResult{},
+		Flows:       flows,
+	}
+	for _, w := range windows {
+		resp.Divergences = append(resp.Divergences, flows.Divergence(w, params.DivergencePct))
+	}
+	return resp, nil
+}
+
+// RankStockMoneyFlow 按最近 days 个交易日主力资金净流入对股票排序
+func (s *StockService) RankStockMoneyFlow(ctx context.Context, params StockMoneyFlowRankParams) ([]StockMoneyFlowRankItem, error) {
+	codes := []string{}
+	for _, code := range goutils.SplitStringFields(params.Codes) {
+		if code != "" {
+			codes = append(codes, strings.ToUpper(code))
+		}
+	}
+	if len(codes) == 0 {
+		return nil, ErrStockCodeRequired
+	}
+	if len(codes) > 50 {
+		return nil, ErrTooManyStocks
+	}
+
+	items := make([]StockMoneyFlowRankItem, len(codes))
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		wg.Add(1)
+		go func(i int, code string) {
+			defer wg.Done()
+			items[i] = StockMoneyFlowRankItem{Code: code}
+			flows, err := models.GetStockMoneyFlows(ctx, code, params.Days)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("RankStockMoneyFlow code:%s err:%v", code, err)
+				return
+			}
+			items[i].Sum = flows.RollingSum(params.Days)
+		}(i, code)
+	}
+	wg.Wait()
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Sum.Main > items[j].Sum.Main
+	})
+	for i := range items {
+		items[i].Rank = i + 1
+	}
+	return items, nil
+}
+
+// CheckStocks 按检测条件或预设批量检测股票基本面，相同参数的并发请求共享同一次检测结果
+func (s *StockService) CheckStocks(ctx context.Context, params StockCheckParams) (*StockCheckResponse, error) {
+	v, err := coalesce(ctx, "stock_check", params, func(ctx context.Context) (interface{}, error) {
+		return s.checkStocks(ctx, params, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*StockCheckResponse), nil
+}
+
+// SelectStocksStream 按筛选条件和检测条件选股，通过 emit 推送处理进度和入选的股票，返回结果汇总
+func (s *StockService) SelectStocksStream(ctx context.Context, params StockSelectParams, emit emitFunc) (*StockSelectSummary, error) {
+	start := time.Now()
+	preset := core.Preset{Filter: eastmoney.DefaultFilter, CheckerOptions: core.DefaultCheckerOptions}
+	if params.Filter != nil {
+		preset.Filter = *params.Filter
+	}
+	if params.CheckerOptions != nil {
+		preset.CheckerOptions = *params.CheckerOptions
+	}
+	if params.Preset != "" {
+		p, err := core.GetPreset(ctx, params.Preset)
+		if err != nil {
+			return nil, err
+		}
+		preset = p
+	}
+
+	summary := &StockSelectSummary{Filter: preset.Filter, CheckerOptions: preset.CheckerOptions, Codes: []string{}}
+	selector := core.NewSelector(ctx, preset.Filter, core.NewChecker(ctx, preset.CheckerOptions))
+	stocks, err := selector.AutoFilterStocksEach(ctx, func(p core.SelectProgress) {
+		if p.Done == 1 {
+			emit.send(StreamEventStart, StreamProgress{Stage: "select", Total: p.Total})
+		}
+		summary.Total = p.Total
+		if p.Selected {
+			emit.send("stock", models.NewExportorData(ctx, p.Stock, preset.CheckerOptions.HVWindow))
+		}
+		emit.progress("select", p.Done, p.Total)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, stock := range stocks {
+		summary.Codes = append(summary.Codes, stock.BaseInfo.Secucode)
+	}
+	summary.Latency = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// CheckStocksStream 批量检测股票基本面，通过 emit 逐个推送检测结果和进度，返回结果汇总
+func (s *StockService) CheckStocksStream(ctx context.Context, params StockCheckParams, emit emitFunc) (*StockCheckSummary, error) {
+	start := time.Now()
+	response, err := s.checkStocks(ctx, params, emit)
+	if err != nil {
+		return nil, err
+	}
+	summary := &StockCheckSummary{
+		CheckerOptions: response.CheckerOptions,
+		Count:          len(response.Results),
+		OKCodes:        []string{},
+	}
+	for _, item := range response.Results {
+		if item.OK {
+			summary.OKCodes = append(summary.OKCodes, item.Secucode)
+		}
+	}
+	summary.Latency = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// checkStocks 按检测条件或预设批量检测股票基本面，emit 不为 nil 时推送处理过程
+func (s *StockService) checkStocks(ctx context.Context, params StockCheckParams, emit emitFunc) (*StockCheckResponse, error) {
+	keywords, err := models.ExpandWatchlistCodes(ctx, auth.UserID(ctx), models.WatchlistKindStock, goutils.SplitStringFields(params.Keyword))
+	if err != nil {
+		return nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, ErrKeywordsRequired
+	}
+	if len(keywords) > 50 {
+		return nil, ErrTooManyStocks
+	}
+
+	opts := core.DefaultCheckerOptions
+	if params.CheckerOptions != nil {
+		opts = *params.CheckerOptions
+	}
+	if params.Preset != "" {
+		preset, err := core.GetPreset(ctx, params.Preset)
+		if err != nil {
+			return nil, err
+		}
+		opts = preset.CheckerOptions
+	}
+
+	emit.send(StreamEventStart, StreamProgress{Stage: "stocks", Total: len(keywords)})
+	checker := core.NewChecker(ctx, opts)
+	results := []StockCheckItem{}
+	var mu sync.Mutex
+	// 每获取到一只股票的数据立即检测并推送结果
+	searcher := core.NewSearcher(ctx)
+	searcher.CheckYears = opts.CheckYears
+	if _, err := searcher.SearchStocksEach(ctx, keywords, func(stock models.Stock) {
+		result, ok := checker.Check(ctx, stock)
+		item := StockCheckItem{
+			Name:     stock.BaseInfo.SecurityNameAbbr,
+			Secucode: stock.BaseInfo.Secucode,
+			OK:       ok,
+			Result:   result,
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, item)
+		emit.send("stock", item)
+		emit.progress("stocks", len(results), len(keywords))
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Secucode < results[j].Secucode
+	})
+	return &StockCheckResponse{Results: results, CheckerOptions: opts}, nil
+}
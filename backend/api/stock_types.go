@@ -0,0 +1,171 @@
+// 股票相关 API 请求参数和响应结构体定义
+package api
+
+import (
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/datacenter/zszx"
+	"github.com/axiaoxin-com/investool/indicators"
+)
+
+// StockPricesParams 股票历史行情请求参数
+type StockPricesParams struct {
+	// 股票代码: 600519.SH
+	Code string `json:"code"            form:"code"`
+	// K线周期: day/week/month
+	Period string `json:"period"          form:"period"`
+	// 复权类型: none/forward/backward
+	FQ string `json:"fq"              form:"fq"`
+	// 返回最近的 K 线条数
+	Limit int `json:"limit"           form:"limit" binding:"min=0,max=5000"`
+	// 是否返回技术指标
+	WithIndicators bool `json:"with_indicators" form:"with_indicators"`
+}
+
+// StockIndicators 股票技术指标序列，与 K 线一一对应
+type StockIndicators struct {
+	MA5       []float64 `json:"ma5"`
+	MA20      []float64 `json:"ma20"`
+	MA60      []float64 `json:"ma60"`
+	MA250     []float64 `json:"ma250"`
+	EMA12     []float64 `json:"ema12"`
+	EMA26     []float64 `json:"ema26"`
+	DIF       []float64 `json:"dif"`
+	DEA       []float64 `json:"dea"`
+	MACD      []float64 `json:"macd"`
+	RSI14     []float64 `json:"rsi14"`
+	BollMid   []float64 `json:"boll_mid"`
+	BollUpper []float64 `json:"boll_upper"`
+	BollLower []float64 `json:"boll_lower"`
+	ATR14     []float64 `json:"atr14"`
+	Drawdown  []float64 `json:"drawdown"`
+}
+
+// StockPricesResponse 股票历史行情响应
+type StockPricesResponse struct {
+	Code       string              `json:"code"`
+	Period     string              `json:"period"`
+	FQ         string              `json:"fq"`
+	Klines     eastmoney.KlineList `json:"klines"`
+	Indicators *StockIndicators    `json:"indicators,omitempty"`
+}
+
+// StockVolatilityParams 股票波动率请求参数
+type StockVolatilityParams struct {
+	// 股票代码: 600519.SH
+	Code string `json:"code"    form:"code"`
+	// 波动率窗口（交易日），逗号分隔: 20,60,120,250
+	Windows string `json:"windows" form:"windows"`
+	// 统计的历史日K数量
+	Limit int `json:"limit"   form:"limit" binding:"min=0,max=5000"`
+}
+
+// StockVolatilityWindow 单个窗口的当前波动率
+type StockVolatilityWindow struct {
+	Window int `json:"window"`
+	// 收盘价已实现波动率
+	Realized float64 `json:"realized"`
+	// Parkinson 波动率
+	Parkinson float64 `json:"parkinson"`
+	// Garman-Klass 波动率
+	GarmanKlass float64 `json:"garman_klass"`
+}
+
+// StockVolatilityResponse 股票波动率响应
+type StockVolatilityResponse struct {
+	Code    string                          `json:"code"`
+	Date    string                          `json:"date"`
+	Windows []StockVolatilityWindow         `json:"windows"`
+	Cone    []indicators.VolatilityConeItem `json:"cone"`
+}
+
+// StockMoneyFlowParams 股票资金流向请求参数
+type StockMoneyFlowParams struct {
+	// 股票代码: 600519.SH
+	Code string `json:"code"             form:"code"`
+	// 返回最近的交易日数
+	Limit int `json:"limit"            form:"limit" binding:"min=0,max=1000"`
+	// 滚动统计窗口（交易日），逗号分隔: 5,20,60
+	Windows string `json:"windows"          form:"windows"`
+	// 判断背离的最小区间涨跌幅（%）
+	DivergencePct float64 `json:"divergence_pct"   form:"divergence_pct" binding:"min=0"`
+}
+
+// StockMoneyFlowResponse 股票资金流向响应
+type StockMoneyFlowResponse struct {
+	Code        string                           `json:"code"`
+	Sums        []zszx.MoneyFlowSum              `json:"sums"`
+	Divergences []zszx.MoneyFlowDivergenceResult `json:"divergences"`
+	Flows       zszx.NetInflowList               `json:"flows"`
+}
+
+// StockMoneyFlowRankParams 股票主力资金净流入排行请求参数
+type StockMoneyFlowRankParams struct {
+	// 股票代码列表，逗号分隔
+	Codes string `json:"codes" form:"codes"`
+	// 统计最近的交易日数
+	Days int `json:"days"  form:"days" binding:"min=0,max=60"`
+}
+
+// StockMoneyFlowRankItem 主力资金净流入排行项
+type StockMoneyFlowRankItem struct {
+	Rank int               `json:"rank"`
+	Code string            `json:"code"`
+	Sum  zszx.MoneyFlowSum `json:"sum"`
+}
+
+// StockCheckParams 股票检测请求参数
+type StockCheckParams struct {
+	// 股票名称或代码，多个使用逗号分隔，watchlist:<name> 表示自选列表中的全部股票
+	Keyword string `json:"keyword"         binding:"required"`
+	// 使用预设中的检测条件，指定后忽略 checker_options
+	Preset string `json:"preset"`
+	// 检测条件，为空时使用默认检测条件
+	CheckerOptions *core.CheckerOptions `json:"checker_options"`
+}
+
+// StockCheckItem 单只股票的检测结果
+type StockCheckItem struct {
+	Name     string           `json:"name"`
+	Secucode string           `json:"secucode"`
+	OK       bool             `json:"ok"`
+	Result   core.CheckResult `json:"result"`
+}
+
+// StockSelectParams 选股请求参数
+type StockSelectParams struct {
+	// 使用预设中的筛选条件和检测条件，指定后忽略 filter 和 checker_options
+	Preset string `json:"preset"`
+	// 筛选条件，为空时使用默认筛选条件
+	Filter *eastmoney.Filter `json:"filter"`
+	// 检测条件，为空时使用默认检测条件
+	CheckerOptions *core.CheckerOptions `json:"checker_options"`
+}
+
+// StockSelectSummary 选股流式响应的结果汇总
+type StockSelectSummary struct {
+	Filter         eastmoney.Filter    `json:"filter"`
+	CheckerOptions core.CheckerOptions `json:"checker_options"`
+	// 待筛选的股票数量
+	Total int `json:"total"`
+	// 入选的股票代码，按 ROE 降序
+	Codes []string `json:"codes"`
+	// 耗时（毫秒）
+	Latency int64 `json:"latency"`
+}
+
+// StockCheckSummary 股票检测流式响应的结果汇总
+type StockCheckSummary struct {
+	CheckerOptions core.CheckerOptions `json:"checker_options"`
+	Count          int                 `json:"count"`
+	// 通过检测的股票代码
+	OKCodes []string `json:"ok_codes"`
+	// 耗时（毫秒）
+	Latency int64 `json:"latency"`
+}
+
+// StockCheckResponse 股票检测响应
+type StockCheckResponse struct {
+	Results        []StockCheckItem    `json:"results"`
+	CheckerOptions core.CheckerOptions `json:"checker_options"`
+}
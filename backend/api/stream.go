@@ -0,0 +1,136 @@
+// Server-Sent Events 流式响应，用于耗时较长的检测接口
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SSE 事件名称
+const (
+	// StreamEventStart 开始处理，数据为需要处理的数量
+	StreamEventStart = "start"
+	// StreamEventProgress 处理进度
+	StreamEventProgress = "progress"
+	// StreamEventDone 处理完成，数据为结果汇总，之后关闭连接
+	StreamEventDone = "done"
+	// StreamEventError 处理失败，之后关闭连接
+	StreamEventError = "error"
+)
+
+// streamHeartbeat 没有事件时发送注释行保持连接的间隔
+const streamHeartbeat = 15 * time.Second
+
+// StreamProgress 处理进度
+type StreamProgress struct {
+	// 处理阶段
+	Stage string `json:"stage"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// StreamError 处理失败事件
+type StreamError struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+// emitFunc 发送 SSE 事件，为 nil 时不发送，用于同一段处理逻辑同时支持普通响应和流式响应
+type emitFunc func(event string, data interface{})
+
+// send 发送事件
+func (e emitFunc) send(event string, data interface{}) {
+	if e != nil {
+		e(event, data)
+	}
+}
+
+// progress 发送处理进度事件
+func (e emitFunc) progress(stage string, done, total int) {
+	e.send(StreamEventProgress, StreamProgress{Stage: stage, Done: done, Total: total})
+}
+
+type streamEvent struct {
+	name string
+	data interface{}
+}
+
+// streamContext 客户端断开时取消，值从 gin.Context 中获取，保留当前用户等请求信息
+type streamContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value 优先从 gin.Context 获取值
+func (c streamContext) Value(key interface{}) interface{} {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// stream 以 SSE 返回 run 的处理过程：run 通过 emit 发送事件，返回后发送 done 事件或 error 事件
+// 客户端断开时取消 run 的 context，并等待 run 返回后结束请求
+func stream(c *gin.Context, errMessage string, run func(ctx context.Context, emit emitFunc) (interface{}, error)) {
+	reqCtx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	ctx := streamContext{Context: reqCtx, values: c}
+
+	events := make(chan streamEvent)
+	finished := make(chan struct{})
+	emit := func(event string, data interface{}) {
+		select {
+		case events <- streamEvent{name: event, data: data}:
+		case <-reqCtx.Done():
+		}
+	}
+	go func() {
+		defer close(finished)
+		result, err := run(ctx, emit)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("stream %s error:%v", c.FullPath(), err)
+			emit(StreamEventError, StreamError{Message: errMessage, Error: err.Error()})
+			return
+		}
+		emit(StreamEventDone, result)
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 关闭 nginx 代理缓冲
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+loop:
+	for {
+		select {
+		case e := <-events:
+			c.SSEvent(e.name, e.data)
+			c.Writer.Flush()
+			if e.name == StreamEventDone || e.name == StreamEventError {
+				break loop
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				break loop
+			}
+			c.Writer.Flush()
+		case <-finished:
+			break loop
+		case <-reqCtx.Done():
+			break loop
+		}
+	}
+	cancel()
+	<-finished
+}
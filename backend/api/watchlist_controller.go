@@ -0,0 +1,207 @@
+// 自选列表和筛选预设 API 控制器
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistController 自选列表控制器
+type WatchlistController struct {
+	service *WatchlistService
+}
+
+// NewWatchlistController 创建自选列表控制器
+func NewWatchlistController() *WatchlistController {
+	return &WatchlistController{
+		service: NewWatchlistService(),
+	}
+}
+
+// isWatchlistParamError 自选列表和预设不存在或参数错误
+func isWatchlistParamError(err error) bool {
+	return err == ErrInvalidParams ||
+		errors.Is(err, models.ErrWatchlistNotFound) ||
+		errors.Is(err, models.ErrInvalidWatchlist) ||
+		errors.Is(err, models.ErrScreenPresetNotFound) ||
+		errors.Is(err, models.ErrInvalidScreenPreset) ||
+		errors.Is(err, core.ErrBuiltinPreset)
+}
+
+// ListWatchlists 获取全部自选列表
+func (c *WatchlistController) ListWatchlists(ctx *gin.Context) {
+	var params WatchlistListParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.ListWatchlists(ctx, params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取自选列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetWatchlist 获取自选列表
+func (c *WatchlistController) GetWatchlist(ctx *gin.Context) {
+	result, err := c.service.GetWatchlist(ctx, ctx.Param("name"))
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取自选列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// SaveWatchlist 创建或替换自选列表
+func (c *WatchlistController) SaveWatchlist(ctx *gin.Context) {
+	var params models.Watchlist
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	// 设置默认值
+	if params.Kind == "" {
+		params.Kind = models.WatchlistKindFund
+	}
+
+	result, err := c.service.SaveWatchlist(ctx, params)
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("保存自选列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// AddWatchlistItems 向自选列表添加条目
+func (c *WatchlistController) AddWatchlistItems(ctx *gin.Context) {
+	var params WatchlistItemsRequest
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+	params.Name = ctx.Param("name")
+
+	result, err := c.service.AddWatchlistItems(ctx, params)
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("添加自选失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// RemoveWatchlistItem 从自选列表移除代码
+func (c *WatchlistController) RemoveWatchlistItem(ctx *gin.Context) {
+	result, err := c.service.RemoveWatchlistItem(ctx, ctx.Param("name"), ctx.Param("code"))
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("移除自选失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DeleteWatchlist 删除自选列表
+func (c *WatchlistController) DeleteWatchlist(ctx *gin.Context) {
+	if err := c.service.DeleteWatchlist(ctx, ctx.Param("name")); err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("删除自选列表失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(nil))
+}
+
+// ListPresets 获取内置预设和保存的预设
+func (c *WatchlistController) ListPresets(ctx *gin.Context) {
+	result, err := c.service.ListPresets(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取预设失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// GetPreset 获取预设
+func (c *WatchlistController) GetPreset(ctx *gin.Context) {
+	result, err := c.service.GetPreset(ctx, ctx.Param("name"))
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("获取预设失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// SavePreset 保存预设，未指定的股票筛选和检测条件使用默认值
+func (c *WatchlistController) SavePreset(ctx *gin.Context) {
+	params := core.Preset{
+		Filter:         eastmoney.DefaultFilter,
+		CheckerOptions: core.DefaultCheckerOptions,
+	}
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数绑定失败", err))
+		return
+	}
+
+	result, err := c.service.SavePreset(ctx, params)
+	if err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("保存预设失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(result))
+}
+
+// DeletePreset 删除保存的预设
+func (c *WatchlistController) DeletePreset(ctx *gin.Context) {
+	if err := c.service.DeletePreset(ctx, ctx.Param("name")); err != nil {
+		if isWatchlistParamError(err) {
+			ctx.JSON(http.StatusBadRequest, BadRequestResponse("参数错误", err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, InternalErrorResponse("删除预设失败", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SuccessResponse(nil))
+}
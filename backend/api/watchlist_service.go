@@ -0,0 +1,78 @@
+// 自选列表和筛选预设 API 服务层
+package api
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// WatchlistService 自选列表服务
+type WatchlistService struct{}
+
+// NewWatchlistService 创建自选列表服务实例
+func NewWatchlistService() *WatchlistService {
+	return &WatchlistService{}
+}
+
+// ListWatchlists 获取全部自选列表
+func (s *WatchlistService) ListWatchlists(ctx context.Context, params WatchlistListParams) ([]models.Watchlist, error) {
+	return models.ListWatchlists(ctx, auth.UserID(ctx), params.Kind, params.Tag)
+}
+
+// GetWatchlist 获取自选列表
+func (s *WatchlistService) GetWatchlist(ctx context.Context, name string) (*models.Watchlist, error) {
+	return models.GetWatchlist(ctx, auth.UserID(ctx), name)
+}
+
+// SaveWatchlist 创建或替换自选列表
+func (s *WatchlistService) SaveWatchlist(ctx context.Context, w models.Watchlist) (*models.Watchlist, error) {
+	if err := models.SaveWatchlist(ctx, auth.UserID(ctx), w); err != nil {
+		return nil, err
+	}
+	return models.GetWatchlist(ctx, auth.UserID(ctx), w.Name)
+}
+
+// AddWatchlistItems 向自选列表添加条目
+func (s *WatchlistService) AddWatchlistItems(ctx context.Context, params WatchlistItemsRequest) (*models.Watchlist, error) {
+	if len(params.Items) == 0 {
+		return nil, ErrInvalidParams
+	}
+	return models.AddWatchlistItems(ctx, auth.UserID(ctx), params.Name, params.Items)
+}
+
+// RemoveWatchlistItem 从自选列表移除代码
+func (s *WatchlistService) RemoveWatchlistItem(ctx context.Context, name, code string) (*models.Watchlist, error) {
+	return models.RemoveWatchlistItems(ctx, auth.UserID(ctx), name, []string{code})
+}
+
+// DeleteWatchlist 删除自选列表
+func (s *WatchlistService) DeleteWatchlist(ctx context.Context, name string) error {
+	return models.DeleteWatchlist(ctx, auth.UserID(ctx), name)
+}
+
+// ListPresets 获取内置预设和保存的预设
+func (s *WatchlistService) ListPresets(ctx context.Context) ([]core.Preset, error) {
+	return core.ListPresets(ctx)
+}
+
+// GetPreset 获取预设
+func (s *WatchlistService) GetPreset(ctx context.Context, name string) (core.Preset, error) {
+	return core.GetPreset(ctx, name)
+}
+
+// SavePreset 保存预设
+func (s *WatchlistService) SavePreset(ctx context.Context, preset core.Preset) (core.Preset, error) {
+	preset.Builtin = false
+	if err := core.SavePreset(ctx, preset); err != nil {
+		return core.Preset{}, err
+	}
+	return core.GetPreset(ctx, preset.Name)
+}
+
+// DeletePreset 删除保存的预设
+func (s *WatchlistService) DeletePreset(ctx context.Context, name string) error {
+	return core.DeletePreset(ctx, name)
+}
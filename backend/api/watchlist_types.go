@@ -0,0 +1,19 @@
+// 自选列表和筛选预设 API 请求参数和响应结构体定义
+package api
+
+import "github.com/axiaoxin-com/investool/models"
+
+// WatchlistListParams 自选列表查询参数
+type WatchlistListParams struct {
+	// 列表类型: fund/stock
+	Kind string `json:"kind" form:"kind"`
+	// 标签
+	Tag string `json:"tag"  form:"tag"`
+}
+
+// WatchlistItemsRequest 自选列表添加条目请求参数
+type WatchlistItemsRequest struct {
+	// 自选列表名称，来自路径参数
+	Name  string                 `json:"name"  uri:"name"`
+	Items []models.WatchlistItem `json:"items" binding:"required"`
+}
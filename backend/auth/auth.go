@@ -0,0 +1,138 @@
+// Package auth 用户认证：bcrypt 密码、JWT 会话和个人 API token
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ContextUserKey 认证通过后当前用户在 gin.Context 中的 key
+const ContextUserKey = "auth_user"
+
+var (
+	// ErrUnauthorized 未登录或凭证无效
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRegisterDisabled 未开放注册
+	ErrRegisterDisabled = errors.New("register is disabled")
+	// ErrAuthDisabled 未开启认证，不能注册和登录
+	ErrAuthDisabled = errors.New("auth is disabled")
+)
+
+// Config 认证配置
+type Config struct {
+	// 是否开启认证，关闭时全部接口开放，数据归属 user_id 0
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// JWT 签名密钥，为空时启动时随机生成，重启后会话失效
+	JWTSecret string `mapstructure:"jwt_secret" json:"-"`
+	// JWT 有效期
+	JWTTTL time.Duration `mapstructure:"jwt_ttl" json:"jwt_ttl"`
+	// 是否开放注册，注册用户的角色为 DefaultRole，管理员只能通过 user 命令创建
+	AllowRegister bool `mapstructure:"allow_register" json:"allow_register"`
+	// 注册用户的默认角色
+	DefaultRole string `mapstructure:"default_role" json:"default_role"`
+}
+
+// Settings 当前认证配置
+var Settings = Config{JWTTTL: 24 * time.Hour, AllowRegister: false, DefaultRole: models.RoleUser}
+
+// InitFromViper 从 viper 的 auth 配置项初始化认证配置
+func InitFromViper() error {
+	cfg := Settings
+	if err := viper.UnmarshalKey("auth", &cfg); err != nil {
+		return err
+	}
+	if cfg.JWTTTL <= 0 {
+		cfg.JWTTTL = 24 * time.Hour
+	}
+	if cfg.DefaultRole == "" {
+		cfg.DefaultRole = models.RoleUser
+	}
+	if !models.ValidRole(cfg.DefaultRole) || cfg.DefaultRole == models.RoleAdmin {
+		return errors.New("invalid auth.default_role: " + cfg.DefaultRole)
+	}
+	if cfg.Enabled && cfg.JWTSecret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		cfg.JWTSecret = hex.EncodeToString(b)
+		logrus.Warn("auth.jwt_secret is empty, using a random secret, sessions will be invalid after restart")
+	}
+	Settings = cfg
+	logrus.Infof("auth initialized, enabled:%v allow_register:%v", cfg.Enabled, cfg.AllowRegister)
+	return nil
+}
+
+// IssueJWT 为用户签发 JWT 会话
+func IssueJWT(user *models.UserDB) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(Settings.JWTTTL)
+	token, err := SignJWT(Settings.JWTSecret, Claims{
+		Subject:   user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	return token, expiresAt, err
+}
+
+// Authenticate 校验 Authorization 请求头中的 Bearer 凭证，支持 JWT 和 API token，返回当前用户
+func Authenticate(ctx context.Context, authorization string) (*models.UserDB, error) {
+	credential := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if credential == "" || credential == authorization {
+		return nil, ErrUnauthorized
+	}
+	if IsAPIToken(credential) {
+		token, user, err := models.GetAPITokenByHash(ctx, HashAPIToken(credential))
+		if err != nil {
+			if errors.Is(err, models.ErrAPITokenNotFound) || errors.Is(err, models.ErrUserNotFound) {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		if err := models.TouchAPIToken(ctx, token.ID); err != nil {
+			logrus.WithContext(ctx).Warnf("TouchAPIToken id:%d error:%v", token.ID, err)
+		}
+		return user, nil
+	}
+
+	claims, err := ParseJWT(Settings.JWTSecret, credential)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	// 以数据库中的角色为准，修改角色后立即生效
+	user, err := models.GetUser(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// CurrentUser 获取认证通过的当前用户，未登录时返回 nil
+func CurrentUser(ctx context.Context) *models.UserDB {
+	if ctx == nil {
+		return nil
+	}
+	user, _ := ctx.Value(ContextUserKey).(*models.UserDB)
+	return user
+}
+
+// UserID 获取当前用户 ID，未登录或未开启认证时为 0
+func UserID(ctx context.Context) uint {
+	if user := CurrentUser(ctx); user != nil {
+		return user.ID
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWT(t *testing.T) {
+	claims := Claims{Subject: 1, Username: "axiaoxin", Role: models.RoleAdmin, IssuedAt: time.Now().Unix(), ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	token, err := SignJWT("secret", claims)
+	require.Nil(t, err)
+	parsed, err := ParseJWT("secret", token)
+	require.Nil(t, err)
+	require.Equal(t, claims, parsed)
+
+	_, err = ParseJWT("other", token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = ParseJWT("secret", token+"x")
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = ParseJWT("secret", "a.b")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	claims.ExpiresAt = time.Now().Add(-time.Second).Unix()
+	token, err = SignJWT("secret", claims)
+	require.Nil(t, err)
+	_, err = ParseJWT("secret", token)
+	require.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = SignJWT("", claims)
+	require.NotNil(t, err)
+}
+
+func TestPassword(t *testing.T) {
+	_, err := HashPassword("short")
+	require.ErrorIs(t, err, models.ErrInvalidUser)
+	hash, err := HashPassword("investool123")
+	require.Nil(t, err)
+	require.True(t, CheckPassword(hash, "investool123"))
+	require.False(t, CheckPassword(hash, "investool124"))
+}
+
+func TestAPIToken(t *testing.T) {
+	token, prefix, err := GenerateAPIToken()
+	require.Nil(t, err)
+	require.True(t, IsAPIToken(token))
+	require.Len(t, token, len(APITokenPrefix)+48)
+	require.Equal(t, token[:len(prefix)], prefix)
+	require.Equal(t, HashAPIToken(token), HashAPIToken(token))
+	require.Len(t, HashAPIToken(token), 64)
+	require.False(t, IsAPIToken("eyJhbGciOiJIUzI1NiJ9.e30.x"))
+}
+
+func TestAuthenticateWithoutCredential(t *testing.T) {
+	_, err := Authenticate(context.Background(), "")
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = Authenticate(context.Background(), "Basic abc")
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.Nil(t, CurrentUser(context.Background()))
+	require.Equal(t, uint(0), UserID(context.Background()))
+}
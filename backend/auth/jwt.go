@@ -0,0 +1,74 @@
+// HS256 JWT 签发和校验
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken JWT 格式或签名错误
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired JWT 已过期
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims JWT 载荷
+type Claims struct {
+	Subject   uint   `json:"sub"`
+	Username  string `json:"name"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func jwtSignature(secret, signingInput string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignJWT 使用 HS256 签发 JWT
+func SignJWT(secret string, claims Claims) (string, error) {
+	if secret == "" {
+		return "", errors.New("jwt secret is empty")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + jwtSignature(secret, signingInput), nil
+}
+
+// ParseJWT 校验 JWT 签名和有效期，返回载荷
+func ParseJWT(secret, token string) (Claims, error) {
+	claims := Claims{}
+	parts := strings.Split(token, ".")
+	if secret == "" || len(parts) != 3 || parts[0] != jwtHeader {
+		return claims, ErrInvalidToken
+	}
+	expected := jwtSignature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return claims, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, ErrInvalidToken
+	}
+	if claims.ExpiresAt <= time.Now().Unix() {
+		return claims, ErrTokenExpired
+	}
+	return claims, nil
+}
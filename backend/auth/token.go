@@ -0,0 +1,58 @@
+// 密码哈希和个人 API token
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// APITokenPrefix 个人 API token 前缀，用于区分 JWT
+const APITokenPrefix = "ivt_"
+
+// MinPasswordLength 密码最小长度
+const MinPasswordLength = 8
+
+// HashPassword 使用 bcrypt 计算密码哈希
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("%w: password must be at least %d characters", models.ErrInvalidUser, MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", models.ErrInvalidUser, err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword 校验密码是否与哈希匹配
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// GenerateAPIToken 生成随机 API token，返回明文 token 和用于展示的前缀
+func GenerateAPIToken() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := APITokenPrefix + hex.EncodeToString(b)
+	return token, token[:len(APITokenPrefix)+6], nil
+}
+
+// IsAPIToken 是否为个人 API token
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, APITokenPrefix)
+}
+
+// HashAPIToken 计算 API token 的 SHA-256 哈希，数据库中只保存哈希
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+func showFundBacktest(result *models.FundBacktestResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{"调仓日期", "持有基金", "换手率", "交易费用"})
+	for _, rb := range result.Rebalances {
+		funds := "空仓"
+		if len(rb.Funds) > 0 {
+			funds = strings.Join(rb.Funds, ",")
+		}
+		table.Append([]string{rb.Date, funds, fmt.Sprintf("%.2f%%", rb.Turnover), fmt.Sprintf("%.2f", rb.Fee)})
+	}
+	table.SetCaption(true, fmt.Sprintf("%s 规则 %s 调仓 前%d只", result.Params.Rule, result.Params.Rebalance, result.Params.TopN))
+	table.Render()
+
+	summary := tablewriter.NewWriter(os.Stdout)
+	summary.SetAlignment(tablewriter.ALIGN_LEFT)
+	summary.SetRowLine(true)
+	summary.SetHeader([]string{"", "累计收益", "年化收益", "最大回撤", "年化波动率"})
+	for _, row := range []struct {
+		name string
+		m    models.BacktestMetrics
+	}{
+		{"策略", result.Strategy},
+		{"基准" + result.Params.Benchmark, result.Benchmark},
+	} {
+		summary.Append([]string{
+			row.name,
+			fmt.Sprintf("%.2f%%", row.m.TotalReturn),
+			fmt.Sprintf("%.2f%%", row.m.CAGR),
+			fmt.Sprintf("%.2f%%", row.m.MaxDrawdown),
+			fmt.Sprintf("%.2f%%", row.m.Volatility),
+		})
+	}
+	summary.SetCaption(true, fmt.Sprintf(
+		"%s ~ %s 年化超额:%.2f%% 年化换手率:%.2f%% 交易费用:%.2f",
+		result.Start, result.End, result.ExcessCAGR, result.Turnover, result.Fees,
+	))
+	summary.Render()
+}
+
+func showStockBacktest(result *models.StockBacktestResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{"调仓日期", "持有至", "入选股票", "组合收益", "基准收益", "超额收益"})
+	for _, period := range result.Periods {
+		stocks := "空仓"
+		if len(period.Stocks) > 0 {
+			stocks = strings.Join(period.Stocks, "\n")
+		}
+		table.Append([]string{
+			period.Date,
+			period.End,
+			stocks,
+			fmt.Sprintf("%.2f%%", period.Return),
+			fmt.Sprintf("%.2f%%", period.Benchmark),
+			fmt.Sprintf("%.2f%%", period.Excess),
+		})
+	}
+	table.SetCaption(true, fmt.Sprintf("%s 调仓 胜率:%.2f%%", result.Params.Rebalance, result.WinRate))
+	table.Render()
+
+	summary := tablewriter.NewWriter(os.Stdout)
+	summary.SetAlignment(tablewriter.ALIGN_LEFT)
+	summary.SetRowLine(true)
+	summary.SetHeader([]string{"", "累计收益", "年化收益", "最大回撤", "年化波动率"})
+	for _, row := range []struct {
+		name string
+		m    models.BacktestMetrics
+	}{
+		{"策略", result.Strategy},
+		{"基准" + result.Params.Benchmark, result.Benchmark},
+	} {
+		summary.Append([]string{
+			row.name,
+			fmt.Sprintf("%.2f%%", row.m.TotalReturn),
+			fmt.Sprintf("%.2f%%", row.m.CAGR),
+			fmt.Sprintf("%.2f%%", row.m.MaxDrawdown),
+			fmt.Sprintf("%.2f%%", row.m.Volatility),
+		})
+	}
+	summary.SetCaption(true, fmt.Sprintf("%s ~ %s 年化超额:%.2f%%", result.Start, result.End, result.ExcessCAGR))
+	summary.Render()
+}
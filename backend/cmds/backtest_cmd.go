@@ -0,0 +1,279 @@
+// 回测
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorBacktest 回测
+	ProcessorBacktest = "backtest"
+)
+
+// FlagsFundBacktest 基金回测 cli flags
+func FlagsFundBacktest() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "rule",
+			Value:    models.BacktestRule4433,
+			Usage:    "选基规则：4433/filter",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "codes",
+			Usage:    "基金池，可指定多个，不指定则使用数据库中的全部股票型和混合型基金",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "top",
+			Value:    5,
+			Usage:    "每次调仓持有满足规则的前 N 只基金（按近一年收益率排序）",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "rebalance",
+			Value:    models.RebalanceQuarterly,
+			Usage:    "调仓周期：monthly/quarterly",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "start",
+			Value:    time.Now().AddDate(-3, 0, 0).Format("2006-01-02"),
+			Usage:    "回测开始日期",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "end",
+			Value:    "",
+			Usage:    "回测结束日期，不指定则到最新交易日",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "cash",
+			Value:    100000,
+			Usage:    "初始资金",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "benchmark",
+			Value:    "000300",
+			Usage:    "基准指数代码",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "fetch",
+			Value:    false,
+			Usage:    "从接口获取基金净值并保存，不指定则只使用数据库中的净值",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于读取数据库中的基金和净值",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "filter.types",
+			Usage:    "规则为 filter 时的基金类型，可指定多个",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.min_scale",
+			Value:    2,
+			Usage:    "规则为 filter 时的最小规模（亿）",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.max_scale",
+			Value:    50,
+			Usage:    "规则为 filter 时的最大规模（亿）",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.min_estab_years",
+			Value:    5,
+			Usage:    "规则为 filter 时的最低成立年限",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.year_1_rank_ratio",
+			Value:    25,
+			Usage:    "规则为 filter 时最近一年收益率排名比",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.this_year_235_rank_ratio",
+			Value:    25,
+			Usage:    "规则为 filter 时今年来、最近两年、三年、五年收益率排名比",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.month_6_rank_ratio",
+			Value:    33.33,
+			Usage:    "规则为 filter 时最近六月收益率排名比",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "filter.month_3_rank_ratio",
+			Value:    33.33,
+			Usage:    "规则为 filter 时最近三月收益率排名比",
+			Required: false,
+		},
+	}
+}
+
+// ActionFundBacktest 基金回测 cli action
+func ActionFundBacktest() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+		initOptionalDatabase(c.String("config"))
+
+		params := models.FundBacktestParams{
+			Rule: c.String("rule"),
+			Filter: models.ParamFundListFilter{
+				Types:                c.StringSlice("filter.types"),
+				MinScale:             c.Float64("filter.min_scale"),
+				MaxScale:             c.Float64("filter.max_scale"),
+				MinEstabYears:        c.Float64("filter.min_estab_years"),
+				Year1RankRatio:       c.Float64("filter.year_1_rank_ratio"),
+				ThisYear235RankRatio: c.Float64("filter.this_year_235_rank_ratio"),
+				Month6RankRatio:      c.Float64("filter.month_6_rank_ratio"),
+				Month3RankRatio:      c.Float64("filter.month_3_rank_ratio"),
+			},
+			Codes:       c.StringSlice("codes"),
+			TopN:        c.Int("top"),
+			Rebalance:   c.String("rebalance"),
+			Start:       c.String("start"),
+			End:         c.String("end"),
+			InitialCash: c.Float64("cash"),
+			Benchmark:   c.String("benchmark"),
+		}
+		result, err := models.BacktestFunds(ctx, params, c.Bool("fetch"))
+		if err != nil {
+			return err
+		}
+		showFundBacktest(result)
+		return nil
+	}
+}
+
+// FlagsStockBacktest 选股回测 cli flags
+func FlagsStockBacktest() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "rebalance",
+			Value:    models.StockRebalanceAnnual,
+			Usage:    "调仓周期：annual（每年5月）/quarterly（每季度）",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "start",
+			Value:    time.Now().AddDate(-5, 0, 0).Format("2006-01-02"),
+			Usage:    "回测开始日期",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "end",
+			Value:    "",
+			Usage:    "回测结束日期，不指定则到最新交易日",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "top",
+			Value:    0,
+			Usage:    "每次调仓最多持有的股票数（按 ROE 排序），为 0 时持有全部入选股票",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "benchmark",
+			Value:    "000300",
+			Usage:    "基准指数代码",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "disable_check",
+			Value:    false,
+			Usage:    "关闭基本面检测，只按 filter 参数选股",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "preset",
+			Value:    "",
+			Usage:    fmt.Sprintf("使用选股预设，指定后忽略 filter 和 checker 参数。内置预设：%s，也可使用 preset 命令保存的预设", strings.Join(core.PresetNames(), "|")),
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于保存和读取K线",
+			Required: false,
+		},
+	}
+}
+
+// ActionStockBacktest 选股回测 cli action
+func ActionStockBacktest() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+		initOptionalDatabase(c.String("config"))
+
+		selector, err := NewSelector(ctx, c)
+		if err != nil {
+			return err
+		}
+		params := models.StockBacktestParams{
+			Rebalance: c.String("rebalance"),
+			Start:     c.String("start"),
+			End:       c.String("end"),
+			TopN:      c.Int("top"),
+			Benchmark: c.String("benchmark"),
+		}
+		result, err := selector.Backtest(ctx, params)
+		if err != nil {
+			return err
+		}
+		showStockBacktest(result)
+		return nil
+	}
+}
+
+// CommandBacktest 回测 cli command
+func CommandBacktest() *cli.Command {
+	cmd := &cli.Command{
+		Name:  ProcessorBacktest,
+		Usage: "回测",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "fund",
+				Usage:  "按 4433 法则或基金筛选参数定期调仓回测",
+				Flags:  FlagsFundBacktest(),
+				Action: ActionFundBacktest(),
+			},
+			{
+				Name:      "stock",
+				Usage:     "按选股器和检测器的历史时点选股结果定期调仓回测",
+				UsageText: "每个调仓日只使用当时已公告的财报和当时的行情选股，等权持有到下一个调仓日并与基准指数对比。股票池为当前上市的股票，建议通过行业或指定代码缩小范围。",
+				Flags:     append(append(FlagsStockBacktest(), FlagsFilter()...), FlagsCheckerOptions()...),
+				Action:    ActionStockBacktest(),
+			},
+		},
+	}
+	return cmd
+}
@@ -17,6 +17,7 @@ import (
 func Check(ctx context.Context, keywords []string, opts core.CheckerOptions) (results map[string]core.CheckResult, err error) {
 	results = make(map[string]core.CheckResult)
 	searcher := core.NewSearcher(ctx)
+	searcher.CheckYears = opts.CheckYears
 	stocks, err := searcher.SearchStocks(ctx, keywords)
     if err != nil {
         logrus.WithContext(ctx).Fatal(err.Error())
@@ -24,7 +25,7 @@ func Check(ctx context.Context, keywords []string, opts core.CheckerOptions) (re
 
 	for _, stock := range stocks {
 		checker := core.NewChecker(ctx, opts)
-		checkResult, ok := checker.CheckFundamentals(ctx, stock)
+		checkResult, ok := checker.Check(ctx, stock)
 		k := fmt.Sprintf("%s-%s", stock.BaseInfo.SecurityNameAbbr, stock.BaseInfo.Secucode)
 		results[k] = checkResult
 		table := newTable()
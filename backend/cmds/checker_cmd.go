@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
@@ -24,9 +25,21 @@ func FlagsChecker() []cli.Flag {
 			Name:     "keyword",
 			Aliases:  []string{"k"},
 			Value:    "",
-			Usage:    "检给定股票名称或代码，多个股票批量检测使用/分割。如: 招商银行/中国平安/600519",
+			Usage:    "检给定股票名称或代码，多个股票批量检测使用/分割。如: 招商银行/中国平安/600519，使用 watchlist:<name> 检测自选列表中的股票",
 			Required: true,
 		},
+		&cli.StringFlag{
+			Name:     "preset",
+			Value:    "",
+			Usage:    fmt.Sprintf("使用预设中的检测条件，指定后忽略 checker 参数。内置预设：%s，也可使用 preset 命令保存的预设", strings.Join(core.PresetNames(), "|")),
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件路径，用于读取自选列表和保存的预设",
+			Required: false,
+		},
 	}
 }
 
@@ -63,6 +76,12 @@ func FlagsCheckerOptions() []cli.Flag {
 			Usage:       "最大历史波动率",
 			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MaxHV),
 		},
+		&cli.IntFlag{
+			Name:        "checker.hv_window",
+			Value:       core.DefaultCheckerOptions.HVWindow,
+			Usage:       "历史波动率窗口（交易日），为 0 时使用上市以来的历史波动率",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.HVWindow),
+		},
 		&cli.Float64Flag{
 			Name:        "checker.min_total_market_cap",
 			Value:       core.DefaultCheckerOptions.MinTotalMarketCap,
@@ -177,6 +196,120 @@ func FlagsCheckerOptions() []cli.Flag {
 			Usage:       "最低股息率",
 			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MinGxl),
 		},
+		&cli.IntFlag{
+			Name:        "checker.min_div_years",
+			Value:       core.DefaultCheckerOptions.MinDivYears,
+			Usage:       "最少连续分红年数",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MinDivYears),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.min_div_cagr",
+			Value:       core.DefaultCheckerOptions.MinDivCAGR,
+			Usage:       "最低每股分红复合增长率(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MinDivCAGR),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.max_payout_ratio_std_dev",
+			Value:       core.DefaultCheckerOptions.MaxPayoutRatioStdDev,
+			Usage:       "最大股利支付率标准差",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MaxPayoutRatioStdDev),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.min_div_fcf_coverage",
+			Value:       core.DefaultCheckerOptions.MinDivFCFCoverage,
+			Usage:       "最低自由现金流分红覆盖倍数",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MinDivFCFCoverage),
+		},
+		&cli.BoolFlag{
+			Name:        "checker.is_check_trend",
+			Value:       core.DefaultCheckerOptions.IsCheckTrend,
+			Usage:       "是否检测价格趋势（股价在均线之上）",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.IsCheckTrend),
+		},
+		&cli.IntFlag{
+			Name:        "checker.trend_ma_period",
+			Value:       core.DefaultCheckerOptions.TrendMAPeriod,
+			Usage:       "趋势检测使用的均线周期（日）",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.TrendMAPeriod),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.max_drawdown_from_high",
+			Value:       core.DefaultCheckerOptions.MaxDrawdownFromHigh,
+			Usage:       "距最近高点的最大回撤(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.MaxDrawdownFromHigh),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.bank_min_nim",
+			Value:       core.DefaultCheckerOptions.BankMinNIM,
+			Usage:       "银行股最小净息差(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankMinNIM),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.bank_max_cost_income_ratio",
+			Value:       core.DefaultCheckerOptions.BankMaxCostIncomeRatio,
+			Usage:       "银行股最大成本收入比(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankMaxCostIncomeRatio),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.bank_min_provision_loan_ratio",
+			Value:       core.DefaultCheckerOptions.BankMinProvisionLoanRatio,
+			Usage:       "银行股最小拨贷比(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankMinProvisionLoanRatio),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.bank_min_loan_growth",
+			Value:       core.DefaultCheckerOptions.BankMinLoanGrowth,
+			Usage:       "银行股最小贷款增速(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankMinLoanGrowth),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.bank_min_deposit_growth",
+			Value:       core.DefaultCheckerOptions.BankMinDepositGrowth,
+			Usage:       "银行股最小存款增速(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankMinDepositGrowth),
+		},
+		&cli.BoolFlag{
+			Name:        "checker.bank_is_check_npl_trend",
+			Value:       core.DefaultCheckerOptions.BankIsCheckNPLTrend,
+			Usage:       "是否检测银行股不良贷款率趋势",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.BankIsCheckNPLTrend),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.insurer_min_ev_growth",
+			Value:       core.DefaultCheckerOptions.InsurerMinEVGrowth,
+			Usage:       "保险股最小内含价值增速(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.InsurerMinEVGrowth),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.insurer_max_combined_ratio",
+			Value:       core.DefaultCheckerOptions.InsurerMaxCombinedRatio,
+			Usage:       "保险股最大综合成本率(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.InsurerMaxCombinedRatio),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.insurer_min_solvency_ratio",
+			Value:       core.DefaultCheckerOptions.InsurerMinSolvencyRatio,
+			Usage:       "保险股最小偿付能力充足率(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.InsurerMinSolvencyRatio),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.fin_required_return",
+			Value:       core.DefaultCheckerOptions.FinRequiredReturn,
+			Usage:       "金融股 PB-ROE 估值的要求回报率(%)",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.FinRequiredReturn),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.fin_max_pbroe",
+			Value:       core.DefaultCheckerOptions.FinMaxPBROE,
+			Usage:       "金融股最大 PB-ROE 比值",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.FinMaxPBROE),
+		},
+		&cli.Float64Flag{
+			Name:        "checker.insurer_max_pev",
+			Value:       core.DefaultCheckerOptions.InsurerMaxPEV,
+			Usage:       "保险股最大 PEV",
+			DefaultText: fmt.Sprint(core.DefaultCheckerOptions.InsurerMaxPEV),
+		},
 	}
 }
 
@@ -188,6 +321,7 @@ func NewCheckerOptions(c *cli.Context) core.CheckerOptions {
 	checkerOpts.NoCheckYearsROE = c.Float64("checker.no_check_years_roe")
 	checkerOpts.MaxDebtAssetRatio = c.Float64("checker.max_debt_asset_ratio")
 	checkerOpts.MaxHV = c.Float64("checker.max_hv")
+	checkerOpts.HVWindow = c.Int("checker.hv_window")
 	checkerOpts.MinTotalMarketCap = c.Float64("checker.min_total_market_cap")
 	checkerOpts.BankMinROA = c.Float64("checker.bank_min_roa")
 	checkerOpts.BankMinZBCZL = c.Float64("checker.bank_min_zbczl")
@@ -207,6 +341,25 @@ func NewCheckerOptions(c *cli.Context) core.CheckerOptions {
 	checkerOpts.IsCheckRevGrow = c.Bool("checker.is_check_rev_grow")
 	checkerOpts.IsCheckNetprofitGrow = c.Bool("checker.is_check_netprofit_grow")
 	checkerOpts.MinGxl = c.Float64("checker.min_gxl")
+	checkerOpts.MinDivYears = c.Int("checker.min_div_years")
+	checkerOpts.MinDivCAGR = c.Float64("checker.min_div_cagr")
+	checkerOpts.MaxPayoutRatioStdDev = c.Float64("checker.max_payout_ratio_std_dev")
+	checkerOpts.MinDivFCFCoverage = c.Float64("checker.min_div_fcf_coverage")
+	checkerOpts.IsCheckTrend = c.Bool("checker.is_check_trend")
+	checkerOpts.TrendMAPeriod = c.Int("checker.trend_ma_period")
+	checkerOpts.MaxDrawdownFromHigh = c.Float64("checker.max_drawdown_from_high")
+	checkerOpts.BankMinNIM = c.Float64("checker.bank_min_nim")
+	checkerOpts.BankMaxCostIncomeRatio = c.Float64("checker.bank_max_cost_income_ratio")
+	checkerOpts.BankMinProvisionLoanRatio = c.Float64("checker.bank_min_provision_loan_ratio")
+	checkerOpts.BankMinLoanGrowth = c.Float64("checker.bank_min_loan_growth")
+	checkerOpts.BankMinDepositGrowth = c.Float64("checker.bank_min_deposit_growth")
+	checkerOpts.BankIsCheckNPLTrend = c.Bool("checker.bank_is_check_npl_trend")
+	checkerOpts.InsurerMinEVGrowth = c.Float64("checker.insurer_min_ev_growth")
+	checkerOpts.InsurerMaxCombinedRatio = c.Float64("checker.insurer_max_combined_ratio")
+	checkerOpts.InsurerMinSolvencyRatio = c.Float64("checker.insurer_min_solvency_ratio")
+	checkerOpts.FinRequiredReturn = c.Float64("checker.fin_required_return")
+	checkerOpts.FinMaxPBROE = c.Float64("checker.fin_max_pbroe")
+	checkerOpts.InsurerMaxPEV = c.Float64("checker.insurer_max_pev")
 	return checkerOpts
 }
 
@@ -221,6 +374,20 @@ func ActionChecker() func(c *cli.Context) error {
 		ctx := context.Background()
 		keywords := strings.Split(keyword, "/")
 		opts := NewCheckerOptions(c)
+		if strings.Contains(keyword, models.WatchlistPrefix) || c.String("preset") != "" {
+			initOptionalDatabase(c.String("config"))
+		}
+		keywords, err := models.ExpandWatchlistCodes(ctx, 0, models.WatchlistKindStock, keywords)
+		if err != nil {
+			return err
+		}
+		if name := c.String("preset"); name != "" {
+			preset, err := core.GetPreset(ctx, name)
+			if err != nil {
+				return err
+			}
+			opts = preset.CheckerOptions
+		}
 		Check(ctx, keywords, opts)
 		return nil
 	}
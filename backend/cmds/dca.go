@@ -0,0 +1,69 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+func showFundDCA(result *models.FundDCAResult, showFlows bool) {
+	if showFlows {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetHeader([]string{"日期", "类型", "金额", "净值", "份额", "费用", "估值百分位"})
+		for _, f := range result.Flows {
+			percentile := "--"
+			if f.Percentile >= 0 {
+				percentile = fmt.Sprintf("%.2f%% x%.1f", f.Percentile, f.Multiple)
+			}
+			table.Append([]string{
+				f.Date,
+				f.Type,
+				fmt.Sprintf("%.2f", f.Amount),
+				fmt.Sprintf("%.4f", f.NAV),
+				fmt.Sprintf("%.2f", f.Units),
+				fmt.Sprintf("%.2f", f.Fee),
+				percentile,
+			})
+		}
+		table.Render()
+	}
+
+	summary := tablewriter.NewWriter(os.Stdout)
+	summary.SetAlignment(tablewriter.ALIGN_LEFT)
+	summary.SetRowLine(true)
+	summary.SetHeader([]string{"", "扣款次数", "累计投入", "累计取回", "持仓市值", "平均成本", "收益", "收益率", "XIRR", "最大回撤", "费用", "止盈次数"})
+	for _, row := range []struct {
+		name string
+		s    models.FundDCASummary
+	}{
+		{"定投", result.DCA},
+		{"一次性投入", result.LumpSum},
+	} {
+		summary.Append([]string{
+			row.name,
+			fmt.Sprint(row.s.Periods),
+			fmt.Sprintf("%.2f", row.s.TotalInvested),
+			fmt.Sprintf("%.2f", row.s.Received),
+			fmt.Sprintf("%.2f", row.s.MarketValue),
+			fmt.Sprintf("%.4f", row.s.AvgCost),
+			fmt.Sprintf("%.2f", row.s.Profit),
+			fmt.Sprintf("%.2f%%", row.s.ReturnRate),
+			fmt.Sprintf("%.2f%%", row.s.XIRR),
+			fmt.Sprintf("%.2f%%", row.s.MaxDrawdown),
+			fmt.Sprintf("%.2f", row.s.Fees),
+			fmt.Sprint(row.s.TakeProfits),
+		})
+	}
+	status := result.FixedInvestmentStatus
+	if status == "" {
+		status = "不可定投"
+	}
+	summary.SetCaption(true, fmt.Sprintf(
+		"%s(%s) %s ~ %s %s 每期 %.2f 当前状态:%s",
+		result.FundName, result.Params.Code, result.Start, result.End, result.Params.Frequency, result.Params.Amount, status,
+	))
+	summary.Render()
+}
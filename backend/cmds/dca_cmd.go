@@ -0,0 +1,130 @@
+// 基金定投模拟
+
+package cmds
+
+import (
+	"context"
+	"time"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorDCA 定投模拟
+	ProcessorDCA = "dca"
+)
+
+// FlagsDCA 定投模拟 cli flags
+func FlagsDCA() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "code",
+			Usage:    "基金代码",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "start",
+			Value:    time.Now().AddDate(-3, 0, 0).Format("2006-01-02"),
+			Usage:    "定投开始日期",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "end",
+			Value:    "",
+			Usage:    "定投结束日期，不指定则到最新净值日期",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "amount",
+			Value:    1000,
+			Usage:    "每期扣款金额",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "frequency",
+			Value:    models.DCAMonthly,
+			Usage:    "扣款频率：weekly/biweekly/monthly",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "smart",
+			Value:    false,
+			Usage:    "按跟踪指数的 PE 百分位调整每期扣款金额（低估多投、高估少投），需要数据库中的指数估值历史",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "index",
+			Value:    "",
+			Usage:    "智能定投使用的估值指数代码，不指定则使用基金的跟踪标的",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "take_profit",
+			Value:    0,
+			Usage:    "目标止盈收益率（%），为 0 时不止盈",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "reinvest",
+			Value:    true,
+			Usage:    "红利再投资，关闭则现金分红",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "flows",
+			Value:    false,
+			Usage:    "显示每笔定投流水",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于读取数据库中的基金、分红和指数估值",
+			Required: false,
+		},
+	}
+}
+
+// ActionDCA 定投模拟 cli action
+func ActionDCA() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+		initOptionalDatabase(c.String("config"))
+
+		params := models.FundDCAParams{
+			Code:              c.String("code"),
+			Start:             c.String("start"),
+			End:               c.String("end"),
+			Amount:            c.Float64("amount"),
+			Frequency:         c.String("frequency"),
+			SmartValuation:    c.Bool("smart"),
+			ValuationIndex:    c.String("index"),
+			TakeProfit:        c.Float64("take_profit"),
+			ReinvestDividends: c.Bool("reinvest"),
+		}
+		result, err := models.SimulateFundDCA(ctx, params)
+		if err != nil {
+			return err
+		}
+		showFundDCA(result, c.Bool("flows"))
+		return nil
+	}
+}
+
+// CommandDCA 定投模拟 cli command
+func CommandDCA() *cli.Command {
+	cmd := &cli.Command{
+		Name:      ProcessorDCA,
+		Usage:     "基金定投模拟",
+		UsageText: "按历史净值和分红模拟定投，计算平均成本、XIRR 和最大回撤，并与期初一次性投入相同总金额比较",
+		Flags:     FlagsDCA(),
+		Action:    ActionDCA(),
+	}
+	return cmd
+}
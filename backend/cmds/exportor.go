@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/axiaoxin-com/goutils"
 	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/models"
 	"github.com/sirupsen/logrus"
@@ -19,13 +20,29 @@ import (
 type Exportor struct {
 	Stocks   models.ExportorDataList
 	Selector core.Selector
+	// 是否导出资金流向列
+	WithMoneyFlow bool
+	// 行业轮动报告，不为空时 excel 中额外生成行业轮动表
+	IndustryRotation *models.IndustryRotationReport
+}
+
+// ExportOptions 导出的可选内容
+type ExportOptions struct {
+	// 导出资金流向列
+	WithMoneyFlow bool
+	// 导出行业轮动表，使用该窗口（交易日）计算行业相对强弱，为 0 时不导出
+	IndustryRotationWindow int
 }
 
 // New 创建要导出的数据列表
 func New(ctx context.Context, stocks models.StockList, selector core.Selector) Exportor {
+	hvWindow := core.DefaultCheckerOptions.HVWindow
+	if selector.Checker != nil {
+		hvWindow = selector.Checker.Options.HVWindow
+	}
 	dlist := models.ExportorDataList{}
 	for _, s := range stocks {
-		dlist = append(dlist, models.NewExportorData(ctx, s))
+		dlist = append(dlist, models.NewExportorData(ctx, s, hvWindow))
 	}
 
 	return Exportor{
@@ -34,8 +51,23 @@ func New(ctx context.Context, stocks models.StockList, selector core.Selector) E
 	}
 }
 
+// Headers 返回要导出的表头，未开启的可选列不导出
+func (e Exportor) Headers() []string {
+	headers := models.ExportorData{}.GetHeaders()
+	if e.WithMoneyFlow {
+		return headers
+	}
+	result := []string{}
+	for _, h := range headers {
+		if !goutils.IsStrInSlice(h, models.MoneyFlowHeaders) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
 // Export 导出数据
-func Export(ctx context.Context, exportFilename string, selector core.Selector) {
+func Export(ctx context.Context, exportFilename string, selector core.Selector, opts ExportOptions) {
 	beginTime := time.Now()
 	filedir := path.Dir(exportFilename)
 	fileext := strings.ToLower(path.Ext(exportFilename))
@@ -64,6 +96,14 @@ func Export(ctx context.Context, exportFilename string, selector core.Selector)
 		logrus.WithContext(ctx).Fatal(err.Error())
 	}
 	e := New(ctx, stocks, selector)
+	e.WithMoneyFlow = opts.WithMoneyFlow
+	if opts.IndustryRotationWindow > 0 {
+		if report, err := models.QueryIndustryRotation(ctx, opts.IndustryRotationWindow); err != nil {
+			logrus.WithContext(ctx).Error("Export QueryIndustryRotation error:" + err.Error())
+		} else {
+			e.IndustryRotation = report
+		}
+	}
 
 	switch exportType {
 	case "json":
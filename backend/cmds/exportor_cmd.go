@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/axiaoxin-com/investool/core"
@@ -43,6 +44,31 @@ func FlagsExportor() []cli.Flag {
 			EnvVars:     []string{"XSTOCK_EXPORTOR_DISABLE_CHECK"},
 			DefaultText: "false",
 		},
+		&cli.BoolFlag{
+			Name:        "with_money_flow",
+			Value:       false,
+			Usage:       "导出近5/20/60日主力资金净流入及资金股价背离列，excel 中额外生成主力资金流入排行表",
+			EnvVars:     []string{"XSTOCK_EXPORTOR_WITH_MONEY_FLOW"},
+			DefaultText: "false",
+		},
+		&cli.IntFlag{
+			Name:        "industry_rotation_window",
+			Value:       0,
+			Usage:       "excel 中额外生成行业轮动表，按该窗口（交易日）计算行业相对强弱及基金配置变化，为 0 时不生成",
+			EnvVars:     []string{"XSTOCK_EXPORTOR_INDUSTRY_ROTATION_WINDOW"},
+			DefaultText: "0",
+		},
+		&cli.StringFlag{
+			Name:  "as_of",
+			Value: "",
+			Usage: "按历史时点选股（如 2021-05-06），只使用该日之前公告的财报和该日之前的行情，为空时使用最新数据",
+		},
+		&cli.StringFlag{
+			Name:    "preset",
+			Aliases: []string{"p"},
+			Value:   "",
+			Usage:   fmt.Sprintf("使用选股预设，指定后忽略 filter 和 checker 参数。内置预设：%s，也可使用 preset 命令保存的预设", strings.Join(core.PresetNames(), "|")),
+		},
 	}
 }
 
@@ -212,6 +238,34 @@ func NewFilter(c *cli.Context) eastmoney.Filter {
 	return filter
 }
 
+// NewSelector 从命令行解析 preset、filter、checker 和 disable_check 参数创建选股器
+func NewSelector(ctx context.Context, c *cli.Context) (core.Selector, error) {
+	checkerOpts := NewCheckerOptions(c)
+	filter := NewFilter(c)
+	if name := c.String("preset"); name != "" {
+		if _, ok := core.Presets[name]; !ok {
+			// 非内置预设从数据库中读取
+			configFile := c.String("config")
+			if configFile == "" {
+				configFile = "./config.yaml"
+			}
+			initOptionalDatabase(configFile)
+		}
+		preset, err := core.GetPreset(ctx, name)
+		if err != nil {
+			return core.Selector{}, fmt.Errorf("invalid preset:%s, builtin presets:%v, err:%w", name, core.PresetNames(), err)
+		}
+		logrus.WithContext(ctx).Infof("use preset %s: %s", preset.Name, preset.Desc)
+		checkerOpts = preset.CheckerOptions
+		filter = preset.Filter
+	}
+	checker := core.NewChecker(ctx, checkerOpts)
+	if c.Bool("disable_check") {
+		checker = nil
+	}
+	return core.NewSelector(ctx, filter, checker), nil
+}
+
 // ActionExportor cli action
 func ActionExportor() func(c *cli.Context) error {
 	return func(c *cli.Context) error {
@@ -221,19 +275,27 @@ func ActionExportor() func(c *cli.Context) error {
 			logrus.SetLevel(lvl)
 		}
 
-		checkerOpts := NewCheckerOptions(c)
-		checker := core.NewChecker(ctx, checkerOpts)
-		if c.Bool("disable_check") {
-			checker = nil
+		selector, err := NewSelector(ctx, c)
+		if err != nil {
+			return err
+		}
+		if asOf := c.String("as_of"); asOf != "" {
+			t, err := time.Parse("2006-01-02", asOf)
+			if err != nil {
+				return fmt.Errorf("invalid as_of:%s", asOf)
+			}
+			selector.AsOf = t
 		}
-		filter := NewFilter(c)
-		selector := core.NewSelector(ctx, filter, checker)
 		b, _ := json.MarshalIndent(map[string]interface{}{
-			"filter":  filter,
-			"checker": checker,
+			"filter":  selector.Filter,
+			"checker": selector.Checker,
+			"as_of":   c.String("as_of"),
 		}, "", "  ")
 		logrus.WithContext(ctx).Debug("exportor params:" + string(b))
-		Export(ctx, c.String("filename"), selector)
+		Export(ctx, c.String("filename"), selector, ExportOptions{
+			WithMoneyFlow:          c.Bool("with_money_flow"),
+			IndustryRotationWindow: c.Int("industry_rotation_window"),
+		})
 		return nil
 	}
 }
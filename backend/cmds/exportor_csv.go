@@ -3,9 +3,13 @@
 package cmds
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"os"
 
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/gocarina/gocsv"
 )
 
@@ -13,9 +17,39 @@ import (
 // 不传文件名则返回 []bytes，传文件名则保存到文件
 func (e Exportor) ExportCSV(ctx context.Context, filename string) (result []byte, err error) {
 	result, err = gocsv.MarshalBytes(&e.Stocks)
+	if err == nil && !e.WithMoneyFlow {
+		result, err = dropCSVColumns(result, models.MoneyFlowHeaders)
+	}
 
 	if filename != "" {
 		err = os.WriteFile(filename, result, 0666)
 	}
 	return
 }
+
+// dropCSVColumns 删除 csv 中指定表头的列
+func dropCSVColumns(data []byte, headers []string) ([]byte, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(records) == 0 {
+		return data, err
+	}
+	keep := []int{}
+	for i, h := range records[0] {
+		if !goutils.IsStrInSlice(h, headers) {
+			keep = append(keep, i)
+		}
+	}
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	for _, record := range records {
+		row := make([]string, 0, len(keep))
+		for _, i := range keep {
+			row = append(row, record[i])
+		}
+		if err := w.Write(row); err != nil {
+			return data, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
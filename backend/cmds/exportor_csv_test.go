@@ -0,0 +1,32 @@
+package cmds
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCSVMoneyFlowColumns(t *testing.T) {
+	e := Exportor{
+		Stocks: []models.ExportorData{
+			{
+				Name:        "中文名称",
+				Code:        "1234code",
+				MainNetIn5D: 100,
+			},
+		},
+	}
+
+	b, err := e.ExportCSV(_ctx, "")
+	require.Nil(t, err)
+	header := strings.Split(string(b), "\n")[0]
+	require.NotContains(t, header, models.MoneyFlowHeaders[0])
+	require.Equal(t, len(e.Headers()), len(strings.Split(header, ",")))
+
+	e.WithMoneyFlow = true
+	b, err = e.ExportCSV(_ctx, "")
+	require.Nil(t, err)
+	require.Contains(t, string(b), models.MoneyFlowHeaders[0])
+}
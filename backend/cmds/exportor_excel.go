@@ -6,10 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/sirupsen/logrus"
 )
 
@@ -64,13 +66,17 @@ func (e Exportor) ExportExcel(ctx context.Context, filename string) (result []by
 	lowPriceSheet := "30元内"
 	hv1Sheet := "历史波动率低于1"
 	hv2Sheet := "历史波动率高于1"
+	moneyFlowSheet := "主力资金流入排行"
 	sheets := []string{defaultSheet, lowPriceSheet, hv1Sheet, hv2Sheet}
+	if e.WithMoneyFlow {
+		sheets = append(sheets, moneyFlowSheet)
+	}
 	// 添加行业
 	for _, industry := range e.Stocks.GetIndustryList() {
 		sheets = append(sheets, industry+"行业")
 	}
 
-	headers := e.Stocks[0].GetHeaders()
+	headers := e.Headers()
 	headersLen := len(headers)
 	headerStyle, err := f.NewStyle(HeaderStyle)
 	if err != nil {
@@ -165,7 +171,13 @@ func (e Exportor) ExportExcel(ctx context.Context, filename string) (result []by
 	// 写 body
 	for _, sheet := range sheets {
 		row := 2
-		for _, stock := range e.Stocks {
+		stocks := e.Stocks
+		if sheet == moneyFlowSheet {
+			// 按近5日主力净流入从高到低排列
+			stocks = append(models.ExportorDataList{}, e.Stocks...)
+			stocks.SortByMainNetIn5D()
+		}
+		for _, stock := range stocks {
 			switch sheet {
 			case defaultSheet:
 			case lowPriceSheet:
@@ -198,6 +210,9 @@ func (e Exportor) ExportExcel(ctx context.Context, filename string) (result []by
 			row++
 		}
 	}
+	if e.IndustryRotation != nil {
+		writeIndustryRotationSheet(ctx, f, e.IndustryRotation, headerStyle)
+	}
 	f.SetDocProps(&excelize.DocProperties{
 		Created:     time.Now().Format("2006-01-02 15:04:05"),
 		Creator:     "axiaoxin",
@@ -210,3 +225,41 @@ func (e Exportor) ExportExcel(ctx context.Context, filename string) (result []by
 	err = f.SaveAs(filename)
 	return
 }
+
+// writeIndustryRotationSheet 写入行业轮动表：上方为行业相对强弱排名，下方为基金行业配置变化
+func writeIndustryRotationSheet(ctx context.Context, f *excelize.File, report *models.IndustryRotationReport, headerStyle int) {
+	sheet := "行业轮动"
+	f.NewSheet(sheet)
+	f.SetColWidth(sheet, "A", "G", 20)
+	rows := [][]interface{}{
+		{fmt.Sprintf("%s 近%d个交易日行业相对强弱，基准沪深300收益率:%.2f%%", report.Date, report.Window, report.BenchmarkReturn)},
+		{"排名", "板块代码", "行业", "区间收益率(%)", "相对强弱(%)", "PE", "PB"},
+	}
+	headerRows := []int{2}
+	for _, m := range report.Momentum {
+		rows = append(rows, []interface{}{m.Rank, m.Code, m.Name, m.Return, m.RelativeStrength, m.PE, m.PB})
+	}
+	if len(report.Allocation) > 0 {
+		a := report.Allocation[0]
+		rows = append(rows,
+			[]interface{}{},
+			[]interface{}{fmt.Sprintf("基金行业配置变化 %s -> %s", a.PrevPubDate, a.PubDate)},
+			[]interface{}{"行业", "原平均占比(%)", "新平均占比(%)", "变化(百分点)", "配置基金数变化"},
+		)
+		headerRows = append(headerRows, len(rows))
+		for _, c := range report.Allocation {
+			rows = append(rows, []interface{}{c.Industry, c.PrevAvgProp, c.AvgProp, c.Change, c.FundCountChange})
+		}
+	}
+	for i, row := range rows {
+		axis, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			logrus.WithContext(ctx).Error("CoordinatesToCellName error:" + err.Error())
+			continue
+		}
+		f.SetSheetRow(sheet, axis, &row)
+	}
+	for _, r := range headerRows {
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", r), fmt.Sprintf("G%d", r), headerStyle)
+	}
+}
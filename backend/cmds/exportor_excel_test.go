@@ -3,6 +3,7 @@ package cmds
 import (
 	"testing"
 
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
 	"github.com/axiaoxin-com/investool/models"
 	"github.com/stretchr/testify/require"
 )
@@ -23,3 +24,23 @@ func TestExportExcel(t *testing.T) {
 	_, err := e.ExportExcel(_ctx, "/tmp/test.xlsx")
 	require.Nil(t, err)
 }
+
+func TestExportExcelIndustryRotation(t *testing.T) {
+	e := Exportor{
+		Stocks: []models.ExportorData{{Name: "中文名称", Code: "1234code"}},
+		IndustryRotation: &models.IndustryRotationReport{
+			Date:       "2023-12-11",
+			Window:     20,
+			Momentum:   []models.IndustryMomentum{{Code: "BK0477", Name: "酿酒行业", Return: 5, RelativeStrength: 3, Rank: 1}},
+			Allocation: []models.IndustryAllocationChange{{Industry: "制造业", PrevPubDate: "2023-06-30", PubDate: "2023-09-30", Change: 1}},
+		},
+	}
+	_, err := e.ExportExcel(_ctx, "/tmp/test_rotation.xlsx")
+	require.Nil(t, err)
+
+	f, err := excelize.OpenFile("/tmp/test_rotation.xlsx")
+	require.Nil(t, err)
+	v, err := f.GetCellValue("行业轮动", "C3")
+	require.Nil(t, err)
+	require.Equal(t, "酿酒行业", v)
+}
@@ -3,11 +3,13 @@ package cmds
 import (
 	"fmt"
 	"os"
-	"strconv"
+	"strings"
 
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/olekukonko/tablewriter"
-	"github.com/sirupsen/logrus"
 )
 
 func showIndexData(data *eastmoney.IndexData) {
@@ -45,65 +47,133 @@ func showIndexData(data *eastmoney.IndexData) {
 	table.Render()
 }
 
-func showIndexStocks(stocks []eastmoney.ZSCFGItem) {
+func showIndexStocks(snapshot models.IndexConstituentSnapshot) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 	table.SetRowLine(true)
-	headers := []string{"股票名称", "股票代码", "持仓占比"}
+	headers := []string{"股票名称", "股票代码", "权重"}
 	table.SetHeader(headers)
 
 	sum := 0.0
-	for _, stock := range stocks {
-		row := []string{stock.StockName, stock.StockCode, stock.Marketcappct}
+	for _, stock := range snapshot.Constituents {
+		row := []string{stock.StockName, stock.StockCode, fmt.Sprintf("%.2f%%", stock.Weight)}
 		table.Append(row)
-		if stock.Marketcappct != "" && stock.Marketcappct != "--" {
-			v, err := strconv.ParseFloat(stock.Marketcappct, 64)
-			if err != nil {
-				logrus.Error(err.Error())
-				continue
-			}
-			sum += v
-		}
+		sum += stock.Weight
 	}
 
-	if len(stocks) > 0 {
-		table.SetCaption(true, stocks[0].IndexName+"成分股")
+	if len(snapshot.Constituents) > 0 {
+		table.SetCaption(true, fmt.Sprintf("%s %s成分股", snapshot.Date, snapshot.Constituents[0].IndexName))
 	}
-	footers := []string{fmt.Sprintf("总数:%d", len(stocks)), "--", fmt.Sprintf("占比求和:%.2f", sum)}
+	footers := []string{fmt.Sprintf("总数:%d", len(snapshot.Constituents)), "--", fmt.Sprintf("权重求和:%.2f", sum)}
 	table.SetFooter(footers)
 	table.Render()
 }
 
-func showIntersecStocks(stocks1, stocks2 []eastmoney.ZSCFGItem) {
+func showIndexSet(op string, codes []string, members []models.IndexSetMember) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
 	table.SetRowLine(true)
-	headers := []string{"股票名称-代码"}
+	headers := []string{"股票名称", "股票代码"}
+	for _, code := range codes {
+		headers = append(headers, code+"权重")
+	}
 	table.SetHeader(headers)
-
-	counter := map[string]int{}
-	for _, stock := range stocks1 {
-		key := fmt.Sprintf("%v-%v", stock.StockName, stock.StockCode)
-		counter[key]++
+	for _, m := range members {
+		row := []string{m.StockName, m.StockCode}
+		for _, code := range codes {
+			weight := "--"
+			if w, ok := m.Weights[code]; ok {
+				weight = fmt.Sprintf("%.2f%%", w)
+			}
+			row = append(row, weight)
+		}
+		table.Append(row)
 	}
 
-	for _, stock := range stocks2 {
-		key := fmt.Sprintf("%v-%v", stock.StockName, stock.StockCode)
-		counter[key]++
+	opName := map[string]string{
+		models.IndexSetOpIntersect:  "∩",
+		models.IndexSetOpUnion:      "∪",
+		models.IndexSetOpDifference: "-",
+	}[op]
+	table.SetCaption(true, fmt.Sprintf("%s 成分股%s 总数:%d", strings.Join(codes, opName), op, len(members)))
+	table.Render()
+}
+
+func showIndexConstituentDiff(diff models.IndexConstituentDiff) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{"变动", "股票名称", "股票代码", "原权重", "新权重"})
+	for _, c := range diff.Added {
+		table.Append([]string{"调入", c.StockName, c.StockCode, "--", fmt.Sprintf("%.2f%%", c.Weight)})
+	}
+	for _, c := range diff.Removed {
+		table.Append([]string{"调出", c.StockName, c.StockCode, fmt.Sprintf("%.2f%%", c.Weight), "--"})
+	}
+	for _, w := range diff.WeightChanges {
+		table.Append([]string{"权重变化", w.StockName, w.StockCode, fmt.Sprintf("%.2f%%", w.FromWeight), fmt.Sprintf("%.2f%%", w.ToWeight)})
 	}
+	table.SetCaption(true, fmt.Sprintf(
+		"%s 成分股变化 %s -> %s 调入:%d 调出:%d",
+		diff.IndexCode, diff.From, diff.To, len(diff.Added), len(diff.Removed),
+	))
+	table.Render()
+}
 
-	intersecCount := 0
-	for k, v := range counter {
-		if v == 2 {
-			table.Append([]string{k})
-			intersecCount++
+func showIndexFunds(result *core.IndexFundsResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	headers := []string{"基金名称", "基金代码", "类别", "规模", "费率", "跟踪误差", "跟踪差", "日均成交额", "得分"}
+	table.SetHeader(headers)
+	for _, f := range result.Funds {
+		amount := "--"
+		if f.AvgAmount > 0 {
+			amount = goutils.YiWanString(f.AvgAmount)
 		}
+		table.Append([]string{
+			f.Name,
+			f.Code,
+			f.Category,
+			goutils.YiWanString(f.Scale),
+			fmt.Sprintf("%.2f%%", f.Fee),
+			fmt.Sprintf("%.2f%%", f.TrackingError),
+			fmt.Sprintf("%.2f%%", f.TrackingDiff),
+			amount,
+			fmt.Sprintf("%.1f", f.Score),
+		})
 	}
+	caption := fmt.Sprintf("%s(%s) 近%d个交易日跟踪基金", result.IndexName, result.IndexCode, result.Days)
+	if r := result.Recommendation; r.Best != nil {
+		caption += fmt.Sprintf(" 推荐:%s(%s)", r.Best.Name, r.Best.Code)
+		if r.BestExchange != nil {
+			caption += fmt.Sprintf(" 场内:%s(%s)", r.BestExchange.Name, r.BestExchange.Code)
+		}
+		if r.BestOTC != nil {
+			caption += fmt.Sprintf(" 场外:%s(%s)", r.BestOTC.Name, r.BestOTC.Code)
+		}
+	}
+	table.SetCaption(true, caption)
+	table.Render()
+}
 
-	if intersecCount > 0 {
-		table.SetCaption(true, fmt.Sprintf("%s∩%s 成分股交集", stocks1[0].IndexName, stocks2[0].IndexName))
+func showIndexSignal(signal models.IndexSignal, bands models.SignalBands, backtest *models.SignalBacktestResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.Append([]string{"估值日期", signal.Date})
+	table.Append([]string{"百分位指标", signal.Metric})
+	table.Append([]string{"当前百分位", fmt.Sprintf("%.2f%%", signal.Percentile)})
+	table.Append([]string{"信号区间", fmt.Sprintf("加倍<%.0f 正常<%.0f 暂停<%.0f 其余止盈", bands.IncreaseBelow, bands.NormalBelow, bands.PauseBelow)})
+	table.Append([]string{"定投信号", fmt.Sprintf("%s(%s) 金额倍数:%.1f", signal.SignalDesc, signal.Signal, signal.Multiple)})
+	if backtest != nil {
+		table.Append([]string{"回测区间", fmt.Sprintf("%s ~ %s 共%d期", backtest.Start, backtest.End, backtest.Periods)})
+		table.Append([]string{"信号定投", fmt.Sprintf("投入:%.2f 市值:%.2f 收益率:%.2f%% 止盈取回:%.2f", backtest.Invested, backtest.Value, backtest.Return, backtest.TakeProfitCash)})
+		table.Append([]string{"普通定投", fmt.Sprintf("投入:%.2f 市值:%.2f 收益率:%.2f%%", backtest.PlainInvested, backtest.PlainValue, backtest.PlainReturn)})
+		table.Append([]string{"信号次数", fmt.Sprintf("加倍:%d 正常:%d 暂停:%d 止盈:%d",
+			backtest.SignalCounts[models.InvestSignalIncrease], backtest.SignalCounts[models.InvestSignalNormal],
+			backtest.SignalCounts[models.InvestSignalPause], backtest.SignalCounts[models.InvestSignalTakeProfit])})
 	}
-	footers := []string{fmt.Sprintf("交集总数:%d", intersecCount)}
-	table.SetFooter(footers)
+	table.SetCaption(true, fmt.Sprintf("%s(%s) 定投信号", signal.IndexName, signal.IndexCode))
 	table.Render()
 }
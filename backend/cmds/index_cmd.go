@@ -6,7 +6,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
@@ -40,10 +44,75 @@ func FlagsIndex() []cli.Flag {
 			Required: false,
 		},
 		&cli.StringFlag{
+			Name:     "date",
+			Value:    "",
+			Usage:    "使用数据库中该日期或之前最近一次的成分股快照，不指定则实时获取并保存当日快照",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
 			Name:     "intersec",
 			Aliases:  []string{"i"},
+			Usage:    "返回与指定指数的成分股交集，可指定多个",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "union",
+			Aliases:  []string{"u"},
+			Usage:    "返回与指定指数的成分股并集，可指定多个",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "except",
+			Aliases:  []string{"e"},
+			Usage:    "返回不在指定指数中的成分股（差集），可指定多个",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "diff",
+			Usage:    "对比两个日期的成分股快照，如: --diff 2023-06-01 --diff 2023-12-11，只指定一个日期时与最新快照对比",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "export",
 			Value:    "",
-			Usage:    "返回成分股交集",
+			Usage:    "将成分股作为选股器的指定代码列表进行检测并导出到该文件，支持的后缀名同 exportor",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "funds",
+			Aliases:  []string{"f"},
+			Value:    false,
+			Usage:    "返回跟踪该指数的基金及跟踪误差、跟踪差、费率、规模、流动性比较",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "days",
+			Value:    250,
+			Usage:    "计算跟踪质量使用的最近交易日数",
+			Required: false,
+		},
+		&cli.BoolFlag{
+			Name:     "signal",
+			Value:    false,
+			Usage:    "返回按估值百分位给出的定投信号，并在数据库保存的估值历史上回测",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "metric",
+			Value:    models.SignalMetricPE,
+			Usage:    "定投信号使用的百分位指标：pe/pb",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "amount",
+			Value:    1000,
+			Usage:    "回测每期基础定投金额",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于保存和读取数据库中的指数估值历史和成分股快照",
 			Required: false,
 		},
 	}
@@ -64,34 +133,121 @@ func ActionIndex() func(c *cli.Context) error {
 			showIndexData(indexData)
 		}
 
-		showStocks := c.Bool("stocks")
-		if showStocks {
-			stocks, err := datacenter.EastMoney.ZSCFG(ctx, indexCode)
+		date := c.String("date")
+		if c.Bool("stocks") {
+			snapshot, err := loadIndexConstituents(ctx, c, indexCode, date)
+			if err != nil {
+				return err
+			}
+			showIndexStocks(snapshot)
+		}
+
+		setOps := []struct {
+			op    string
+			codes []string
+		}{
+			{models.IndexSetOpIntersect, c.StringSlice("intersec")},
+			{models.IndexSetOpUnion, c.StringSlice("union")},
+			{models.IndexSetOpDifference, c.StringSlice("except")},
+		}
+		for _, setOp := range setOps {
+			if len(setOp.codes) == 0 {
+				continue
+			}
+			codes := append([]string{indexCode}, setOp.codes...)
+			snapshots := []models.IndexConstituentSnapshot{}
+			for _, code := range codes {
+				snapshot, err := loadIndexConstituents(ctx, c, code, date)
+				if err != nil {
+					return err
+				}
+				snapshots = append(snapshots, snapshot)
+			}
+			members, err := models.IndexConstituentSet(setOp.op, snapshots)
 			if err != nil {
 				return err
 			}
-			showIndexStocks(stocks)
+			showIndexSet(setOp.op, codes, members)
 		}
 
-		intersecIndexCode := c.String("intersec")
-		if intersecIndexCode != "" {
-			stocks1, err := datacenter.EastMoney.ZSCFG(ctx, indexCode)
+		if dates := c.StringSlice("diff"); len(dates) > 0 {
+			initOptionalDatabase(c.String("config"))
+			from, err := models.LoadIndexConstituents(ctx, indexCode, dates[0])
+			if err != nil {
+				return err
+			}
+			to := ""
+			if len(dates) > 1 {
+				to = dates[1]
+			}
+			toSnapshot, err := models.LoadIndexConstituents(ctx, indexCode, to)
 			if err != nil {
 				return err
 			}
-			stocks2, err := datacenter.EastMoney.ZSCFG(ctx, intersecIndexCode)
+			showIndexConstituentDiff(models.DiffIndexConstituents(from, toSnapshot))
+		}
+
+		if filename := c.String("export"); filename != "" {
+			snapshot, err := loadIndexConstituents(ctx, c, indexCode, date)
 			if err != nil {
 				return err
 			}
-			showIntersecStocks(stocks1, stocks2)
+			if len(snapshot.Constituents) == 0 {
+				return fmt.Errorf("index %s has no constituents", indexCode)
+			}
+			filter := eastmoney.DefaultFilter
+			filter.SpecialSecurityCodeList = snapshot.Codes()
+			checker := core.NewChecker(ctx, NewCheckerOptions(c))
+			Export(ctx, filename, core.NewSelector(ctx, filter, checker), ExportOptions{})
+		}
+
+		if c.Bool("funds") {
+			result, err := core.NewSearcher(ctx).SearchIndexFunds(ctx, indexCode, c.Int("days"))
+			if err != nil {
+				return err
+			}
+			showIndexFunds(result)
+		}
+
+		if c.Bool("signal") {
+			// 数据库不可用时仍可给出当前信号，只是无法回测
+			initOptionalDatabase(c.String("config"))
+			bands := models.DefaultSignalBands()
+			bands.Metric = c.String("metric")
+			if err := bands.Validate(); err != nil {
+				return err
+			}
+			v, err := models.QueryIndexValuation(ctx, indexCode)
+			if err != nil {
+				return err
+			}
+			signal := models.NewIndexSignal(v, bands)
+			var backtest *models.SignalBacktestResult
+			history, err := models.LoadIndexValuations(ctx, indexCode, "", "")
+			if err != nil {
+				logrus.Debug("LoadIndexValuations err:" + err.Error())
+			} else if result, err := models.BacktestIndexSignal(history, bands, c.Float64("amount")); err == nil {
+				backtest = &result
+			}
+			showIndexSignal(signal, bands, backtest)
 		}
 		return nil
 	}
 }
 
+// loadIndexConstituents 日期为空时实时获取成分股并保存快照，否则读取数据库中的快照
+func loadIndexConstituents(ctx context.Context, c *cli.Context, indexCode, date string) (models.IndexConstituentSnapshot, error) {
+	initOptionalDatabase(c.String("config"))
+	if date == "" {
+		return models.GetIndexConstituents(ctx, indexCode)
+	}
+	return models.LoadIndexConstituents(ctx, indexCode, date)
+}
+
 // CommandIndex 指数成分股 cli command
 func CommandIndex() *cli.Command {
 	flags := FlagsIndex()
+	flags = append(flags, FlagsCheckerOptions()...)
 	cmd := &cli.Command{
 		Name:   ProcessorIndex,
 		Usage:  "指数数据",
@@ -0,0 +1,39 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+func showMarketTemperature(temp models.MarketTemperature) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	headers := []string{"指数", "PE", "PE百分位", "PB", "PB百分位", "ERP", "ERP百分位", "温度", "等级"}
+	table.SetHeader(headers)
+	for _, i := range temp.Indexes {
+		erpPercentile := "--"
+		if i.ERPPercentile >= 0 {
+			erpPercentile = fmt.Sprintf("%.2f%%", i.ERPPercentile)
+		}
+		table.Append([]string{
+			i.IndexName,
+			fmt.Sprintf("%.2f", i.PE),
+			fmt.Sprintf("%.2f%%", i.PEPercentile),
+			fmt.Sprintf("%.2f", i.PB),
+			fmt.Sprintf("%.2f%%", i.PBPercentile),
+			fmt.Sprintf("%.2f%%", i.ERP),
+			erpPercentile,
+			fmt.Sprintf("%.1f", i.Temperature),
+			i.Level,
+		})
+	}
+	table.SetCaption(true, fmt.Sprintf(
+		"%s 市场温度:%.1f(%s) 无风险利率:%.2f%% 参考股票仓位:%.0f%%",
+		temp.Date, temp.Temperature, temp.Level, temp.RiskFreeRate, temp.SuggestedEquityRatio,
+	))
+	table.Render()
+}
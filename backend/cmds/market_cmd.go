@@ -0,0 +1,87 @@
+// 市场温度
+
+package cmds
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorMarket 市场温度
+	ProcessorMarket = "market"
+)
+
+// FlagsMarket cli flags
+func FlagsMarket() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "date",
+			Aliases:  []string{"d"},
+			Value:    "",
+			Usage:    "查看数据库中指定日期的市场温度，不指定则实时计算",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Aliases:  []string{"c"},
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于读取数据库中的国债收益率和历史温度",
+			Required: false,
+		},
+	}
+}
+
+// ActionMarket cli action
+func ActionMarket() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+
+		// 数据库不可用时仍可实时计算，只是没有历史 ERP 百分位
+		initOptionalDatabase(c.String("config"))
+
+		var temp models.MarketTemperature
+		var err error
+		if date := c.String("date"); date != "" {
+			temp, err = models.LoadMarketTemperature(ctx, date)
+		} else {
+			temp, err = models.QueryMarketTemperature(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		showMarketTemperature(temp)
+		return nil
+	}
+}
+
+// initOptionalDatabase 按配置文件初始化数据库，失败时只记录日志，由调用方在 models.DB 为空时降级
+func initOptionalDatabase(configFile string) {
+	if models.DB != nil {
+		return
+	}
+	if err := models.LoadDatabaseConfig(configFile); err != nil {
+		logrus.Debug("load database config failed:" + err.Error())
+	} else if err := models.InitDatabase(); err != nil {
+		logrus.Warn("database initialization failed:" + err.Error())
+	}
+}
+
+// CommandMarket 市场温度 cli command
+func CommandMarket() *cli.Command {
+	flags := FlagsMarket()
+	cmd := &cli.Command{
+		Name:   ProcessorMarket,
+		Usage:  "市场温度：股权风险溢价、宽基指数估值百分位及参考股票仓位",
+		Flags:  flags,
+		Action: ActionMarket(),
+	}
+	return cmd
+}
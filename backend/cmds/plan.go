@@ -0,0 +1,73 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+func showAllocationPlan(plan *models.AllocationPlan) {
+	if plan.Suggestion != nil {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetHeader([]string{"基金", "建议权重", "年化收益", "年化波动率", "风险贡献"})
+		for _, w := range plan.Suggestion.Weights {
+			table.Append([]string{
+				fmt.Sprintf("%s(%s)", w.Name, w.Code),
+				fmt.Sprintf("%.2f%%", w.Weight),
+				fmt.Sprintf("%.2f%%", w.Return),
+				fmt.Sprintf("%.2f%%", w.Volatility),
+				fmt.Sprintf("%.2f%%", w.RiskContribution),
+			})
+		}
+		table.SetCaption(true, fmt.Sprintf(
+			"%s 共同交易日:%d 预期年化收益:%.2f%% 预期年化波动率:%.2f%%",
+			plan.Suggestion.Method, plan.Suggestion.Days, plan.Suggestion.ExpectedReturn, plan.Suggestion.Volatility,
+		))
+		table.Render()
+	}
+
+	exposures := tablewriter.NewWriter(os.Stdout)
+	exposures.SetAlignment(tablewriter.ALIGN_LEFT)
+	exposures.SetHeader([]string{"目标", "目标占比", "当前占比", "偏离", "调仓后占比"})
+	for _, e := range plan.Exposures {
+		exposures.Append([]string{
+			e.Name,
+			fmt.Sprintf("%.2f%%", e.Target),
+			fmt.Sprintf("%.2f%%", e.Current),
+			fmt.Sprintf("%+.2f", e.Drift),
+			fmt.Sprintf("%.2f%%", e.After),
+		})
+	}
+	exposures.SetCaption(true, fmt.Sprintf(
+		"总资产:%.2f 当前股/债/现金:%.2f%%/%.2f%%/%.2f%% 调仓后:%.2f%%/%.2f%%/%.2f%%",
+		plan.TotalValue, plan.CurrentMix.Equity, plan.CurrentMix.Bond, plan.CurrentMix.Cash,
+		plan.AfterMix.Equity, plan.AfterMix.Bond, plan.AfterMix.Cash,
+	))
+	exposures.Render()
+
+	if !plan.NeedRebalance {
+		fmt.Println("不需要再平衡：" + plan.Reason)
+		return
+	}
+	trades := tablewriter.NewWriter(os.Stdout)
+	trades.SetAlignment(tablewriter.ALIGN_LEFT)
+	trades.SetHeader([]string{"基金", "操作", "金额", "费用", "调仓后市值"})
+	for _, t := range plan.Trades {
+		action := "买入"
+		if t.Action == models.TradeSell {
+			action = "卖出"
+		}
+		trades.Append([]string{
+			fmt.Sprintf("%s(%s)", t.Name, t.Code),
+			action,
+			fmt.Sprintf("%.2f", t.Amount),
+			fmt.Sprintf("%.2f", t.Fee),
+			fmt.Sprintf("%.2f", t.After),
+		})
+	}
+	trades.SetCaption(true, fmt.Sprintf("%s 预计交易费用:%.2f", plan.Reason, plan.Fees))
+	trades.Render()
+}
@@ -0,0 +1,193 @@
+// 资产配置调仓计划
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorPlan 资产配置调仓计划
+	ProcessorPlan = "plan"
+)
+
+// FlagsPlan 调仓计划 cli flags
+func FlagsPlan() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "target",
+			Usage:    "目标配置，格式为 资产类别或基金代码:权重，如 equity:60 bond:30 cash:10 或 000001:60 000002:30",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:     "holding",
+			Usage:    "当前持仓，格式为 基金代码:市值[:持有天数]，如 000001:80000:400",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "cash",
+			Value:    0,
+			Usage:    "当前现金",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "candidates",
+			Usage:    "按资产类别配置时可以买入的基金代码",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "policy",
+			Value:    models.RebalancePolicyThreshold,
+			Usage:    "再平衡策略：calendar（定期）/threshold（偏离阈值）",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "frequency",
+			Value:    models.RebalanceQuarterly,
+			Usage:    "定期再平衡周期：monthly/quarterly/annual",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "last",
+			Value:    "",
+			Usage:    "上次再平衡日期",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "band",
+			Value:    5,
+			Usage:    "偏离阈值（百分点）",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "optimize",
+			Value:    "",
+			Usage:    "按净值协方差计算基金的建议权重并替换目标权重：mean_variance/risk_parity",
+			Required: false,
+		},
+		&cli.Float64Flag{
+			Name:     "risk_aversion",
+			Value:    3,
+			Usage:    "均值方差优化的风险厌恶系数",
+			Required: false,
+		},
+		&cli.IntFlag{
+			Name:     "days",
+			Value:    750,
+			Usage:    "计算协方差使用的交易日数",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，用于读取数据库中的基金信息和资产占比",
+			Required: false,
+		},
+	}
+}
+
+// parseAllocationTargets 解析 资产类别或基金代码:权重
+func parseAllocationTargets(values []string) ([]models.AllocationTarget, error) {
+	targets := []models.AllocationTarget{}
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid target: %s", v)
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target weight: %s", v)
+		}
+		t := models.AllocationTarget{Weight: weight}
+		switch parts[0] {
+		case models.AssetEquity, models.AssetBond, models.AssetCash:
+			t.Asset = parts[0]
+		default:
+			t.Code = parts[0]
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// parseAllocationHoldings 解析 基金代码:市值[:持有天数]
+func parseAllocationHoldings(values []string) ([]models.AllocationHolding, error) {
+	holdings := []models.AllocationHolding{}
+	for _, v := range values {
+		parts := strings.Split(v, ":")
+		if len(parts) != 2 && len(parts) != 3 {
+			return nil, fmt.Errorf("invalid holding: %s", v)
+		}
+		amount, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holding amount: %s", v)
+		}
+		h := models.AllocationHolding{Code: parts[0], Amount: amount}
+		if len(parts) == 3 {
+			if h.HoldDays, err = strconv.Atoi(parts[2]); err != nil {
+				return nil, fmt.Errorf("invalid holding days: %s", v)
+			}
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, nil
+}
+
+// ActionPlan 调仓计划 cli action
+func ActionPlan() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+		initOptionalDatabase(c.String("config"))
+
+		targets, err := parseAllocationTargets(c.StringSlice("target"))
+		if err != nil {
+			return err
+		}
+		holdings, err := parseAllocationHoldings(c.StringSlice("holding"))
+		if err != nil {
+			return err
+		}
+		params := models.AllocationParams{
+			Targets:       targets,
+			Holdings:      holdings,
+			Cash:          c.Float64("cash"),
+			Candidates:    c.StringSlice("candidates"),
+			Policy:        c.String("policy"),
+			Frequency:     c.String("frequency"),
+			LastRebalance: c.String("last"),
+			Band:          c.Float64("band"),
+			Optimize:      c.String("optimize"),
+			RiskAversion:  c.Float64("risk_aversion"),
+			Days:          c.Int("days"),
+		}
+		plan, err := models.BuildAllocationPlan(ctx, params)
+		if err != nil {
+			return err
+		}
+		showAllocationPlan(plan)
+		return nil
+	}
+}
+
+// CommandPlan 调仓计划 cli command
+func CommandPlan() *cli.Command {
+	cmd := &cli.Command{
+		Name:      ProcessorPlan,
+		Usage:     "资产配置与再平衡调仓计划",
+		UsageText: "按目标配置、当前持仓和再平衡策略计算扣除交易费用后回到目标所需的交易，资产类别按基金披露的资产占比穿透计算",
+		Flags:     FlagsPlan(),
+		Action:    ActionPlan(),
+	}
+	return cmd
+}
@@ -0,0 +1,143 @@
+// 筛选预设管理
+
+package cmds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorPreset 筛选预设
+	ProcessorPreset = "preset"
+)
+
+// FlagsPreset 筛选预设 cli flags
+func FlagsPreset() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，保存的预设存储在数据库中",
+			Required: false,
+		},
+	}
+}
+
+// flagsPresetSave 保存预设的参数，股票筛选和检测条件复用 exportor 的 filter 和 checker 参数
+func flagsPresetSave() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "name",
+			Aliases:  []string{"n"},
+			Usage:    "预设名称",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "desc",
+			Value:    "",
+			Usage:    "预设说明",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "fund_filter",
+			Value:    "",
+			Usage:    `基金筛选条件 JSON，如 {"min_scale":2,"year_1_rank_ratio":25}`,
+			Required: false,
+		},
+	}
+	flags = append(flags, FlagsFilter()...)
+	flags = append(flags, FlagsCheckerOptions()...)
+	return flags
+}
+
+func presetNameFlag() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "name", Aliases: []string{"n"}, Usage: "预设名称", Required: true},
+	}
+}
+
+// CommandPreset 筛选预设 cli command
+func CommandPreset() *cli.Command {
+	cmd := &cli.Command{
+		Name:  ProcessorPreset,
+		Usage: "筛选预设管理，保存的预设可在 exportor、checker、backtest 和基金检测中按名称使用",
+		Flags: FlagsPreset(),
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "列出内置预设和保存的预设",
+				Action: func(c *cli.Context) error {
+					ctx := context.Background()
+					initOptionalDatabase(c.String("config"))
+					presets, err := core.ListPresets(ctx)
+					if err != nil {
+						return err
+					}
+					showPresets(presets)
+					return nil
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "查看预设的筛选和检测条件",
+				Flags: presetNameFlag(),
+				Action: func(c *cli.Context) error {
+					ctx := context.Background()
+					initOptionalDatabase(c.String("config"))
+					preset, err := core.GetPreset(ctx, c.String("name"))
+					if err != nil {
+						return err
+					}
+					b, err := json.MarshalIndent(preset, "", "  ")
+					if err != nil {
+						return err
+					}
+					fmt.Println(string(b))
+					return nil
+				},
+			},
+			{
+				Name:  "save",
+				Usage: "按 filter 和 checker 参数保存预设，同名预设已存在时覆盖",
+				Flags: flagsPresetSave(),
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					preset := core.Preset{
+						Name:           c.String("name"),
+						Desc:           c.String("desc"),
+						Filter:         NewFilter(c),
+						CheckerOptions: NewCheckerOptions(c),
+					}
+					if s := c.String("fund_filter"); s != "" {
+						if err := json.Unmarshal([]byte(s), &preset.FundFilter); err != nil {
+							return fmt.Errorf("invalid fund_filter: %w", err)
+						}
+					}
+					if err := core.SavePreset(ctx, preset); err != nil {
+						return err
+					}
+					logrus.Infof("preset %s saved", preset.Name)
+					return nil
+				}),
+			},
+			{
+				Name:  "delete",
+				Usage: "删除保存的预设",
+				Flags: presetNameFlag(),
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					if err := core.DeletePreset(ctx, c.String("name")); err != nil {
+						return err
+					}
+					logrus.Infof("preset %s deleted", c.String("name"))
+					return nil
+				}),
+			},
+		},
+	}
+	return cmd
+}
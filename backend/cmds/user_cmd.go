@@ -0,0 +1,118 @@
+// 用户管理
+
+package cmds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorUser 用户管理
+	ProcessorUser = "user"
+)
+
+// cliUserID 按 --user 参数获取用户 ID，未指定时为 0
+func cliUserID(ctx context.Context, c *cli.Context) (uint, error) {
+	username := c.String("user")
+	if username == "" {
+		return 0, nil
+	}
+	user, err := models.GetUserByName(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// CommandUser 用户管理 cli command
+func CommandUser() *cli.Command {
+	usernameFlag := &cli.StringFlag{Name: "username", Aliases: []string{"n"}, Usage: "用户名", Required: true}
+	passwordFlag := &cli.StringFlag{Name: "password", Aliases: []string{"p"}, Usage: fmt.Sprintf("密码，至少 %d 位", auth.MinPasswordLength), Required: true}
+	roleFlag := &cli.StringFlag{Name: "role", Aliases: []string{"r"}, Value: models.RoleUser, Usage: "角色：admin/user/viewer"}
+	cmd := &cli.Command{
+		Name:  ProcessorUser,
+		Usage: "用户管理，开启认证后可用于创建管理员或在关闭注册时添加用户",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "config",
+				Value:    "./config.yaml",
+				Usage:    "配置文件，用户保存在数据库中",
+				Required: false,
+			},
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "列出全部用户",
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					users, err := models.ListUsers(ctx)
+					if err != nil {
+						return err
+					}
+					showUsers(users)
+					return nil
+				}),
+			},
+			{
+				Name:  "create",
+				Usage: "创建用户，管理员需要通过该命令指定 --role admin 创建",
+				Flags: []cli.Flag{usernameFlag, passwordFlag, roleFlag},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					hash, err := auth.HashPassword(c.String("password"))
+					if err != nil {
+						return err
+					}
+					user := &models.UserDB{Username: c.String("username"), PasswordHash: hash, Role: c.String("role")}
+					if err := models.CreateUser(ctx, user); err != nil {
+						return err
+					}
+					logrus.Infof("user %s created with role %s", user.Username, user.Role)
+					return nil
+				}),
+			},
+			{
+				Name:  "role",
+				Usage: "修改用户角色",
+				Flags: []cli.Flag{usernameFlag, roleFlag},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					user, err := models.GetUserByName(ctx, c.String("username"))
+					if err != nil {
+						return err
+					}
+					if _, err := models.UpdateUserRole(ctx, user.ID, c.String("role")); err != nil {
+						return err
+					}
+					logrus.Infof("user %s role changed to %s", user.Username, c.String("role"))
+					return nil
+				}),
+			},
+			{
+				Name:  "passwd",
+				Usage: "重置用户密码",
+				Flags: []cli.Flag{usernameFlag, passwordFlag},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					user, err := models.GetUserByName(ctx, c.String("username"))
+					if err != nil {
+						return err
+					}
+					hash, err := auth.HashPassword(c.String("password"))
+					if err != nil {
+						return err
+					}
+					if err := models.UpdateUserPassword(ctx, user.ID, hash); err != nil {
+						return err
+					}
+					logrus.Infof("user %s password reset", user.Username)
+					return nil
+				}),
+			},
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,68 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+func showWatchlists(watchlists []models.Watchlist) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"名称", "类型", "数量", "标签", "备注", "更新时间"})
+	for _, w := range watchlists {
+		table.Append([]string{
+			w.Name,
+			w.Kind,
+			fmt.Sprint(len(w.Items)),
+			strings.Join(w.Tags, ","),
+			w.Note,
+			w.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	table.Render()
+}
+
+func showWatchlist(w *models.Watchlist) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"代码", "标签", "备注", "添加时间"})
+	for _, item := range w.Items {
+		table.Append([]string{
+			item.Code,
+			strings.Join(item.Tags, ","),
+			item.Note,
+			item.CreatedAt.Format("2006-01-02"),
+		})
+	}
+	table.SetCaption(true, fmt.Sprintf("%s(%s) %s 标签:%s 引用方式:%s%s", w.Name, w.Kind, w.Note, strings.Join(w.Tags, ","), models.WatchlistPrefix, w.Name))
+	table.Render()
+}
+
+func showPresets(presets []core.Preset) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"名称", "内置", "说明"})
+	for _, p := range presets {
+		table.Append([]string{p.Name, fmt.Sprint(p.Builtin), p.Desc})
+	}
+	table.Render()
+}
+
+func showUsers(users []models.UserDB) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeader([]string{"ID", "用户名", "角色", "最近登录", "创建时间"})
+	for _, u := range users {
+		lastLogin := "--"
+		if u.LastLoginAt != nil {
+			lastLogin = u.LastLoginAt.Format("2006-01-02 15:04:05")
+		}
+		table.Append([]string{fmt.Sprint(u.ID), u.Username, u.Role, lastLogin, u.CreatedAt.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+}
@@ -0,0 +1,226 @@
+// 自选列表管理
+
+package cmds
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// ProcessorWatchlist 自选列表
+	ProcessorWatchlist = "watchlist"
+)
+
+// FlagsWatchlist 自选列表 cli flags
+func FlagsWatchlist() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "config",
+			Value:    "./config.yaml",
+			Usage:    "配置文件，自选列表保存在数据库中",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "user",
+			Aliases:  []string{"u"},
+			Value:    "",
+			Usage:    "自选列表所属用户名，为空时管理未开启认证时使用的共享列表",
+			Required: false,
+		},
+	}
+}
+
+// flagsWatchlistItems 自选列表条目参数
+func flagsWatchlistItems() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "name",
+			Aliases:  []string{"n"},
+			Usage:    "自选列表名称",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:     "code",
+			Aliases:  []string{"c"},
+			Usage:    "基金代码或股票代码，可指定多个",
+			Required: false,
+		},
+		&cli.StringFlag{
+			Name:     "note",
+			Value:    "",
+			Usage:    "备注",
+			Required: false,
+		},
+		&cli.StringSliceFlag{
+			Name:     "tags",
+			Usage:    "标签，可指定多个",
+			Required: false,
+		},
+	}
+}
+
+func newWatchlistItems(c *cli.Context) []models.WatchlistItem {
+	items := []models.WatchlistItem{}
+	for _, code := range c.StringSlice("code") {
+		items = append(items, models.WatchlistItem{Code: code, Note: c.String("note"), Tags: c.StringSlice("tags")})
+	}
+	return items
+}
+
+// databaseAction 初始化数据库后执行需要数据库的子命令
+func databaseAction(fn func(ctx context.Context, c *cli.Context) error) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		loglevel := c.String("loglevel")
+		if lvl, err := logrus.ParseLevel(loglevel); err == nil {
+			logrus.SetLevel(lvl)
+		}
+		if err := models.LoadDatabaseConfig(c.String("config")); err != nil {
+			return err
+		}
+		if err := models.InitDatabase(); err != nil {
+			return err
+		}
+		return fn(context.Background(), c)
+	}
+}
+
+// CommandWatchlist 自选列表 cli command
+func CommandWatchlist() *cli.Command {
+	cmd := &cli.Command{
+		Name:  ProcessorWatchlist,
+		Usage: "自选列表管理，股票检测和基金检测中可使用 watchlist:<name> 引用自选列表",
+		Flags: FlagsWatchlist(),
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "列出全部自选列表",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "kind", Usage: "按类型过滤：fund/stock"},
+					&cli.StringFlag{Name: "tag", Usage: "按标签过滤"},
+				},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					watchlists, err := models.ListWatchlists(ctx, userID, c.String("kind"), c.String("tag"))
+					if err != nil {
+						return err
+					}
+					showWatchlists(watchlists)
+					return nil
+				}),
+			},
+			{
+				Name:  "show",
+				Usage: "查看自选列表中的条目",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Aliases: []string{"n"}, Usage: "自选列表名称", Required: true},
+				},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					w, err := models.GetWatchlist(ctx, userID, c.String("name"))
+					if err != nil {
+						return err
+					}
+					showWatchlist(w)
+					return nil
+				}),
+			},
+			{
+				Name:  "create",
+				Usage: "创建自选列表，同名列表已存在时整体替换",
+				Flags: append(flagsWatchlistItems(), &cli.StringFlag{
+					Name:  "kind",
+					Value: models.WatchlistKindFund,
+					Usage: "列表类型：fund/stock",
+				}),
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					w := models.Watchlist{
+						Name:  c.String("name"),
+						Kind:  c.String("kind"),
+						Note:  c.String("note"),
+						Tags:  c.StringSlice("tags"),
+						Items: []models.WatchlistItem{},
+					}
+					for _, code := range c.StringSlice("code") {
+						w.Items = append(w.Items, models.WatchlistItem{Code: code})
+					}
+					if err := models.SaveWatchlist(ctx, userID, w); err != nil {
+						return err
+					}
+					saved, err := models.GetWatchlist(ctx, userID, w.Name)
+					if err != nil {
+						return err
+					}
+					showWatchlist(saved)
+					return nil
+				}),
+			},
+			{
+				Name:  "add",
+				Usage: "向自选列表添加代码，已存在的代码更新备注和标签",
+				Flags: flagsWatchlistItems(),
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					w, err := models.AddWatchlistItems(ctx, userID, c.String("name"), newWatchlistItems(c))
+					if err != nil {
+						return err
+					}
+					showWatchlist(w)
+					return nil
+				}),
+			},
+			{
+				Name:  "remove",
+				Usage: "从自选列表移除代码",
+				Flags: flagsWatchlistItems(),
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					w, err := models.RemoveWatchlistItems(ctx, userID, c.String("name"), c.StringSlice("code"))
+					if err != nil {
+						return err
+					}
+					showWatchlist(w)
+					return nil
+				}),
+			},
+			{
+				Name:  "delete",
+				Usage: "删除自选列表",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Aliases: []string{"n"}, Usage: "自选列表名称", Required: true},
+				},
+				Action: databaseAction(func(ctx context.Context, c *cli.Context) error {
+					userID, err := cliUserID(ctx, c)
+					if err != nil {
+						return err
+					}
+					if err := models.DeleteWatchlist(ctx, userID, c.String("name")); err != nil {
+						return err
+					}
+					logrus.Infof("watchlist %s deleted", c.String("name"))
+					return nil
+				}),
+			},
+		},
+	}
+	return cmd
+}
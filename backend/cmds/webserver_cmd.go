@@ -5,7 +5,11 @@ package cmds
 import (
 	"time"
 
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/cron"
+	"github.com/axiaoxin-com/investool/middleware"
 	"github.com/axiaoxin-com/investool/models"
+	"github.com/axiaoxin-com/investool/notify"
 	"github.com/axiaoxin-com/investool/routes"
 	"github.com/axiaoxin-com/investool/webserver"
 	"github.com/go-co-op/gocron"
@@ -39,6 +43,24 @@ func ActionWebserver() func(c *cli.Context) error {
 		// 初始化 viper（webserver 需要）
 		webserver.InitWithConfigFile(configFile)
 
+		// 初始化告警和任务失败通知
+		if err := notify.InitFromViper(); err != nil {
+			logrus.Warn("notify initialization failed:" + err.Error())
+		}
+
+		// 初始化用户认证
+		if err := auth.InitFromViper(); err != nil {
+			logrus.Warn("auth initialization failed:" + err.Error())
+		}
+		if err := middleware.CheckCORSConfig(); err != nil {
+			logrus.Warn(err.Error())
+		}
+
+		// 初始化限流
+		if err := middleware.InitRateLimitFromViper(); err != nil {
+			logrus.Warn("ratelimit initialization failed:" + err.Error())
+		}
+
 		// 加载数据库配置
 		if err := models.LoadDatabaseConfig(configFile); err != nil {
 			// 加载失败不影响运行
@@ -52,6 +74,8 @@ func ActionWebserver() func(c *cli.Context) error {
 
 		// 启动定时任务：每3天执行一次 SyncFund
 		startSyncFundScheduler()
+		// 启动配置文件中 app.cronexp 指定的定时任务
+		cron.RunCronJobs(true)
 
 		server := webserver.NewGinEngine()
 		// 注册路由
@@ -0,0 +1,274 @@
+// 告警规则评估：按规则类型获取监控对象的最新数据，与上次状态比较后记录告警
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/axiaoxin-com/investool/notify"
+	"github.com/sirupsen/logrus"
+)
+
+// 各同步任务完成后需要评估的告警规则类型
+var (
+	// AlertTypesAfterSyncFund 基金同步后评估
+	AlertTypesAfterSyncFund = []string{models.AlertFund4433Lost, models.AlertFundScaleBelow}
+	// AlertTypesAfterSyncFundManagers 基金经理同步后评估
+	AlertTypesAfterSyncFundManagers = []string{models.AlertFundManagerChange}
+	// AlertTypesAfterSyncIndexValuation 指数估值同步后评估
+	AlertTypesAfterSyncIndexValuation = []string{models.AlertIndexPEBelow}
+	// AlertTypesAfterSyncDaily 每日行情同步后评估
+	AlertTypesAfterSyncDaily = []string{models.AlertStockPriceSpace, models.AlertStockCheckFailed}
+)
+
+// AlertEvaluation 一次告警评估的结果
+type AlertEvaluation struct {
+	Rules  int              `json:"rules"`
+	Alerts []models.AlertDB `json:"alerts"`
+}
+
+// EvaluateAlerts 评估指定类型的启用规则，types 为空时评估全部启用规则
+func EvaluateAlerts(ctx context.Context, types ...string) (*AlertEvaluation, error) {
+	rules, err := models.ListAlertRules(ctx, true, types...)
+	if err != nil {
+		return nil, err
+	}
+	result := &AlertEvaluation{Rules: len(rules), Alerts: []models.AlertDB{}}
+	for _, rule := range rules {
+		alerts, err := EvaluateAlertRule(ctx, rule)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("EvaluateAlertRule id:%d type:%s err:%v", rule.ID, rule.Type, err)
+			continue
+		}
+		result.Alerts = append(result.Alerts, alerts...)
+	}
+	return result, nil
+}
+
+// EvaluateAlertRule 评估单条规则并保存状态，返回本次触发的告警
+func EvaluateAlertRule(ctx context.Context, rule models.AlertRuleDB) ([]models.AlertDB, error) {
+	targets, err := models.ExpandWatchlistCodes(ctx, rule.UserID, models.AlertTargetKind(rule.Type), goutils.SplitStringFields(rule.Targets))
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	states, err := models.LoadAlertStates(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	observations, err := observeAlertTargets(ctx, rule, targets)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	alerts := []models.AlertDB{}
+	for _, obs := range observations {
+		state, alert := models.NextAlertState(rule, states[obs.Target], obs, now)
+		if err := models.SaveAlertResult(ctx, state, alert); err != nil {
+			return alerts, err
+		}
+		if alert != nil {
+			alerts = append(alerts, *alert)
+			notify.Notify(ctx, notify.EventAlert, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func observeAlertTargets(ctx context.Context, rule models.AlertRuleDB, targets []string) ([]models.AlertObservation, error) {
+	switch rule.Type {
+	case models.AlertFund4433Lost, models.AlertFundScaleBelow:
+		return observeFunds(ctx, rule, targets)
+	case models.AlertFundManagerChange:
+		return observeFundManagers(ctx, targets)
+	case models.AlertIndexPEBelow:
+		return observeIndexValuations(ctx, rule, targets)
+	case models.AlertStockPriceSpace, models.AlertStockCheckFailed:
+		return observeStocks(ctx, rule, targets)
+	}
+	return nil, fmt.Errorf("%w: type %s", models.ErrInvalidAlertRule, rule.Type)
+}
+
+// observeFunds 从数据库读取基金的 4433 状态和规模
+func observeFunds(ctx context.Context, rule models.AlertRuleDB, codes []string) ([]models.AlertObservation, error) {
+	funds := []models.FundDB{}
+	if err := models.DB.WithContext(ctx).Where("code IN ?", codes).Find(&funds).Error; err != nil {
+		return nil, err
+	}
+	result := []models.AlertObservation{}
+	for _, f := range funds {
+		obs := models.AlertObservation{Target: f.Code, Name: f.Name}
+		if rule.Type == models.AlertFund4433Lost {
+			obs.Active = !f.Is4433
+			obs.Value = fmt.Sprint(f.Is4433)
+			obs.Message = fmt.Sprintf("%s(%s) 不再满足4433法则", f.Name, f.Code)
+		} else {
+			scale := f.NetAssetsScale / 100000000
+			obs.Active = scale < rule.Threshold
+			obs.Value = fmt.Sprintf("%.2f", scale)
+			obs.Message = fmt.Sprintf("%s(%s) 规模 %.2f 亿，低于 %.2f 亿", f.Name, f.Code, scale, rule.Threshold)
+		}
+		result = append(result, obs)
+	}
+	return result, nil
+}
+
+// observeFundManagers 读取基金经理管理的基金关联表中基金的现任经理
+func observeFundManagers(ctx context.Context, codes []string) ([]models.AlertObservation, error) {
+	rows := []models.FundManagerFundsDB{}
+	if err := models.DB.WithContext(ctx).Where("fund_code IN ?", codes).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	managerIDs := []string{}
+	fundManagers := map[string][]string{}
+	fundNames := map[string]string{}
+	for _, row := range rows {
+		managerIDs = append(managerIDs, row.ManagerID)
+		fundManagers[row.FundCode] = append(fundManagers[row.FundCode], row.ManagerID)
+		fundNames[row.FundCode] = row.FundName
+	}
+	managers := []models.FundManagerDB{}
+	if err := models.DB.WithContext(ctx).Where("id IN ?", managerIDs).Find(&managers).Error; err != nil {
+		return nil, err
+	}
+	managerNames := map[string]string{}
+	for _, m := range managers {
+		managerNames[m.ID] = m.Name
+	}
+
+	result := []models.AlertObservation{}
+	for _, code := range codes {
+		ids, ok := fundManagers[code]
+		if !ok {
+			continue
+		}
+		sort.Strings(ids)
+		names := []string{}
+		for _, id := range ids {
+			names = append(names, managerNames[id])
+		}
+		result = append(result, models.AlertObservation{
+			Target:  code,
+			Name:    fundNames[code],
+			Value:   strings.Join(ids, ","),
+			Message: fmt.Sprintf("%s(%s) 基金经理变更为 %s", fundNames[code], code, strings.Join(names, "、")),
+		})
+	}
+	return result, nil
+}
+
+// observeIndexValuations 读取指数最新一日的 PE 百分位
+func observeIndexValuations(ctx context.Context, rule models.AlertRuleDB, codes []string) ([]models.AlertObservation, error) {
+	threshold := rule.Threshold
+	if threshold == 0 {
+		threshold = models.DefaultAlertIndexPEPercentile
+	}
+	result := []models.AlertObservation{}
+	for _, code := range codes {
+		valuations, err := models.LoadIndexValuations(ctx, code, "", "")
+		if err != nil {
+			return nil, err
+		}
+		if len(valuations) == 0 {
+			continue
+		}
+		v := valuations[len(valuations)-1]
+		result = append(result, models.AlertObservation{
+			Target:  code,
+			Name:    v.IndexName,
+			Active:  v.PEPercentile < threshold,
+			Value:   fmt.Sprintf("%.2f", v.PEPercentile),
+			Message: fmt.Sprintf("%s(%s) %s PE百分位 %.2f%%，低于 %.2f%%", v.IndexName, code, v.Date, v.PEPercentile, threshold),
+		})
+	}
+	return result, nil
+}
+
+// AlertStockSecucodes 返回启用的股票告警规则监控的股票代码（带后缀），按出现顺序去重
+func AlertStockSecucodes(ctx context.Context) ([]string, error) {
+	rules, err := models.ListAlertRules(ctx, true, AlertTypesAfterSyncDaily...)
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	seen := map[string]bool{}
+	for _, rule := range rules {
+		keywords, err := models.ExpandWatchlistCodes(ctx, rule.UserID, models.AlertTargetKind(rule.Type), goutils.SplitStringFields(rule.Targets))
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("AlertStockSecucodes rule id:%d ExpandWatchlistCodes err:%v", rule.ID, err)
+			continue
+		}
+		for _, kw := range keywords {
+			searchResults, err := datacenter.Sina.KeywordSearch(ctx, kw)
+			if err != nil || len(searchResults) == 0 {
+				logrus.WithContext(ctx).Warnf("AlertStockSecucodes search %s no data, err:%v", kw, err)
+				continue
+			}
+			secucode := searchResults[0].Secucode
+			if !seen[secucode] {
+				seen[secucode] = true
+				result = append(result, secucode)
+			}
+		}
+	}
+	return result, nil
+}
+
+// observeStocks 实时获取股票数据，计算合理价差或检测基本面
+func observeStocks(ctx context.Context, rule models.AlertRuleDB, keywords []string) ([]models.AlertObservation, error) {
+	opts := DefaultCheckerOptions
+	if rule.Type == models.AlertStockCheckFailed && rule.Preset != "" {
+		preset, err := GetPreset(ctx, rule.Preset)
+		if err != nil {
+			return nil, err
+		}
+		opts = preset.CheckerOptions
+	}
+	searcher := NewSearcher(ctx)
+	searcher.CheckYears = opts.CheckYears
+	stocks, err := searcher.SearchStocks(ctx, keywords)
+	if err != nil {
+		return nil, err
+	}
+	checker := NewChecker(ctx, opts)
+
+	result := []models.AlertObservation{}
+	for _, stock := range stocks {
+		name := stock.BaseInfo.SecurityNameAbbr
+		code := stock.BaseInfo.SecurityCode
+		obs := models.AlertObservation{Target: code, Name: name}
+		if rule.Type == models.AlertStockPriceSpace {
+			obs.Value = fmt.Sprintf("%.2f", stock.PriceSpace)
+			if rule.Direction == models.AlertDirectionAbove {
+				obs.Active = stock.PriceSpace >= rule.Threshold
+				obs.Message = fmt.Sprintf("%s(%s) 合理价差 %.2f%%，上穿 %.2f%%", name, code, stock.PriceSpace, rule.Threshold)
+			} else {
+				obs.Active = stock.PriceSpace <= rule.Threshold
+				obs.Message = fmt.Sprintf("%s(%s) 合理价差 %.2f%%，下穿 %.2f%%", name, code, stock.PriceSpace, rule.Threshold)
+			}
+		} else {
+			checkResult, ok := checker.Check(ctx, stock)
+			failed := []string{}
+			for item, r := range checkResult {
+				if r["ok"] == "false" {
+					failed = append(failed, item)
+				}
+			}
+			sort.Strings(failed)
+			obs.Active = !ok
+			obs.Value = strings.Join(failed, ",")
+			obs.Message = fmt.Sprintf("%s(%s) 基本面检测不通过：%s", name, code, strings.Join(failed, "、"))
+		}
+		result = append(result, obs)
+	}
+	return result, nil
+}
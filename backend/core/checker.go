@@ -12,6 +12,7 @@ import (
 
 	"github.com/axiaoxin-com/goutils"
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/indicators"
 	"github.com/axiaoxin-com/investool/models"
 	mapset "github.com/deckarep/golang-set"
 	"github.com/sirupsen/logrus"
@@ -29,6 +30,8 @@ type CheckerOptions struct {
 	MaxDebtAssetRatio float64 `json:"max_debt_asset_ratio"    form:"checker_max_debt_asset_ratio"`
 	// 最大历史波动率
 	MaxHV float64 `json:"max_hv"                  form:"checker_max_hv"`
+	// 历史波动率窗口（交易日），为 0 时使用上市以来的历史波动率
+	HVWindow int `json:"hv_window"               form:"checker_hv_window"`
 	// 最小市值（亿）
 	MinTotalMarketCap float64 `json:"min_total_market_cap"    form:"checker_min_total_market_cap"`
 	// 银行股最小 ROA
@@ -67,6 +70,22 @@ type CheckerOptions struct {
 	IsCheckNetprofitGrow bool `json:"is_check_netprofit_grow" form:"checker_is_check_netprofit_grow"`
 	// 最低股息率
 	MinGxl float64 `json:"min_gxl"                 form:"checker_min_gxl"`
+	// 最少连续分红年数
+	MinDivYears int `json:"min_div_years"           form:"checker_min_div_years"`
+	// 最低每股分红复合增长率(%)
+	MinDivCAGR float64 `json:"min_div_cagr"            form:"checker_min_div_cagr"`
+	// 最大股利支付率标准差
+	MaxPayoutRatioStdDev float64 `json:"max_payout_ratio_std_dev" form:"checker_max_payout_ratio_std_dev"`
+	// 最低自由现金流分红覆盖倍数
+	MinDivFCFCoverage float64 `json:"min_div_fcf_coverage"    form:"checker_min_div_fcf_coverage"`
+	// 是否检测价格趋势（股价在均线之上）
+	IsCheckTrend bool `json:"is_check_trend"          form:"checker_is_check_trend"`
+	// 趋势检测使用的均线周期（日）
+	TrendMAPeriod int `json:"trend_ma_period"         form:"checker_trend_ma_period"`
+	// 距最近高点的最大回撤(%)
+	MaxDrawdownFromHigh float64 `json:"max_drawdown_from_high"  form:"checker_max_drawdown_from_high"`
+	// 金融股专项检测选项
+	FinancialCheckerOptions
 }
 
 // DefaultCheckerOptions 默认检测值
@@ -76,6 +95,7 @@ var DefaultCheckerOptions = CheckerOptions{
 	NoCheckYearsROE:      20.0,
 	MaxDebtAssetRatio:    60.0,
 	MaxHV:                1.0,
+	HVWindow:             250,
 	MinTotalMarketCap:    100.0,
 	BankMinROA:           0.5,
 	BankMinZBCZL:         8.0,
@@ -95,11 +115,31 @@ var DefaultCheckerOptions = CheckerOptions{
 	IsCheckRevGrow:       true,
 	IsCheckNetprofitGrow: true,
 	MinGxl:               0.0,
+	MinDivYears:          0,
+	MinDivCAGR:           0.0,
+	MaxPayoutRatioStdDev: 0.0,
+	MinDivFCFCoverage:    0.0,
+	IsCheckTrend:         false,
+	TrendMAPeriod:        250,
+	MaxDrawdownFromHigh:  0.0,
+
+	FinancialCheckerOptions: DefaultFinancialCheckerOptions,
 }
 
 // Checker 检测器实例
 type Checker struct {
 	Options CheckerOptions
+	// 历史时点，为零值时使用当前时间。非零值时股票应为 models.StockHistory.AsOf 还原的数据，
+	// 只有最新数据的检测项（整体质地、行业估值、四率估值）不做检测
+	AsOf time.Time
+}
+
+// now 检测使用的当前时间
+func (c Checker) now() time.Time {
+	if c.AsOf.IsZero() {
+		return time.Now()
+	}
+	return c.AsOf
 }
 
 // NewChecker 创建检查器实例
@@ -126,13 +166,17 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 	checkItemName := "净资产收益率(ROE)"
 	itemOK := true
 	// 最新一期的年报
-	lastYearReport := stock.HistoricalFinaMainData.GetReport(ctx, time.Now().Year()-1, eastmoney.FinaReportTypeYear)
+	lastYearReport := stock.HistoricalFinaMainData.GetReport(ctx, c.now().Year()-1, eastmoney.FinaReportTypeYear)
 	// nil fix: 新的一年刚开始，这时上一年的年报还没有披露
 	if lastYearReport == nil {
-		lastYearReport = stock.HistoricalFinaMainData.GetReport(ctx, time.Now().Year()-2, eastmoney.FinaReportTypeYear)
+		lastYearReport = stock.HistoricalFinaMainData.GetReport(ctx, c.now().Year()-2, eastmoney.FinaReportTypeYear)
 	}
 	// 最新一期的财报
 	curReport := stock.HistoricalFinaMainData.CurrentReport(ctx)
+	// 上市不久没有年报时使用最新一期财报
+	if lastYearReport == nil {
+		lastYearReport = curReport
+	}
 	desc := fmt.Sprintf("%sROE:%.2f%%，同比增长:%.2f%%<br/>%sROE:%.2f%%，同比增长:%.2f%%",
 		lastYearReport.ReportDateName, lastYearReport.Roejq, lastYearReport.Roejqtz,
 		curReport.ReportDateName, curReport.Roejq, curReport.Roejqtz)
@@ -285,53 +329,9 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 		"ok":   fmt.Sprint(itemOK),
 	}
 
-	// 整体质地
-	checkItemName = "整体质地"
-	itemOK = true
-	desc = stock.JZPG.GetValueTotalScore()
-	if !goutils.IsStrInSlice(stock.JZPG.GetValueTotalScore(), []string{"优秀", "良好"}) {
-		ok = false
-		itemOK = false
-	}
-	result[checkItemName] = map[string]string{
-		"desc": desc,
-		"ok":   fmt.Sprint(itemOK),
-	}
-
-	// 行业均值水平估值
-	checkItemName = "行业均值水平估值"
-	itemOK = true
-	desc = stock.JZPG.GetValuationScore()
-	if stock.JZPG.GetValuationScore() == "高于行业均值水平" {
-		ok = false
-		itemOK = false
-	}
-	result[checkItemName] = map[string]string{
-		"desc": desc,
-		"ok":   fmt.Sprint(itemOK),
-	}
-
-	// 市盈率、市净率、市现率、市销率全部估值较高
-	checkItemName = "四率估值"
-	itemOK = true
-	allHighValuation := true
-	valuationDesc := []string{}
-	for k, v := range stock.ValuationMap {
-		valuationDesc = append(valuationDesc, k+v)
-	}
-	for _, v := range stock.ValuationMap {
-		if v != "估值较高" {
-			allHighValuation = false
-			break
-		}
-	}
-	if allHighValuation {
-		ok = false
-		itemOK = false
-	}
-	result[checkItemName] = map[string]string{
-		"desc": strings.Join(valuationDesc, "<br/>"),
-		"ok":   fmt.Sprint(itemOK),
+	// 价值评估和四率估值接口只有最新数据，历史时点不检测
+	if c.AsOf.IsZero() {
+		c.checkValuationScore(stock, result, &ok)
 	}
 
 	// 股价低于合理价格
@@ -379,10 +379,18 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 	// 历史波动率 （可选条件）
 	checkItemName = "历史波动率"
 	itemOK = true
-	desc = fmt.Sprintf("历史波动率:%f", stock.HistoricalVolatility)
-	if c.Options.MaxHV != 0 {
-		if stock.HistoricalVolatility > c.Options.MaxHV {
-			desc = fmt.Sprintf("历史波动率:%f<br/>高于:%f", stock.HistoricalVolatility, c.Options.MaxHV)
+	hvName := "历史波动率"
+	if c.Options.HVWindow > 0 {
+		hvName = fmt.Sprintf("%d日年化波动率", c.Options.HVWindow)
+	}
+	// 指定窗口时使用窗口内的年化已实现波动率，K线数据不足时不做检测
+	hv, hvOK := stock.Volatility(c.Options.HVWindow)
+	desc = fmt.Sprintf("%s:%f", hvName, hv)
+	if !hvOK {
+		desc = fmt.Sprintf("%s:数据不足", hvName)
+	} else if c.Options.MaxHV != 0 {
+		if hv > c.Options.MaxHV {
+			desc = fmt.Sprintf("%s:%f<br/>高于:%f", hvName, hv, c.Options.MaxHV)
 			ok = false
 			itemOK = false
 		}
@@ -392,6 +400,43 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 		"ok":   fmt.Sprint(itemOK),
 	}
 
+	// 价格趋势 （可选条件）
+	if len(stock.HistoricalKlines) > 0 {
+		closes := stock.HistoricalKlines.Closes()
+		lastClose := indicators.Last(closes)
+		checkItemName = "均线趋势"
+		itemOK = true
+		if len(closes) >= c.Options.TrendMAPeriod && c.Options.TrendMAPeriod > 0 {
+			ma := indicators.Last(indicators.MA(closes, c.Options.TrendMAPeriod))
+			desc = fmt.Sprintf("最新收盘价:%.2f<br/>%d日均线:%.2f", lastClose, c.Options.TrendMAPeriod, ma)
+			if c.Options.IsCheckTrend && lastClose < ma {
+				desc += "<br/>股价低于均线"
+				ok = false
+				itemOK = false
+			}
+		} else {
+			desc = fmt.Sprintf("K线数据不足%d日", c.Options.TrendMAPeriod)
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "距高点回撤"
+		itemOK = true
+		dd := indicators.Last(indicators.DrawdownFromHigh(closes))
+		desc = fmt.Sprintf("%d日内距最高点回撤:%.2f%%", len(closes), dd)
+		if c.Options.MaxDrawdownFromHigh != 0 && -dd > c.Options.MaxDrawdownFromHigh {
+			desc += fmt.Sprintf("<br/>超过:%.2f%%", c.Options.MaxDrawdownFromHigh)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+	}
+
 	// 市值
 	checkItemName = "市值"
 	itemOK = true
@@ -626,6 +671,56 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 		"ok":   fmt.Sprint(itemOK),
 	}
 
+	// 分红连续性及增长
+	da := stock.DividendAnalysis
+	checkItemName = "分红连续性"
+	itemOK = true
+	desc = fmt.Sprintf("截止%d年连续分红:%d年<br/>每股分红复合增长率:%.2f%%", da.LastYear, da.ConsecutiveYears, da.CAGR)
+	if c.Options.MinDivYears != 0 && da.ConsecutiveYears < c.Options.MinDivYears {
+		desc = fmt.Sprintf("截止%d年连续分红:%d年<br/>少于:%d年", da.LastYear, da.ConsecutiveYears, c.Options.MinDivYears)
+		ok = false
+		itemOK = false
+	}
+	if c.Options.MinDivCAGR != 0 && da.CAGR < c.Options.MinDivCAGR {
+		desc += fmt.Sprintf("<br/>分红增长率低于:%.2f%%", c.Options.MinDivCAGR)
+		ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+
+	// 股利支付率稳定性
+	checkItemName = "股利支付率稳定性"
+	itemOK = true
+	desc = fmt.Sprintf("历年股利支付率:<br/>%v<br/>标准差:%.2f", eastmoney.FinaValueList(da.PayoutRatioList), da.PayoutRatioStdDev)
+	if c.Options.MaxPayoutRatioStdDev != 0 {
+		if len(da.PayoutRatioList) < 2 || da.PayoutRatioStdDev > c.Options.MaxPayoutRatioStdDev {
+			desc += fmt.Sprintf("<br/>高于:%.2f", c.Options.MaxPayoutRatioStdDev)
+			ok = false
+			itemOK = false
+		}
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+
+	// 自由现金流对分红的覆盖
+	checkItemName = "分红现金流覆盖"
+	itemOK = true
+	desc = fmt.Sprintf("%d年自由现金流/分红总额:%.2f", da.LastYear, da.FCFCoverage)
+	if c.Options.MinDivFCFCoverage != 0 && da.FCFCoverage < c.Options.MinDivFCFCoverage {
+		desc += fmt.Sprintf("<br/>低于:%.2f", c.Options.MinDivFCFCoverage)
+		ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+
 	// 负债流动比检测
 	checkItemName = "负债流动比"
 	itemOK = true
@@ -674,6 +769,58 @@ func (c Checker) CheckFundamentals(ctx context.Context, stock models.Stock) (res
 	return
 }
 
+// checkValuationScore 检测价值评估和四率估值
+func (c Checker) checkValuationScore(stock models.Stock, result CheckResult, ok *bool) {
+	// 整体质地
+	checkItemName := "整体质地"
+	itemOK := true
+	desc := stock.JZPG.GetValueTotalScore()
+	if !goutils.IsStrInSlice(stock.JZPG.GetValueTotalScore(), []string{"优秀", "良好"}) {
+		*ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+
+	// 行业均值水平估值
+	checkItemName = "行业均值水平估值"
+	itemOK = true
+	desc = stock.JZPG.GetValuationScore()
+	if stock.JZPG.GetValuationScore() == "高于行业均值水平" {
+		*ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+
+	// 市盈率、市净率、市现率、市销率全部估值较高
+	checkItemName = "四率估值"
+	itemOK = true
+	allHighValuation := true
+	valuationDesc := []string{}
+	for k, v := range stock.ValuationMap {
+		valuationDesc = append(valuationDesc, k+v)
+	}
+	for _, v := range stock.ValuationMap {
+		if v != "估值较高" {
+			allHighValuation = false
+			break
+		}
+	}
+	if allHighValuation {
+		*ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": strings.Join(valuationDesc, "<br/>"),
+		"ok":   fmt.Sprint(itemOK),
+	}
+}
+
 // FundStocksCheckResult 股票持仓检测结果
 type FundStocksCheckResult struct {
 	Names                   []string      `json:"names"`
@@ -692,12 +839,13 @@ func (c Checker) CheckFundStocks(ctx context.Context, fund *models.Fund) (result
 		codes = append(codes, s.Code)
 	}
 	searcher := NewSearcher(ctx)
+	searcher.CheckYears = c.Options.CheckYears
 	stocks, err := searcher.SearchStocks(ctx, codes)
 	if err != nil {
 		return
 	}
 	for _, stock := range stocks {
-		result, _ := c.CheckFundamentals(ctx, stock)
+		result, _ := c.Check(ctx, stock)
 		name := fmt.Sprintf("%s-%s", stock.BaseInfo.SecurityNameAbbr, stock.BaseInfo.Secucode)
 		results.Names = append(results.Names, name)
 		results.CheckResults = append(results.CheckResults, result)
@@ -729,6 +877,11 @@ func (c Checker) GetFundStocksSimilarity(ctx context.Context, codes []string) ([
 	if err != nil {
 		return nil, err
 	}
+	return FundsStocksSimilarity(funds), nil
+}
+
+// FundsStocksSimilarity 计算已查询到的基金之间的持仓相似度，按相似度降序排列
+func FundsStocksSimilarity(funds map[string]*models.Fund) []FundStocksSimilarity {
 	sims := []FundStocksSimilarity{}
 	for codeA, fund := range funds {
 		setA := mapset.NewSet()
@@ -761,5 +914,5 @@ func (c Checker) GetFundStocksSimilarity(ctx context.Context, codes []string) ([
 	sort.Slice(sims, func(i, j int) bool {
 		return sims[i].SimilarityValue > sims[j].SimilarityValue
 	})
-	return sims, nil
+	return sims
 }
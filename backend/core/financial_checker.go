@@ -0,0 +1,252 @@
+// 银行、保险等金融股专项检测
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// FinancialCheckerOptions 金融股检测条件选项
+type FinancialCheckerOptions struct {
+	// 银行股最小净息差(%)
+	BankMinNIM float64 `json:"bank_min_nim"                 form:"checker_bank_min_nim"`
+	// 银行股最大成本收入比(%)
+	BankMaxCostIncomeRatio float64 `json:"bank_max_cost_income_ratio"   form:"checker_bank_max_cost_income_ratio"`
+	// 银行股最小拨贷比(%)
+	BankMinProvisionLoanRatio float64 `json:"bank_min_provision_loan_ratio" form:"checker_bank_min_provision_loan_ratio"`
+	// 银行股最小贷款增速(%)
+	BankMinLoanGrowth float64 `json:"bank_min_loan_growth"         form:"checker_bank_min_loan_growth"`
+	// 银行股最小存款增速(%)
+	BankMinDepositGrowth float64 `json:"bank_min_deposit_growth"      form:"checker_bank_min_deposit_growth"`
+	// 是否检测银行股不良贷款率趋势
+	BankIsCheckNPLTrend bool `json:"bank_is_check_npl_trend"      form:"checker_bank_is_check_npl_trend"`
+	// 保险股最小内含价值增速(%)
+	InsurerMinEVGrowth float64 `json:"insurer_min_ev_growth"        form:"checker_insurer_min_ev_growth"`
+	// 保险股最大综合成本率(%)
+	InsurerMaxCombinedRatio float64 `json:"insurer_max_combined_ratio"   form:"checker_insurer_max_combined_ratio"`
+	// 保险股最小偿付能力充足率(%)
+	InsurerMinSolvencyRatio float64 `json:"insurer_min_solvency_ratio"   form:"checker_insurer_min_solvency_ratio"`
+	// PB-ROE 估值的要求回报率(%)
+	FinRequiredReturn float64 `json:"fin_required_return"          form:"checker_fin_required_return"`
+	// 最大 PB-ROE 比值（PB / (ROE/要求回报率)）
+	FinMaxPBROE float64 `json:"fin_max_pbroe"                form:"checker_fin_max_pbroe"`
+	// 保险股最大 PEV
+	InsurerMaxPEV float64 `json:"insurer_max_pev"              form:"checker_insurer_max_pev"`
+}
+
+// DefaultFinancialCheckerOptions 金融股默认检测值
+var DefaultFinancialCheckerOptions = FinancialCheckerOptions{
+	BankMinNIM:                1.8,
+	BankMaxCostIncomeRatio:    35.0,
+	BankMinProvisionLoanRatio: 2.5,
+	BankMinLoanGrowth:         0.0,
+	BankMinDepositGrowth:      0.0,
+	BankIsCheckNPLTrend:       true,
+	InsurerMinEVGrowth:        5.0,
+	InsurerMaxCombinedRatio:   100.0,
+	InsurerMinSolvencyRatio:   150.0,
+	FinRequiredReturn:         10.0,
+	FinMaxPBROE:               1.0,
+	InsurerMaxPEV:             1.0,
+}
+
+// financialSkipItems 不适用于金融股的通用检测项
+var financialSkipItems = []string{
+	"负债率",
+	"负债流动比",
+	"本业营收比",
+	"现金流量",
+	"毛利率稳定性",
+	"毛利率逐年递增且>0",
+	"净利率稳定性",
+	"净利率逐年递增且>0",
+	"分红现金流覆盖",
+}
+
+// Check 检测股票，金融股自动使用金融股专项检测
+func (c Checker) Check(ctx context.Context, stock models.Stock) (result CheckResult, ok bool) {
+	if stock.IsFinancial() {
+		return c.CheckFinancial(ctx, stock)
+	}
+	return c.CheckFundamentals(ctx, stock)
+}
+
+// CheckFinancial 检测银行、保险股：通用检测中适用的部分 + 金融股专项指标 + PB-ROE/PEV 估值
+func (c Checker) CheckFinancial(ctx context.Context, stock models.Stock) (result CheckResult, ok bool) {
+	result, _ = c.CheckFundamentals(ctx, stock)
+	if result == nil {
+		return
+	}
+	for _, name := range financialSkipItems {
+		delete(result, name)
+	}
+	ok = true
+	for _, item := range result {
+		if item["ok"] == "false" {
+			ok = false
+		}
+	}
+
+	fa := stock.FinancialAnalysis
+	// 历史时点不能获取最新的专项报表
+	if fa == nil && c.AsOf.IsZero() {
+		fa = models.NewFinancialAnalysis(ctx, stock, c.Options.CheckYears)
+	}
+	if fa == nil {
+		return
+	}
+	opts := c.Options.FinancialCheckerOptions
+
+	if fa.Bank != nil {
+		bank := fa.Bank
+
+		checkItemName := "净息差"
+		itemOK := true
+		desc := fmt.Sprintf("%s净息差:%.2f%%", bank.ReportDateName, bank.NIM)
+		if opts.BankMinNIM != 0 && bank.NIM < opts.BankMinNIM {
+			desc = fmt.Sprintf("%s净息差:%.2f%%<br/>低于:%.2f%%", bank.ReportDateName, bank.NIM, opts.BankMinNIM)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "成本收入比"
+		itemOK = true
+		desc = fmt.Sprintf("成本收入比:%.2f%%", bank.CostIncomeRatio)
+		if opts.BankMaxCostIncomeRatio != 0 && bank.CostIncomeRatio > opts.BankMaxCostIncomeRatio {
+			desc = fmt.Sprintf("成本收入比:%.2f%%<br/>高于:%.2f%%", bank.CostIncomeRatio, opts.BankMaxCostIncomeRatio)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "拨贷比"
+		itemOK = true
+		desc = fmt.Sprintf("拨贷比:%.2f%%", bank.ProvisionLoanRatio)
+		if bank.ProvisionLoanRatio < opts.BankMinProvisionLoanRatio {
+			desc = fmt.Sprintf("拨贷比:%.2f%%<br/>低于:%.2f%%", bank.ProvisionLoanRatio, opts.BankMinProvisionLoanRatio)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "存贷款增速"
+		itemOK = true
+		desc = fmt.Sprintf("贷款增速:%.2f%%<br/>存款增速:%.2f%%", bank.LoanGrowth, bank.DepositGrowth)
+		if bank.LoanGrowth < opts.BankMinLoanGrowth || bank.DepositGrowth < opts.BankMinDepositGrowth {
+			desc = fmt.Sprintf(
+				"贷款增速:%.2f%%(最低%.2f%%)<br/>存款增速:%.2f%%(最低%.2f%%)",
+				bank.LoanGrowth, opts.BankMinLoanGrowth, bank.DepositGrowth, opts.BankMinDepositGrowth,
+			)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "不良贷款率趋势"
+		itemOK = true
+		desc = fmt.Sprintf("%d年内不良贷款率(年报):<br/>%+v", c.Options.CheckYears, bank.NPLList)
+		if opts.BankIsCheckNPLTrend && !bank.IsNPLImproving() {
+			desc = fmt.Sprintf("%d年内不良贷款率持续恶化:<br/>%+v", c.Options.CheckYears, bank.NPLList)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+	}
+
+	if fa.Insurer != nil {
+		insurer := fa.Insurer
+
+		checkItemName := "内含价值增速"
+		itemOK := true
+		desc := fmt.Sprintf("%s内含价值增速:%.2f%%", insurer.ReportDateName, insurer.EVGrowth)
+		if insurer.EVGrowth < opts.InsurerMinEVGrowth {
+			desc = fmt.Sprintf("%s内含价值增速:%.2f%%<br/>低于:%.2f%%", insurer.ReportDateName, insurer.EVGrowth, opts.InsurerMinEVGrowth)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "综合成本率"
+		itemOK = true
+		desc = fmt.Sprintf("综合成本率:%.2f%%", insurer.CombinedRatio)
+		if opts.InsurerMaxCombinedRatio != 0 && insurer.CombinedRatio > opts.InsurerMaxCombinedRatio {
+			desc = fmt.Sprintf("综合成本率:%.2f%%<br/>高于:%.2f%%", insurer.CombinedRatio, opts.InsurerMaxCombinedRatio)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "偿付能力充足率"
+		itemOK = true
+		desc = fmt.Sprintf("偿付能力充足率:%.2f%%", insurer.SolvencyRatio)
+		if insurer.SolvencyRatio < opts.InsurerMinSolvencyRatio {
+			desc = fmt.Sprintf("偿付能力充足率:%.2f%%<br/>低于:%.2f%%", insurer.SolvencyRatio, opts.InsurerMinSolvencyRatio)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+
+		checkItemName = "PEV估值"
+		itemOK = true
+		desc = fmt.Sprintf("PEV:%.2f", fa.PEV)
+		if opts.InsurerMaxPEV != 0 && fa.PEV > opts.InsurerMaxPEV {
+			desc = fmt.Sprintf("PEV:%.2f<br/>高于:%.2f", fa.PEV, opts.InsurerMaxPEV)
+			ok = false
+			itemOK = false
+		}
+		result[checkItemName] = map[string]string{
+			"desc": desc,
+			"ok":   fmt.Sprint(itemOK),
+		}
+	}
+
+	checkItemName := "PB-ROE估值"
+	itemOK := true
+	pbroe := fa.PBROE(opts.FinRequiredReturn)
+	desc := fmt.Sprintf("PB:%.2f ROE:%.2f%%<br/>PB-ROE:%.2f", fa.PB, fa.ROE, pbroe)
+	switch {
+	case fa.ROE <= 0:
+		desc = fmt.Sprintf("PB:%.2f ROE:%.2f%%<br/>ROE为负或为0，PB-ROE无效", fa.PB, fa.ROE)
+	case pbroe < 0:
+		desc = fmt.Sprintf("PB:%.2f ROE:%.2f%%<br/>要求回报率:%.2f%%无效，PB-ROE无效", fa.PB, fa.ROE, opts.FinRequiredReturn)
+	case opts.FinMaxPBROE != 0 && pbroe > opts.FinMaxPBROE:
+		desc = fmt.Sprintf("PB:%.2f ROE:%.2f%%<br/>PB-ROE:%.2f 高于:%.2f", fa.PB, fa.ROE, pbroe, opts.FinMaxPBROE)
+	}
+	if opts.FinMaxPBROE != 0 && (pbroe < 0 || pbroe > opts.FinMaxPBROE) {
+		ok = false
+		itemOK = false
+	}
+	result[checkItemName] = map[string]string{
+		"desc": desc,
+		"ok":   fmt.Sprint(itemOK),
+	}
+	return
+}
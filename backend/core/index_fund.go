@@ -0,0 +1,150 @@
+// 查找跟踪同一指数的基金并比较跟踪质量
+
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+)
+
+// IndexFundsResult 指数基金跟踪质量比较结果
+type IndexFundsResult struct {
+	// 指数代码
+	IndexCode string `json:"index_code"`
+	// 指数名称
+	IndexName string `json:"index_name"`
+	// 统计的交易日数
+	Days int `json:"days"`
+	// 按得分降序排列的跟踪基金
+	Funds []models.IndexFundTracking `json:"funds"`
+	// 推荐基金
+	Recommendation models.IndexFundRecommendation `json:"recommendation"`
+}
+
+// SearchIndexFunds 查找跟踪指数 indexCode 的全部基金，按最近 days 个交易日计算跟踪质量
+func (s Searcher) SearchIndexFunds(ctx context.Context, indexCode string, days int) (*IndexFundsResult, error) {
+	funds, indexName, err := s.findIndexFunds(ctx, indexCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(funds) == 0 {
+		return nil, errors.New("no fund tracks index " + indexCode)
+	}
+
+	klines, err := datacenter.EastMoney.QueryKlineBySecID(ctx, eastmoney.IndexSecID(indexCode), eastmoney.KlineTypeDay, eastmoney.KlineFQNone, days)
+	if err != nil {
+		return nil, err
+	}
+	indexReturns := map[string]float64{}
+	for _, k := range klines {
+		indexReturns[k.Date] = k.ChangePct / 100
+	}
+
+	// 限制并发，避免被接口限流
+	semaphore := make(chan struct{}, 4)
+	list := make([]models.IndexFundTracking, len(funds))
+	var wg sync.WaitGroup
+	for i, fund := range funds {
+		wg.Add(1)
+		go func(i int, fund *models.Fund) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+			}()
+			semaphore <- struct{}{}
+			t := models.IndexFundTracking{
+				Code:     fund.Code,
+				Name:     fund.Name,
+				Category: models.IndexFundCategory(fund.Name),
+				Scale:    fund.NetAssetsScale,
+				Fee:      models.ParseFundRate(fund.Rate),
+			}
+			navs, err := datacenter.EastMoney.QueryFundNetHistory(ctx, fund.Code, days)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("SearchIndexFunds QueryFundNetHistory code:%s err:%v", fund.Code, err)
+			} else {
+				t.Days, t.TrackingError, t.TrackingDiff = models.TrackingStats(navs.DailyReturns(), indexReturns)
+			}
+			if t.IsExchangeTraded() {
+				t.AvgAmount = avgAmount(ctx, fund.Code)
+			}
+			list[i] = t
+		}(i, fund)
+	}
+	wg.Wait()
+
+	models.ScoreIndexFunds(list)
+	if indexName == "" {
+		indexName = funds[0].IndexName
+	}
+	return &IndexFundsResult{
+		IndexCode:      indexCode,
+		IndexName:      indexName,
+		Days:           len(klines),
+		Funds:          list,
+		Recommendation: models.RecommendIndexFund(list),
+	}, nil
+}
+
+// findIndexFunds 优先从数据库中按跟踪标的查找基金，数据库不可用时按指数名称搜索
+func (s Searcher) findIndexFunds(ctx context.Context, indexCode string) ([]*models.Fund, string, error) {
+	funds := []*models.Fund{}
+	if models.DB != nil {
+		fundDBs := []models.FundDB{}
+		if err := models.DB.Where("index_code = ?", indexCode).Find(&fundDBs).Error; err != nil {
+			logrus.WithContext(ctx).Error("findIndexFunds query db err:" + err.Error())
+		}
+		funds = append(funds, models.ToFunds(fundDBs)...)
+		if len(funds) > 0 {
+			return funds, funds[0].IndexName, nil
+		}
+	}
+
+	index, err := datacenter.EastMoney.Index(ctx, indexCode)
+	if err != nil {
+		return nil, "", err
+	}
+	results, err := datacenter.EastMoney.SearchFund(ctx, index.IndexName)
+	if err != nil {
+		return nil, "", err
+	}
+	codes := []string{}
+	for _, r := range results {
+		codes = append(codes, r.Code)
+	}
+	if len(codes) == 0 {
+		return funds, index.IndexName, nil
+	}
+	fundsMap, err := s.SearchFunds(ctx, codes)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, fund := range fundsMap {
+		if fund.IndexCode == indexCode {
+			funds = append(funds, fund)
+		}
+	}
+	return funds, index.IndexName, nil
+}
+
+// avgAmount 场内基金近20个交易日的日均成交额
+func avgAmount(ctx context.Context, code string) float64 {
+	klines, err := datacenter.EastMoney.QueryKline(ctx, code, eastmoney.KlineTypeDay, eastmoney.KlineFQNone, 20)
+	if err != nil || len(klines) == 0 {
+		if err != nil {
+			logrus.WithContext(ctx).Debugf("avgAmount QueryKline code:%s err:%v", code, err)
+		}
+		return 0
+	}
+	sum := 0.0
+	for _, k := range klines {
+		sum += k.Amount
+	}
+	return sum / float64(len(klines))
+}
@@ -0,0 +1,179 @@
+// 选股预设：预先组合好的筛选条件和检测条件
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+)
+
+// ErrBuiltinPreset 内置预设不可修改
+var ErrBuiltinPreset = errors.New("builtin preset can not be modified")
+
+// Preset 选股预设
+type Preset struct {
+	// 预设名称
+	Name string `json:"name"`
+	// 预设说明
+	Desc string `json:"desc"`
+	// 股票筛选条件
+	Filter eastmoney.Filter `json:"filter"`
+	// 股票检测条件
+	CheckerOptions CheckerOptions `json:"checker_options"`
+	// 基金筛选条件
+	FundFilter models.ParamFundListFilter `json:"fund_filter"`
+	// 是否为内置预设
+	Builtin bool `json:"builtin"`
+}
+
+// PresetDividend 红利增长预设：连续多年分红、分红稳步增长、支付率稳定且有自由现金流覆盖
+var PresetDividend = func() Preset {
+	filter := eastmoney.DefaultFilter
+	filter.MinZXGXL = 3.0
+	filter.MinNetprofitYoyRatio = -20.0
+	filter.MinToiYoyRatio = -20.0
+	filter.ListingOver5Y = true
+
+	opts := DefaultCheckerOptions
+	// 红利股更看重现金回报的稳定性，不要求各项指标逐年递增
+	opts.IsCheckEPSGrow = false
+	opts.IsCheckRevGrow = false
+	opts.IsCheckNetprofitGrow = false
+	opts.IsCheckPriceByCalc = false
+	opts.MaxPEG = 0
+	opts.MinGxl = 3.0
+	opts.MinDivYears = 5
+	opts.MinDivCAGR = 3.0
+	opts.MaxPayoutRatioStdDev = 15.0
+	opts.MinDivFCFCoverage = 1.0
+
+	return Preset{
+		Name:           "dividend",
+		Desc:           "红利增长：连续5年以上分红，分红复合增长率不低于3%，股利支付率稳定，自由现金流可覆盖分红",
+		Filter:         filter,
+		CheckerOptions: opts,
+		Builtin:        true,
+	}
+}()
+
+// Presets 全部选股预设
+var Presets = map[string]Preset{
+	PresetDividend.Name: PresetDividend,
+}
+
+// PresetNames 返回全部预设名称
+func PresetNames() []string {
+	names := []string{}
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToScreenPresetDB 转换为数据库存储结构
+func (p Preset) ToScreenPresetDB() (models.ScreenPresetDB, error) {
+	fundFilter, err := json.Marshal(p.FundFilter)
+	if err != nil {
+		return models.ScreenPresetDB{}, err
+	}
+	stockFilter, err := json.Marshal(p.Filter)
+	if err != nil {
+		return models.ScreenPresetDB{}, err
+	}
+	opts, err := json.Marshal(p.CheckerOptions)
+	if err != nil {
+		return models.ScreenPresetDB{}, err
+	}
+	return models.ScreenPresetDB{
+		Name:           p.Name,
+		Desc:           p.Desc,
+		FundFilter:     string(fundFilter),
+		StockFilter:    string(stockFilter),
+		CheckerOptions: string(opts),
+	}, nil
+}
+
+// NewPresetFromDB 从数据库记录还原预设，未保存的条件使用默认值
+func NewPresetFromDB(row models.ScreenPresetDB) (Preset, error) {
+	p := Preset{
+		Name:           row.Name,
+		Desc:           row.Desc,
+		Filter:         eastmoney.DefaultFilter,
+		CheckerOptions: DefaultCheckerOptions,
+	}
+	if row.FundFilter != "" {
+		if err := json.Unmarshal([]byte(row.FundFilter), &p.FundFilter); err != nil {
+			return p, err
+		}
+	}
+	if row.StockFilter != "" {
+		if err := json.Unmarshal([]byte(row.StockFilter), &p.Filter); err != nil {
+			return p, err
+		}
+	}
+	if row.CheckerOptions != "" {
+		if err := json.Unmarshal([]byte(row.CheckerOptions), &p.CheckerOptions); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// GetPreset 按名称获取预设，优先使用内置预设，其次查找数据库中保存的预设
+func GetPreset(ctx context.Context, name string) (Preset, error) {
+	if p, ok := Presets[name]; ok {
+		return p, nil
+	}
+	row, err := models.GetScreenPreset(ctx, name)
+	if err != nil {
+		return Preset{}, err
+	}
+	return NewPresetFromDB(*row)
+}
+
+// ListPresets 返回内置预设和数据库中保存的全部预设
+func ListPresets(ctx context.Context) ([]Preset, error) {
+	result := []Preset{}
+	for _, name := range PresetNames() {
+		result = append(result, Presets[name])
+	}
+	rows, err := models.ListScreenPresets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		p, err := NewPresetFromDB(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// SavePreset 保存预设到数据库，不可覆盖内置预设
+func SavePreset(ctx context.Context, p Preset) error {
+	if _, ok := Presets[p.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrBuiltinPreset, p.Name)
+	}
+	row, err := p.ToScreenPresetDB()
+	if err != nil {
+		return err
+	}
+	return models.SaveScreenPreset(ctx, row)
+}
+
+// DeletePreset 删除数据库中保存的预设
+func DeletePreset(ctx context.Context, name string) error {
+	if _, ok := Presets[name]; ok {
+		return fmt.Errorf("%w: %s", ErrBuiltinPreset, name)
+	}
+	return models.DeleteScreenPreset(ctx, name)
+}
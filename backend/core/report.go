@@ -0,0 +1,433 @@
+// 定时报告：按配置生成基金 4433 变化和选股结果报告，渲染为 HTML 邮件和 XLSX 附件并存档
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/axiaoxin-com/investool/notify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// 报告类型
+const (
+	// ReportFund4433 4433 基金变化及候选基金
+	ReportFund4433 = "fund_4433"
+	// ReportStockSelection 选股结果
+	ReportStockSelection = "stock_selection"
+)
+
+// 报告格式
+const (
+	ReportFormatHTML = "html"
+	ReportFormatXLSX = "xlsx"
+)
+
+// XLSXContentType xlsx 文件的 Content-Type
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// ErrInvalidReportConfig 报告配置错误
+var ErrInvalidReportConfig = errors.New("invalid report config")
+
+// ReportConfig 定时报告配置
+type ReportConfig struct {
+	// 报告名称，同名报告之间比较变化
+	Name string `mapstructure:"name" json:"name"`
+	// 报告标题，为空时按类型生成
+	Title string `mapstructure:"title" json:"title"`
+	// cron 表达式
+	Cronexp string `mapstructure:"cronexp" json:"cronexp"`
+	// 报告类型：fund_4433/stock_selection
+	Kind string `mapstructure:"kind" json:"kind"`
+	// 预设名称，基金报告使用其中的基金筛选条件选出候选基金，选股报告使用其中的筛选和检测条件
+	Preset string `mapstructure:"preset" json:"preset"`
+	// 报告格式：html/xlsx，为空时两者都生成
+	Formats []string `mapstructure:"formats" json:"formats"`
+	// 邮件收件人，为空时使用通知渠道配置的收件人
+	Recipients []string `mapstructure:"recipients" json:"recipients"`
+}
+
+// Validate 检查报告配置
+func (c ReportConfig) Validate() error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidReportConfig)
+	case c.Kind != ReportFund4433 && c.Kind != ReportStockSelection:
+		return fmt.Errorf("%w: %s kind %s", ErrInvalidReportConfig, c.Name, c.Kind)
+	}
+	for _, format := range c.Formats {
+		if format != ReportFormatHTML && format != ReportFormatXLSX {
+			return fmt.Errorf("%w: %s format %s", ErrInvalidReportConfig, c.Name, format)
+		}
+	}
+	return nil
+}
+
+// HasFormat 是否生成指定格式
+func (c ReportConfig) HasFormat(format string) bool {
+	return len(c.Formats) == 0 || goutils.IsStrInSlice(format, c.Formats)
+}
+
+// ReportConfigs 从 viper 的 reports 配置项读取定时报告配置
+func ReportConfigs() ([]ReportConfig, error) {
+	configs := []ReportConfig{}
+	if err := viper.UnmarshalKey("reports", &configs); err != nil {
+		return nil, err
+	}
+	for _, c := range configs {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+// ReportTable 报告中的一张表格
+type ReportTable struct {
+	Name    string
+	Headers []string
+	Rows    [][]interface{}
+	// 只写入 XLSX，不在 HTML 中展示
+	XLSXOnly bool
+}
+
+// reportContent 报告生成的内容
+type reportContent struct {
+	Title   string
+	Summary []string
+	Tables  []ReportTable
+	Codes   []string
+}
+
+// GenerateReport 按配置生成报告并存档
+func GenerateReport(ctx context.Context, cfg ReportConfig) (*models.ReportDB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	prev, err := models.LatestReport(ctx, cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	var prevCodes []string
+	if prev != nil {
+		prevCodes = goutils.SplitStringFields(prev.Codes)
+	}
+
+	var content *reportContent
+	if cfg.Kind == ReportFund4433 {
+		content, err = fund4433Report(ctx, cfg, prev != nil, prevCodes)
+	} else {
+		content, err = stockSelectionReport(ctx, cfg, prev != nil, prevCodes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	title := cfg.Title
+	if title == "" {
+		title = content.Title
+	}
+	report := &models.ReportDB{
+		Name:       cfg.Name,
+		Kind:       cfg.Kind,
+		Preset:     cfg.Preset,
+		Title:      fmt.Sprintf("[investool] %s %s", title, now.Format("2006-01-02")),
+		Summary:    strings.Join(content.Summary, "\n"),
+		Codes:      strings.Join(content.Codes, ","),
+		Recipients: strings.Join(cfg.Recipients, ","),
+		CreatedAt:  now,
+	}
+	if cfg.HasFormat(ReportFormatHTML) {
+		if report.HTML, err = renderReportHTML(report.Title, content); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HasFormat(ReportFormatXLSX) {
+		if report.Content, err = renderReportXLSX(report.Title, content.Tables); err != nil {
+			return nil, err
+		}
+		report.Filename = fmt.Sprintf("investool.%s.%s.xlsx", cfg.Name, now.Format("20060102"))
+	}
+	if err := models.SaveReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// DeliverReport 通过通知渠道发送报告，downloadURL 不为空时在摘要中附带下载地址，发送结果写回存档
+// 未配置通知渠道、没有订阅报告事件的渠道或 dry run 时报告未发送，Error 记录原因
+func DeliverReport(ctx context.Context, report *models.ReportDB, downloadURL string) error {
+	content := report.Summary
+	if downloadURL != "" {
+		content += "\n\n下载：" + downloadURL
+	}
+	msg := notify.Message{
+		Event:   notify.EventReport,
+		Title:   report.Title,
+		Content: content,
+		Data: map[string]interface{}{
+			"id":           report.ID,
+			"name":         report.Name,
+			"kind":         report.Kind,
+			"download_url": downloadURL,
+		},
+		Time: report.CreatedAt,
+		HTML: report.HTML,
+		To:   goutils.SplitStringFields(report.Recipients),
+	}
+	if len(report.Content) > 0 {
+		msg.Attachments = []notify.Attachment{{Filename: report.Filename, ContentType: XLSXContentType, Data: report.Content}}
+	}
+	err := notify.Send(ctx, msg)
+	report.Delivered = err == nil
+	report.Error = ""
+	if err != nil {
+		report.Error = err.Error()
+	}
+	if saveErr := models.UpdateReportDelivery(ctx, report); saveErr != nil {
+		logrus.WithContext(ctx).Errorf("DeliverReport update report:%d error:%v", report.ID, saveErr)
+	}
+	return err
+}
+
+// changeSummary 生成与上一期相比的变化说明
+func changeSummary(hasPrev bool, added, removed []string) string {
+	if !hasPrev {
+		return "首期报告，下期开始对比变化"
+	}
+	return fmt.Sprintf("较上期新增 %d 只，移出 %d 只", len(added), len(removed))
+}
+
+// fund4433Report 4433 基金报告：较上期新进和移出 4433 的基金，以及满足预设基金筛选条件的候选基金
+func fund4433Report(ctx context.Context, cfg ReportConfig, hasPrev bool, prevCodes []string) (*reportContent, error) {
+	fundDBs := []models.FundDB{}
+	if err := models.DB.WithContext(ctx).Where("is_4433 = ?", true).Order("code").Find(&fundDBs).Error; err != nil {
+		return nil, err
+	}
+	funds := models.FundList{}
+	codes := []string{}
+	fundMap := map[string]*models.Fund{}
+	for _, fund := range models.ToFunds(fundDBs) {
+		funds = append(funds, fund)
+		codes = append(codes, fund.Code)
+		fundMap[fund.Code] = fund
+	}
+	added, removed := models.DiffCodes(prevCodes, codes)
+	if !hasPrev {
+		added = nil
+	}
+
+	candidates := funds
+	if cfg.Preset != "" {
+		preset, err := GetPreset(ctx, cfg.Preset)
+		if err != nil {
+			return nil, err
+		}
+		candidates = funds.Filter(ctx, preset.FundFilter)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Performance.Year1RankRatio < candidates[j].Performance.Year1RankRatio
+	})
+
+	// 移出的基金已不在 4433 列表中，从数据库补充基金信息
+	removedFunds := models.FundList{}
+	if len(removed) > 0 {
+		rows := []models.FundDB{}
+		if err := models.DB.WithContext(ctx).Where("code IN ?", removed).Order("code").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		removedFunds = append(removedFunds, models.ToFunds(rows)...)
+	}
+	addedFunds := models.FundList{}
+	for _, code := range added {
+		addedFunds = append(addedFunds, fundMap[code])
+	}
+
+	return &reportContent{
+		Title: "4433基金报告",
+		Summary: []string{
+			fmt.Sprintf("当前满足4433法则的基金 %d 只，%s", len(funds), changeSummary(hasPrev, added, removed)),
+			fmt.Sprintf("候选基金 %d 只", len(candidates)),
+		},
+		Tables: []ReportTable{
+			fundReportTable("新进4433", addedFunds),
+			fundReportTable("移出4433", removedFunds),
+			fundReportTable("候选基金", candidates),
+		},
+		Codes: codes,
+	}, nil
+}
+
+func fundReportTable(name string, funds models.FundList) ReportTable {
+	table := ReportTable{
+		Name:    name,
+		Headers: []string{"基金代码", "基金名称", "基金类型", "规模(亿)", "近1年收益率(%)", "近1年同类排名(%)", "基金经理", "管理年限"},
+	}
+	for _, f := range funds {
+		table.Rows = append(table.Rows, []interface{}{
+			f.Code,
+			f.Name,
+			f.Type,
+			fmt.Sprintf("%.2f", f.NetAssetsScale/100000000),
+			fmt.Sprintf("%.2f", f.Performance.Year1ProfitRatio),
+			fmt.Sprintf("%.2f", f.Performance.Year1RankRatio),
+			f.Manager.Name,
+			fmt.Sprintf("%.1f", f.Manager.ManageDays/365),
+		})
+	}
+	return table
+}
+
+// stockSelectionReport 选股报告：按预设选股，标出较上期新入选和移出的股票，XLSX 中附带完整数据
+func stockSelectionReport(ctx context.Context, cfg ReportConfig, hasPrev bool, prevCodes []string) (*reportContent, error) {
+	preset := Preset{Filter: eastmoney.DefaultFilter, CheckerOptions: DefaultCheckerOptions}
+	if cfg.Preset != "" {
+		p, err := GetPreset(ctx, cfg.Preset)
+		if err != nil {
+			return nil, err
+		}
+		preset = p
+	}
+	selector := NewSelector(ctx, preset.Filter, NewChecker(ctx, preset.CheckerOptions))
+	stocks, err := selector.AutoFilterStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dlist := models.NewExportorDataList(ctx, stocks, preset.CheckerOptions.HVWindow)
+	codes := []string{}
+	for _, d := range dlist {
+		codes = append(codes, d.Code)
+	}
+	added, removed := models.DiffCodes(prevCodes, codes)
+	isAdded := map[string]bool{}
+	if hasPrev {
+		for _, code := range added {
+			isAdded[code] = true
+		}
+	}
+
+	selected := ReportTable{
+		Name:    "入选股票",
+		Headers: []string{"股票名", "股票代码", "所属行业", "价格", "估算合理价格", "合理价差", "当前 ROE", "较上期"},
+	}
+	for _, d := range dlist {
+		change := ""
+		if isAdded[d.Code] {
+			change = "新入选"
+		}
+		selected.Rows = append(selected.Rows, []interface{}{d.Name, d.Code, d.Industry, d.Price, d.RightPrice, d.PriceSpace, d.LatestROE, change})
+	}
+	removedTable := ReportTable{Name: "移出股票", Headers: []string{"股票代码"}}
+	for _, code := range removed {
+		removedTable.Rows = append(removedTable.Rows, []interface{}{code})
+	}
+
+	// 完整数据不包含资金流向列
+	detail := ReportTable{Name: "明细", XLSXOnly: true}
+	for _, h := range (models.ExportorData{}).GetHeaders() {
+		if !goutils.IsStrInSlice(h, models.MoneyFlowHeaders) {
+			detail.Headers = append(detail.Headers, h)
+		}
+	}
+	for _, d := range dlist {
+		values := d.GetHeaderValueMap()
+		row := make([]interface{}, 0, len(detail.Headers))
+		for _, h := range detail.Headers {
+			row = append(row, values[h])
+		}
+		detail.Rows = append(detail.Rows, row)
+	}
+
+	return &reportContent{
+		Title: "选股报告",
+		Summary: []string{
+			fmt.Sprintf("本期入选股票 %d 只，%s", len(dlist), changeSummary(hasPrev, added, removed)),
+		},
+		Tables: []ReportTable{selected, removedTable, detail},
+		Codes:  codes,
+	}, nil
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<html>
+<body style="font-family: sans-serif;">
+<h2>{{.Title}}</h2>
+{{range .Summary}}<p>{{.}}</p>
+{{end}}{{range .Tables}}{{if not .XLSXOnly}}<h3>{{.Name}}（{{len .Rows}}）</h3>
+{{if .Rows}}<table border="1" cellspacing="0" cellpadding="4" style="border-collapse: collapse;">
+<tr>{{range .Headers}}<th style="background: #ffcccc;">{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p>无</p>
+{{end}}{{end}}{{end}}</body>
+</html>`))
+
+// renderReportHTML 渲染 HTML 正文
+func renderReportHTML(title string, content *reportContent) (string, error) {
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, map[string]interface{}{
+		"Title":   title,
+		"Summary": content.Summary,
+		"Tables":  content.Tables,
+	})
+	return buf.String(), err
+}
+
+// renderReportXLSX 每张表格写入一个 sheet
+func renderReportXLSX(title string, tables []ReportTable) ([]byte, error) {
+	f := excelize.NewFile()
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFCCCC"}},
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, table := range tables {
+		sheet := table.Name
+		if i == 0 {
+			f.SetSheetName("Sheet1", sheet)
+		} else {
+			f.NewSheet(sheet)
+		}
+		for col, header := range table.Headers {
+			axis, _ := excelize.CoordinatesToCellName(col+1, 1)
+			f.SetCellValue(sheet, axis, header)
+		}
+		if len(table.Headers) > 0 {
+			lastCol, _ := excelize.ColumnNumberToName(len(table.Headers))
+			f.SetCellStyle(sheet, "A1", lastCol+"1", headerStyle)
+			f.SetColWidth(sheet, "A", lastCol, 16)
+		}
+		for r, row := range table.Rows {
+			for col, value := range row {
+				axis, _ := excelize.CoordinatesToCellName(col+1, r+2)
+				f.SetCellValue(sheet, axis, value)
+			}
+		}
+	}
+	f.SetDocProps(&excelize.DocProperties{
+		Title:    title,
+		Created:  time.Now().Format("2006-01-02 15:04:05"),
+		Creator:  "investool",
+		Keywords: "investool: https://github.com/axiaoxin-com/investool",
+	})
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -20,7 +20,10 @@ import (
 )
 
 // Searcher 搜索器实例
-type Searcher struct{}
+type Searcher struct {
+	// 金融股专项分析使用的年报数量，为 0 时使用默认检测值
+	CheckYears int
+}
 
 // NewSearcher 创建搜索器实例
 func NewSearcher(ctx context.Context) Searcher {
@@ -29,6 +32,11 @@ func NewSearcher(ctx context.Context) Searcher {
 
 // SearchStocks 按股票名或代码搜索股票
 func (s Searcher) SearchStocks(ctx context.Context, keywords []string) (map[string]models.Stock, error) {
+	return s.SearchStocksEach(ctx, keywords, nil)
+}
+
+// SearchStocksEach 按股票名或代码搜索股票，每获取到一只股票的完整数据调用一次 onStock，onStock 会被并发调用
+func (s Searcher) SearchStocksEach(ctx context.Context, keywords []string, onStock func(stock models.Stock)) (map[string]models.Stock, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	kLen := len(keywords)
@@ -62,6 +70,10 @@ func (s Searcher) SearchStocks(ctx context.Context, keywords []string) (map[stri
 	if len(matchedResults) == 0 {
 		return nil, fmt.Errorf("无法获取对应数据 %v", keywords)
 	}
+	checkYears := s.CheckYears
+	if checkYears == 0 {
+		checkYears = DefaultCheckerOptions.CheckYears
+	}
 	// 查询匹配到的股票代码的股票信息
 	filter := eastmoney.Filter{}
 	for _, result := range matchedResults {
@@ -78,7 +90,7 @@ func (s Searcher) SearchStocks(ctx context.Context, keywords []string) (map[stri
 			defer func() {
 				wg.Done()
 			}()
-			mstock, err := models.NewStock(ctx, stock)
+			mstock, err := models.NewStock(ctx, stock, checkYears)
 			if err != nil {
 				logrus.WithContext(ctx).Errorf("%s new models stock error:%v", stock.SecurityCode, err.Error())
 				return
@@ -86,6 +98,9 @@ func (s Searcher) SearchStocks(ctx context.Context, keywords []string) (map[stri
 			mu.Lock()
 			results[stock.SecurityCode] = mstock
 			mu.Unlock()
+			if onStock != nil {
+				onStock(mstock)
+			}
 		}(stock)
 	}
 	wg.Wait()
@@ -94,6 +109,11 @@ func (s Searcher) SearchStocks(ctx context.Context, keywords []string) (map[stri
 
 // SearchFunds 按基金代码搜索基金
 func (s Searcher) SearchFunds(ctx context.Context, fundCodes []string) (map[string]*models.Fund, error) {
+	return s.SearchFundsEach(ctx, fundCodes, nil)
+}
+
+// SearchFundsEach 按基金代码查询基金，每查询到一只基金调用一次 onFund，onFund 不会被并发调用
+func (s Searcher) SearchFundsEach(ctx context.Context, fundCodes []string, onFund func(fund *models.Fund)) (map[string]*models.Fund, error) {
 	codeLen := len(fundCodes)
 	if codeLen == 0 {
 		return nil, errors.New("empty fund codes")
@@ -149,6 +169,9 @@ func (s Searcher) SearchFunds(ctx context.Context, fundCodes []string) (map[stri
 			fund := models.NewFund(ctx, fundresp)
 			mu.Lock()
 			result[fund.Code] = fund
+			if onFund != nil {
+				onFund(fund)
+			}
 			mu.Unlock()
 		}(code)
 	}
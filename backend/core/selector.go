@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/axiaoxin-com/investool/datacenter"
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
@@ -29,6 +30,9 @@ import (
 type Selector struct {
 	Filter  eastmoney.Filter
 	Checker *Checker
+	// 历史时点，为零值时使用最新数据选股。非零值时只使用该日之前公告的财报和该日之前的行情，
+	// 股票池只按代码、行业、板块等不随时间变化的条件查询，其余筛选条件按当时的数据在本地计算
+	AsOf time.Time
 }
 
 // NewSelector 创建选股器
@@ -39,9 +43,47 @@ func NewSelector(ctx context.Context, filter eastmoney.Filter, checker *Checker)
 	}
 }
 
+// checker 返回与选股器历史时点一致的检测器
+func (s Selector) checker() *Checker {
+	if s.Checker == nil {
+		return nil
+	}
+	checker := *s.Checker
+	checker.AsOf = s.AsOf
+	return &checker
+}
+
+// checkYears 分红和财务分析使用的年数，未设置检测器时使用默认值
+func (s Selector) checkYears() int {
+	if s.Checker == nil {
+		return DefaultCheckerOptions.CheckYears
+	}
+	return s.Checker.Options.CheckYears
+}
+
+// SelectProgress 选股过程中单只股票的处理结果
+type SelectProgress struct {
+	// 获取数据失败时为零值
+	Stock    models.Stock
+	Selected bool
+	// 已处理的股票数量和待筛选的股票总数
+	Done  int
+	Total int
+}
+
 // AutoFilterStocks 按默认设置自动筛选股票
 func (s Selector) AutoFilterStocks(ctx context.Context) (result models.StockList, err error) {
-	stocks, err := datacenter.EastMoney.QuerySelectedStocksWithFilter(ctx, s.Filter)
+	return s.AutoFilterStocksEach(ctx, nil)
+}
+
+// AutoFilterStocksEach 按默认设置自动筛选股票，每处理完一只股票调用一次 onProgress，onProgress 不会被并发调用
+func (s Selector) AutoFilterStocksEach(ctx context.Context, onProgress func(p SelectProgress)) (result models.StockList, err error) {
+	var stocks eastmoney.StockInfoList
+	if s.AsOf.IsZero() {
+		stocks, err = datacenter.EastMoney.QuerySelectedStocksWithFilter(ctx, s.Filter)
+	} else {
+		stocks, err = datacenter.EastMoney.QueryStockUniverse(ctx, s.Filter)
+	}
 	if err != nil {
 		return
 	}
@@ -50,44 +92,68 @@ func (s Selector) AutoFilterStocks(ctx context.Context) (result models.StockList
 		return
 	}
 
+	checkYears := s.checkYears()
+
 	// 并发执行筛选任务
 	workerCount := int(math.Min(float64(len(stocks)), float64(viper.GetFloat64("app.chan_size"))))
 	jobChan := make(chan struct{}, workerCount)
 	wg := sync.WaitGroup{}
 	var mu sync.Mutex
+	checker := s.checker()
+	done := 0
+	// finish 记录单只股票的处理结果
+	finish := func(stock models.Stock, selected bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if selected {
+			result = append(result, stock)
+		}
+		if onProgress != nil {
+			onProgress(SelectProgress{Stock: stock, Selected: selected, Done: done, Total: len(stocks)})
+		}
+	}
 
 	for _, baseInfo := range stocks {
 		wg.Add(1)
 		jobChan <- struct{}{}
 
 		go func(ctx context.Context, baseInfo eastmoney.StockInfo) {
+			var stock models.Stock
+			selected := false
 			defer func() {
-				wg.Done()
-				<-jobChan
 				if r := recover(); r != nil {
 					logrus.WithContext(ctx).Errorf("recover from:%v", r)
 				}
+				finish(stock, selected)
+				wg.Done()
+				<-jobChan
 			}()
 
-			stock, err := models.NewStock(ctx, baseInfo)
+			var err error
+			if s.AsOf.IsZero() {
+				stock, err = models.NewStock(ctx, baseInfo, checkYears)
+			} else {
+				stock, err = models.NewStockAsOf(ctx, baseInfo, s.AsOf, checkYears)
+			}
 			if err != nil {
 				logrus.WithContext(ctx).Error("NewStock error:" + err.Error())
+				stock = models.Stock{}
 				return
 			}
-			if s.Checker == nil {
-				mu.Lock()
-				result = append(result, stock)
-				mu.Unlock()
-			} else {
-				// 检测是否为优质股票
-				if details, ok := s.Checker.CheckFundamentals(ctx, stock); ok {
-					mu.Lock()
-					result = append(result, stock)
-					mu.Unlock()
-				} else {
-					logrus.WithContext(ctx).WithField("details", details).Debug(fmt.Sprintf("%s %s has some defects", stock.BaseInfo.SecurityNameAbbr, stock.BaseInfo.Secucode))
-				}
+			if !s.AsOf.IsZero() && !matchFilterAsOf(s.Filter, stock, s.AsOf) {
+				return
 			}
+			if checker == nil {
+				selected = true
+				return
+			}
+			// 检测是否为优质股票
+			details, ok := checker.Check(ctx, stock)
+			if !ok {
+				logrus.WithContext(ctx).WithField("details", details).Debug(fmt.Sprintf("%s %s has some defects", stock.BaseInfo.SecurityNameAbbr, stock.BaseInfo.Secucode))
+			}
+			selected = ok
 		}(ctx, baseInfo)
 	}
 	wg.Wait()
@@ -95,3 +161,108 @@ func (s Selector) AutoFilterStocks(ctx context.Context) (result models.StockList
 	result.SortByROE()
 	return
 }
+
+// matchFilterAsOf 按历史时点还原的股票数据检查选股指标。
+// 上市以来年化收益率、年化波动率和预测增长率没有历史数据，不参与筛选
+func matchFilterAsOf(f eastmoney.Filter, stock models.Stock, asOf time.Time) bool {
+	info := stock.BaseInfo
+	price := stock.GetPrice()
+	switch {
+	case info.RoeWeight < f.MinROE,
+		info.NetprofitYoyRatio < f.MinNetprofitYoyRatio,
+		info.ToiYoyRatio < f.MinToiYoyRatio,
+		info.Zxgxl < f.MinZXGXL,
+		info.NetprofitGrowthrate3Y < f.MinNetprofitGrowthrate3Y,
+		info.IncomeGrowthrate3Y < f.MinIncomeGrowthrate3Y,
+		info.PBNewMRQ < f.MinPBNewMRQ:
+		return false
+	case f.MaxDebtAssetRatio != 0 && info.DebtAssetRatio > f.MaxDebtAssetRatio,
+		f.MinTotalMarketCap != 0 && info.TotalMarketCap < f.MinTotalMarketCap*100000000,
+		f.MinPrice != 0 && price < f.MinPrice,
+		f.MaxPrice != 0 && price > f.MaxPrice,
+		f.MinROA != 0 && info.ROA < f.MinROA:
+		return false
+	}
+	if f.ListingOver5Y && !eastmoney.IsPublishedBefore(info.ListingDate, asOf.AddDate(-5, 0, 0).Format("2006-01-02")) {
+		return false
+	}
+	return true
+}
+
+// selectAsOf 在 asOf 时点按选股指标和检测器选股，结果按 ROE 排序
+func (s Selector) selectAsOf(ctx context.Context, asOf time.Time, stocks models.StockList) models.StockList {
+	s.AsOf = asOf
+	checker := s.checker()
+	result := models.StockList{}
+	for _, stock := range stocks {
+		if !matchFilterAsOf(s.Filter, stock, asOf) {
+			continue
+		}
+		if checker != nil && !s.checkAsOf(ctx, checker, stock) {
+			continue
+		}
+		result = append(result, stock)
+	}
+	result.SortByROE()
+	logrus.WithContext(ctx).Infof("Selector selectAsOf %s selected %d/%d stocks", asOf.Format("2006-01-02"), len(result), len(stocks))
+	return result
+}
+
+// checkAsOf 历史数据可能不完整，检测出错时视为不通过
+func (s Selector) checkAsOf(ctx context.Context, checker *Checker, stock models.Stock) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.WithContext(ctx).Errorf("checkAsOf %s recover from:%v", stock.BaseInfo.Secucode, r)
+			ok = false
+		}
+	}()
+	_, ok = checker.Check(ctx, stock)
+	return
+}
+
+// Backtest 回测选股器：在每个调仓日只使用当时已公告的财报和当时的行情选股，
+// 等权持有到下一个调仓日并与基准指数对比。股票池为当前仍在上市的股票，存在幸存者偏差
+func (s Selector) Backtest(ctx context.Context, p models.StockBacktestParams) (*models.StockBacktestResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	start, _ := time.Parse("2006-01-02", p.Start)
+	stocks, err := datacenter.EastMoney.QueryStockUniverse(ctx, s.Filter)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithContext(ctx).Infof("Selector Backtest will load %d stocks history", len(stocks))
+
+	// 多取一年的K线用于趋势和波动率检测
+	limit := models.StockKlineLimit(start, 300)
+	workerCount := int(math.Max(1, math.Min(float64(len(stocks)), viper.GetFloat64("app.chan_size"))))
+	jobChan := make(chan struct{}, workerCount)
+	wg := sync.WaitGroup{}
+	var mu sync.Mutex
+	histories := []*models.StockHistory{}
+	for _, baseInfo := range stocks {
+		wg.Add(1)
+		jobChan <- struct{}{}
+		go func(baseInfo eastmoney.StockInfo) {
+			defer func() {
+				wg.Done()
+				<-jobChan
+			}()
+			h, err := models.LoadStockHistory(ctx, baseInfo, limit)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("Selector Backtest LoadStockHistory %s err:%v", baseInfo.Secucode, err)
+				return
+			}
+			mu.Lock()
+			histories = append(histories, h)
+			mu.Unlock()
+		}(baseInfo)
+	}
+	wg.Wait()
+
+	benchmark, err := models.GetIndexPrices(ctx, p.Benchmark, limit)
+	if err != nil {
+		return nil, err
+	}
+	return models.RunStockBacktest(ctx, p, histories, benchmark, s.checkYears(), s.selectAsOf)
+}
@@ -0,0 +1,29 @@
+// 同步完成后评估告警规则
+
+package cron
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+)
+
+// EvaluateAlerts 评估同步任务相关的告警规则
+func EvaluateAlerts(jobname string, types []string) {
+	if models.DB == nil {
+		return
+	}
+	ctx := context.Background()
+	result, err := core.EvaluateAlerts(ctx, types...)
+	if err != nil {
+		logrus.Errorf("EvaluateAlerts after %s error:%v", jobname, err)
+		syncJobFailed("EvaluateAlerts", err)
+		return
+	}
+	for _, alert := range result.Alerts {
+		logrus.Warnf("EvaluateAlerts after %s alert rule:%s %s", jobname, alert.RuleName, alert.Message)
+	}
+	logrus.Infof("EvaluateAlerts after %s evaluated %d rules, %d alerts fired", jobname, result.Rules, len(result.Alerts))
+}
@@ -3,20 +3,78 @@ package cron
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/axiaoxin-com/goutils"
 	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/chinabond"
 	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
 )
 
-// SyncBond 同步债券
+// SyncBond 同步中债全部收益率曲线的各期限收益率，并更新无风险利率等全局变量
 func SyncBond() {
 	if !goutils.IsTradingDay() {
 		return
 	}
 	ctx := context.Background()
-	syl := datacenter.ChinaBond.QueryAAACompanyBondSyl(ctx)
-	if syl != 0 {
-		models.AAACompanyBondSyl = syl
+	SyncBondYields(ctx, goutils.GetLatestTradingDay())
+}
+
+// SyncBondYields 同步指定日期全部收益率曲线到 bond_yields 表
+func SyncBondYields(ctx context.Context, date string) {
+	job := newSyncJob("SyncBond")
+	defer job.done()
+	curves, err := datacenter.ChinaBond.QueryCurveList(ctx)
+	if err != nil {
+		logrus.Errorf("SyncBondYields QueryCurveList error:%v", err)
+		job.fail(err)
+		return
+	}
+	count := 0
+	for name, id := range curves {
+		curve, err := datacenter.ChinaBond.QueryYieldCurve(ctx, id, date)
+		if err != nil {
+			logrus.Errorf("SyncBondYields QueryYieldCurve name:%s error:%v", name, err)
+			job.fail(fmt.Errorf("query curve %s: %w", name, err))
+			continue
+		}
+		if len(curve.Points) == 0 {
+			continue
+		}
+		if curve.Name == "" {
+			curve.Name = name
+		}
+		switch name {
+		case chinabond.CurveCompanyAAA:
+			// 保持原有口径：取曲线第一个期限点
+			models.SetAAACompanyBondSyl(curve.Points[0].Yield)
+		case chinabond.CurveTreasury:
+			if y, ok := curve.YieldAt(models.RiskFreeRateTenor); ok {
+				models.SetTreasuryYield10Y(y)
+			}
+		}
+		if err := models.SaveBondYieldCurve(ctx, curve); err != nil {
+			logrus.Errorf("SyncBondYields SaveBondYieldCurve name:%s error:%v", name, err)
+			job.fail(fmt.Errorf("save curve %s: %w", name, err))
+			continue
+		}
+		count++
+	}
+	logrus.Infof("SyncBondYields date:%s synced %d curves", date, count)
+}
+
+// SyncMarketTemperature 计算并保存当日市场温度
+func SyncMarketTemperature() {
+	if !goutils.IsTradingDay() {
+		return
+	}
+	ctx := context.Background()
+	temp, err := models.QueryMarketTemperature(ctx)
+	if err != nil {
+		logrus.Errorf("SyncMarketTemperature error:%v", err)
+		syncJobFailed("SyncMarketTemperature", err)
+		return
 	}
+	logrus.Infof("SyncMarketTemperature date:%s temperature:%.2f", temp.Date, temp.Temperature)
 }
@@ -2,12 +2,17 @@
 package cron
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/notify"
 	"github.com/go-co-op/gocron"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -23,6 +28,52 @@ var (
 	)
 )
 
+// syncJobFailed 记录定时任务失败次数并发送失败通知
+func syncJobFailed(jobname string, err error) {
+	promSyncError.WithLabelValues(jobname).Inc()
+	notifyJobFailure(jobname, err)
+}
+
+func notifyJobFailure(jobname string, err error) {
+	notify.Notify(context.Background(), notify.EventJobFailure, notify.JobFailure{
+		Job:   jobname,
+		Error: err.Error(),
+		Time:  time.Now(),
+	})
+}
+
+// syncJob 汇总一次任务运行中的多个错误，任务结束时最多发送一次失败通知
+type syncJob struct {
+	name  string
+	count int
+	first error
+}
+
+func newSyncJob(name string) *syncJob {
+	return &syncJob{name: name}
+}
+
+// fail 记录一次错误
+func (j *syncJob) fail(err error) {
+	promSyncError.WithLabelValues(j.name).Inc()
+	j.count++
+	if j.first == nil {
+		j.first = err
+	}
+}
+
+// done 任务运行结束，有错误时发送失败通知
+func (j *syncJob) done() {
+	if j.count == 0 {
+		return
+	}
+	err := j.first
+	if j.count > 1 {
+		err = fmt.Errorf("%d errors, first error: %w", j.count, j.first)
+	}
+	notifyJobFailure(j.name, err)
+}
+
 // RunCronJobs 启动定时任务
 func RunCronJobs(async bool) {
 	timezone, err := time.LoadLocation("Asia/Shanghai")
@@ -39,6 +90,68 @@ func RunCronJobs(async bool) {
 	// sched.Cron(viper.GetString("app.cronexp.sync_industry_list")).Do(SyncIndustryList)
 	// 同步基金经理列表
 	// sched.Cron(viper.GetString("app.cronexp.sync_fund_managers")).Do(SyncFundManagers)
+	// 同步中债收益率曲线
+	if exp := viper.GetString("app.cronexp.sync_bond"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncBond); err != nil {
+			logrus.Errorf("RunCronJobs add SyncBond job error:%v", err)
+		}
+	}
+	// 计算市场温度，依赖当日国债收益率，需晚于 sync_bond
+	if exp := viper.GetString("app.cronexp.sync_market_temperature"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncMarketTemperature); err != nil {
+			logrus.Errorf("RunCronJobs add SyncMarketTemperature job error:%v", err)
+		}
+	}
+	// 保存关注指数的每日估值
+	if exp := viper.GetString("app.cronexp.sync_index_valuation"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncIndexValuation); err != nil {
+			logrus.Errorf("RunCronJobs add SyncIndexValuation job error:%v", err)
+		}
+	}
+	// 保存行业板块每日行情及估值
+	if exp := viper.GetString("app.cronexp.sync_industry_daily"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncIndustryDaily); err != nil {
+			logrus.Errorf("RunCronJobs add SyncIndustryDaily job error:%v", err)
+		}
+	}
+	// 保存告警规则监控股票的日 K 线并评估股票告警
+	if exp := viper.GetString("app.cronexp.sync_stock_price"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncStockPrice); err != nil {
+			logrus.Errorf("RunCronJobs add SyncStockPrice job error:%v", err)
+		}
+	}
+	// 保存告警规则监控股票的资金流向
+	if exp := viper.GetString("app.cronexp.sync_stock_money_flow"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncStockMoneyFlow); err != nil {
+			logrus.Errorf("RunCronJobs add SyncStockMoneyFlow job error:%v", err)
+		}
+	}
+	// 计算股票型和混合型基金的风格箱
+	if exp := viper.GetString("app.cronexp.sync_fund_style"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncFundStyle); err != nil {
+			logrus.Errorf("RunCronJobs add SyncFundStyle job error:%v", err)
+		}
+	}
+	// 保存基金历史净值
+	if exp := viper.GetString("app.cronexp.sync_fund_nav"); exp != "" {
+		if _, err := sched.Cron(exp).Do(SyncFundNav); err != nil {
+			logrus.Errorf("RunCronJobs add SyncFundNav job error:%v", err)
+		}
+	}
+
+	// 定时报告
+	reports, err := core.ReportConfigs()
+	if err != nil {
+		logrus.Errorf("RunCronJobs load reports config error:%v", err)
+	}
+	for _, cfg := range reports {
+		if cfg.Cronexp == "" {
+			continue
+		}
+		if _, err := sched.Cron(cfg.Cronexp).Do(RunReport, cfg); err != nil {
+			logrus.Errorf("RunCronJobs add report %s job error:%v", cfg.Name, err)
+		}
+	}
 
 	if async {
 		sched.StartAsync()
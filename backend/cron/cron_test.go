@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axiaoxin-com/investool/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type recordChannel struct {
+	sent chan notify.Message
+}
+
+func (c recordChannel) Name() string { return "record" }
+
+func (c recordChannel) Send(ctx context.Context, msg notify.Message) error {
+	c.sent <- msg
+	return nil
+}
+
+func TestSyncJob(t *testing.T) {
+	n, err := notify.NewNotifier(notify.Config{})
+	require.Nil(t, err)
+	ch := recordChannel{sent: make(chan notify.Message, 10)}
+	n.AddChannel(ch)
+	defaultNotifier := notify.Default
+	notify.Default = n
+	defer func() { notify.Default = defaultNotifier }()
+
+	// 没有错误时不通知
+	newSyncJob("TestJob").done()
+
+	// 多个错误只发送一次通知
+	job := newSyncJob("TestJob")
+	job.fail(errors.New("first"))
+	job.fail(errors.New("second"))
+	job.done()
+	select {
+	case msg := <-ch.sent:
+		data := msg.Data.(notify.JobFailure)
+		require.Equal(t, "TestJob", data.Job)
+		require.Equal(t, "2 errors, first error: first", data.Error)
+	case <-time.After(time.Second):
+		t.Fatal("job failure not notified")
+	}
+	select {
+	case msg := <-ch.sent:
+		t.Fatalf("unexpected notification: %s", msg.Title)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
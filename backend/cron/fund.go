@@ -3,6 +3,7 @@ package cron
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/axiaoxin-com/investool/core"
@@ -16,6 +17,8 @@ import (
 func SyncFund() {
 	ctx := context.Background()
 	logrus.Info("SyncFund request start...")
+	job := newSyncJob("SyncFund")
+	defer job.done()
 	fundTypes := []eastmoney.FundType{
 		eastmoney.FundTypeStock,
 		eastmoney.FundTypeMix,
@@ -35,7 +38,7 @@ func SyncFund() {
 		efunds, err := datacenter.EastMoney.QueryAllFundList(ctx, fundType)
 		if err != nil {
 			logrus.Errorf("SyncFund QueryAllFundList error:%v", err)
-			promSyncError.WithLabelValues("SyncFund").Inc()
+			job.fail(err)
 			return
 		}
 		efundlist = append(efundlist, efunds...)
@@ -47,7 +50,7 @@ func SyncFund() {
 		data, err := s.SearchFunds(ctx, fundCodes)
 		if err != nil {
 			logrus.Errorf("SyncFund SearchFunds error:%v", err)
-			promSyncError.WithLabelValues("SyncFund").Inc()
+			job.fail(err)
 			return
 		}
 		fundlist := models.FundList{}
@@ -68,20 +71,14 @@ func SyncFund() {
 			// 使用 CreateOrUpdate 模式保存基金基本信息
 			if err := models.DB.Save(fundDB).Error; err != nil {
 				logrus.Errorf("SyncFund Save fund error: code=%s, error=%v", fund.Code, err)
-				promSyncError.WithLabelValues("SyncFund").Inc()
+				job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 				continue
 			}
 
-			// 保存基金持仓股票
-			stocks := fund.ToFundStocks()
-			if len(stocks) > 0 {
-				// 删除旧的持仓记录
-				models.DB.Where("fund_code = ?", fund.Code).Delete(&models.FundStockDB{})
-				// 插入新的持仓记录
-				if err := models.DB.CreateInBatches(stocks, 100).Error; err != nil {
-					logrus.Errorf("SyncFund Save stocks error: code=%s, error=%v", fund.Code, err)
-					promSyncError.WithLabelValues("SyncFund").Inc()
-				}
+			// 保存基金持仓股票，按报告期保留历史持仓
+			if err := models.SaveFundStocks(models.DB, fund.Code, fund.StocksReportDate, fund.ToFundStocks()); err != nil {
+				logrus.Errorf("SyncFund Save stocks error: code=%s, error=%v", fund.Code, err)
+				job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 			}
 
 			// 保存基金经理关联
@@ -92,7 +89,7 @@ func SyncFund() {
 					Assign(*managerRel).
 					FirstOrCreate(managerRel).Error; err != nil {
 					logrus.Errorf("SyncFund Save manager error: code=%s, error=%v", fund.Code, err)
-					promSyncError.WithLabelValues("SyncFund").Inc()
+					job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 				}
 			}
 
@@ -104,7 +101,7 @@ func SyncFund() {
 				// 插入新的分红记录
 				if err := models.DB.CreateInBatches(dividends, 100).Error; err != nil {
 					logrus.Errorf("SyncFund Save dividends error: code=%s, error=%v", fund.Code, err)
-					promSyncError.WithLabelValues("SyncFund").Inc()
+					job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 				}
 			}
 
@@ -116,10 +113,16 @@ func SyncFund() {
 					Assign(*assetsProp).
 					FirstOrCreate(assetsProp).Error; err != nil {
 					logrus.Errorf("SyncFund Save assets proportion error: code=%s, error=%v", fund.Code, err)
-					promSyncError.WithLabelValues("SyncFund").Inc()
+					job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 				}
 			}
 
+			// 保存基金历史规模，用于回测时按调仓日的规模筛选
+			if err := models.SaveFundScales(context.Background(), fund.Code, fund.ScaleHistory); err != nil {
+				logrus.Errorf("SyncFund Save scales error: code=%s, error=%v", fund.Code, err)
+				job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
+			}
+
 			// 保存基金行业占比
 			industryProps := fund.ToFundIndustryProportions()
 			if len(industryProps) > 0 {
@@ -128,7 +131,7 @@ func SyncFund() {
 				// 插入新的行业占比记录
 				if err := models.DB.CreateInBatches(industryProps, 100).Error; err != nil {
 					logrus.Errorf("SyncFund Save industry proportions error: code=%s, error=%v", fund.Code, err)
-					promSyncError.WithLabelValues("SyncFund").Inc()
+					job.fail(fmt.Errorf("fund %s: %w", fund.Code, err))
 				}
 			}
 		}
@@ -138,6 +141,7 @@ func SyncFund() {
 		time.Sleep(1 * time.Minute)
 	}
 
+	EvaluateAlerts("SyncFund", core.AlertTypesAfterSyncFund)
 	logrus.Info("SyncFund request end...")
 }
 
@@ -183,3 +187,29 @@ func Update4433(allFundlist models.FundList) {
 
 	logrus.Infof("Update4433 request end...")
 }
+
+// SyncFundStyle 计算基金风格箱及风格漂移
+func SyncFundStyle() {
+	ctx := context.Background()
+	logrus.Info("SyncFundStyle request start...")
+	count, err := models.SyncFundStyles(ctx)
+	if err != nil {
+		logrus.Errorf("SyncFundStyle error:%v", err)
+		syncJobFailed("SyncFundStyle", err)
+		return
+	}
+	logrus.Infof("SyncFundStyle %d funds saved", count)
+}
+
+// SyncFundNav 保存股票型和混合型基金的历史净值，供回测使用
+func SyncFundNav() {
+	ctx := context.Background()
+	logrus.Info("SyncFundNav request start...")
+	count, err := models.SyncFundNavs(ctx, 1500)
+	if err != nil {
+		logrus.Errorf("SyncFundNav error:%v", err)
+		syncJobFailed("SyncFundNav", err)
+		return
+	}
+	logrus.Infof("SyncFundNav %d funds saved", count)
+}
@@ -181,22 +181,9 @@ func updateSingleFund(ctx context.Context, db *gorm.DB, fundCode string) error {
 			return errors.New("updateSingleFund FirstOrCreate error: " + err.Error())
 		}
 
-		// 删除旧持仓
-		tx.Where("fund_code = ?", fund.Code).Delete(&models.FundStockDB{})
-
-		// 插入新持仓
-		for _, stock := range fund.Stocks {
-			stockDB := models.FundStockDB{
-				FundCode:    fund.Code,
-				StockCode:   stock.Code,
-				StockName:   stock.Name,
-				Industry:    stock.Industry,
-				ExCode:      stock.ExCode,
-				HoldRatio:   stock.HoldRatio,
-				AdjustRatio: stock.AdjustRatio,
-				UpdatedAt:   time.Now(),
-			}
-			tx.Create(&stockDB)
+		// 保存持仓，按报告期保留历史持仓
+		if err := models.SaveFundStocks(tx, fund.Code, fund.StocksReportDate, fund.ToFundStocks()); err != nil {
+			return errors.New("updateSingleFund SaveFundStocks error: " + err.Error())
 		}
 
 		// 更新同步时间
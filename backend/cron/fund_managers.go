@@ -3,7 +3,10 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/datacenter"
 	"github.com/axiaoxin-com/investool/models"
 	"github.com/sirupsen/logrus"
@@ -12,16 +15,20 @@ import (
 // SyncFundManagers 同步基金经理
 func SyncFundManagers() {
 	ctx := context.Background()
+	job := newSyncJob("SyncFundManagers")
+	defer job.done()
 
 	// 检查数据库是否初始化
 	if models.DB == nil {
 		logrus.Error("SyncFundManagers: database not initialized")
+		job.fail(errors.New("database not initialized"))
 		return
 	}
 
 	managers, err := datacenter.EastMoney.FundMangers(ctx, "all", "penavgrowth", "desc")
 	if err != nil {
 		logrus.Errorf("SyncFundManagers error: %v", err)
+		job.fail(err)
 		return
 	}
 	managers.SortByYieldse()
@@ -32,6 +39,7 @@ func SyncFundManagers() {
 		managerDB := models.ToFundManagerDB(manager)
 		if err := models.DB.Save(managerDB).Error; err != nil {
 			logrus.Errorf("SyncFundManagers Save manager error: id=%s, error=%v", manager.ID, err)
+			job.fail(fmt.Errorf("manager %s: %w", manager.ID, err))
 			continue
 		}
 
@@ -44,10 +52,12 @@ func SyncFundManagers() {
 			if len(funds) > 0 {
 				if err := models.DB.CreateInBatches(funds, 100).Error; err != nil {
 					logrus.Errorf("SyncFundManagers Save manager funds error: id=%s, error=%v", manager.ID, err)
+					job.fail(fmt.Errorf("manager %s funds: %w", manager.ID, err))
 				}
 			}
 		}
 	}
 
 	logrus.Info("SyncFundManagers saved to database successfully")
+	EvaluateAlerts("SyncFundManagers", core.AlertTypesAfterSyncFundManagers)
 }
@@ -0,0 +1,42 @@
+// 同步指数估值
+
+package cron
+
+import (
+	"context"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// IndexWatchList 需要每日保存估值的指数代码，未配置时使用市场温度指数
+func IndexWatchList() []string {
+	codes := viper.GetStringSlice("app.index_watch_list")
+	if len(codes) > 0 {
+		return codes
+	}
+	for _, index := range models.MarketIndexes {
+		codes = append(codes, index.Code)
+	}
+	return codes
+}
+
+// SyncIndexValuation 保存关注指数的当日估值
+func SyncIndexValuation() {
+	if !goutils.IsTradingDay() {
+		return
+	}
+	ctx := context.Background()
+	codes := IndexWatchList()
+	count, err := models.SyncIndexValuations(ctx, codes)
+	if err != nil {
+		logrus.Errorf("SyncIndexValuation error:%v", err)
+		syncJobFailed("SyncIndexValuation", err)
+		return
+	}
+	logrus.Infof("SyncIndexValuation saved %d/%d indexes", count, len(codes))
+	EvaluateAlerts("SyncIndexValuation", core.AlertTypesAfterSyncIndexValuation)
+}
@@ -0,0 +1,88 @@
+// 可手动触发的定时任务
+
+package cron
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrJobNotFound 任务不存在
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobRunning 任务正在执行
+var ErrJobRunning = errors.New("job is running")
+
+// Jobs 可手动触发的同步任务
+var Jobs = map[string]func(){
+	"SyncFund":              SyncFund,
+	"SyncFundManagers":      SyncFundManagers,
+	"SyncIndustryList":      SyncIndustryList,
+	"SyncBond":              SyncBond,
+	"SyncMarketTemperature": SyncMarketTemperature,
+	"SyncIndexValuation":    SyncIndexValuation,
+	"SyncIndustryDaily":     SyncIndustryDaily,
+	"SyncStockPrice":        SyncStockPrice,
+	"SyncStockMoneyFlow":    SyncStockMoneyFlow,
+	"SyncFundStyle":         SyncFundStyle,
+	"SyncFundNav":           SyncFundNav,
+	"EvaluateAlerts": func() {
+		EvaluateAlerts("manual", nil)
+	},
+}
+
+var running sync.Map
+
+// JobNames 返回全部可手动触发的任务名称，包括配置的定时报告 Report:<name>
+func JobNames() []string {
+	names := []string{}
+	for name := range Jobs {
+		names = append(names, name)
+	}
+	reports, _ := core.ReportConfigs()
+	for _, cfg := range reports {
+		names = append(names, "Report:"+cfg.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupJob 按名称查找任务，Report:<name> 为配置的定时报告
+func lookupJob(name string) (func(), error) {
+	if job, ok := Jobs[name]; ok {
+		return job, nil
+	}
+	reports, err := core.ReportConfigs()
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range reports {
+		if "Report:"+cfg.Name == name {
+			cfg := cfg
+			return func() { RunReport(cfg) }, nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// TriggerJob 在后台执行任务，同名任务正在执行时返回 ErrJobRunning
+func TriggerJob(name string) error {
+	job, err := lookupJob(name)
+	if err != nil {
+		return err
+	}
+	if _, loaded := running.LoadOrStore(name, true); loaded {
+		return ErrJobRunning
+	}
+	go func() {
+		defer running.Delete(name)
+		logrus.Infof("TriggerJob %s started", name)
+		job()
+		logrus.Infof("TriggerJob %s finished", name)
+	}()
+	return nil
+}
@@ -0,0 +1,30 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerJob(t *testing.T) {
+	require.ErrorIs(t, TriggerJob("NotExists"), ErrJobNotFound)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	Jobs["TestJob"] = func() {
+		<-release
+		close(done)
+	}
+	defer delete(Jobs, "TestJob")
+
+	require.Nil(t, TriggerJob("TestJob"))
+	require.ErrorIs(t, TriggerJob("TestJob"), ErrJobRunning)
+	require.Contains(t, JobNames(), "TestJob")
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job not finished")
+	}
+}
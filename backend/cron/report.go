@@ -0,0 +1,49 @@
+// 定时生成并发送报告
+
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/core"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/axiaoxin-com/investool/notify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// RunReport 生成报告并存档，通过通知渠道发送
+func RunReport(cfg core.ReportConfig) {
+	jobname := "Report:" + cfg.Name
+	if models.DB == nil {
+		logrus.Warnf("%s skipped: database not initialized", jobname)
+		return
+	}
+	ctx := context.Background()
+	report, err := core.GenerateReport(ctx, cfg)
+	if err != nil {
+		logrus.Errorf("%s GenerateReport error:%v", jobname, err)
+		syncJobFailed(jobname, err)
+		return
+	}
+
+	downloadURL := ""
+	if hostURL := viper.GetString("server.host_url"); hostURL != "" && report.Filename != "" {
+		downloadURL = fmt.Sprintf("%s/api/reports/%d", strings.TrimRight(hostURL, "/"), report.ID)
+	}
+	err = core.DeliverReport(ctx, report, downloadURL)
+	if errors.Is(err, notify.ErrNotConfigured) || errors.Is(err, notify.ErrNoChannel) || errors.Is(err, notify.ErrDryRun) {
+		// 未配置发送渠道时报告只存档，不作为任务失败
+		logrus.Warnf("%s report id:%d not delivered: %v", jobname, report.ID, err)
+		return
+	}
+	if err != nil {
+		logrus.Errorf("%s DeliverReport id:%d error:%v", jobname, report.ID, err)
+		syncJobFailed(jobname, err)
+		return
+	}
+	logrus.Infof("%s generated report id:%d title:%s", jobname, report.ID, report.Title)
+}
@@ -6,18 +6,26 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/core"
 	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
 	"github.com/axiaoxin-com/investool/models"
 	"github.com/sirupsen/logrus"
 )
 
+// stockPriceSyncDays 每次同步的 K 线条数，覆盖中断几天后的补齐
+const stockPriceSyncDays = 30
+
 // SyncIndustryList 同步行业列表
 func SyncIndustryList() {
 	ctx := context.Background()
+	job := newSyncJob("SyncIndustryList")
+	defer job.done()
 	indlist, err := datacenter.EastMoney.QueryIndustryList(ctx)
 	if err != nil {
 		logrus.Errorf("SyncIndustryList QueryIndustryList error: %v", err)
-		promSyncError.WithLabelValues("SyncIndustryList").Inc()
+		job.fail(err)
 		return
 	}
 	if len(indlist) != 0 {
@@ -29,7 +37,7 @@ func SyncIndustryList() {
 		// 清空旧的行业数据
 		if err := models.DB.Exec("TRUNCATE TABLE industries").Error; err != nil {
 			logrus.Errorf("SyncIndustryList truncate error: %v", err)
-			promSyncError.WithLabelValues("SyncIndustryList").Inc()
+			job.fail(err)
 		}
 
 		// 批量插入新的行业数据
@@ -44,7 +52,7 @@ func SyncIndustryList() {
 		if len(industries) > 0 {
 			if err := models.DB.CreateInBatches(industries, 100).Error; err != nil {
 				logrus.Errorf("SyncIndustryList save to database error: %v", err)
-				promSyncError.WithLabelValues("SyncIndustryList").Inc()
+				job.fail(err)
 			} else {
 				logrus.Info(fmt.Sprintf("SyncIndustryList saved %d industries to database successfully", len(industries)))
 			}
@@ -52,3 +60,71 @@ func SyncIndustryList() {
 	}
 
 }
+
+// SyncIndustryDaily 保存行业板块当日行情及估值
+func SyncIndustryDaily() {
+	if !goutils.IsTradingDay() {
+		return
+	}
+	ctx := context.Background()
+	count, err := models.SyncIndustryDailies(ctx)
+	if err != nil {
+		logrus.Errorf("SyncIndustryDaily error:%v", err)
+		syncJobFailed("SyncIndustryDaily", err)
+		return
+	}
+	logrus.Infof("SyncIndustryDaily saved %d industries", count)
+}
+
+// SyncStockPrice 保存股票告警规则监控股票的日 K 线，完成后评估股票告警
+func SyncStockPrice() {
+	if !goutils.IsTradingDay() {
+		return
+	}
+	ctx := context.Background()
+	job := newSyncJob("SyncStockPrice")
+	defer job.done()
+	secucodes, err := core.AlertStockSecucodes(ctx)
+	if err != nil {
+		logrus.Errorf("SyncStockPrice AlertStockSecucodes error:%v", err)
+		job.fail(err)
+		return
+	}
+	for _, secucode := range secucodes {
+		klines, err := datacenter.EastMoney.QueryKline(ctx, secucode, eastmoney.KlineTypeDay, eastmoney.KlineFQForward, stockPriceSyncDays)
+		if err != nil {
+			logrus.Errorf("SyncStockPrice %s QueryKline error:%v", secucode, err)
+			job.fail(err)
+			continue
+		}
+		if err := models.SaveStockPrices(ctx, secucode, eastmoney.KlineTypeDay, eastmoney.KlineFQForward, klines); err != nil {
+			logrus.Errorf("SyncStockPrice %s SaveStockPrices error:%v", secucode, err)
+			job.fail(err)
+		}
+	}
+	logrus.Infof("SyncStockPrice saved %d stocks", len(secucodes))
+	EvaluateAlerts("SyncStockPrice", core.AlertTypesAfterSyncDaily)
+}
+
+// SyncStockMoneyFlow 保存股票告警规则监控股票的资金流向，积累超出接口窗口的历史数据
+func SyncStockMoneyFlow() {
+	if !goutils.IsTradingDay() {
+		return
+	}
+	ctx := context.Background()
+	job := newSyncJob("SyncStockMoneyFlow")
+	defer job.done()
+	secucodes, err := core.AlertStockSecucodes(ctx)
+	if err != nil {
+		logrus.Errorf("SyncStockMoneyFlow AlertStockSecucodes error:%v", err)
+		job.fail(err)
+		return
+	}
+	for _, secucode := range secucodes {
+		if _, err := models.SyncStockMoneyFlows(ctx, secucode); err != nil {
+			logrus.Errorf("SyncStockMoneyFlow %s error:%v", secucode, err)
+			job.fail(err)
+		}
+	}
+	logrus.Infof("SyncStockMoneyFlow saved %d stocks", len(secucodes))
+}
@@ -25,6 +25,35 @@ type RespQueryTree []TreeItem
 
 // QueryTree 查询债券曲线树数据，返回key为债券曲线名称，value为曲线名称对应的随机id
 func (c ChinaBond) QueryTree(ctx context.Context) (map[string]string, error) {
+	items, err := c.QueryTreeItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, i := range items {
+		result[i.Name] = i.ID
+	}
+	return result, nil
+}
+
+// QueryCurveList 查询全部收益率曲线（不含分类节点），返回key为曲线名称，value为曲线id
+func (c ChinaBond) QueryCurveList(ctx context.Context) (map[string]string, error) {
+	items, err := c.QueryTreeItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	for _, i := range items {
+		if i.IsParent == "true" {
+			continue
+		}
+		result[i.Name] = i.ID
+	}
+	return result, nil
+}
+
+// QueryTreeItems 查询债券曲线树全部节点
+func (c ChinaBond) QueryTreeItems(ctx context.Context) (RespQueryTree, error) {
 	apiurl := "https://yield.chinabond.com.cn/cbweb-mn/yc/queryTree?locale=zh_CN"
 	logrus.WithContext(ctx).Debug("ChinaBond QueryTree " + apiurl + " begin")
 	beginTime := time.Now()
@@ -41,9 +70,5 @@ func (c ChinaBond) QueryTree(ctx context.Context) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	result := map[string]string{}
-	for _, i := range resp {
-		result[i.Name] = i.ID
-	}
-	return result, nil
+	return resp, nil
 }
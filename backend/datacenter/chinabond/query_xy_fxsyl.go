@@ -36,6 +36,43 @@ type RespQueryFxsyl struct {
 // treeItemID 为QueryTree中对应债券的id
 // date为string格式的指定日期：YYYY-mm-dd
 func (c ChinaBond) QueryFxsyl(ctx context.Context, treeItemID, date string) ([][]float64, error) {
+	resp, err := c.queryFxsyl(ctx, treeItemID, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.YcChartDataList) == 0 {
+		return nil, nil
+	}
+
+	return resp.YcChartDataList[0].SeriesData, nil
+}
+
+// QueryYieldCurve 查询指定曲线在指定日期全部期限的收益率
+func (c ChinaBond) QueryYieldCurve(ctx context.Context, treeItemID, date string) (YieldCurve, error) {
+	curve := YieldCurve{ID: treeItemID, Date: date, Points: []YieldPoint{}}
+	resp, err := c.queryFxsyl(ctx, treeItemID, date)
+	if err != nil {
+		return curve, err
+	}
+	if len(resp.YcChartDataList) == 0 {
+		return curve, nil
+	}
+	data := resp.YcChartDataList[0]
+	curve.Name = data.YcDefName
+	if data.Worktime != "" {
+		curve.Date = data.Worktime
+	}
+	for _, i := range data.SeriesData {
+		if len(i) != 2 {
+			continue
+		}
+		curve.Points = append(curve.Points, YieldPoint{Tenor: i[0], Yield: i[1]})
+	}
+	return curve, nil
+}
+
+// queryFxsyl 请求曲线收益率接口
+func (c ChinaBond) queryFxsyl(ctx context.Context, treeItemID, date string) (RespQueryFxsyl, error) {
 	apiurl := fmt.Sprintf(
 		"https://yield.chinabond.com.cn/cbweb-mn/yc/searchXyFxsyl?xyzSelect=txy&&workTimes=%s&&dxbj=4&&qxll=1,&&yqqxN=N&&yqqxK=K&&ycDefIds=%s,&&locale=zh_CN",
 		date,
@@ -46,7 +83,7 @@ func (c ChinaBond) QueryFxsyl(ctx context.Context, treeItemID, date string) ([][
 	resp := RespQueryFxsyl{}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiurl, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "QueryFxsyl NewRequestWithContext")
+		return resp, errors.Wrap(err, "QueryFxsyl NewRequestWithContext")
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", uarand.GetRandom())
@@ -56,14 +93,7 @@ func (c ChinaBond) QueryFxsyl(ctx context.Context, treeItemID, date string) ([][
 		"latency(ms)": latency,
 		"resp":        resp,
 	}).Debug("ChinaBond QueryFxsyl " + apiurl + " end")
-	if err != nil {
-		return nil, err
-	}
-	if len(resp.YcChartDataList) == 0 {
-		return nil, nil
-	}
-
-	return resp.YcChartDataList[0].SeriesData, nil
+	return resp, err
 }
 
 // QueryCurrentSyl 返回债券当期收益率
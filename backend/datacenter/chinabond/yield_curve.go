@@ -0,0 +1,63 @@
+// 收益率曲线数据结构及期限插值
+
+package chinabond
+
+import "sort"
+
+// 常用收益率曲线名称
+const (
+	// CurveTreasury 中债国债收益率曲线
+	CurveTreasury = "中债国债收益率曲线"
+	// CurveCDB 中债国开债收益率曲线
+	CurveCDB = "中债国开债收益率曲线"
+	// CurveCompanyAAA 中债证券公司债收益率曲线(AAA)
+	CurveCompanyAAA = "中债证券公司债收益率曲线(AAA)"
+)
+
+// YieldPoint 收益率曲线上的一个期限点
+type YieldPoint struct {
+	// 期限（年）
+	Tenor float64 `json:"tenor"`
+	// 收益率（%）
+	Yield float64 `json:"yield"`
+}
+
+// YieldCurve 某一日期的收益率曲线
+type YieldCurve struct {
+	// 曲线 id
+	ID string `json:"id"`
+	// 曲线名称
+	Name string `json:"name"`
+	// 日期：YYYY-mm-dd
+	Date string `json:"date"`
+	// 各期限收益率
+	Points []YieldPoint `json:"points"`
+}
+
+// YieldAt 返回指定期限的收益率，期限不在曲线节点上时线性插值，超出范围时取端点值
+func (c YieldCurve) YieldAt(tenor float64) (float64, bool) {
+	if len(c.Points) == 0 {
+		return 0, false
+	}
+	points := append([]YieldPoint{}, c.Points...)
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Tenor < points[j].Tenor
+	})
+	if tenor <= points[0].Tenor {
+		return points[0].Yield, true
+	}
+	last := points[len(points)-1]
+	if tenor >= last.Tenor {
+		return last.Yield, true
+	}
+	for i := 1; i < len(points); i++ {
+		if tenor <= points[i].Tenor {
+			lo, hi := points[i-1], points[i]
+			if hi.Tenor == lo.Tenor {
+				return hi.Yield, true
+			}
+			return lo.Yield + (hi.Yield-lo.Yield)*(tenor-lo.Tenor)/(hi.Tenor-lo.Tenor), true
+		}
+	}
+	return last.Yield, true
+}
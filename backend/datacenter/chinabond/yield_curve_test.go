@@ -0,0 +1,29 @@
+package chinabond
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestYieldCurveYieldAt(t *testing.T) {
+	c := YieldCurve{
+		Points: []YieldPoint{
+			{Tenor: 10, Yield: 2.8},
+			{Tenor: 1, Yield: 2.0},
+			{Tenor: 5, Yield: 2.4},
+		},
+	}
+	y, ok := c.YieldAt(5)
+	require.True(t, ok)
+	require.Equal(t, 2.4, y)
+	y, _ = c.YieldAt(3)
+	require.InDelta(t, 2.2, y, 1e-9)
+	y, _ = c.YieldAt(0.5)
+	require.Equal(t, 2.0, y)
+	y, _ = c.YieldAt(30)
+	require.Equal(t, 2.8, y)
+
+	_, ok = YieldCurve{}.YieldAt(10)
+	require.False(t, ok)
+}
@@ -0,0 +1,201 @@
+// 获取 A 股历史分红送配数据
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/sirupsen/logrus"
+)
+
+// StockDividend 分红送配记录
+type StockDividend struct {
+	SecurityCode     string `json:"SECURITY_CODE"`
+	SecurityNameAbbr string `json:"SECURITY_NAME_ABBR"`
+	// 报告期: 2021-12-31 00:00:00
+	ReportDate string `json:"REPORT_DATE"`
+	// 预案公告日
+	PlanNoticeDate string `json:"PLAN_NOTICE_DATE"`
+	// 股权登记日
+	EquityRecordDate string `json:"EQUITY_RECORD_DATE"`
+	// 除权除息日
+	ExDividendDate string `json:"EX_DIVIDEND_DATE"`
+	// 每10股派息（税前，元）
+	PretaxBonusRMB float64 `json:"PRETAX_BONUS_RMB"`
+	// 每10股送转总比例
+	BonusItRatio float64 `json:"BONUS_IT_RATIO"`
+	// 方案进度: 实施分配、股东大会预案、董事会预案、不分配
+	AssignProgress string `json:"ASSIGN_PROGRESS"`
+	// 报告期基本每股收益
+	BasicEps float64 `json:"BASIC_EPS"`
+	// 股息率
+	DividentRatio float64 `json:"DIVIDENT_RATIO"`
+	// 方案说明
+	ImplPlanProfile string `json:"IMPL_PLAN_PROFILE"`
+}
+
+// CashPerShare 每股现金分红（税前，元）
+func (d StockDividend) CashPerShare() float64 {
+	return d.PretaxBonusRMB / 10
+}
+
+// ReportYear 分红对应的财报年度
+func (d StockDividend) ReportYear() int {
+	if len(d.ReportDate) < 4 {
+		return 0
+	}
+	year, _ := strconv.Atoi(d.ReportDate[:4])
+	return year
+}
+
+// IsImplemented 是否已实施分配
+func (d StockDividend) IsImplemented() bool {
+	return d.AssignProgress == "实施分配"
+}
+
+// StockDividendList 历史分红列表，最新的在最前面
+type StockDividendList []StockDividend
+
+// FilterByExDividendDate 只保留 before 之前已除权除息的分红记录
+func (l StockDividendList) FilterByExDividendDate(before string) StockDividendList {
+	result := StockDividendList{}
+	for _, d := range l {
+		if d.IsImplemented() && IsPublishedBefore(d.ExDividendDate, before) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// YearlyCashPerShare 按财报年度汇总已实施的每股现金分红
+func (l StockDividendList) YearlyCashPerShare() map[int]float64 {
+	r := map[int]float64{}
+	for _, d := range l {
+		if !d.IsImplemented() || d.PretaxBonusRMB <= 0 {
+			continue
+		}
+		r[d.ReportYear()] += d.CashPerShare()
+	}
+	return r
+}
+
+// YearlyPayoutRatio 按财报年度计算股利支付率（%）：每股分红 / 年度基本每股收益
+// 年度 EPS 以年报（12-31）记录为准
+func (l StockDividendList) YearlyPayoutRatio() map[int]float64 {
+	eps := map[int]float64{}
+	for _, d := range l {
+		if len(d.ReportDate) >= 10 && d.ReportDate[5:10] == "12-31" && d.BasicEps != 0 {
+			eps[d.ReportYear()] = d.BasicEps
+		}
+	}
+	r := map[int]float64{}
+	for year, cash := range l.YearlyCashPerShare() {
+		if e, ok := eps[year]; ok && e > 0 {
+			r[year] = cash / e * 100
+		}
+	}
+	return r
+}
+
+// ConsecutiveYears 截止 lastYear 年度的连续分红年数
+func (l StockDividendList) ConsecutiveYears(lastYear int) int {
+	yearly := l.YearlyCashPerShare()
+	// 最新年度可能还未公布分红方案
+	if _, ok := yearly[lastYear]; !ok {
+		lastYear--
+	}
+	count := 0
+	for y := lastYear; ; y-- {
+		if yearly[y] <= 0 {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// CAGR 截止 lastYear 年度 years 年的每股分红复合增长率（%）
+func (l StockDividendList) CAGR(lastYear, years int) (float64, error) {
+	yearly := l.YearlyCashPerShare()
+	if _, ok := yearly[lastYear]; !ok {
+		lastYear--
+	}
+	begin, end := yearly[lastYear-years], yearly[lastYear]
+	if years <= 0 || begin <= 0 || end <= 0 {
+		return 0, fmt.Errorf("invalid dividend data for CAGR: %d-%d", lastYear-years, lastYear)
+	}
+	return (math.Pow(end/begin, 1/float64(years)) - 1) * 100, nil
+}
+
+// PayoutRatioList 截止 lastYear 年度最近 years 年的股利支付率，最新的在最前面
+func (l StockDividendList) PayoutRatioList(lastYear, years int) []float64 {
+	payout := l.YearlyPayoutRatio()
+	keys := []int{}
+	for y := range payout {
+		if y <= lastYear {
+			keys = append(keys, y)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+	if len(keys) > years {
+		keys = keys[:years]
+	}
+	r := []float64{}
+	for _, y := range keys {
+		r = append(r, payout[y])
+	}
+	return r
+}
+
+// RespStockDividend 分红送配接口返回结构
+type RespStockDividend struct {
+	Version string `json:"version"`
+	Result  struct {
+		Pages int               `json:"pages"`
+		Data  StockDividendList `json:"data"`
+		Count int               `json:"count"`
+	} `json:"result"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// QueryStockDividendHistory 获取股票历史分红送配记录，securityCode 为 6 位股票代码
+func (e EastMoney) QueryStockDividendHistory(ctx context.Context, securityCode string) (StockDividendList, error) {
+	apiurl := "https://datacenter-web.eastmoney.com/api/data/v1/get"
+	params := map[string]string{
+		"reportName":  "RPT_SHAREBONUS_DET",
+		"columns":     "ALL",
+		"filter":      fmt.Sprintf(`(SECURITY_CODE="%s")`, securityCode),
+		"sortColumns": "REPORT_DATE",
+		"sortTypes":   "-1",
+		"pageSize":    "100",
+		"pageNumber":  "1",
+		"source":      "WEB",
+		"client":      "WEB",
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryStockDividendHistory " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespStockDividend{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryStockDividendHistory " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	// 没有分红记录时 code 为 9201
+	if resp.Code != 0 && resp.Code != 9201 {
+		return nil, fmt.Errorf("%s %#v", securityCode, resp)
+	}
+	return resp.Result.Data, nil
+}
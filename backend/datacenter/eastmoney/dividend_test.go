@@ -0,0 +1,35 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStockDividendHistory(t *testing.T) {
+	data, err := _em.QueryStockDividendHistory(_ctx, "600036")
+	require.Nil(t, err)
+	require.NotEmpty(t, data)
+	t.Log(data[0])
+}
+
+func TestStockDividendListAnalysis(t *testing.T) {
+	l := StockDividendList{
+		{ReportDate: "2022-12-31 00:00:00", PretaxBonusRMB: 12.1, AssignProgress: "实施分配", BasicEps: 3.0},
+		{ReportDate: "2022-06-30 00:00:00", PretaxBonusRMB: 2, AssignProgress: "实施分配"},
+		{ReportDate: "2021-12-31 00:00:00", PretaxBonusRMB: 11, AssignProgress: "实施分配", BasicEps: 2.8},
+		{ReportDate: "2020-12-31 00:00:00", PretaxBonusRMB: 10, AssignProgress: "实施分配", BasicEps: 2.5},
+		{ReportDate: "2019-12-31 00:00:00", PretaxBonusRMB: 0, AssignProgress: "不分配", BasicEps: 2.0},
+	}
+	require.Equal(t, 3, l.ConsecutiveYears(2023))
+	require.Equal(t, 3, l.ConsecutiveYears(2022))
+	cagr, err := l.CAGR(2022, 2)
+	require.Nil(t, err)
+	require.InDelta(t, 18.74, cagr, 0.01)
+	_, err = l.CAGR(2022, 3)
+	require.NotNil(t, err)
+	payout := l.PayoutRatioList(2022, 5)
+	require.Len(t, payout, 3)
+	require.InDelta(t, 47.0, payout[0], 1e-9)
+	require.InDelta(t, 40.0, payout[2], 1e-9)
+}
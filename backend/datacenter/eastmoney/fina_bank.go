@@ -0,0 +1,155 @@
+// 获取银行股财务分析利润表、资产负债表数据
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/sirupsen/logrus"
+)
+
+// BankIncomeData 银行利润表数据
+type BankIncomeData struct {
+	Secucode         string         `json:"SECUCODE"`
+	SecurityCode     string         `json:"SECURITY_CODE"`
+	SecurityNameAbbr string         `json:"SECURITY_NAME_ABBR"`
+	ReportDate       string         `json:"REPORT_DATE"`
+	ReportType       FinaReportType `json:"REPORT_TYPE"`
+	ReportDateName   string         `json:"REPORT_DATE_NAME"`
+	// 营业收入
+	OperateIncome float64 `json:"OPERATE_INCOME"`
+	// 利息净收入
+	InterestNi float64 `json:"INTEREST_NI"`
+	// 利息收入
+	InterestIncome float64 `json:"INTEREST_INCOME"`
+	// 利息支出
+	InterestExpense float64 `json:"INTEREST_EXPENSE"`
+	// 手续费及佣金净收入
+	FeeCommissionNi float64 `json:"FEE_COMMISSION_NI"`
+	// 业务及管理费
+	BusinessManage float64 `json:"BUSINESS_MANAGE"`
+	// 信用减值损失
+	CreditImpairmentLoss float64 `json:"CREDIT_IMPAIRMENT_LOSS"`
+	// 归属于母公司股东的净利润
+	ParentNetprofit float64 `json:"PARENT_NETPROFIT"`
+}
+
+// BankIncomeDataList 银行利润表历史数据，最新的在最前面
+type BankIncomeDataList []BankIncomeData
+
+// RespFinaBankIncomeData 银行利润表接口返回结构
+type RespFinaBankIncomeData struct {
+	Version string `json:"version"`
+	Result  struct {
+		Pages int                `json:"pages"`
+		Data  BankIncomeDataList `json:"data"`
+		Count int                `json:"count"`
+	} `json:"result"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// QueryFinaBankIncomeData 获取银行利润表数据，最新数据在最前面
+func (e EastMoney) QueryFinaBankIncomeData(ctx context.Context, secuCode string) (BankIncomeDataList, error) {
+	apiurl := "https://datacenter.eastmoney.com/securities/api/data/get"
+	params := map[string]string{
+		"source": "HSF10",
+		"client": "APP",
+		"type":   "RPT_F10_FINANCE_BINCOME",
+		"sty":    "APP_F10_BINCOME",
+		"filter": fmt.Sprintf(`(SECUCODE="%s")`, strings.ToUpper(secuCode)),
+		"ps":     "20",
+		"sr":     "-1",
+		"st":     "REPORT_DATE",
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryFinaBankIncomeData " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespFinaBankIncomeData{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryFinaBankIncomeData " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("%s %#v", secuCode, resp)
+	}
+	return resp.Result.Data, nil
+}
+
+// BankBalanceData 银行资产负债表数据
+type BankBalanceData struct {
+	Secucode         string         `json:"SECUCODE"`
+	SecurityCode     string         `json:"SECURITY_CODE"`
+	SecurityNameAbbr string         `json:"SECURITY_NAME_ABBR"`
+	ReportDate       string         `json:"REPORT_DATE"`
+	ReportType       FinaReportType `json:"REPORT_TYPE"`
+	ReportDateName   string         `json:"REPORT_DATE_NAME"`
+	// 资产总计
+	TotalAssets float64 `json:"TOTAL_ASSETS"`
+	// 发放贷款及垫款
+	LoanAdvance float64 `json:"LOAN_ADVANCE"`
+	// 吸收存款
+	AcceptDeposit float64 `json:"ACCEPT_DEPOSIT"`
+	// 负债合计
+	TotalLiabilities float64 `json:"TOTAL_LIABILITIES"`
+	// 归属于母公司股东权益合计
+	TotalParentEquity float64 `json:"TOTAL_PARENT_EQUITY"`
+}
+
+// BankBalanceDataList 银行资产负债表历史数据，最新的在最前面
+type BankBalanceDataList []BankBalanceData
+
+// RespFinaBankBalanceData 银行资产负债表接口返回结构
+type RespFinaBankBalanceData struct {
+	Version string `json:"version"`
+	Result  struct {
+		Pages int                 `json:"pages"`
+		Data  BankBalanceDataList `json:"data"`
+		Count int                 `json:"count"`
+	} `json:"result"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// QueryFinaBankBalanceData 获取银行资产负债表数据，最新数据在最前面
+func (e EastMoney) QueryFinaBankBalanceData(ctx context.Context, secuCode string) (BankBalanceDataList, error) {
+	apiurl := "https://datacenter.eastmoney.com/securities/api/data/get"
+	params := map[string]string{
+		"source": "HSF10",
+		"client": "APP",
+		"type":   "RPT_F10_FINANCE_BBALANCE",
+		"sty":    "APP_F10_BBALANCE",
+		"filter": fmt.Sprintf(`(SECUCODE="%s")`, strings.ToUpper(secuCode)),
+		"ps":     "20",
+		"sr":     "-1",
+		"st":     "REPORT_DATE",
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryFinaBankBalanceData " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespFinaBankBalanceData{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryFinaBankBalanceData " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("%s %#v", secuCode, resp)
+	}
+	return resp.Result.Data, nil
+}
@@ -0,0 +1,21 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFinaBankIncomeData(t *testing.T) {
+	data, err := _em.QueryFinaBankIncomeData(_ctx, "600036.SH")
+	require.Nil(t, err)
+	require.NotEmpty(t, data)
+	t.Log(data[0].InterestNi)
+}
+
+func TestQueryFinaBankBalanceData(t *testing.T) {
+	data, err := _em.QueryFinaBankBalanceData(_ctx, "600036.SH")
+	require.Nil(t, err)
+	require.NotEmpty(t, data)
+	t.Log(data[0].TotalAssets)
+}
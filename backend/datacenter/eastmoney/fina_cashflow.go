@@ -274,6 +274,17 @@ type CashflowData struct {
 // CashflowDataList cashflow 列表
 type CashflowDataList []CashflowData
 
+// FilterByNoticeDate 按公告日期过滤，只保留 before 之前已公告的现金流量表
+func (l CashflowDataList) FilterByNoticeDate(before string) CashflowDataList {
+	result := CashflowDataList{}
+	for _, i := range l {
+		if IsPublishedBefore(i.NoticeDate, before) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
 // RespFinaCashflowData 现金流量接口返回数据
 type RespFinaCashflowData struct {
 	Version string `json:"version"`
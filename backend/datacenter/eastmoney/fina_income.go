@@ -222,6 +222,17 @@ type GincomeData struct {
 // GincomeDataList 利润表历史数据
 type GincomeDataList []GincomeData
 
+// FilterByNoticeDate 按公告日期过滤，只保留 before 之前已公告的利润表
+func (l GincomeDataList) FilterByNoticeDate(before string) GincomeDataList {
+	result := GincomeDataList{}
+	for _, i := range l {
+		if IsPublishedBefore(i.NoticeDate, before) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
 // RespFinaGincomeData 财务分析利润表接口返回结构
 type RespFinaGincomeData struct {
 	Version string `json:"version"`
@@ -0,0 +1,91 @@
+// 获取保险股财务分析利润表数据
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/sirupsen/logrus"
+)
+
+// InsuranceIncomeData 保险利润表数据
+type InsuranceIncomeData struct {
+	Secucode         string         `json:"SECUCODE"`
+	SecurityCode     string         `json:"SECURITY_CODE"`
+	SecurityNameAbbr string         `json:"SECURITY_NAME_ABBR"`
+	ReportDate       string         `json:"REPORT_DATE"`
+	ReportType       FinaReportType `json:"REPORT_TYPE"`
+	ReportDateName   string         `json:"REPORT_DATE_NAME"`
+	// 营业收入
+	OperateIncome float64 `json:"OPERATE_INCOME"`
+	// 已赚保费
+	EarnedPremium float64 `json:"EARNED_PREMIUM"`
+	// 退保金
+	SurrenderValue float64 `json:"SURRENDER_VALUE"`
+	// 赔付支出
+	CompensateExpense float64 `json:"COMPENSATE_EXPENSE"`
+	// 摊回赔付支出
+	AmortizeCompensateExpense float64 `json:"AMORTIZE_COMPENSATE_EXPENSE"`
+	// 提取保险责任准备金
+	ExtractInsuranceReserve float64 `json:"EXTRACT_INSURANCE_RESERVE"`
+	// 摊回保险责任准备金
+	AmortizeInsuranceReserve float64 `json:"AMORTIZE_INSURANCE_RESERVE"`
+	// 手续费及佣金支出
+	FeeCommissionExpense float64 `json:"FEE_COMMISSION_EXPENSE"`
+	// 业务及管理费
+	BusinessManageExpense float64 `json:"BUSINESS_MANAGE_EXPENSE"`
+	// 归属于母公司股东的净利润
+	ParentNetprofit float64 `json:"PARENT_NETPROFIT"`
+}
+
+// InsuranceIncomeDataList 保险利润表历史数据，最新的在最前面
+type InsuranceIncomeDataList []InsuranceIncomeData
+
+// RespFinaInsuranceIncomeData 保险利润表接口返回结构
+type RespFinaInsuranceIncomeData struct {
+	Version string `json:"version"`
+	Result  struct {
+		Pages int                     `json:"pages"`
+		Data  InsuranceIncomeDataList `json:"data"`
+		Count int                     `json:"count"`
+	} `json:"result"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// QueryFinaInsuranceIncomeData 获取保险利润表数据，最新数据在最前面
+func (e EastMoney) QueryFinaInsuranceIncomeData(ctx context.Context, secuCode string) (InsuranceIncomeDataList, error) {
+	apiurl := "https://datacenter.eastmoney.com/securities/api/data/get"
+	params := map[string]string{
+		"source": "HSF10",
+		"client": "APP",
+		"type":   "RPT_F10_FINANCE_IINCOME",
+		"sty":    "APP_F10_IINCOME",
+		"filter": fmt.Sprintf(`(SECUCODE="%s")`, strings.ToUpper(secuCode)),
+		"ps":     "20",
+		"sr":     "-1",
+		"st":     "REPORT_DATE",
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryFinaInsuranceIncomeData " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespFinaInsuranceIncomeData{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryFinaInsuranceIncomeData " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, fmt.Errorf("%s %#v", secuCode, resp)
+	}
+	return resp.Result.Data, nil
+}
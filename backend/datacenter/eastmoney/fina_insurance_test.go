@@ -0,0 +1,14 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFinaInsuranceIncomeData(t *testing.T) {
+	data, err := _em.QueryFinaInsuranceIncomeData(_ctx, "601318.SH")
+	require.Nil(t, err)
+	require.NotEmpty(t, data)
+	t.Log(data[0].EarnedPremium)
+}
@@ -224,6 +224,25 @@ func (h HistoricalFinaMainData) FilterByReportYear(ctx context.Context, reportYe
 	return result
 }
 
+// FilterByNoticeDate 按公告日期过滤，只保留 before（2021-05-01）之前已公告的财报
+func (h HistoricalFinaMainData) FilterByNoticeDate(ctx context.Context, before string) HistoricalFinaMainData {
+	result := HistoricalFinaMainData{}
+	for _, i := range h {
+		if IsPublishedBefore(i.NoticeDate, before) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// IsPublishedBefore 日期（2021-04-30 00:00:00）是否早于 before（2021-05-01），日期为空视为未公告
+func IsPublishedBefore(date, before string) bool {
+	if len(date) < 10 {
+		return false
+	}
+	return date[:10] < before
+}
+
 // GetReport 获取指定年份+季度的财报
 func (h HistoricalFinaMainData) GetReport(ctx context.Context, reportYear int, reportType FinaReportType) *FinaMainData {
 	year := fmt.Sprint(reportYear)
@@ -271,6 +290,8 @@ const (
 	ValueListTypeMLL ValueListType = "MLL"
 	// ValueListTypeJLL 净利率
 	ValueListTypeJLL ValueListType = "JLL"
+	// ValueListTypeNPL 不良贷款率
+	ValueListTypeNPL ValueListType = "NPL"
 )
 
 // FinaValueList 历史数据值列表
@@ -322,6 +343,8 @@ func (h HistoricalFinaMainData) ValueList(
 			value = i.Xsmll
 		case ValueListTypeJLL:
 			value = i.Xsjll
+		case ValueListTypeNPL:
+			value = i.NonPerLoan
 		}
 		r = append(r, value)
 	}
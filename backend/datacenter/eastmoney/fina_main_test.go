@@ -30,3 +30,15 @@ func TestQueryFinaPublishDateList(t *testing.T) {
 	require.Nil(t, err)
 	t.Log("pubdate:", date)
 }
+
+func TestFilterByNoticeDate(t *testing.T) {
+	h := HistoricalFinaMainData{
+		{ReportDateName: "2021一季报", NoticeDate: "2021-04-30 00:00:00"},
+		{ReportDateName: "2020年报", NoticeDate: "2021-03-20 00:00:00"},
+		{ReportDateName: "2020三季报", NoticeDate: ""},
+	}
+	r := h.FilterByNoticeDate(_ctx, "2021-04-30")
+	require.Len(t, r, 1)
+	require.Equal(t, "2020年报", r[0].ReportDateName)
+	require.Len(t, h.FilterByNoticeDate(_ctx, "2021-05-01"), 2)
+}
@@ -229,6 +229,27 @@ type JJCC struct {
 		// }
 		SectorAllocation map[string][]map[string]string `json:"SectorAllocation"`
 	} `json:"Datas"`
+	// 持仓报告期: 2021-03-31
+	Expansion interface{} `json:"Expansion"`
+}
+
+// ReportDate 重仓股票的报告期，接口未返回时使用资产配置中最新的日期
+func (j JJCC) ReportDate() string {
+	if s, ok := j.Expansion.(string); ok && len(s) >= 10 {
+		return s[:10]
+	}
+	date := ""
+	for d := range j.Datas.AssetAllocation {
+		if d > date {
+			date = d
+		}
+	}
+	for d := range j.Datas.SectorAllocation {
+		if d > date {
+			date = d
+		}
+	}
+	return date
 }
 
 // TSSJ 特色数据
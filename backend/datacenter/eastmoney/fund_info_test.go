@@ -15,3 +15,11 @@ func TestQueryFundInfo(t *testing.T) {
 	_, err = json.Marshal(data)
 	require.Nil(t, err)
 }
+
+func TestJJCCReportDate(t *testing.T) {
+	j := JJCC{Expansion: "2023-09-30"}
+	require.Equal(t, "2023-09-30", j.ReportDate())
+	j = JJCC{}
+	j.Datas.AssetAllocation = map[string][]map[string]string{"2023-06-30": nil, "2023-09-30": nil}
+	require.Equal(t, "2023-09-30", j.ReportDate())
+}
@@ -0,0 +1,79 @@
+// 基金历史净值
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/corpix/uarand"
+	"github.com/sirupsen/logrus"
+)
+
+// FundNetValue 基金单日净值
+type FundNetValue struct {
+	// 净值日期
+	Date string `json:"FSRQ"`
+	// 单位净值
+	NAV string `json:"DWJZ"`
+	// 累计净值
+	AccNAV string `json:"LJJZ"`
+	// 日增长率（%），已考虑分红拆分
+	GrowthRate string `json:"JZZZL"`
+}
+
+// FundNetValueList 基金历史净值列表，按日期降序排列
+type FundNetValueList []FundNetValue
+
+// DailyReturns 返回日期到日收益率（小数）的映射，缺失的增长率会被跳过
+func (l FundNetValueList) DailyReturns() map[string]float64 {
+	result := map[string]float64{}
+	for _, v := range l {
+		r, err := strconv.ParseFloat(v.GrowthRate, 64)
+		if err != nil {
+			continue
+		}
+		result[v.Date] = r / 100
+	}
+	return result
+}
+
+// RespFundNetHistory 基金历史净值接口返回结构
+type RespFundNetHistory struct {
+	Datas        FundNetValueList `json:"Datas"`
+	ErrCode      int              `json:"ErrCode"`
+	Success      bool             `json:"Success"`
+	ErrMsg       interface{}      `json:"ErrMsg"`
+	Message      interface{}      `json:"Message"`
+	ErrorCode    string           `json:"ErrorCode"`
+	ErrorMessage interface{}      `json:"ErrorMessage"`
+	TotalCount   int              `json:"TotalCount"`
+}
+
+// QueryFundNetHistory 获取基金最近 pageSize 个交易日的历史净值
+func (e EastMoney) QueryFundNetHistory(ctx context.Context, fundCode string, pageSize int) (FundNetValueList, error) {
+	apiurl := fmt.Sprintf(
+		"https://fundmobapi.eastmoney.com/FundMNewApi/FundMNHisNetList?FCODE=%s&IsShareNet=true&pageIndex=1&pagesize=%d&deviceid=Wap&plat=Wap&product=EFund&version=2.0.0",
+		fundCode,
+		pageSize,
+	)
+	logrus.WithContext(ctx).Debug("EastMoney QueryFundNetHistory " + apiurl + " begin")
+	beginTime := time.Now()
+	header := map[string]string{
+		"user-agent": uarand.GetRandom(),
+	}
+	resp := RespFundNetHistory{}
+	err := goutils.HTTPGET(ctx, e.HTTPClient, apiurl, header, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryFundNetHistory " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.ErrCode != 0 {
+		return nil, fmt.Errorf("%s %#v", fundCode, resp)
+	}
+	return resp.Datas, nil
+}
@@ -0,0 +1,14 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFundNetHistory(t *testing.T) {
+	data, err := _em.QueryFundNetHistory(_ctx, "510300", 20)
+	require.Nil(t, err)
+	require.NotEqual(t, 0, len(data))
+	require.NotEqual(t, 0, len(data.DailyReturns()))
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/axiaoxin-com/goutils"
@@ -51,6 +52,24 @@ func (h HistoricalPEList) GetMidValue(ctx context.Context) (float64, error) {
 	return goutils.MidValueFloat64(values)
 }
 
+// Before 返回 date（2021-05-01）之前的历史 pe，无法解析日期的数据不返回
+func (h HistoricalPEList) Before(date string) HistoricalPEList {
+	result := HistoricalPEList{}
+	for _, i := range h {
+		// 接口日期格式可能为 2021-06-30 或 2021/6/30 0:00:00
+		d := strings.Split(i.Date, " ")[0]
+		for _, layout := range []string{"2006-01-02", "2006/1/2"} {
+			if t, err := time.Parse(layout, d); err == nil {
+				if t.Format("2006-01-02") < date {
+					result = append(result, i)
+				}
+				break
+			}
+		}
+	}
+	return result
+}
+
 // QueryHistoricalPEList 获取历史市盈率
 func (e EastMoney) QueryHistoricalPEList(ctx context.Context, secuCode string) (HistoricalPEList, error) {
 	apiurl := "https://emfront.eastmoney.com/APP_HSF10/CPBD/GZFX"
@@ -0,0 +1,93 @@
+// 任意指数的成分股及权重
+
+package eastmoney
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IndexConstituent 指数成分股及权重
+type IndexConstituent struct {
+	IndexCode string `json:"index_code"`
+	IndexName string `json:"index_name"`
+	StockCode string `json:"stock_code"`
+	StockName string `json:"stock_name"`
+	// 权重（%），接口未提供时为 0
+	Weight float64 `json:"weight"`
+}
+
+// ToIndexConstituent 转换为带权重的成分股
+func (i ZSCFGItem) ToIndexConstituent() IndexConstituent {
+	weight, _ := strconv.ParseFloat(strings.TrimSuffix(i.Marketcappct, "%"), 64)
+	return IndexConstituent{
+		IndexCode: i.IndexCode,
+		IndexName: i.IndexName,
+		StockCode: i.StockCode,
+		StockName: i.StockName,
+		Weight:    weight,
+	}
+}
+
+// QueryIndexConstituents 返回任意指数的成分股及权重
+// 沪深300和中证500在通用接口失败时使用专用接口
+func (e EastMoney) QueryIndexConstituents(ctx context.Context, indexCode string) ([]IndexConstituent, error) {
+	beginTime := time.Now()
+	logrus.WithContext(ctx).Debugf("QueryIndexConstituents begin index:%s", indexCode)
+	defer func() {
+		logrus.WithContext(ctx).Debugf("QueryIndexConstituents end index:%s latency:%+v", indexCode, time.Since(beginTime))
+	}()
+
+	items, err := e.ZSCFG(ctx, indexCode)
+	if err == nil && len(items) > 0 {
+		results := make([]IndexConstituent, 0, len(items))
+		for _, i := range items {
+			c := i.ToIndexConstituent()
+			if c.IndexCode == "" {
+				c.IndexCode = indexCode
+			}
+			results = append(results, c)
+		}
+		return results, nil
+	}
+
+	switch indexCode {
+	case "000300":
+		stocks, herr := e.HS300(ctx)
+		if herr != nil {
+			return nil, herr
+		}
+		results := make([]IndexConstituent, 0, len(stocks))
+		for _, s := range stocks {
+			results = append(results, IndexConstituent{
+				IndexCode: indexCode,
+				IndexName: "沪深300",
+				StockCode: s.SecurityCode,
+				StockName: s.SecurityNameAbbr,
+				Weight:    s.Weight,
+			})
+		}
+		return results, nil
+	case "000905":
+		stocks, zerr := e.ZZ500(ctx)
+		if zerr != nil {
+			return nil, zerr
+		}
+		results := make([]IndexConstituent, 0, len(stocks))
+		for _, s := range stocks {
+			results = append(results, IndexConstituent{
+				IndexCode: indexCode,
+				IndexName: "中证500",
+				StockCode: s.SecurityCode,
+				StockName: s.SecurityNameAbbr,
+				Weight:    s.Weight,
+			})
+		}
+		return results, nil
+	}
+	return nil, err
+}
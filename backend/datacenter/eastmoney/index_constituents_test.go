@@ -0,0 +1,20 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryIndexConstituents(t *testing.T) {
+	results, err := _em.QueryIndexConstituents(_ctx, "000016")
+	require.Nil(t, err)
+	require.Len(t, results, 50)
+	t.Log(results[0])
+}
+
+func TestZSCFGItemToIndexConstituent(t *testing.T) {
+	c := ZSCFGItem{IndexCode: "000016", StockCode: "600519", Marketcappct: "12.34"}.ToIndexConstituent()
+	require.Equal(t, 12.34, c.Weight)
+	require.Equal(t, "600519", c.StockCode)
+}
@@ -0,0 +1,100 @@
+// 行业板块行情及估值
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/sirupsen/logrus"
+)
+
+// IndustryBoard 行业板块行情
+type IndustryBoard struct {
+	// 板块代码: BK0477
+	Code string `json:"code"`
+	// 板块名称: 酿酒行业
+	Name string `json:"name"`
+	// 板块指数点位
+	Price float64 `json:"price"`
+	// 当日涨跌幅（%）
+	ChangePct float64 `json:"change_pct"`
+	// 板块市盈率（动态）
+	PE float64 `json:"pe"`
+	// 板块市净率
+	PB float64 `json:"pb"`
+	// 总市值（元）
+	TotalMarketCap float64 `json:"total_market_cap"`
+	// 换手率（%）
+	TurnoverRate float64 `json:"turnover_rate"`
+}
+
+// RespIndustryBoards 行业板块列表接口返回结构，缺失的数值返回 "-"
+type RespIndustryBoards struct {
+	Rc   int `json:"rc"`
+	Data *struct {
+		Total int                      `json:"total"`
+		Diff  []map[string]interface{} `json:"diff"`
+	} `json:"data"`
+}
+
+// IndustrySecID 行业板块的 secid，用于查询板块指数 K 线
+func IndustrySecID(boardCode string) string {
+	return "90." + boardCode
+}
+
+// QueryIndustryBoards 获取全部行业板块的行情和估值
+func (e EastMoney) QueryIndustryBoards(ctx context.Context) ([]IndustryBoard, error) {
+	apiurl := "https://push2.eastmoney.com/api/qt/clist/get"
+	params := map[string]string{
+		"pn":     "1",
+		"pz":     "500",
+		"po":     "1",
+		"np":     "1",
+		"fltt":   "2",
+		"invt":   "2",
+		"fid":    "f3",
+		"fs":     "m:90 t:2",
+		"fields": "f2,f3,f8,f9,f12,f14,f20,f23",
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryIndustryBoards " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespIndustryBoards{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryIndustryBoards " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Rc != 0 || resp.Data == nil {
+		return nil, fmt.Errorf("%#v", resp)
+	}
+	result := make([]IndustryBoard, 0, len(resp.Data.Diff))
+	for _, item := range resp.Data.Diff {
+		result = append(result, IndustryBoard{
+			Code:           fmt.Sprint(item["f12"]),
+			Name:           fmt.Sprint(item["f14"]),
+			Price:          boardFloat(item["f2"]),
+			ChangePct:      boardFloat(item["f3"]),
+			PE:             boardFloat(item["f9"]),
+			PB:             boardFloat(item["f23"]),
+			TotalMarketCap: boardFloat(item["f20"]),
+			TurnoverRate:   boardFloat(item["f8"]),
+		})
+	}
+	return result, nil
+}
+
+// boardFloat 解析板块接口的数值字段，缺失时返回 0
+func boardFloat(v interface{}) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,20 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryIndustryBoards(t *testing.T) {
+	results, err := _em.QueryIndustryBoards(_ctx)
+	require.Nil(t, err)
+	require.NotEmpty(t, results)
+	t.Log(results[0])
+}
+
+func TestBoardFloat(t *testing.T) {
+	require.Equal(t, 1.5, boardFloat(1.5))
+	require.Equal(t, 0.0, boardFloat("-"))
+	require.Equal(t, "90.BK0477", IndustrySecID("BK0477"))
+}
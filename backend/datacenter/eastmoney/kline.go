@@ -0,0 +1,241 @@
+// 获取股票、指数 K 线数据（OHLCV）
+
+package eastmoney
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/sirupsen/logrus"
+)
+
+// KlineType K 线周期
+type KlineType int
+
+const (
+	// KlineTypeDay 日K
+	KlineTypeDay KlineType = 101
+	// KlineTypeWeek 周K
+	KlineTypeWeek KlineType = 102
+	// KlineTypeMonth 月K
+	KlineTypeMonth KlineType = 103
+)
+
+// KlineFQ 复权类型
+type KlineFQ int
+
+const (
+	// KlineFQNone 不复权
+	KlineFQNone KlineFQ = 0
+	// KlineFQForward 前复权
+	KlineFQForward KlineFQ = 1
+	// KlineFQBackward 后复权
+	KlineFQBackward KlineFQ = 2
+)
+
+// Kline K 线数据
+type Kline struct {
+	// 日期: 2021-01-04
+	Date string `json:"date"`
+	// 开盘价
+	Open float64 `json:"open"`
+	// 收盘价
+	Close float64 `json:"close"`
+	// 最高价
+	High float64 `json:"high"`
+	// 最低价
+	Low float64 `json:"low"`
+	// 成交量（手）
+	Volume float64 `json:"volume"`
+	// 成交额（元）
+	Amount float64 `json:"amount"`
+	// 振幅（%）
+	Amplitude float64 `json:"amplitude"`
+	// 涨跌幅（%）
+	ChangePct float64 `json:"change_pct"`
+	// 涨跌额
+	Change float64 `json:"change"`
+	// 换手率（%）
+	Turnover float64 `json:"turnover"`
+}
+
+// KlineList K 线列表，按日期升序排列
+type KlineList []Kline
+
+// Closes 收盘价列表
+func (k KlineList) Closes() []float64 {
+	r := make([]float64, 0, len(k))
+	for _, i := range k {
+		r = append(r, i.Close)
+	}
+	return r
+}
+
+// Highs 最高价列表
+func (k KlineList) Highs() []float64 {
+	r := make([]float64, 0, len(k))
+	for _, i := range k {
+		r = append(r, i.High)
+	}
+	return r
+}
+
+// Lows 最低价列表
+func (k KlineList) Lows() []float64 {
+	r := make([]float64, 0, len(k))
+	for _, i := range k {
+		r = append(r, i.Low)
+	}
+	return r
+}
+
+// Opens 开盘价列表
+func (k KlineList) Opens() []float64 {
+	r := make([]float64, 0, len(k))
+	for _, i := range k {
+		r = append(r, i.Open)
+	}
+	return r
+}
+
+// Dates 日期列表
+func (k KlineList) Dates() []string {
+	r := make([]string, 0, len(k))
+	for _, i := range k {
+		r = append(r, i.Date)
+	}
+	return r
+}
+
+// Before 返回 date 之前（不含当日）的 K 线
+func (k KlineList) Before(date string) KlineList {
+	i := sort.Search(len(k), func(i int) bool { return k[i].Date >= date })
+	return k[:i]
+}
+
+// ParseKline 解析接口返回的 K 线字符串:
+// 日期,开盘,收盘,最高,最低,成交量,成交额,振幅,涨跌幅,涨跌额,换手率
+func ParseKline(line string) (Kline, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) < 11 {
+		return Kline{}, fmt.Errorf("invalid kline:%s", line)
+	}
+	values := make([]float64, 10)
+	for i, f := range fields[1:11] {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			// 停牌等情况下部分字段为 "-"
+			v = 0
+		}
+		values[i] = v
+	}
+	return Kline{
+		Date:      fields[0],
+		Open:      values[0],
+		Close:     values[1],
+		High:      values[2],
+		Low:       values[3],
+		Volume:    values[4],
+		Amount:    values[5],
+		Amplitude: values[6],
+		ChangePct: values[7],
+		Change:    values[8],
+		Turnover:  values[9],
+	}, nil
+}
+
+// SecID 将 600519.SH 或 000001.SZ 形式的代码转换为接口需要的 secid: 1.600519 / 0.000001
+func SecID(secuCode string) string {
+	secuCode = strings.ToUpper(secuCode)
+	items := strings.Split(secuCode, ".")
+	code := items[0]
+	market := ""
+	if len(items) == 2 {
+		market = items[1]
+	}
+	switch market {
+	case "SH":
+		return "1." + code
+	case "SZ", "BJ":
+		return "0." + code
+	}
+	// 没有后缀时按代码规则判断
+	if strings.HasPrefix(code, "6") || strings.HasPrefix(code, "9") || strings.HasPrefix(code, "5") {
+		return "1." + code
+	}
+	return "0." + code
+}
+
+// IndexSecID 将指数代码转换为 secid：深证指数 399xxx 为 0.，中证系列 93xxxx/Hxxxxx 为 2.，其余为 1.
+func IndexSecID(indexCode string) string {
+	code := strings.ToUpper(indexCode)
+	switch {
+	case strings.HasPrefix(code, "399"):
+		return "0." + code
+	case strings.HasPrefix(code, "93"), strings.HasPrefix(code, "H"):
+		return "2." + code
+	}
+	return "1." + code
+}
+
+// RespKline K 线接口返回结构
+type RespKline struct {
+	Rc   int `json:"rc"`
+	Rt   int `json:"rt"`
+	Data *struct {
+		Code   string   `json:"code"`
+		Market int      `json:"market"`
+		Name   string   `json:"name"`
+		Klines []string `json:"klines"`
+	} `json:"data"`
+}
+
+// QueryKline 获取 K 线数据，按日期升序返回最近 limit 条数据
+func (e EastMoney) QueryKline(ctx context.Context, secuCode string, klt KlineType, fqt KlineFQ, limit int) (KlineList, error) {
+	return e.QueryKlineBySecID(ctx, SecID(secuCode), klt, fqt, limit)
+}
+
+// QueryKlineBySecID 按 secid 获取 K 线数据，指数等需要指定市场的情况可直接传入 secid
+func (e EastMoney) QueryKlineBySecID(ctx context.Context, secid string, klt KlineType, fqt KlineFQ, limit int) (KlineList, error) {
+	apiurl := "https://push2his.eastmoney.com/api/qt/stock/kline/get"
+	params := map[string]string{
+		"secid":   secid,
+		"fields1": "f1,f2,f3,f4,f5,f6",
+		"fields2": "f51,f52,f53,f54,f55,f56,f57,f58,f59,f60,f61",
+		"klt":     fmt.Sprint(int(klt)),
+		"fqt":     fmt.Sprint(int(fqt)),
+		"end":     "20500101",
+		"lmt":     fmt.Sprint(limit),
+	}
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"params": params}).Debug("EastMoney QueryKline " + apiurl + " begin")
+	beginTime := time.Now()
+	apiurl, err := goutils.NewHTTPGetURLWithQueryString(ctx, apiurl, params)
+	if err != nil {
+		return nil, err
+	}
+	resp := RespKline{}
+	err = goutils.HTTPGET(ctx, e.HTTPClient, apiurl, nil, &resp)
+	latency := time.Now().Sub(beginTime).Milliseconds()
+	logrus.WithContext(ctx).WithFields(logrus.Fields{"latency(ms)": latency}).Debug("EastMoney QueryKline " + apiurl + " end")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Rc != 0 || resp.Data == nil {
+		return nil, fmt.Errorf("%s %#v", secid, resp)
+	}
+	result := KlineList{}
+	for _, line := range resp.Data.Klines {
+		k, err := ParseKline(line)
+		if err != nil {
+			logrus.WithContext(ctx).Warn("QueryKline ParseKline error:" + err.Error())
+			continue
+		}
+		result = append(result, k)
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package eastmoney
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryKline(t *testing.T) {
+	data, err := _em.QueryKline(_ctx, "600519.SH", KlineTypeDay, KlineFQForward, 10)
+	require.Nil(t, err)
+	require.Len(t, data, 10)
+	t.Log(data[len(data)-1])
+}
+
+func TestParseKline(t *testing.T) {
+	k, err := ParseKline("2021-01-04,1999.98,1998.00,2009.00,1960.00,58633,11743489536.00,2.45,0.01,0.10,0.47")
+	require.Nil(t, err)
+	require.Equal(t, "2021-01-04", k.Date)
+	require.Equal(t, 1998.00, k.Close)
+	require.Equal(t, 2009.00, k.High)
+	require.Equal(t, 58633.0, k.Volume)
+	_, err = ParseKline("2021-01-04,1")
+	require.NotNil(t, err)
+}
+
+func TestSecID(t *testing.T) {
+	require.Equal(t, "1.600519", SecID("600519.sh"))
+	require.Equal(t, "0.000001", SecID("000001.SZ"))
+	require.Equal(t, "1.601318", SecID("601318"))
+	require.Equal(t, "0.300750", SecID("300750"))
+}
+
+func TestIndexSecID(t *testing.T) {
+	require.Equal(t, "1.000300", IndexSecID("000300"))
+	require.Equal(t, "0.399006", IndexSecID("399006"))
+	require.Equal(t, "2.930050", IndexSecID("930050"))
+	require.Equal(t, "2.H30269", IndexSecID("h30269"))
+}
+
+func TestKlineListBefore(t *testing.T) {
+	k := KlineList{{Date: "2021-04-29"}, {Date: "2021-04-30"}, {Date: "2021-05-06"}}
+	require.Len(t, k.Before("2021-05-01"), 2)
+	require.Len(t, k.Before("2021-04-30"), 1)
+	require.Empty(t, k.Before("2021-01-01"))
+}
@@ -135,6 +135,23 @@ func (f Filter) String() string {
 	return filter
 }
 
+// UniverseString 只包含不随时间变化条件的请求参数，最新的财务和行情指标不参与筛选
+func (f Filter) UniverseString() string {
+	if len(f.SpecialSecurityNameAbbrList) > 0 || len(f.SpecialSecurityCodeList) > 0 {
+		return f.String()
+	}
+	// 接口要求至少一个条件
+	filter := `(TOTAL_MARKET_CAP>0)`
+	if len(f.IndustryList) != 0 {
+		industryIn := []string{}
+		for _, i := range f.IndustryList {
+			industryIn = append(industryIn, fmt.Sprintf(`"%s"`, i))
+		}
+		filter += fmt.Sprintf(`(INDUSTRY in (%s))`, strings.Join(industryIn, ","))
+	}
+	return filter
+}
+
 var (
 	// DefaultFilter 默认指标值
 	DefaultFilter = Filter{
@@ -226,13 +243,22 @@ func (e EastMoney) QuerySelectedStocks(ctx context.Context) (StockInfoList, erro
 
 // QuerySelectedStocksWithFilter 自定义选股指标值筛选股票
 func (e EastMoney) QuerySelectedStocksWithFilter(ctx context.Context, filter Filter) (StockInfoList, error) {
+	return e.querySelectedStocks(ctx, filter, filter.String())
+}
+
+// QueryStockUniverse 只按不随时间变化的条件（指定代码/名称、行业、板块）查询股票池，用于历史时点选股
+func (e EastMoney) QueryStockUniverse(ctx context.Context, filter Filter) (StockInfoList, error) {
+	return e.querySelectedStocks(ctx, filter, filter.UniverseString())
+}
+
+func (e EastMoney) querySelectedStocks(ctx context.Context, filter Filter, filterStr string) (StockInfoList, error) {
 	apiurl := "https://datacenter.eastmoney.com/stock/selection/api/data/get/"
 	reqData := map[string]string{
 		"source": "SELECT_SECURITIES",
 		"client": "APP",
 		"type":   "RPTA_APP_STOCKSELECT",
 		"sty":    "SECUCODE,SECURITY_CODE,SECURITY_NAME_ABBR,INDUSTRY,ROE_WEIGHT,NETPROFIT_YOY_RATIO,TOI_YOY_RATIO,ZXGXL,NETPROFIT_GROWTHRATE_3Y,INCOME_GROWTHRATE_3Y,LISTING_YIELD_YEAR,PBNEWMRQ,PREDICT_NETPROFIT_RATIO,PREDICT_INCOME_RATIO,TOTAL_MARKET_CAP,NEW_PRICE,LISTING_VOLATILITY_YEAR,LISTING_DATE,DEBT_ASSET_RATIO,JROA,PE9",
-		"filter": filter.String(),
+		"filter": filterStr,
 		"p":      "1",      // page
 		"ps":     "100000", // page size
 	}
@@ -250,7 +276,7 @@ func (e EastMoney) QuerySelectedStocksWithFilter(ctx context.Context, filter Fil
 		return nil, err
 	}
 	if resp.Code != 0 {
-		return nil, fmt.Errorf("%s %#v", filter.String(), resp)
+		return nil, fmt.Errorf("%s %#v", filterStr, resp)
 	}
 	result := StockInfoList{}
 	for _, i := range resp.Result.Data {
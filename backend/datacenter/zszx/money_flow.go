@@ -0,0 +1,137 @@
+// 资金流向分析：按单型的滚动净流入汇总、资金与股价背离检测
+
+package zszx
+
+import (
+	"strconv"
+)
+
+// DefaultMoneyFlowWindows 默认的资金流滚动统计窗口（交易日）
+var DefaultMoneyFlowWindows = []int{5, 20, 60}
+
+// MoneyFlowDivergence 资金流与股价背离类型
+type MoneyFlowDivergence string
+
+const (
+	// MoneyFlowDivergenceNone 无背离
+	MoneyFlowDivergenceNone MoneyFlowDivergence = ""
+	// MoneyFlowDivergenceBullish 底背离：股价下跌但主力资金净流入
+	MoneyFlowDivergenceBullish MoneyFlowDivergence = "bullish"
+	// MoneyFlowDivergenceBearish 顶背离：股价上涨但主力资金净流出
+	MoneyFlowDivergenceBearish MoneyFlowDivergence = "bearish"
+)
+
+// String 背离类型中文描述
+func (d MoneyFlowDivergence) String() string {
+	switch d {
+	case MoneyFlowDivergenceBullish:
+		return "底背离"
+	case MoneyFlowDivergenceBearish:
+		return "顶背离"
+	}
+	return "无"
+}
+
+// parseFloat 解析接口返回的数值字符串，非法值按 0 处理
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// ClosePrice 当日收盘价
+func (i NetInflow) ClosePrice() float64 {
+	return parseFloat(i.ClsPrc)
+}
+
+// MainNetIn 主力净流入（万元）
+func (i NetInflow) MainNetIn() float64 {
+	return parseFloat(i.MainMnyNetIn)
+}
+
+// MoneyFlowSum 一段时间内各单型资金净流入合计（万元）
+type MoneyFlowSum struct {
+	// 统计窗口（交易日）
+	Window int `json:"window"`
+	// 实际参与统计的交易日数，数据不足时小于 Window
+	Days int `json:"days"`
+	// 主力净流入
+	Main float64 `json:"main"`
+	// 超大单净流入
+	Huge float64 `json:"huge"`
+	// 大单净流入
+	Big float64 `json:"big"`
+	// 中单净流入
+	Mid float64 `json:"mid"`
+	// 小单净流入
+	Small float64 `json:"small"`
+}
+
+// RollingSum 最近 window 个交易日各单型净流入合计，列表按日期降序排列
+func (n NetInflowList) RollingSum(window int) MoneyFlowSum {
+	days := window
+	if days > len(n) {
+		days = len(n)
+	}
+	if days < 0 {
+		days = 0
+	}
+	sum := MoneyFlowSum{Window: window, Days: days}
+	for _, i := range n[:days] {
+		sum.Main += parseFloat(i.MainMnyNetIn)
+		sum.Huge += parseFloat(i.HugeNetIn)
+		sum.Big += parseFloat(i.BigNetIn)
+		sum.Mid += parseFloat(i.MidNetIn)
+		sum.Small += parseFloat(i.SmallNetIn)
+	}
+	return sum
+}
+
+// RollingSums 多个窗口的净流入合计
+func (n NetInflowList) RollingSums(windows []int) []MoneyFlowSum {
+	result := []MoneyFlowSum{}
+	for _, w := range windows {
+		result = append(result, n.RollingSum(w))
+	}
+	return result
+}
+
+// MoneyFlowDivergenceResult 资金流与股价背离检测结果
+type MoneyFlowDivergenceResult struct {
+	// 统计窗口（交易日）
+	Window int `json:"window"`
+	// 区间股价涨跌幅（%）
+	PriceChangePct float64 `json:"price_change_pct"`
+	// 区间主力净流入（万元）
+	MainNetIn float64 `json:"main_net_in"`
+	// 背离类型
+	Divergence MoneyFlowDivergence `json:"divergence"`
+	// 背离描述
+	Desc string `json:"desc"`
+}
+
+// Divergence 检测最近 window 个交易日资金流与股价的背离：
+// 区间涨跌幅绝对值不低于 minPriceChgPct 且主力资金方向与股价方向相反时认为存在背离
+func (n NetInflowList) Divergence(window int, minPriceChgPct float64) MoneyFlowDivergenceResult {
+	result := MoneyFlowDivergenceResult{Window: window}
+	// 需要区间起点前一日的收盘价作为基准
+	if window < 1 || len(n) <= window {
+		result.Desc = MoneyFlowDivergenceNone.String()
+		return result
+	}
+	latest := n[0].ClosePrice()
+	base := n[window].ClosePrice()
+	if base > 0 {
+		result.PriceChangePct = (latest - base) / base * 100
+	}
+	result.MainNetIn = n.RollingSum(window).Main
+	if result.PriceChangePct <= -minPriceChgPct && result.MainNetIn > 0 {
+		result.Divergence = MoneyFlowDivergenceBullish
+	} else if result.PriceChangePct >= minPriceChgPct && result.MainNetIn < 0 {
+		result.Divergence = MoneyFlowDivergenceBearish
+	}
+	result.Desc = result.Divergence.String()
+	return result
+}
@@ -0,0 +1,53 @@
+package zszx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetInflowListRollingSum(t *testing.T) {
+	n := NetInflowList{
+		{TrdDt: "2021-01-05", ClsPrc: "10.5", MainMnyNetIn: "100", HugeNetIn: "60", BigNetIn: "40", MidNetIn: "-30", SmallNetIn: "-70"},
+		{TrdDt: "2021-01-04", ClsPrc: "10.2", MainMnyNetIn: "-50", HugeNetIn: "-20", BigNetIn: "-30", MidNetIn: "20", SmallNetIn: "30"},
+		{TrdDt: "2021-01-03", ClsPrc: "10", MainMnyNetIn: "20", HugeNetIn: "10", BigNetIn: "10", MidNetIn: "-", SmallNetIn: "-20"},
+	}
+	s := n.RollingSum(2)
+	require.Equal(t, 2, s.Days)
+	require.Equal(t, 50.0, s.Main)
+	require.Equal(t, 40.0, s.Huge)
+	require.Equal(t, 10.0, s.Big)
+	require.Equal(t, -10.0, s.Mid)
+	require.Equal(t, -40.0, s.Small)
+
+	sums := n.RollingSums(DefaultMoneyFlowWindows)
+	require.Len(t, sums, 3)
+	require.Equal(t, 5, sums[0].Window)
+	require.Equal(t, 3, sums[0].Days)
+	require.Equal(t, 70.0, sums[0].Main)
+}
+
+func TestNetInflowListDivergence(t *testing.T) {
+	// 股价下跌但主力净流入
+	n := NetInflowList{
+		{ClsPrc: "9", MainMnyNetIn: "100"},
+		{ClsPrc: "9.5", MainMnyNetIn: "50"},
+		{ClsPrc: "10", MainMnyNetIn: "-10"},
+	}
+	d := n.Divergence(2, 5)
+	require.Equal(t, MoneyFlowDivergenceBullish, d.Divergence)
+	require.InDelta(t, -10.0, d.PriceChangePct, 1e-9)
+	require.Equal(t, 150.0, d.MainNetIn)
+
+	// 股价上涨但主力净流出
+	n = NetInflowList{
+		{ClsPrc: "11", MainMnyNetIn: "-100"},
+		{ClsPrc: "10", MainMnyNetIn: "10"},
+	}
+	d = n.Divergence(1, 5)
+	require.Equal(t, MoneyFlowDivergenceBearish, d.Divergence)
+
+	// 涨跌幅不足或数据不足时无背离
+	require.Equal(t, MoneyFlowDivergenceNone, n.Divergence(1, 20).Divergence)
+	require.Equal(t, MoneyFlowDivergenceNone, n.Divergence(2, 5).Divergence)
+}
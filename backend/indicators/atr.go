@@ -0,0 +1,37 @@
+// ATR 平均真实波幅
+
+package indicators
+
+import "math"
+
+// TrueRange 真实波幅序列，第一个值为当日最高价与最低价之差
+func TrueRange(highs, lows, closes []float64) []float64 {
+	r := make([]float64, len(closes))
+	for i := range closes {
+		tr := highs[i] - lows[i]
+		if i > 0 {
+			tr = math.Max(tr, math.Abs(highs[i]-closes[i-1]))
+			tr = math.Max(tr, math.Abs(lows[i]-closes[i-1]))
+		}
+		r[i] = tr
+	}
+	return r
+}
+
+// ATR 使用 Wilder 平滑计算 n 周期平均真实波幅，常用参数 14
+func ATR(highs, lows, closes []float64, n int) []float64 {
+	r := make([]float64, len(closes))
+	if n <= 0 || len(closes) < n || len(highs) != len(closes) || len(lows) != len(closes) {
+		return r
+	}
+	tr := TrueRange(highs, lows, closes)
+	sum := 0.0
+	for _, v := range tr[:n] {
+		sum += v
+	}
+	r[n-1] = sum / float64(n)
+	for i := n; i < len(closes); i++ {
+		r[i] = (r[i-1]*float64(n-1) + tr[i]) / float64(n)
+	}
+	return r
+}
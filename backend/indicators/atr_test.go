@@ -0,0 +1,18 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestATR(t *testing.T) {
+	highs := []float64{10, 11, 12, 13}
+	lows := []float64{9, 10, 11, 12}
+	closes := []float64{9.5, 10.5, 11.5, 12.5}
+	require.Equal(t, []float64{1, 1.5, 1.5, 1.5}, TrueRange(highs, lows, closes))
+	r := ATR(highs, lows, closes, 2)
+	require.Equal(t, 0.0, r[0])
+	require.Equal(t, 1.25, r[1])
+	require.Equal(t, 1.375, r[2])
+}
@@ -0,0 +1,25 @@
+// 布林带
+
+package indicators
+
+import "math"
+
+// Bollinger 计算 n 周期、k 倍标准差的布林带中轨、上轨、下轨，常用参数 20, 2
+func Bollinger(values []float64, n int, k float64) (mid, upper, lower []float64) {
+	mid = MA(values, n)
+	upper = make([]float64, len(values))
+	lower = make([]float64, len(values))
+	if n <= 0 {
+		return
+	}
+	for i := n - 1; i < len(values); i++ {
+		variance := 0.0
+		for _, v := range values[i-n+1 : i+1] {
+			variance += (v - mid[i]) * (v - mid[i])
+		}
+		sd := math.Sqrt(variance / float64(n))
+		upper[i] = mid[i] + k*sd
+		lower[i] = mid[i] - k*sd
+	}
+	return
+}
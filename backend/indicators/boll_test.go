@@ -0,0 +1,14 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBollinger(t *testing.T) {
+	mid, upper, lower := Bollinger([]float64{2, 4, 2, 4}, 2, 2)
+	require.Equal(t, []float64{0, 3, 3, 3}, mid)
+	require.Equal(t, 5.0, upper[3])
+	require.Equal(t, 1.0, lower[3])
+}
@@ -0,0 +1,29 @@
+// 回撤
+
+package indicators
+
+// DrawdownFromHigh 每个时点相对此前最高点的回撤百分比（<=0）
+func DrawdownFromHigh(values []float64) []float64 {
+	r := make([]float64, len(values))
+	high := 0.0
+	for i, v := range values {
+		if v > high {
+			high = v
+		}
+		if high > 0 {
+			r[i] = (v - high) / high * 100
+		}
+	}
+	return r
+}
+
+// MaxDrawdown 最大回撤百分比（>=0）
+func MaxDrawdown(values []float64) float64 {
+	maxDD := 0.0
+	for _, dd := range DrawdownFromHigh(values) {
+		if -dd > maxDD {
+			maxDD = -dd
+		}
+	}
+	return maxDD
+}
@@ -0,0 +1,13 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawdown(t *testing.T) {
+	values := []float64{1, 2, 1, 1.5, 3}
+	require.Equal(t, []float64{0, 0, -50, -25, 0}, DrawdownFromHigh(values))
+	require.Equal(t, 50.0, MaxDrawdown(values))
+}
@@ -0,0 +1,3 @@
+// Package indicators 技术指标计算
+// 输入序列均按时间升序排列，返回的序列与输入等长，数据不足以计算的位置值为 0
+package indicators
@@ -0,0 +1,44 @@
+// 移动平均线
+
+package indicators
+
+// MA 简单移动平均
+func MA(values []float64, n int) []float64 {
+	r := make([]float64, len(values))
+	if n <= 0 {
+		return r
+	}
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= n {
+			sum -= values[i-n]
+		}
+		if i >= n-1 {
+			r[i] = sum / float64(n)
+		}
+	}
+	return r
+}
+
+// EMA 指数移动平均，以第一个值作为初始值
+func EMA(values []float64, n int) []float64 {
+	r := make([]float64, len(values))
+	if n <= 0 || len(values) == 0 {
+		return r
+	}
+	alpha := 2.0 / float64(n+1)
+	r[0] = values[0]
+	for i := 1; i < len(values); i++ {
+		r[i] = alpha*values[i] + (1-alpha)*r[i-1]
+	}
+	return r
+}
+
+// Last 返回序列最后一个值，空序列返回 0
+func Last(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
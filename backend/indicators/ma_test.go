@@ -0,0 +1,18 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMA(t *testing.T) {
+	r := MA([]float64{1, 2, 3, 4, 5}, 3)
+	require.Equal(t, []float64{0, 0, 2, 3, 4}, r)
+	require.Equal(t, 4.0, Last(r))
+}
+
+func TestEMA(t *testing.T) {
+	r := EMA([]float64{1, 2, 3}, 3)
+	require.Equal(t, []float64{1, 1.5, 2.25}, r)
+}
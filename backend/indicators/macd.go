@@ -0,0 +1,19 @@
+// MACD 指标
+
+package indicators
+
+// MACD 计算 DIF、DEA 和 MACD 柱（2*(DIF-DEA)），常用参数 12, 26, 9
+func MACD(values []float64, fast, slow, signal int) (dif, dea, hist []float64) {
+	emaFast := EMA(values, fast)
+	emaSlow := EMA(values, slow)
+	dif = make([]float64, len(values))
+	for i := range values {
+		dif[i] = emaFast[i] - emaSlow[i]
+	}
+	dea = EMA(dif, signal)
+	hist = make([]float64, len(values))
+	for i := range values {
+		hist[i] = 2 * (dif[i] - dea[i])
+	}
+	return
+}
@@ -0,0 +1,20 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMACD(t *testing.T) {
+	values := []float64{}
+	for i := 0; i < 60; i++ {
+		values = append(values, float64(i))
+	}
+	dif, dea, hist := MACD(values, 12, 26, 9)
+	require.Len(t, dif, 60)
+	// 持续上涨时 DIF 在 DEA 之上
+	require.Greater(t, Last(dif), 0.0)
+	require.Greater(t, Last(dif), Last(dea))
+	require.InDelta(t, 2*(Last(dif)-Last(dea)), Last(hist), 1e-9)
+}
@@ -0,0 +1,43 @@
+// RSI 相对强弱指标
+
+package indicators
+
+// RSI 使用 Wilder 平滑计算 n 周期相对强弱指标，取值 0-100
+func RSI(values []float64, n int) []float64 {
+	r := make([]float64, len(values))
+	if n <= 0 || len(values) <= n {
+		return r
+	}
+	gain, loss := 0.0, 0.0
+	for i := 1; i <= n; i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+	avgGain, avgLoss := gain/float64(n), loss/float64(n)
+	r[n] = rsiValue(avgGain, avgLoss)
+	for i := n + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		g, l := 0.0, 0.0
+		if change > 0 {
+			g = change
+		} else {
+			l = -change
+		}
+		avgGain = (avgGain*float64(n-1) + g) / float64(n)
+		avgLoss = (avgLoss*float64(n-1) + l) / float64(n)
+		r[i] = rsiValue(avgGain, avgLoss)
+	}
+	return r
+}
+
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		return 100
+	}
+	rs := avgGain / avgLoss
+	return 100 - 100/(1+rs)
+}
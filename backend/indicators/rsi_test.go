@@ -0,0 +1,15 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSI(t *testing.T) {
+	r := RSI([]float64{1, 2, 3, 4, 5}, 3)
+	require.Equal(t, 0.0, r[2])
+	require.Equal(t, 100.0, r[3])
+	r = RSI([]float64{1, 2, 1, 2, 1}, 2)
+	require.InDelta(t, 50.0, r[2], 1e-9)
+}
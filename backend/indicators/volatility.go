@@ -0,0 +1,184 @@
+// 波动率：滚动已实现波动率、Parkinson/Garman-Klass 估计及波动率锥
+
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// TradingDaysPerYear 年化使用的年交易日数
+const TradingDaysPerYear = 252
+
+// LogReturns 对数收益率序列，长度为 len(closes)-1
+func LogReturns(closes []float64) []float64 {
+	if len(closes) < 2 {
+		return []float64{}
+	}
+	r := make([]float64, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] <= 0 || closes[i] <= 0 {
+			r = append(r, 0)
+			continue
+		}
+		r = append(r, math.Log(closes[i]/closes[i-1]))
+	}
+	return r
+}
+
+// RealizedVolatility 基于收盘价的 window 日滚动年化波动率（样本标准差 * sqrt(252)）
+// 返回序列与 closes 等长，第 i 个值使用 i 之前 window 个对数收益率
+func RealizedVolatility(closes []float64, window int) []float64 {
+	r := make([]float64, len(closes))
+	if window < 2 {
+		return r
+	}
+	rets := LogReturns(closes)
+	for i := window; i < len(closes); i++ {
+		r[i] = stddev(rets[i-window:i]) * math.Sqrt(TradingDaysPerYear)
+	}
+	return r
+}
+
+// Parkinson 基于最高价、最低价的 window 日滚动年化波动率
+func Parkinson(highs, lows []float64, window int) []float64 {
+	r := make([]float64, len(highs))
+	if window < 1 || len(lows) != len(highs) {
+		return r
+	}
+	k := 1 / (4 * math.Ln2)
+	for i := window - 1; i < len(highs); i++ {
+		sum := 0.0
+		for j := i - window + 1; j <= i; j++ {
+			if lows[j] <= 0 {
+				continue
+			}
+			hl := math.Log(highs[j] / lows[j])
+			sum += hl * hl
+		}
+		r[i] = math.Sqrt(k * sum / float64(window) * TradingDaysPerYear)
+	}
+	return r
+}
+
+// GarmanKlass 基于 OHLC 的 window 日滚动年化波动率
+func GarmanKlass(opens, highs, lows, closes []float64, window int) []float64 {
+	r := make([]float64, len(closes))
+	n := len(closes)
+	if window < 1 || len(opens) != n || len(highs) != n || len(lows) != n {
+		return r
+	}
+	for i := window - 1; i < n; i++ {
+		sum := 0.0
+		for j := i - window + 1; j <= i; j++ {
+			if lows[j] <= 0 || opens[j] <= 0 {
+				continue
+			}
+			hl := math.Log(highs[j] / lows[j])
+			co := math.Log(closes[j] / opens[j])
+			sum += 0.5*hl*hl - (2*math.Ln2-1)*co*co
+		}
+		v := sum / float64(window) * TradingDaysPerYear
+		if v > 0 {
+			r[i] = math.Sqrt(v)
+		}
+	}
+	return r
+}
+
+// VolatilityConeItem 波动率锥中单个窗口的统计
+type VolatilityConeItem struct {
+	// 窗口（交易日）
+	Window int `json:"window"`
+	// 历史最小值
+	Min float64 `json:"min"`
+	// 25 分位
+	P25 float64 `json:"p25"`
+	// 中位数
+	Median float64 `json:"median"`
+	// 75 分位
+	P75 float64 `json:"p75"`
+	// 历史最大值
+	Max float64 `json:"max"`
+	// 当前值
+	Current float64 `json:"current"`
+	// 当前值在历史中的百分位（0-100）
+	CurrentPercentile float64 `json:"current_percentile"`
+}
+
+// VolatilityCone 计算多个窗口的波动率锥，数据不足的窗口不返回
+func VolatilityCone(closes []float64, windows []int) []VolatilityConeItem {
+	cone := []VolatilityConeItem{}
+	for _, w := range windows {
+		if w < 2 || len(closes) <= w {
+			continue
+		}
+		history := RealizedVolatility(closes, w)[w:]
+		current := Last(history)
+		cone = append(cone, VolatilityConeItem{
+			Window:            w,
+			Min:               Percentile(history, 0),
+			P25:               Percentile(history, 25),
+			Median:            Percentile(history, 50),
+			P75:               Percentile(history, 75),
+			Max:               Percentile(history, 100),
+			Current:           current,
+			CurrentPercentile: PercentileRank(history, current),
+		})
+	}
+	return cone
+}
+
+// Percentile 线性插值计算 p 分位数（p 取值 0-100）
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// PercentileRank 返回 v 在 values 中的百分位（不高于 v 的值所占比例，0-100）
+func PercentileRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	count := 0
+	for _, i := range values {
+		if i <= v {
+			count++
+		}
+	}
+	return float64(count) / float64(len(values)) * 100
+}
+
+// stddev 样本标准差
+func stddev(values []float64) float64 {
+	n := len(values)
+	if n < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(n-1))
+}
@@ -0,0 +1,59 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealizedVolatility(t *testing.T) {
+	// 每日交替涨跌 1%，波动率固定
+	closes := []float64{100}
+	for i := 1; i < 30; i++ {
+		if i%2 == 0 {
+			closes = append(closes, closes[i-1]*1.01)
+		} else {
+			closes = append(closes, closes[i-1]/1.01)
+		}
+	}
+	hv := RealizedVolatility(closes, 20)
+	require.Len(t, hv, 30)
+	require.Equal(t, 0.0, hv[19])
+	expected := stddev(LogReturns(closes)[:20]) * math.Sqrt(TradingDaysPerYear)
+	require.InDelta(t, expected, hv[20], 1e-12)
+	require.InDelta(t, math.Log(1.01)*math.Sqrt(TradingDaysPerYear), hv[29], 0.01)
+}
+
+func TestParkinsonAndGarmanKlass(t *testing.T) {
+	highs := []float64{11, 11, 11}
+	lows := []float64{10, 10, 10}
+	opens := []float64{10, 10, 10}
+	closes := []float64{10, 10, 10}
+	p := Parkinson(highs, lows, 2)
+	hl := math.Log(1.1)
+	require.InDelta(t, math.Sqrt(hl*hl/(4*math.Ln2)*TradingDaysPerYear), p[2], 1e-12)
+	gk := GarmanKlass(opens, highs, lows, closes, 2)
+	require.InDelta(t, math.Sqrt(0.5*hl*hl*TradingDaysPerYear), gk[2], 1e-12)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	require.Equal(t, 1.0, Percentile(values, 0))
+	require.Equal(t, 3.0, Percentile(values, 50))
+	require.Equal(t, 2.0, Percentile(values, 25))
+	require.Equal(t, 5.0, Percentile(values, 100))
+	require.Equal(t, 60.0, PercentileRank(values, 3))
+}
+
+func TestVolatilityCone(t *testing.T) {
+	closes := []float64{}
+	for i := 0; i < 100; i++ {
+		closes = append(closes, 100+float64(i%7))
+	}
+	cone := VolatilityCone(closes, []int{20, 60, 200})
+	require.Len(t, cone, 2)
+	require.Equal(t, 20, cone[0].Window)
+	require.LessOrEqual(t, cone[0].Min, cone[0].Median)
+	require.LessOrEqual(t, cone[0].Median, cone[0].Max)
+}
@@ -16,7 +16,7 @@ import (
 
 var (
 	// ProcessorOptions 要启动运行的进程可选项
-	ProcessorOptions = []string{cmds.ProcessorChecker, cmds.ProcessorExportor, cmds.ProcessorWebserver, cmds.ProcessorIndex, cmds.ProcessorJSON}
+	ProcessorOptions = []string{cmds.ProcessorChecker, cmds.ProcessorExportor, cmds.ProcessorWebserver, cmds.ProcessorIndex, cmds.ProcessorJSON, cmds.ProcessorMarket}
 )
 
 func init() {
@@ -52,6 +52,13 @@ func main() {
 	app.Commands = append(app.Commands, cmds.CommandWebserver())
 	app.Commands = append(app.Commands, cmds.CommandIndex())
 	app.Commands = append(app.Commands, cmds.CommandJSON())
+	app.Commands = append(app.Commands, cmds.CommandMarket())
+	app.Commands = append(app.Commands, cmds.CommandBacktest())
+	app.Commands = append(app.Commands, cmds.CommandDCA())
+	app.Commands = append(app.Commands, cmds.CommandPlan())
+	app.Commands = append(app.Commands, cmds.CommandWatchlist())
+	app.Commands = append(app.Commands, cmds.CommandPreset())
+	app.Commands = append(app.Commands, cmds.CommandUser())
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Println(err.Error())
@@ -0,0 +1,92 @@
+// 认证和权限中间件
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Authenticate 认证中间件，请求携带 Authorization: Bearer <JWT 或 API token> 时校验凭证并设置当前用户
+// 未携带凭证的请求继续处理，由 RequireRole 决定是否需要登录；未开启认证时不做处理
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !auth.Settings.Enabled || header == "" {
+			c.Next()
+			return
+		}
+		user, err := auth.Authenticate(c, header)
+		if err != nil {
+			if errors.Is(err, auth.ErrUnauthorized) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"code":    http.StatusUnauthorized,
+					"message": "未登录或凭证无效",
+					"error":   err.Error(),
+				})
+				return
+			}
+			logrus.WithContext(c).Errorf("Authenticate error:%v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    http.StatusInternalServerError,
+				"message": "认证失败",
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.Set(auth.ContextUserKey, user)
+		c.Next()
+	}
+}
+
+// RequireRole 权限中间件，要求当前用户已登录且拥有指定角色的权限
+// 未开启认证时普通接口不做处理，管理员接口只允许本机访问
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.Settings.Enabled {
+			if role == models.RoleAdmin && !isLoopback(c) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"code":    http.StatusForbidden,
+					"message": "未开启认证时管理员接口只允许本机访问",
+					"error":   "role " + role + " required",
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+		user := auth.CurrentUser(c)
+		if user == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    http.StatusUnauthorized,
+				"message": "请先登录",
+				"error":   auth.ErrUnauthorized.Error(),
+			})
+			return
+		}
+		if !models.HasRole(user.Role, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "没有权限",
+				"error":   "role " + role + " required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// isLoopback 请求是否来自本机，使用连接的对端地址，不信任 X-Forwarded-For 等请求头
+func isLoopback(c *gin.Context) bool {
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		host = c.Request.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -2,20 +2,31 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/axiaoxin-com/investool/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
-// CORS 跨域中间件
+// CORS 跨域中间件，只允许 server.cors_allow_origins 中配置的来源，未配置时不允许跨域请求
+// 配置为 * 时允许全部来源但不允许携带凭证，开启认证后忽略 *
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		origins := viper.GetStringSlice("server.cors_allow_origins")
+		origin := allowOrigin(origins, c.GetHeader("Origin"), auth.Settings.Enabled)
+		if origin != "" {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			if origin != "*" {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		c.Header("Vary", "Origin")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -26,6 +37,35 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
+// allowOrigin 返回允许的跨域来源，不允许时返回空字符串，requireExplicit 为 true 时忽略 *
+func allowOrigin(origins []string, origin string, requireExplicit bool) string {
+	if origin == "" {
+		return ""
+	}
+	for _, o := range origins {
+		if o == "*" && !requireExplicit {
+			return "*"
+		}
+		if o == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+// CheckCORSConfig 检查跨域配置，开启认证时必须配置明确的来源
+func CheckCORSConfig() error {
+	if !auth.Settings.Enabled {
+		return nil
+	}
+	for _, o := range viper.GetStringSlice("server.cors_allow_origins") {
+		if o == "*" {
+			return errors.New("server.cors_allow_origins must list explicit origins when auth is enabled, * is ignored")
+		}
+	}
+	return nil
+}
+
 // Recovery 恢复中间件
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
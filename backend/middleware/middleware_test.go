@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/axiaoxin-com/investool/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowOrigin(t *testing.T) {
+	require.Equal(t, "", allowOrigin(nil, "https://a.com", false))
+	require.Equal(t, "", allowOrigin([]string{"https://a.com"}, "", false))
+	require.Equal(t, "https://a.com", allowOrigin([]string{"https://a.com"}, "https://a.com", true))
+	require.Equal(t, "", allowOrigin([]string{"https://a.com"}, "https://b.com", false))
+	require.Equal(t, "*", allowOrigin([]string{"*"}, "https://b.com", false))
+	require.Equal(t, "", allowOrigin([]string{"*"}, "https://b.com", true))
+}
+
+func TestRequireRoleAuthDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth.Settings.Enabled = false
+	r := gin.New()
+	r.GET("/admin", RequireRole(models.RoleAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/user", RequireRole(models.RoleUser), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "127.0.0.1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	// 未开启认证时管理员接口只允许本机访问，普通接口不受限制
+	require.Equal(t, http.StatusOK, do("/admin", "127.0.0.1:12345"))
+	require.Equal(t, http.StatusOK, do("/admin", "[::1]:12345"))
+	require.Equal(t, http.StatusForbidden, do("/admin", "10.0.0.1:12345"))
+	require.Equal(t, http.StatusOK, do("/user", "10.0.0.1:12345"))
+}
@@ -0,0 +1,200 @@
+// 限流中间件：按客户端限制请求频率和请求成本预算
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/investool/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrRateLimited 请求过于频繁或请求成本预算不足
+	ErrRateLimited = errors.New("rate limited")
+	// ErrCostExceedsBudget 单个请求的成本超过预算上限，无论等待多久都无法执行
+	ErrCostExceedsBudget = errors.New("request cost exceeds budget")
+)
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	// 是否开启限流
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// 每个客户端每秒允许的请求数
+	Rate float64 `mapstructure:"rate" json:"rate"`
+	// 请求数的突发上限
+	Burst int `mapstructure:"burst" json:"burst"`
+	// 每个客户端在 budget_window 内可消耗的请求成本
+	Budget int `mapstructure:"budget" json:"budget"`
+	// 成本预算的恢复周期，预算在周期内匀速恢复
+	BudgetWindow time.Duration `mapstructure:"budget_window" json:"budget_window"`
+}
+
+// RateLimitSettings 当前限流配置
+var RateLimitSettings = RateLimitConfig{Enabled: true, Rate: 2, Burst: 10, Budget: 1200, BudgetWindow: 10 * time.Minute}
+
+// InitRateLimitFromViper 从 viper 的 ratelimit 配置项初始化限流配置
+func InitRateLimitFromViper() error {
+	cfg := RateLimitSettings
+	if err := viper.UnmarshalKey("ratelimit", &cfg); err != nil {
+		return err
+	}
+	if cfg.Enabled && (cfg.Rate <= 0 || cfg.Burst <= 0 || cfg.Budget <= 0 || cfg.BudgetWindow <= 0) {
+		return errors.New("ratelimit rate, burst, budget and budget_window must be positive")
+	}
+	RateLimitSettings = cfg
+	logrus.Infof("ratelimit initialized, enabled:%v rate:%v burst:%v budget:%v/%v", cfg.Enabled, cfg.Rate, cfg.Burst, cfg.Budget, cfg.BudgetWindow)
+	return nil
+}
+
+// bucket 令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill 按经过的时间恢复令牌，新建的桶为满桶
+func (b *bucket) refill(now time.Time, capacity, rate float64) {
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.last = now
+}
+
+// wait 返回桶内令牌足够 n 个需要等待的时间
+func (b *bucket) wait(n, rate float64) time.Duration {
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / rate * float64(time.Second))
+}
+
+// rateClient 单个客户端的限流状态
+type rateClient struct {
+	requests bucket
+	budget   bucket
+}
+
+// maxRateClients 客户端数量超过该值时清理已恢复满额的客户端
+const maxRateClients = 10000
+
+// RateLimiter 按客户端限制请求频率和请求成本
+type RateLimiter struct {
+	mu      sync.Mutex
+	clients map[string]*rateClient
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{clients: map[string]*rateClient{}}
+}
+
+// Allow 检查客户端 key 能否执行成本为 cost 的请求，允许时扣减请求数和成本预算，
+// 不允许时不扣减并返回需要等待的时间
+func (l *RateLimiter) Allow(key string, cost int, cfg RateLimitConfig, now time.Time) (time.Duration, error) {
+	if cost > cfg.Budget {
+		return 0, ErrCostExceedsBudget
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.clients) >= maxRateClients {
+		l.sweep(cfg, now)
+	}
+	client, ok := l.clients[key]
+	if !ok {
+		client = &rateClient{}
+		l.clients[key] = client
+	}
+	budgetRate := float64(cfg.Budget) / cfg.BudgetWindow.Seconds()
+	client.requests.refill(now, float64(cfg.Burst), cfg.Rate)
+	client.budget.refill(now, float64(cfg.Budget), budgetRate)
+
+	wait := client.requests.wait(1, cfg.Rate)
+	if w := client.budget.wait(float64(cost), budgetRate); w > wait {
+		wait = w
+	}
+	if wait > 0 {
+		return wait, ErrRateLimited
+	}
+	client.requests.tokens--
+	client.budget.tokens -= float64(cost)
+	return 0, nil
+}
+
+// sweep 清理请求数和预算都已恢复满额的客户端，它们的状态与新客户端相同
+func (l *RateLimiter) sweep(cfg RateLimitConfig, now time.Time) {
+	budgetRate := float64(cfg.Budget) / cfg.BudgetWindow.Seconds()
+	for key, client := range l.clients {
+		client.requests.refill(now, float64(cfg.Burst), cfg.Rate)
+		client.budget.refill(now, float64(cfg.Budget), budgetRate)
+		if client.requests.tokens >= float64(cfg.Burst) && client.budget.tokens >= float64(cfg.Budget) {
+			delete(l.clients, key)
+		}
+	}
+}
+
+var defaultRateLimiter = NewRateLimiter()
+
+// rateLimitKey 限流的客户端标识：API token 按 token 限流，登录用户按用户限流，其余按 IP 限流
+func rateLimitKey(c *gin.Context) string {
+	if user := auth.CurrentUser(c); user != nil {
+		credential := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if auth.IsAPIToken(credential) {
+			return "token:" + auth.HashAPIToken(credential)
+		}
+		return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit 限流中间件，cost 计算请求成本，为 nil 时每个请求成本为 1
+// 请求过于频繁或成本预算不足时返回 429 和 Retry-After，需要在 Authenticate 之后使用
+func RateLimit(cost func(c *gin.Context) int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := RateLimitSettings
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		n := 1
+		if cost != nil {
+			n = cost(c)
+			// 成本计算中已返回错误响应，例如请求体过大
+			if c.IsAborted() {
+				return
+			}
+		}
+		key := rateLimitKey(c)
+		wait, err := defaultRateLimiter.Allow(key, n, cfg, time.Now())
+		if errors.Is(err, ErrCostExceedsBudget) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"code":    http.StatusBadRequest,
+				"message": "请求成本超过预算上限，请减少代码数量",
+				"error":   err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			logrus.WithContext(c).Warnf("RateLimit %s cost:%d retry after %ds", key, n, retryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"code":    http.StatusTooManyRequests,
+				"message": "请求过于频繁，请稍后重试",
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	cfg := RateLimitConfig{Enabled: true, Rate: 1, Burst: 2, Budget: 100, BudgetWindow: 100 * time.Second}
+	l := NewRateLimiter()
+	now := time.Now()
+
+	// 突发上限
+	_, err := l.Allow("a", 1, cfg, now)
+	require.Nil(t, err)
+	_, err = l.Allow("a", 1, cfg, now)
+	require.Nil(t, err)
+	wait, err := l.Allow("a", 1, cfg, now)
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, time.Second, wait)
+	// 其他客户端不受影响
+	_, err = l.Allow("b", 1, cfg, now)
+	require.Nil(t, err)
+
+	// 请求数恢复后受成本预算限制，被拒绝的请求不扣减预算
+	now = now.Add(2 * time.Second)
+	_, err = l.Allow("a", 90, cfg, now)
+	require.Nil(t, err)
+	wait, err = l.Allow("a", 50, cfg, now.Add(time.Second))
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, 39*time.Second, wait)
+	_, err = l.Allow("a", 10, cfg, now.Add(time.Second))
+	require.Nil(t, err)
+
+	_, err = l.Allow("a", 101, cfg, now)
+	require.ErrorIs(t, err, ErrCostExceedsBudget)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	settings := RateLimitSettings
+	defer func() { RateLimitSettings = settings }()
+	RateLimitSettings = RateLimitConfig{Enabled: true, Rate: 0.5, Burst: 1, Budget: 10, BudgetWindow: time.Minute}
+
+	engine := gin.New()
+	engine.GET("/cost/:n", RateLimit(func(c *gin.Context) int {
+		switch c.Param("n") {
+		case "big":
+			return 11
+		case "abort":
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		}
+		return 1
+	}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusOK, do("/cost/1").Code)
+	w := do("/cost/1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusBadRequest, do("/cost/big").Code)
+	// 成本计算中止请求时不再限流和执行 handler
+	require.Equal(t, http.StatusRequestEntityTooLarge, do("/cost/abort").Code)
+}
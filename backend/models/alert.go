@@ -0,0 +1,311 @@
+// 告警规则：每次同步后评估规则，条件从不成立变为成立时记录告警历史
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 告警规则类型
+const (
+	// AlertFund4433Lost 基金不再满足 4433 法则
+	AlertFund4433Lost = "fund_4433_lost"
+	// AlertFundManagerChange 基金经理变更
+	AlertFundManagerChange = "fund_manager_change"
+	// AlertFundScaleBelow 基金规模低于阈值（亿）
+	AlertFundScaleBelow = "fund_scale_below"
+	// AlertStockPriceSpace 股票合理价差穿越阈值（%）
+	AlertStockPriceSpace = "stock_price_space"
+	// AlertIndexPEBelow 指数 PE 百分位低于阈值
+	AlertIndexPEBelow = "index_pe_below"
+	// AlertStockCheckFailed 股票基本面检测开始不通过
+	AlertStockCheckFailed = "stock_check_failed"
+)
+
+// 合理价差穿越方向
+const (
+	AlertDirectionAbove = "above"
+	AlertDirectionBelow = "below"
+)
+
+// AlertTypes 全部告警规则类型
+var AlertTypes = []string{
+	AlertFund4433Lost,
+	AlertFundManagerChange,
+	AlertFundScaleBelow,
+	AlertStockPriceSpace,
+	AlertIndexPEBelow,
+	AlertStockCheckFailed,
+}
+
+// DefaultAlertIndexPEPercentile 指数 PE 百分位告警的默认阈值
+const DefaultAlertIndexPEPercentile = 20.0
+
+var (
+	// ErrInvalidAlertRule 告警规则参数错误
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound 告警规则不存在
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+)
+
+// Validate 检查告警规则参数
+func (r AlertRuleDB) Validate() error {
+	valid := false
+	for _, t := range AlertTypes {
+		if r.Type == t {
+			valid = true
+		}
+	}
+	switch {
+	case !valid:
+		return fmt.Errorf("%w: type %s", ErrInvalidAlertRule, r.Type)
+	case r.Targets == "":
+		return fmt.Errorf("%w: targets is required", ErrInvalidAlertRule)
+	case r.Type == AlertFundScaleBelow && r.Threshold <= 0:
+		return fmt.Errorf("%w: threshold must be positive", ErrInvalidAlertRule)
+	case r.Type == AlertStockPriceSpace && r.Direction != AlertDirectionAbove && r.Direction != AlertDirectionBelow:
+		return fmt.Errorf("%w: direction %s", ErrInvalidAlertRule, r.Direction)
+	case r.Type == AlertIndexPEBelow && (r.Threshold < 0 || r.Threshold > 100):
+		return fmt.Errorf("%w: threshold must be in [0, 100]", ErrInvalidAlertRule)
+	}
+	return nil
+}
+
+// AlertTargetKind 规则监控对象对应的自选列表类型，指数规则不能使用自选列表，返回空字符串
+func AlertTargetKind(ruleType string) string {
+	switch ruleType {
+	case AlertFund4433Lost, AlertFundManagerChange, AlertFundScaleBelow:
+		return WatchlistKindFund
+	case AlertStockPriceSpace, AlertStockCheckFailed:
+		return WatchlistKindStock
+	}
+	return ""
+}
+
+// alertNeedsBaseline 表示状态变化的规则首次评估只记录基线，不触发
+func alertNeedsBaseline(ruleType string) bool {
+	switch ruleType {
+	case AlertFund4433Lost, AlertFundManagerChange, AlertStockCheckFailed:
+		return true
+	}
+	return false
+}
+
+// AlertObservation 规则在一个监控对象上的本次观察结果
+type AlertObservation struct {
+	Target string
+	Name   string
+	// 告警条件是否成立
+	Active bool
+	// 观察值，基金经理变更规则比较前后两次的值
+	Value   string
+	Message string
+}
+
+// NextAlertState 根据上次状态和本次观察计算新状态，条件从不成立变为成立时返回告警
+func NextAlertState(rule AlertRuleDB, prev *AlertStateDB, obs AlertObservation, now time.Time) (AlertStateDB, *AlertDB) {
+	state := AlertStateDB{
+		RuleID:      rule.ID,
+		Target:      obs.Target,
+		Active:      obs.Active,
+		Value:       obs.Value,
+		EvaluatedAt: now,
+	}
+	if prev != nil {
+		state.ID = prev.ID
+	}
+
+	fire := false
+	switch {
+	case rule.Type == AlertFundManagerChange:
+		fire = prev != nil && prev.Value != "" && obs.Value != "" && prev.Value != obs.Value
+		if obs.Value == "" && prev != nil {
+			// 未取到经理信息时保留上次的值
+			state.Value = prev.Value
+		}
+	case prev == nil:
+		fire = obs.Active && !alertNeedsBaseline(rule.Type)
+	default:
+		fire = obs.Active && !prev.Active
+	}
+	if !fire {
+		return state, nil
+	}
+	return state, &AlertDB{
+		UserID:     rule.UserID,
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		Type:       rule.Type,
+		Target:     obs.Target,
+		TargetName: obs.Name,
+		Value:      obs.Value,
+		Message:    obs.Message,
+		CreatedAt:  now,
+	}
+}
+
+// SaveAlertRule 创建或更新告警规则
+func SaveAlertRule(ctx context.Context, rule *AlertRuleDB) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Save(rule).Error
+}
+
+// GetAlertRule 按 ID 获取用户的告警规则
+func GetAlertRule(ctx context.Context, userID, id uint) (*AlertRuleDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rule := AlertRuleDB{}
+	if err := DB.WithContext(ctx).Where("user_id = ?", userID).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrAlertRuleNotFound, id)
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListAlertRules 获取告警规则，types 不为空时只返回指定类型的启用规则
+func ListAlertRules(ctx context.Context, enabledOnly bool, types ...string) ([]AlertRuleDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	query := DB.WithContext(ctx).Model(&AlertRuleDB{})
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	rules := []AlertRuleDB{}
+	err := query.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// ListUserAlertRules 获取用户的全部告警规则
+func ListUserAlertRules(ctx context.Context, userID uint) ([]AlertRuleDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rules := []AlertRuleDB{}
+	err := DB.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+// DeleteAlertRule 删除用户的告警规则及其状态，保留告警历史
+func DeleteAlertRule(ctx context.Context, userID, id uint) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&AlertRuleDB{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %d", ErrAlertRuleNotFound, id)
+		}
+		return tx.Where("rule_id = ?", id).Delete(&AlertStateDB{}).Error
+	})
+}
+
+// LoadAlertStates 获取规则在各监控对象上的状态
+func LoadAlertStates(ctx context.Context, ruleID uint) (map[string]*AlertStateDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []AlertStateDB{}
+	if err := DB.WithContext(ctx).Where("rule_id = ?", ruleID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	states := make(map[string]*AlertStateDB, len(rows))
+	for i := range rows {
+		states[rows[i].Target] = &rows[i]
+	}
+	return states, nil
+}
+
+// SaveAlertResult 保存规则状态，有告警时同时写入告警历史
+func SaveAlertResult(ctx context.Context, state AlertStateDB, alert *AlertDB) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "rule_id"}, {Name: "target"}},
+			DoUpdates: clause.AssignmentColumns([]string{"active", "value", "evaluated_at"}),
+		}).Create(&state).Error
+		if err != nil {
+			return err
+		}
+		if alert == nil {
+			return nil
+		}
+		return tx.Create(alert).Error
+	})
+}
+
+// ParamAlertList 告警历史查询参数
+type ParamAlertList struct {
+	// 所属用户，由登录信息设置
+	UserID   uint   `json:"-"         form:"-"`
+	RuleID   uint   `json:"rule_id"   form:"rule_id"`
+	Type     string `json:"type"      form:"type"`
+	Target   string `json:"target"    form:"target"`
+	Start    string `json:"start"     form:"start"`
+	End      string `json:"end"       form:"end"`
+	PageNum  int    `json:"page_num"  form:"page_num"  binding:"min=0"`
+	PageSize int    `json:"page_size" form:"page_size" binding:"min=0,max=100"`
+}
+
+// ListAlerts 分页查询告警历史，按时间倒序
+func ListAlerts(ctx context.Context, p ParamAlertList) ([]AlertDB, int64, error) {
+	if DB == nil {
+		return nil, 0, errors.New("database not initialized")
+	}
+	query := DB.WithContext(ctx).Model(&AlertDB{}).Where("user_id = ?", p.UserID)
+	if p.RuleID > 0 {
+		query = query.Where("rule_id = ?", p.RuleID)
+	}
+	if p.Type != "" {
+		query = query.Where("type = ?", p.Type)
+	}
+	if p.Target != "" {
+		query = query.Where("target = ?", p.Target)
+	}
+	if p.Start != "" {
+		start, err := time.ParseInLocation("2006-01-02", p.Start, time.Local)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: start %s", ErrInvalidAlertRule, p.Start)
+		}
+		query = query.Where("created_at >= ?", start)
+	}
+	if p.End != "" {
+		end, err := time.ParseInLocation("2006-01-02", p.End, time.Local)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: end %s", ErrInvalidAlertRule, p.End)
+		}
+		query = query.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	alerts := []AlertDB{}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((p.PageNum - 1) * p.PageSize).
+		Limit(p.PageSize).
+		Find(&alerts).Error
+	return alerts, total, err
+}
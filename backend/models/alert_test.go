@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleValidate(t *testing.T) {
+	require.Nil(t, AlertRuleDB{Type: AlertFund4433Lost, Targets: "000001"}.Validate())
+	require.ErrorIs(t, AlertRuleDB{Type: "unknown", Targets: "000001"}.Validate(), ErrInvalidAlertRule)
+	require.ErrorIs(t, AlertRuleDB{Type: AlertFund4433Lost}.Validate(), ErrInvalidAlertRule)
+	require.ErrorIs(t, AlertRuleDB{Type: AlertFundScaleBelow, Targets: "000001"}.Validate(), ErrInvalidAlertRule)
+	require.ErrorIs(t, AlertRuleDB{Type: AlertStockPriceSpace, Targets: "600519"}.Validate(), ErrInvalidAlertRule)
+	require.ErrorIs(t, AlertRuleDB{Type: AlertIndexPEBelow, Targets: "000300", Threshold: 120}.Validate(), ErrInvalidAlertRule)
+}
+
+func TestNextAlertState(t *testing.T) {
+	now := time.Now()
+
+	// 阈值类规则首次评估条件成立即触发，之后持续成立不重复触发，恢复后再次成立重新触发
+	rule := AlertRuleDB{ID: 1, Type: AlertFundScaleBelow, Targets: "000001", Threshold: 2}
+	state, alert := NextAlertState(rule, nil, AlertObservation{Target: "000001", Active: true, Value: "1.50"}, now)
+	require.NotNil(t, alert)
+	require.Equal(t, "000001", alert.Target)
+	require.True(t, state.Active)
+	_, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Active: true, Value: "1.40"}, now)
+	require.Nil(t, alert)
+	state, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Active: false, Value: "2.50"}, now)
+	require.Nil(t, alert)
+	_, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Active: true, Value: "1.90"}, now)
+	require.NotNil(t, alert)
+
+	// 状态变化类规则首次评估只记录基线
+	rule = AlertRuleDB{ID: 2, Type: AlertFund4433Lost, Targets: "000001"}
+	state, alert = NextAlertState(rule, nil, AlertObservation{Target: "000001", Active: true}, now)
+	require.Nil(t, alert)
+	require.True(t, state.Active)
+	state, alert = NextAlertState(rule, nil, AlertObservation{Target: "000001", Active: false}, now)
+	require.Nil(t, alert)
+	_, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Active: true}, now)
+	require.NotNil(t, alert)
+
+	// 基金经理变更比较前后两次的经理
+	rule = AlertRuleDB{ID: 3, Type: AlertFundManagerChange, Targets: "000001"}
+	state, alert = NextAlertState(rule, nil, AlertObservation{Target: "000001", Value: "m1"}, now)
+	require.Nil(t, alert)
+	state, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Value: "m1"}, now)
+	require.Nil(t, alert)
+	state, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Value: ""}, now)
+	require.Nil(t, alert)
+	require.Equal(t, "m1", state.Value)
+	_, alert = NextAlertState(rule, &state, AlertObservation{Target: "000001", Value: "m2"}, now)
+	require.NotNil(t, alert)
+	require.Equal(t, "m2", alert.Value)
+}
@@ -0,0 +1,776 @@
+// 资产配置与再平衡：按目标配置和再平衡策略生成考虑交易费用的调仓计划，并基于净值协方差给出均值方差或风险平价的建议权重
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 资产类别
+const (
+	AssetEquity = "equity"
+	AssetBond   = "bond"
+	AssetCash   = "cash"
+)
+
+// 再平衡策略
+const (
+	// RebalancePolicyCalendar 距上次再平衡满一个周期时调仓
+	RebalancePolicyCalendar = "calendar"
+	// RebalancePolicyThreshold 任一目标的偏离超过阈值时调仓
+	RebalancePolicyThreshold = "threshold"
+)
+
+// 建议权重的计算方法
+const (
+	OptimizeMeanVariance = "mean_variance"
+	OptimizeRiskParity   = "risk_parity"
+)
+
+// 调仓方向
+const (
+	TradeBuy  = "buy"
+	TradeSell = "sell"
+)
+
+// ErrInvalidAllocationParams 资产配置参数错误
+var ErrInvalidAllocationParams = errors.New("invalid allocation params")
+
+// AllocationTarget 目标配置，Asset 和 Code 二选一
+type AllocationTarget struct {
+	// 资产类别：equity/bond/cash
+	Asset string `json:"asset"`
+	// 基金代码
+	Code string `json:"code"`
+	// 目标权重（%）
+	Weight float64 `json:"weight"`
+}
+
+// AllocationHolding 当前持仓
+type AllocationHolding struct {
+	// 基金代码
+	Code string `json:"code"`
+	// 持仓市值
+	Amount float64 `json:"amount"`
+	// 持有天数，用于计算赎回费
+	HoldDays int `json:"hold_days"`
+}
+
+// AllocationParams 资产配置参数
+type AllocationParams struct {
+	// 目标配置：全部按资产类别，或按基金代码（可包含 cash），按基金代码时未分配的权重为现金
+	Targets []AllocationTarget `json:"targets"`
+	// 当前持有的基金
+	Holdings []AllocationHolding `json:"holdings"`
+	// 当前现金
+	Cash float64 `json:"cash"`
+	// 按资产类别配置时可以买入的基金，当前持有的基金总是可以交易
+	Candidates []string `json:"candidates"`
+	// 再平衡策略：calendar/threshold
+	Policy string `json:"policy"`
+	// 定期再平衡周期：monthly/quarterly/annual
+	Frequency string `json:"frequency"`
+	// 上次再平衡日期，为空时视为需要再平衡
+	LastRebalance string `json:"last_rebalance"`
+	// 阈值再平衡的偏离阈值（百分点）
+	Band float64 `json:"band"`
+	// 计划日期，为空时为当天
+	Date string `json:"date"`
+	// 按基金代码配置时用建议权重替换基金的目标权重：mean_variance/risk_parity，为空时不计算
+	Optimize string `json:"optimize"`
+	// 均值方差优化的风险厌恶系数
+	RiskAversion float64 `json:"risk_aversion"`
+	// 计算协方差使用的交易日数
+	Days int `json:"days"`
+	// 赎回费率档位，为空时使用 DefaultRedemptionFees
+	RedemptionFees []RedemptionFee `json:"redemption_fees"`
+}
+
+// byAsset 是否按资产类别配置
+func (p AllocationParams) byAsset() bool {
+	for _, t := range p.Targets {
+		if t.Asset != "" && t.Asset != AssetCash {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 检查参数
+func (p AllocationParams) Validate() error {
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("%w: targets are required", ErrInvalidAllocationParams)
+	}
+	byAsset := p.byAsset()
+	sum := 0.0
+	for _, t := range p.Targets {
+		switch {
+		case t.Weight < 0:
+			return fmt.Errorf("%w: weight must not be negative", ErrInvalidAllocationParams)
+		case t.Asset != "" && t.Code != "":
+			return fmt.Errorf("%w: only one of asset and code can be set", ErrInvalidAllocationParams)
+		case t.Asset != "" && t.Asset != AssetEquity && t.Asset != AssetBond && t.Asset != AssetCash:
+			return fmt.Errorf("%w: asset %s", ErrInvalidAllocationParams, t.Asset)
+		case t.Asset == "" && t.Code == "":
+			return fmt.Errorf("%w: asset or code is required", ErrInvalidAllocationParams)
+		case byAsset && t.Code != "":
+			return fmt.Errorf("%w: asset and fund targets can not be mixed", ErrInvalidAllocationParams)
+		}
+		sum += t.Weight
+	}
+	if sum > 100+1e-6 || (byAsset && math.Abs(sum-100) > 1e-6) {
+		return fmt.Errorf("%w: target weights sum to %.2f", ErrInvalidAllocationParams, sum)
+	}
+	total := p.Cash
+	for _, h := range p.Holdings {
+		if h.Code == "" || h.Amount < 0 {
+			return fmt.Errorf("%w: invalid holding %s", ErrInvalidAllocationParams, h.Code)
+		}
+		total += h.Amount
+	}
+	if p.Cash < 0 || total <= 0 {
+		return fmt.Errorf("%w: total value must be positive", ErrInvalidAllocationParams)
+	}
+	switch p.Policy {
+	case RebalancePolicyCalendar:
+		if p.Frequency != RebalanceMonthly && p.Frequency != RebalanceQuarterly && p.Frequency != RebalanceAnnual {
+			return fmt.Errorf("%w: frequency %s", ErrInvalidAllocationParams, p.Frequency)
+		}
+	case RebalancePolicyThreshold:
+		if p.Band <= 0 {
+			return fmt.Errorf("%w: band must be positive", ErrInvalidAllocationParams)
+		}
+	default:
+		return fmt.Errorf("%w: policy %s", ErrInvalidAllocationParams, p.Policy)
+	}
+	if p.Optimize != "" {
+		if p.Optimize != OptimizeMeanVariance && p.Optimize != OptimizeRiskParity {
+			return fmt.Errorf("%w: optimize %s", ErrInvalidAllocationParams, p.Optimize)
+		}
+		if byAsset {
+			return fmt.Errorf("%w: optimize requires fund targets", ErrInvalidAllocationParams)
+		}
+	}
+	return nil
+}
+
+// AssetMix 穿透后的资产占比（%），其他资产计入现金
+type AssetMix struct {
+	Equity float64 `json:"equity"`
+	Bond   float64 `json:"bond"`
+	Cash   float64 `json:"cash"`
+}
+
+func (m AssetMix) get(asset string) float64 {
+	switch asset {
+	case AssetEquity:
+		return m.Equity
+	case AssetBond:
+		return m.Bond
+	}
+	return m.Cash
+}
+
+// assetClasses 穿透计算使用的资产类别顺序
+var assetClasses = []string{AssetEquity, AssetBond, AssetCash}
+
+// FundAssetMix 按基金最新披露的资产占比计算股债现金比例，没有披露数据时按基金类型估计
+func FundAssetMix(f *Fund) AssetMix {
+	stock := ParseFundRate(f.AssetsProportion.Stock)
+	bond := ParseFundRate(f.AssetsProportion.Bond)
+	cash := ParseFundRate(f.AssetsProportion.Cash) + ParseFundRate(f.AssetsProportion.Other)
+	if sum := stock + bond + cash; sum > 0 {
+		return AssetMix{Equity: stock / sum * 100, Bond: bond / sum * 100, Cash: cash / sum * 100}
+	}
+	switch {
+	case strings.Contains(f.Type, "货币"):
+		return AssetMix{Cash: 100}
+	case strings.Contains(f.Type, "债"):
+		return AssetMix{Bond: 100}
+	}
+	return AssetMix{Equity: 100}
+}
+
+// AllocationExposure 单个目标的当前和调仓后占比（%）
+type AllocationExposure struct {
+	// 资产类别或基金代码，现金为 cash
+	Name    string  `json:"name"`
+	Target  float64 `json:"target"`
+	Current float64 `json:"current"`
+	// 偏离（百分点）：当前 - 目标
+	Drift float64 `json:"drift"`
+	After float64 `json:"after"`
+}
+
+// AllocationTrade 一笔调仓交易
+type AllocationTrade struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// 买卖方向：buy/sell
+	Action string `json:"action"`
+	// 交易金额：买入为支付金额（含申购费），卖出为赎回的持仓市值
+	Amount float64 `json:"amount"`
+	// 申购费或赎回费
+	Fee float64 `json:"fee"`
+	// 交易后市值
+	After float64 `json:"after"`
+}
+
+// AllocationWeight 建议权重
+type AllocationWeight struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// 建议权重（%），基金部分内部的占比
+	Weight float64 `json:"weight"`
+	// 年化收益率（%）
+	Return float64 `json:"return"`
+	// 年化波动率（%）
+	Volatility float64 `json:"volatility"`
+	// 风险贡献占比（%）
+	RiskContribution float64 `json:"risk_contribution"`
+}
+
+// AllocationSuggestion 基于净值协方差的建议权重
+type AllocationSuggestion struct {
+	Method string `json:"method"`
+	// 计算使用的共同交易日数
+	Days    int                `json:"days"`
+	Weights []AllocationWeight `json:"weights"`
+	// 基金部分的预期年化收益率（%）
+	ExpectedReturn float64 `json:"expected_return"`
+	// 基金部分的预期年化波动率（%）
+	Volatility float64 `json:"volatility"`
+}
+
+// AllocationPlan 调仓计划
+type AllocationPlan struct {
+	Date string `json:"date"`
+	// 当前总资产
+	TotalValue float64 `json:"total_value"`
+	// 是否需要再平衡
+	NeedRebalance bool   `json:"need_rebalance"`
+	Reason        string `json:"reason"`
+	// 各目标的偏离情况
+	Exposures []AllocationExposure `json:"exposures"`
+	// 当前穿透后的股债现金比例
+	CurrentMix AssetMix `json:"current_mix"`
+	// 调仓后穿透后的股债现金比例
+	AfterMix AssetMix `json:"after_mix"`
+	// 需要执行的交易，先卖后买，不需要再平衡时为空
+	Trades []AllocationTrade `json:"trades"`
+	// 预计交易费用
+	Fees       float64               `json:"fees"`
+	Suggestion *AllocationSuggestion `json:"suggestion,omitempty"`
+}
+
+// allocationDue 定期再平衡是否到期
+func allocationDue(frequency, last, date string) bool {
+	if last == "" {
+		return true
+	}
+	t, err := time.Parse("2006-01-02", last)
+	if err != nil {
+		return true
+	}
+	switch frequency {
+	case RebalanceMonthly:
+		t = t.AddDate(0, 1, 0)
+	case RebalanceQuarterly:
+		t = t.AddDate(0, 3, 0)
+	case RebalanceAnnual:
+		t = t.AddDate(1, 0, 0)
+	}
+	return date >= t.Format("2006-01-02")
+}
+
+// solveAssetWeights 按资产类别配置时求各基金和现金（最后一项）的权重：
+// 最小化穿透后的资产占比与目标的偏差，并以 turnover 惩罚与当前权重 current 的差异，使交易尽量少
+func solveAssetWeights(mixes []AssetMix, target AssetMix, current []float64) []float64 {
+	n := len(mixes) + 1
+	// a[k][i] 第 i 项中资产类别 k 的占比
+	a := make([][]float64, len(assetClasses))
+	norm := 0.0
+	for k, asset := range assetClasses {
+		a[k] = make([]float64, n)
+		for i, m := range mixes {
+			a[k][i] = m.get(asset) / 100
+		}
+		if asset == AssetCash {
+			a[k][n-1] = 1
+		}
+		for i := range a[k] {
+			norm += a[k][i] * a[k][i]
+		}
+	}
+	const turnover = 0.01
+	t := []float64{target.Equity / 100, target.Bond / 100, target.Cash / 100}
+	w := append([]float64{}, current...)
+	step := 1 / (2 * (norm + turnover))
+	for iter := 0; iter < 20000; iter++ {
+		residual := make([]float64, len(a))
+		for k := range a {
+			residual[k] = -t[k]
+			for i := range w {
+				residual[k] += a[k][i] * w[i]
+			}
+		}
+		next := make([]float64, n)
+		for i := range w {
+			grad := 2 * turnover * (w[i] - current[i])
+			for k := range a {
+				grad += 2 * a[k][i] * residual[k]
+			}
+			next[i] = w[i] - step*grad
+		}
+		next = projectSimplex(next)
+		delta := 0.0
+		for i := range next {
+			delta = math.Max(delta, math.Abs(next[i]-w[i]))
+		}
+		w = next
+		if delta < 1e-12 {
+			break
+		}
+	}
+	return w
+}
+
+// covariance 年化收益率均值和协方差矩阵，series[i] 为第 i 只基金的日收益序列
+func covariance(series [][]float64) ([]float64, [][]float64) {
+	n := len(series)
+	means := make([]float64, n)
+	for i, s := range series {
+		for _, r := range s {
+			means[i] += r
+		}
+		means[i] /= float64(len(s))
+	}
+	cov := make([][]float64, n)
+	for i := range series {
+		cov[i] = make([]float64, n)
+		for j := range series {
+			for t := range series[i] {
+				cov[i][j] += (series[i][t] - means[i]) * (series[j][t] - means[j])
+			}
+			cov[i][j] = cov[i][j] / float64(len(series[i])-1) * 250
+		}
+	}
+	for i := range means {
+		means[i] *= 250
+	}
+	return means, cov
+}
+
+// mulCov 计算 cov * w
+func mulCov(cov [][]float64, w []float64) []float64 {
+	result := make([]float64, len(w))
+	for i := range cov {
+		for j := range cov[i] {
+			result[i] += cov[i][j] * w[j]
+		}
+	}
+	return result
+}
+
+// MeanVarianceWeights 在权重非负且和为 1 的约束下最大化 μ'w - λ/2 w'Σw
+func MeanVarianceWeights(means []float64, cov [][]float64, riskAversion float64) []float64 {
+	n := len(means)
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1 / float64(n)
+	}
+	trace := 0.0
+	for i := range cov {
+		trace += cov[i][i]
+	}
+	if trace <= 0 || riskAversion <= 0 {
+		return w
+	}
+	step := 1 / (riskAversion * trace)
+	for iter := 0; iter < 20000; iter++ {
+		sw := mulCov(cov, w)
+		next := make([]float64, n)
+		for i := range w {
+			next[i] = w[i] + step*(means[i]-riskAversion*sw[i])
+		}
+		next = projectSimplex(next)
+		delta := 0.0
+		for i := range next {
+			delta = math.Max(delta, math.Abs(next[i]-w[i]))
+		}
+		w = next
+		if delta < 1e-12 {
+			break
+		}
+	}
+	return w
+}
+
+// RiskParityWeights 各基金风险贡献相等的权重，从波动率倒数加权开始迭代
+func RiskParityWeights(cov [][]float64) []float64 {
+	n := len(cov)
+	w := make([]float64, n)
+	sum := 0.0
+	for i := range w {
+		if cov[i][i] > 0 {
+			w[i] = 1 / math.Sqrt(cov[i][i])
+		}
+		sum += w[i]
+	}
+	if sum == 0 {
+		for i := range w {
+			w[i] = 1 / float64(n)
+		}
+		return w
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	for iter := 0; iter < 1000; iter++ {
+		sw := mulCov(cov, w)
+		total := 0.0
+		for i := range w {
+			total += w[i] * sw[i]
+		}
+		next := make([]float64, n)
+		sum := 0.0
+		for i := range w {
+			next[i] = w[i]
+			if rc := w[i] * sw[i]; rc > 0 {
+				next[i] = w[i] * math.Sqrt(total/float64(n)/rc)
+			}
+			sum += next[i]
+		}
+		delta := 0.0
+		for i := range next {
+			next[i] /= sum
+			delta = math.Max(delta, math.Abs(next[i]-w[i]))
+		}
+		w = next
+		if delta < 1e-10 {
+			break
+		}
+	}
+	return w
+}
+
+// NewAllocationSuggestion 按基金日收益率计算建议权重，returns 与 funds 一一对应
+func NewAllocationSuggestion(method string, riskAversion float64, funds []*Fund, returns []map[string]float64) (*AllocationSuggestion, error) {
+	if len(funds) == 0 || len(funds) != len(returns) {
+		return nil, fmt.Errorf("%w: no fund for %s", ErrInvalidAllocationParams, method)
+	}
+	_, y, xs := AlignStyleReturns(returns[0], returns[1:])
+	if len(y) < 20 {
+		return nil, fmt.Errorf("%w: only %d common trading days", ErrInvalidAllocationParams, len(y))
+	}
+	means, cov := covariance(append([][]float64{y}, xs...))
+	var w []float64
+	if method == OptimizeMeanVariance {
+		if riskAversion <= 0 {
+			riskAversion = 3
+		}
+		w = MeanVarianceWeights(means, cov, riskAversion)
+	} else {
+		w = RiskParityWeights(cov)
+	}
+	sw := mulCov(cov, w)
+	variance := 0.0
+	for i := range w {
+		variance += w[i] * sw[i]
+	}
+	s := &AllocationSuggestion{Method: method, Days: len(y), Volatility: math.Sqrt(variance) * 100}
+	for i, f := range funds {
+		weight := AllocationWeight{
+			Code:       f.Code,
+			Name:       f.Name,
+			Weight:     w[i] * 100,
+			Return:     means[i] * 100,
+			Volatility: math.Sqrt(cov[i][i]) * 100,
+		}
+		if variance > 0 {
+			weight.RiskContribution = w[i] * sw[i] / variance * 100
+		}
+		s.ExpectedReturn += w[i] * means[i] * 100
+		s.Weights = append(s.Weights, weight)
+	}
+	return s, nil
+}
+
+// PlanAllocation 生成调仓计划，funds 包含目标、持仓和候选中的全部基金，
+// suggestion 不为空时用其权重按比例替换基金的目标权重。
+// 目标市值按扣除预计交易费用后的总资产计算，买入按 Fund.Rate 外扣申购费，卖出按持有天数收取赎回费
+func PlanAllocation(p AllocationParams, funds map[string]*Fund, suggestion *AllocationSuggestion) (*AllocationPlan, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	date := p.Date
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	fees := p.RedemptionFees
+	if len(fees) == 0 {
+		fees = DefaultRedemptionFees
+	}
+
+	// 可交易的基金：目标、持仓、候选，现金单独计算
+	codes := []string{}
+	seen := map[string]bool{}
+	addCode := func(code string) {
+		if code != "" && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	for _, t := range p.Targets {
+		addCode(t.Code)
+	}
+	for _, h := range p.Holdings {
+		addCode(h.Code)
+	}
+	if p.byAsset() {
+		for _, code := range p.Candidates {
+			addCode(code)
+		}
+	}
+	for _, code := range codes {
+		if funds[code] == nil {
+			return nil, fmt.Errorf("%w: fund %s not found", ErrInvalidAllocationParams, code)
+		}
+	}
+
+	holdings := map[string]AllocationHolding{}
+	current := make([]float64, len(codes)+1)
+	total := p.Cash
+	for _, h := range p.Holdings {
+		total += h.Amount
+		// 同一基金的多笔持仓合并，赎回费按最短的持有天数估计
+		if old, ok := holdings[h.Code]; ok {
+			h.Amount += old.Amount
+			if old.HoldDays < h.HoldDays {
+				h.HoldDays = old.HoldDays
+			}
+		}
+		holdings[h.Code] = h
+	}
+	for i, code := range codes {
+		current[i] = holdings[code].Amount / total
+	}
+	current[len(codes)] = p.Cash / total
+
+	mixes := make([]AssetMix, len(codes))
+	for i, code := range codes {
+		mixes[i] = FundAssetMix(funds[code])
+	}
+	lookThrough := func(w []float64) AssetMix {
+		m := AssetMix{Cash: w[len(codes)] * 100}
+		for i := range codes {
+			m.Equity += w[i] * mixes[i].Equity
+			m.Bond += w[i] * mixes[i].Bond
+			m.Cash += w[i] * mixes[i].Cash
+		}
+		return m
+	}
+
+	// 目标权重
+	target := make([]float64, len(codes)+1)
+	plan := &AllocationPlan{Date: date, TotalValue: total, CurrentMix: lookThrough(current), Suggestion: suggestion}
+	if p.byAsset() {
+		targetMix := AssetMix{}
+		for _, t := range p.Targets {
+			switch t.Asset {
+			case AssetEquity:
+				targetMix.Equity += t.Weight
+			case AssetBond:
+				targetMix.Bond += t.Weight
+			default:
+				targetMix.Cash += t.Weight
+			}
+		}
+		target = solveAssetWeights(mixes, targetMix, current)
+		for _, asset := range assetClasses {
+			plan.Exposures = append(plan.Exposures, AllocationExposure{
+				Name:    asset,
+				Target:  targetMix.get(asset),
+				Current: plan.CurrentMix.get(asset),
+			})
+		}
+	} else {
+		index := map[string]int{}
+		for i, code := range codes {
+			index[code] = i
+		}
+		fundWeight := 0.0
+		for _, t := range p.Targets {
+			if t.Code != "" {
+				target[index[t.Code]] += t.Weight / 100
+				fundWeight += t.Weight / 100
+			}
+		}
+		if suggestion != nil {
+			for i := range codes {
+				target[i] = 0
+			}
+			for _, w := range suggestion.Weights {
+				if i, ok := index[w.Code]; ok {
+					target[i] = w.Weight / 100 * fundWeight
+				}
+			}
+		}
+		target[len(codes)] = 1 - fundWeight
+		for i, code := range codes {
+			if target[i] > 0 || current[i] > 0 {
+				plan.Exposures = append(plan.Exposures, AllocationExposure{Name: code, Target: target[i] * 100, Current: current[i] * 100})
+			}
+		}
+		plan.Exposures = append(plan.Exposures, AllocationExposure{Name: AssetCash, Target: target[len(codes)] * 100, Current: current[len(codes)] * 100})
+	}
+
+	maxDrift := 0.0
+	for i := range plan.Exposures {
+		e := &plan.Exposures[i]
+		e.Drift = e.Current - e.Target
+		e.After = e.Current
+		maxDrift = math.Max(maxDrift, math.Abs(e.Drift))
+	}
+	plan.AfterMix = plan.CurrentMix
+	if p.Policy == RebalancePolicyCalendar {
+		plan.NeedRebalance = allocationDue(p.Frequency, p.LastRebalance, date)
+		if p.LastRebalance == "" {
+			plan.Reason = "没有再平衡记录"
+		} else if plan.NeedRebalance {
+			plan.Reason = fmt.Sprintf("上次再平衡 %s，已满 %s 周期", p.LastRebalance, p.Frequency)
+		} else {
+			plan.Reason = fmt.Sprintf("上次再平衡 %s，未满 %s 周期", p.LastRebalance, p.Frequency)
+		}
+	} else {
+		plan.NeedRebalance = maxDrift > p.Band
+		plan.Reason = fmt.Sprintf("最大偏离 %.2f 个百分点，阈值 %.2f", maxDrift, p.Band)
+	}
+	plan.Trades = []AllocationTrade{}
+	if !plan.NeedRebalance {
+		return plan, nil
+	}
+
+	// 交易费用会减少可分配的总资产，迭代到费用稳定
+	var trades []AllocationTrade
+	after := make([]float64, len(codes)+1)
+	for iter := 0; iter < 10; iter++ {
+		net := total - plan.Fees
+		trades = []AllocationTrade{}
+		sells, buys := []AllocationTrade{}, []AllocationTrade{}
+		fee := 0.0
+		for i, code := range codes {
+			f := funds[code]
+			cur := holdings[code].Amount
+			want := target[i] * net
+			diff := want - cur
+			// 忽略不足 1 元的调整
+			if math.Abs(diff) < 1 {
+				after[i] = cur
+				continue
+			}
+			after[i] = want
+			if diff < 0 {
+				rate := redemptionRate(fees, holdings[code].HoldDays)
+				sells = append(sells, AllocationTrade{Code: code, Name: f.Name, Action: TradeSell, Amount: -diff, Fee: -diff * rate, After: want})
+				fee += -diff * rate
+			} else {
+				rate := ParseFundRate(f.Rate) / 100
+				buys = append(buys, AllocationTrade{Code: code, Name: f.Name, Action: TradeBuy, Amount: diff * (1 + rate), Fee: diff * rate, After: want})
+				fee += diff * rate
+			}
+		}
+		trades = append(sells, buys...)
+		converged := math.Abs(fee-plan.Fees) < 0.01
+		plan.Fees = fee
+		if converged {
+			break
+		}
+	}
+	fundsAfter := 0.0
+	for i := range codes {
+		fundsAfter += after[i]
+	}
+	after[len(codes)] = total - plan.Fees - fundsAfter
+	plan.Trades = trades
+	for i := range after {
+		after[i] /= total - plan.Fees
+	}
+	plan.AfterMix = lookThrough(after)
+	for i := range plan.Exposures {
+		e := &plan.Exposures[i]
+		if p.byAsset() {
+			e.After = plan.AfterMix.get(e.Name)
+		} else if e.Name == AssetCash {
+			e.After = after[len(codes)] * 100
+		} else {
+			for j, code := range codes {
+				if code == e.Name {
+					e.After = after[j] * 100
+				}
+			}
+		}
+	}
+	return plan, nil
+}
+
+// BuildAllocationPlan 加载基金信息和净值后生成调仓计划
+func BuildAllocationPlan(ctx context.Context, p AllocationParams) (*AllocationPlan, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	codes := []string{}
+	for _, t := range p.Targets {
+		if t.Code != "" {
+			codes = append(codes, t.Code)
+		}
+	}
+	for _, h := range p.Holdings {
+		codes = append(codes, h.Code)
+	}
+	codes = append(codes, p.Candidates...)
+	pool, err := backtestFundPool(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	funds := map[string]*Fund{}
+	for _, f := range pool {
+		funds[f.Code] = f
+	}
+
+	var suggestion *AllocationSuggestion
+	if p.Optimize != "" {
+		days := p.Days
+		if days <= 0 {
+			days = tradingDaysYear3
+		}
+		targetFunds := []*Fund{}
+		returns := []map[string]float64{}
+		for _, t := range p.Targets {
+			f := funds[t.Code]
+			if t.Code == "" || f == nil {
+				continue
+			}
+			navs, err := GetFundNavs(ctx, t.Code, days)
+			if err != nil {
+				return nil, err
+			}
+			targetFunds = append(targetFunds, f)
+			returns = append(returns, navs.DailyReturns())
+		}
+		if suggestion, err = NewAllocationSuggestion(p.Optimize, p.RiskAversion, targetFunds, returns); err != nil {
+			return nil, err
+		}
+		sort.Slice(suggestion.Weights, func(i, j int) bool { return suggestion.Weights[i].Weight > suggestion.Weights[j].Weight })
+		logrus.WithContext(ctx).Debugf("BuildAllocationPlan suggestion:%+v", suggestion)
+	}
+	return PlanAllocation(p, funds, suggestion)
+}
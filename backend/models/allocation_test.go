@@ -0,0 +1,139 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func allocationFunds() map[string]*Fund {
+	return map[string]*Fund{
+		"000001": {Code: "000001", Name: "股票基金", Type: "股票型", Rate: "0.15%", AssetsProportion: fundAssetsProportion{Stock: "90%", Bond: "0%", Cash: "8%", Other: "2%"}},
+		"000002": {Code: "000002", Name: "债券基金", Type: "债券型", Rate: "0.08%", AssetsProportion: fundAssetsProportion{Stock: "10%", Bond: "85%", Cash: "5%", Other: "--%"}},
+		"000003": {Code: "000003", Name: "纯债基金", Type: "债券型-纯债", Rate: "0%"},
+	}
+}
+
+func TestFundAssetMix(t *testing.T) {
+	funds := allocationFunds()
+	m := FundAssetMix(funds["000001"])
+	require.InDelta(t, 90, m.Equity, 1e-9)
+	require.InDelta(t, 10, m.Cash, 1e-9)
+	require.Equal(t, AssetMix{Bond: 100}, FundAssetMix(funds["000003"]))
+}
+
+func TestPlanAllocationByFund(t *testing.T) {
+	funds := allocationFunds()
+	p := AllocationParams{
+		Targets:  []AllocationTarget{{Code: "000001", Weight: 60}, {Code: "000002", Weight: 30}},
+		Holdings: []AllocationHolding{{Code: "000001", Amount: 80000, HoldDays: 400}, {Code: "000003", Amount: 10000, HoldDays: 30}},
+		Cash:     10000,
+		Policy:   RebalancePolicyThreshold,
+		Band:     5,
+	}
+	plan, err := PlanAllocation(p, funds, nil)
+	require.Nil(t, err)
+	require.True(t, plan.NeedRebalance)
+	require.Equal(t, 100000.0, plan.TotalValue)
+	require.Len(t, plan.Trades, 3)
+	// 先卖后买
+	require.Equal(t, "sell", plan.Trades[0].Action)
+	require.Equal(t, "000001", plan.Trades[0].Code)
+	require.Equal(t, "sell", plan.Trades[1].Action)
+	require.Equal(t, "000003", plan.Trades[1].Code)
+	require.InDelta(t, 10000*0.005, plan.Trades[1].Fee, 1e-6)
+	require.Equal(t, "buy", plan.Trades[2].Action)
+	fees := 0.0
+	for _, trade := range plan.Trades {
+		fees += trade.Fee
+	}
+	require.InDelta(t, fees, plan.Fees, 1e-9)
+	net := plan.TotalValue - plan.Fees
+	require.InDelta(t, net*0.6, plan.Trades[0].After, 0.01)
+	require.InDelta(t, net*0.3, plan.Trades[2].After, 0.01)
+	for _, e := range plan.Exposures {
+		if e.Name == AssetCash {
+			require.InDelta(t, 10, e.After, 1e-6)
+		}
+	}
+
+	// 偏离没有超过阈值时不调仓
+	p.Holdings = []AllocationHolding{{Code: "000001", Amount: 61000}, {Code: "000002", Amount: 29000}}
+	plan, err = PlanAllocation(p, funds, nil)
+	require.Nil(t, err)
+	require.False(t, plan.NeedRebalance)
+	require.Empty(t, plan.Trades)
+
+	// 定期再平衡
+	p.Policy = RebalancePolicyCalendar
+	p.Frequency = RebalanceQuarterly
+	p.LastRebalance = "2021-01-15"
+	p.Date = "2021-04-14"
+	plan, err = PlanAllocation(p, funds, nil)
+	require.Nil(t, err)
+	require.False(t, plan.NeedRebalance)
+	p.Date = "2021-04-15"
+	plan, err = PlanAllocation(p, funds, nil)
+	require.Nil(t, err)
+	require.True(t, plan.NeedRebalance)
+
+	p.Targets = append(p.Targets, AllocationTarget{Code: "000003", Weight: 20})
+	_, err = PlanAllocation(p, funds, nil)
+	require.ErrorIs(t, err, ErrInvalidAllocationParams)
+}
+
+func TestPlanAllocationByAsset(t *testing.T) {
+	funds := allocationFunds()
+	p := AllocationParams{
+		Targets:    []AllocationTarget{{Asset: AssetEquity, Weight: 60}, {Asset: AssetBond, Weight: 30}, {Asset: AssetCash, Weight: 10}},
+		Holdings:   []AllocationHolding{{Code: "000001", Amount: 100000, HoldDays: 800}},
+		Candidates: []string{"000003"},
+		Policy:     RebalancePolicyThreshold,
+		Band:       5,
+	}
+	plan, err := PlanAllocation(p, funds, nil)
+	require.Nil(t, err)
+	require.True(t, plan.NeedRebalance)
+	require.InDelta(t, 90, plan.CurrentMix.Equity, 1e-9)
+	require.InDelta(t, 60, plan.AfterMix.Equity, 0.5)
+	require.InDelta(t, 30, plan.AfterMix.Bond, 0.5)
+	require.InDelta(t, 10, plan.AfterMix.Cash, 0.5)
+	// 股票基金自带现金，调仓后不需要额外持有现金
+	for _, trade := range plan.Trades {
+		if trade.Code == "000003" {
+			require.Equal(t, "buy", trade.Action)
+			require.InDelta(t, 30000, trade.After, 500)
+		}
+	}
+
+	p.Optimize = OptimizeRiskParity
+	_, err = PlanAllocation(p, funds, nil)
+	require.ErrorIs(t, err, ErrInvalidAllocationParams)
+}
+
+func TestAllocationWeights(t *testing.T) {
+	// 两只不相关的基金，波动率之比 1:2
+	cov := [][]float64{{0.01, 0}, {0, 0.04}}
+	w := RiskParityWeights(cov)
+	require.InDelta(t, 2.0/3, w[0], 1e-6)
+	require.InDelta(t, 1.0/3, w[1], 1e-6)
+
+	// 无约束最优解 w = Σ⁻¹μ/λ = (0.5, 0.5)
+	w = MeanVarianceWeights([]float64{0.015, 0.06}, cov, 3)
+	require.InDelta(t, 0.5, w[0], 1e-6)
+	require.InDelta(t, 0.5, w[1], 1e-6)
+
+	funds := []*Fund{{Code: "000001"}, {Code: "000002"}}
+	returns := []map[string]float64{{}, {}}
+	for i := 0; i < 100; i++ {
+		date := "2021-" + string(rune('A'+i/26)) + string(rune('a'+i%26))
+		returns[0][date] = 0.01 * math.Sin(float64(i))
+		returns[1][date] = 0.02 * math.Cos(float64(i))
+	}
+	s, err := NewAllocationSuggestion(OptimizeRiskParity, 0, funds, returns)
+	require.Nil(t, err)
+	require.Equal(t, 100, s.Days)
+	require.InDelta(t, 50, s.Weights[0].RiskContribution, 1)
+	require.InDelta(t, 100, s.Weights[0].Weight+s.Weights[1].Weight, 1e-6)
+}
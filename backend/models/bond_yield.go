@@ -0,0 +1,196 @@
+// 债券收益率曲线存储、查询及无风险利率
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/chinabond"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRiskFreeRate 没有国债收益率数据时使用的无风险利率（%）
+	DefaultRiskFreeRate = 2.5
+	// RiskFreeRateTenor 无风险利率使用的国债期限（年）
+	RiskFreeRateTenor = 10.0
+)
+
+// ErrBondYieldNotFound 收益率数据不存在
+var ErrBondYieldNotFound = errors.New("bond yield not found")
+
+// BondYieldPoint 收益率时间序列中的一个点
+type BondYieldPoint struct {
+	Date  string  `json:"date"`
+	Yield float64 `json:"yield"`
+}
+
+// BondYieldSpreadPoint 两条曲线同期限收益率利差
+type BondYieldSpreadPoint struct {
+	Date      string  `json:"date"`
+	Yield     float64 `json:"yield"`
+	BaseYield float64 `json:"base_yield"`
+	// 利差（BP）
+	Spread float64 `json:"spread"`
+}
+
+// SaveBondYieldCurve 保存收益率曲线，覆盖同一曲线同一日期的旧数据
+func SaveBondYieldCurve(ctx context.Context, curve chinabond.YieldCurve) error {
+	if DB == nil || len(curve.Points) == 0 {
+		return nil
+	}
+	if err := DB.Where("curve_name = ? AND date = ?", curve.Name, curve.Date).Delete(&BondYieldDB{}).Error; err != nil {
+		return err
+	}
+	return DB.CreateInBatches(ToBondYieldsDB(curve), 500).Error
+}
+
+// LoadBondYieldCurve 从数据库加载指定曲线在 date 当日或之前最近一个交易日的收益率曲线，date 为空时返回最新曲线
+func LoadBondYieldCurve(ctx context.Context, name, date string) (chinabond.YieldCurve, error) {
+	curve := chinabond.YieldCurve{Name: name, Points: []chinabond.YieldPoint{}}
+	if DB == nil {
+		return curve, errors.New("database not initialized")
+	}
+	latest := BondYieldDB{}
+	query := DB.Where("curve_name = ?", name)
+	if date != "" {
+		query = query.Where("date <= ?", date)
+	}
+	if err := query.Order("date DESC").Limit(1).Find(&latest).Error; err != nil {
+		return curve, err
+	}
+	if latest.ID == 0 {
+		return curve, ErrBondYieldNotFound
+	}
+	rows := []BondYieldDB{}
+	if err := DB.Where("curve_name = ? AND date = ?", name, latest.Date).Order("tenor").Find(&rows).Error; err != nil {
+		return curve, err
+	}
+	curve.ID = latest.CurveID
+	curve.Date = latest.Date
+	for _, r := range rows {
+		curve.Points = append(curve.Points, chinabond.YieldPoint{Tenor: r.Tenor, Yield: r.Yield})
+	}
+	return curve, nil
+}
+
+// GetBondYieldCurve 获取指定日期的收益率曲线，数据库中没有当日数据时从中债接口获取并保存
+func GetBondYieldCurve(ctx context.Context, name, date string) (chinabond.YieldCurve, error) {
+	curve, err := LoadBondYieldCurve(ctx, name, date)
+	if err == nil && (date == "" || curve.Date == date) {
+		return curve, nil
+	}
+	if date == "" {
+		date = goutils.GetLatestTradingDay()
+	}
+	curves, qerr := datacenter.ChinaBond.QueryCurveList(ctx)
+	if qerr != nil {
+		logrus.WithContext(ctx).Error("GetBondYieldCurve QueryCurveList err:" + qerr.Error())
+		return curve, err
+	}
+	id, ok := curves[name]
+	if !ok {
+		return curve, fmt.Errorf("债券曲线不存在:%s", name)
+	}
+	remote, qerr := datacenter.ChinaBond.QueryYieldCurve(ctx, id, date)
+	if qerr != nil || len(remote.Points) == 0 {
+		if qerr != nil {
+			logrus.WithContext(ctx).Error("GetBondYieldCurve QueryYieldCurve err:" + qerr.Error())
+		}
+		// 接口无当日数据时退回数据库中最近的曲线
+		return curve, err
+	}
+	if remote.Name == "" {
+		remote.Name = name
+	}
+	if err := SaveBondYieldCurve(ctx, remote); err != nil {
+		logrus.WithContext(ctx).Error("GetBondYieldCurve SaveBondYieldCurve err:" + err.Error())
+	}
+	return remote, nil
+}
+
+// LoadBondYieldSeries 加载指定曲线某期限在日期区间内的收益率序列，期限不在曲线节点上时线性插值
+func LoadBondYieldSeries(ctx context.Context, name string, tenor float64, start, end string) ([]BondYieldPoint, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []BondYieldDB{}
+	query := DB.Where("curve_name = ?", name)
+	if start != "" {
+		query = query.Where("date >= ?", start)
+	}
+	if end != "" {
+		query = query.Where("date <= ?", end)
+	}
+	if err := query.Order("date, tenor").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	curves := map[string]*chinabond.YieldCurve{}
+	dates := []string{}
+	for _, r := range rows {
+		c, ok := curves[r.Date]
+		if !ok {
+			c = &chinabond.YieldCurve{Name: name, Date: r.Date}
+			curves[r.Date] = c
+			dates = append(dates, r.Date)
+		}
+		c.Points = append(c.Points, chinabond.YieldPoint{Tenor: r.Tenor, Yield: r.Yield})
+	}
+	sort.Strings(dates)
+	result := []BondYieldPoint{}
+	for _, d := range dates {
+		if y, ok := curves[d].YieldAt(tenor); ok {
+			result = append(result, BondYieldPoint{Date: d, Yield: y})
+		}
+	}
+	return result, nil
+}
+
+// BondYieldSpread 计算曲线 name 相对基准曲线 baseName 在指定期限上的利差序列，只保留两条曲线都有数据的日期
+func BondYieldSpread(ctx context.Context, name, baseName string, tenor float64, start, end string) ([]BondYieldSpreadPoint, error) {
+	series, err := LoadBondYieldSeries(ctx, name, tenor, start, end)
+	if err != nil {
+		return nil, err
+	}
+	baseSeries, err := LoadBondYieldSeries(ctx, baseName, tenor, start, end)
+	if err != nil {
+		return nil, err
+	}
+	baseMap := map[string]float64{}
+	for _, p := range baseSeries {
+		baseMap[p.Date] = p.Yield
+	}
+	result := []BondYieldSpreadPoint{}
+	for _, p := range series {
+		base, ok := baseMap[p.Date]
+		if !ok {
+			continue
+		}
+		result = append(result, BondYieldSpreadPoint{
+			Date:      p.Date,
+			Yield:     p.Yield,
+			BaseYield: base,
+			Spread:    (p.Yield - base) * 100,
+		})
+	}
+	return result, nil
+}
+
+// RiskFreeRate 返回无风险利率（%）：优先使用同步的10年期国债收益率，其次使用数据库中最新的国债曲线，都没有时使用默认值
+func RiskFreeRate(ctx context.Context) float64 {
+	if y := TreasuryYield10Y(); y > 0 {
+		return y
+	}
+	if curve, err := LoadBondYieldCurve(ctx, chinabond.CurveTreasury, ""); err == nil {
+		if y, ok := curve.YieldAt(RiskFreeRateTenor); ok && y > 0 {
+			SetTreasuryYield10Y(y)
+			return y
+		}
+	}
+	return DefaultRiskFreeRate
+}
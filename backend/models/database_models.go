@@ -3,9 +3,12 @@ package models
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 
+	"github.com/axiaoxin-com/investool/datacenter/chinabond"
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/datacenter/zszx"
 	"gorm.io/gorm"
 )
 
@@ -48,7 +51,8 @@ func (FundDB) TableName() string {
 // FundStockDB 基金持仓股票数据库模型
 type FundStockDB struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	FundCode    string    `gorm:"column:fund_code;index" json:"fund_code"`
+	FundCode    string    `gorm:"column:fund_code;index;index:idx_fund_stock_report" json:"fund_code"`
+	ReportDate  string    `gorm:"column:report_date;index:idx_fund_stock_report" json:"report_date"`
 	StockCode   string    `gorm:"column:stock_code" json:"stock_code"`
 	StockName   string    `gorm:"column:stock_name" json:"stock_name"`
 	Industry    string    `gorm:"column:industry" json:"industry"`
@@ -92,6 +96,32 @@ func (f *FundDB) UpdateSyncTime(db *gorm.DB) error {
 
 // ToFund 将 FundDB 转换为 Fund
 func (f *FundDB) ToFund() *Fund {
+	reportDate := ""
+	if DB != nil {
+		reportDate = LatestFundStocksReportDate(DB, f.Code)
+	}
+	return f.toFund(reportDate)
+}
+
+// ToFunds 批量将 FundDB 转换为 Fund，各基金持仓的最新报告期通过一次分组查询获取
+func ToFunds(fundDBs []FundDB) []*Fund {
+	reportDates := map[string]string{}
+	if DB != nil && len(fundDBs) > 0 {
+		codes := make([]string, 0, len(fundDBs))
+		for _, f := range fundDBs {
+			codes = append(codes, f.Code)
+		}
+		reportDates = LatestFundStocksReportDates(DB, codes)
+	}
+	funds := make([]*Fund, len(fundDBs))
+	for i := range fundDBs {
+		funds[i] = fundDBs[i].toFund(reportDates[fundDBs[i].Code])
+	}
+	return funds
+}
+
+// toFund 将 FundDB 转换为 Fund，reportDate 为持仓的最新报告期
+func (f *FundDB) toFund(reportDate string) *Fund {
 	fund := &Fund{
 		Code:                  f.Code,
 		Name:                  f.Name,
@@ -123,8 +153,14 @@ func (f *FundDB) ToFund() *Fund {
 
 	// 加载持仓股票（如果数据库已初始化）
 	if DB != nil {
+		// 只加载最新报告期的持仓
 		var stocks []FundStockDB
-		DB.Where("fund_code = ?", f.Code).Find(&stocks)
+		if reportDate != "" {
+			DB.Where("fund_code = ? AND report_date = ?", f.Code, reportDate).Find(&stocks)
+		} else {
+			DB.Where("fund_code = ?", f.Code).Find(&stocks)
+		}
+		fund.StocksReportDate = reportDate
 		fund.Stocks = make([]fundStock, len(stocks))
 		for i, s := range stocks {
 			fund.Stocks[i] = fundStock{
@@ -149,6 +185,19 @@ func (f *FundDB) ToFund() *Fund {
 				YearsAvgRepay: manager.YearsAvgRepay,
 			}
 		}
+
+		// 加载最新的资产占比
+		var assets FundAssetsProportionDB
+		if err := DB.Where("fund_code = ?", f.Code).Order("pub_date DESC").First(&assets).Error; err == nil {
+			fund.AssetsProportion = fundAssetsProportion{
+				PubDate:   assets.PubDate,
+				Stock:     assets.Stock,
+				Bond:      assets.Bond,
+				Cash:      assets.Cash,
+				Other:     assets.Other,
+				NetAssets: assets.NetAssets,
+			}
+		}
 	}
 
 	return fund
@@ -198,6 +247,7 @@ func (f *Fund) ToFundStocks() []FundStockDB {
 	for _, stock := range f.Stocks {
 		stocks = append(stocks, FundStockDB{
 			FundCode:    f.Code,
+			ReportDate:  f.StocksReportDate,
 			StockCode:   stock.Code,
 			StockName:   stock.Name,
 			Industry:    stock.Industry,
@@ -412,3 +462,673 @@ func (f *Fund) ToFundIndustryProportions() []FundIndustryProportionDB {
 	}
 	return proportions
 }
+
+// StockDividendDB 股票分红数据库模型
+type StockDividendDB struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SecurityCode   string    `gorm:"column:security_code;index;uniqueIndex:idx_stock_div" json:"security_code"`
+	ReportDate     string    `gorm:"column:report_date;uniqueIndex:idx_stock_div" json:"report_date"`
+	PlanNoticeDate string    `gorm:"column:plan_notice_date" json:"plan_notice_date"`
+	ExDividendDate string    `gorm:"column:ex_dividend_date" json:"ex_dividend_date"`
+	PretaxBonusRMB float64   `gorm:"column:pretax_bonus_rmb" json:"pretax_bonus_rmb"`
+	BonusItRatio   float64   `gorm:"column:bonus_it_ratio" json:"bonus_it_ratio"`
+	AssignProgress string    `gorm:"column:assign_progress" json:"assign_progress"`
+	BasicEps       float64   `gorm:"column:basic_eps" json:"basic_eps"`
+	DividentRatio  float64   `gorm:"column:divident_ratio" json:"divident_ratio"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StockDividendDB) TableName() string {
+	return "stock_dividends"
+}
+
+// ToStockDividendsDB 将分红记录转换为 StockDividendDB 列表
+func ToStockDividendsDB(dividends eastmoney.StockDividendList) []StockDividendDB {
+	result := make([]StockDividendDB, 0, len(dividends))
+	for _, d := range dividends {
+		result = append(result, StockDividendDB{
+			SecurityCode:   d.SecurityCode,
+			ReportDate:     d.ReportDate,
+			PlanNoticeDate: d.PlanNoticeDate,
+			ExDividendDate: d.ExDividendDate,
+			PretaxBonusRMB: d.PretaxBonusRMB,
+			BonusItRatio:   d.BonusItRatio,
+			AssignProgress: d.AssignProgress,
+			BasicEps:       d.BasicEps,
+			DividentRatio:  d.DividentRatio,
+			UpdatedAt:      time.Now(),
+		})
+	}
+	return result
+}
+
+// ToStockDividend 将 StockDividendDB 转换为分红记录
+func (d StockDividendDB) ToStockDividend() eastmoney.StockDividend {
+	return eastmoney.StockDividend{
+		SecurityCode:   d.SecurityCode,
+		ReportDate:     d.ReportDate,
+		PlanNoticeDate: d.PlanNoticeDate,
+		ExDividendDate: d.ExDividendDate,
+		PretaxBonusRMB: d.PretaxBonusRMB,
+		BonusItRatio:   d.BonusItRatio,
+		AssignProgress: d.AssignProgress,
+		BasicEps:       d.BasicEps,
+		DividentRatio:  d.DividentRatio,
+	}
+}
+
+// StockPriceDB 股票历史行情数据库模型
+type StockPriceDB struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Secucode  string    `gorm:"column:secucode;uniqueIndex:idx_stock_price" json:"secucode"`
+	KlineType int       `gorm:"column:kline_type;uniqueIndex:idx_stock_price" json:"kline_type"`
+	FQ        int       `gorm:"column:fq;uniqueIndex:idx_stock_price" json:"fq"`
+	Date      string    `gorm:"column:date;uniqueIndex:idx_stock_price" json:"date"`
+	Open      float64   `gorm:"column:open" json:"open"`
+	Close     float64   `gorm:"column:close" json:"close"`
+	High      float64   `gorm:"column:high" json:"high"`
+	Low       float64   `gorm:"column:low" json:"low"`
+	Volume    float64   `gorm:"column:volume" json:"volume"`
+	Amount    float64   `gorm:"column:amount" json:"amount"`
+	ChangePct float64   `gorm:"column:change_pct" json:"change_pct"`
+	Turnover  float64   `gorm:"column:turnover" json:"turnover"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StockPriceDB) TableName() string {
+	return "stock_prices"
+}
+
+// ToStockPricesDB 将 K 线数据转换为 StockPriceDB 列表
+func ToStockPricesDB(secucode string, klt eastmoney.KlineType, fqt eastmoney.KlineFQ, klines eastmoney.KlineList) []StockPriceDB {
+	result := make([]StockPriceDB, 0, len(klines))
+	for _, k := range klines {
+		result = append(result, StockPriceDB{
+			Secucode:  secucode,
+			KlineType: int(klt),
+			FQ:        int(fqt),
+			Date:      k.Date,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Volume:    k.Volume,
+			Amount:    k.Amount,
+			ChangePct: k.ChangePct,
+			Turnover:  k.Turnover,
+			UpdatedAt: time.Now(),
+		})
+	}
+	return result
+}
+
+// ToKline 将 StockPriceDB 转换为 K 线数据
+func (p StockPriceDB) ToKline() eastmoney.Kline {
+	return eastmoney.Kline{
+		Date:      p.Date,
+		Open:      p.Open,
+		Close:     p.Close,
+		High:      p.High,
+		Low:       p.Low,
+		Volume:    p.Volume,
+		Amount:    p.Amount,
+		ChangePct: p.ChangePct,
+		Turnover:  p.Turnover,
+	}
+}
+
+// StockMoneyFlowDB 个股资金流向数据库模型，金额单位：万元
+type StockMoneyFlowDB struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Secucode   string    `gorm:"column:secucode;uniqueIndex:idx_stock_money_flow" json:"secucode"`
+	Date       string    `gorm:"column:date;uniqueIndex:idx_stock_money_flow" json:"date"`
+	Close      string    `gorm:"column:close" json:"close"`
+	MainNetIn  string    `gorm:"column:main_net_in" json:"main_net_in"`
+	HugeNetIn  string    `gorm:"column:huge_net_in" json:"huge_net_in"`
+	BigNetIn   string    `gorm:"column:big_net_in" json:"big_net_in"`
+	MidNetIn   string    `gorm:"column:mid_net_in" json:"mid_net_in"`
+	SmallNetIn string    `gorm:"column:small_net_in" json:"small_net_in"`
+	TotalNetIn string    `gorm:"column:total_net_in" json:"total_net_in"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (StockMoneyFlowDB) TableName() string {
+	return "stock_money_flows"
+}
+
+// ToStockMoneyFlowsDB 将资金净流入数据转换为 StockMoneyFlowDB 列表
+func ToStockMoneyFlowsDB(secucode string, inflows zszx.NetInflowList) []StockMoneyFlowDB {
+	result := make([]StockMoneyFlowDB, 0, len(inflows))
+	for _, i := range inflows {
+		result = append(result, StockMoneyFlowDB{
+			Secucode:   secucode,
+			Date:       i.TrdDt,
+			Close:      i.ClsPrc,
+			MainNetIn:  i.MainMnyNetIn,
+			HugeNetIn:  i.HugeNetIn,
+			BigNetIn:   i.BigNetIn,
+			MidNetIn:   i.MidNetIn,
+			SmallNetIn: i.SmallNetIn,
+			TotalNetIn: i.TTLMnyNetIn,
+			UpdatedAt:  time.Now(),
+		})
+	}
+	return result
+}
+
+// ToNetInflow 将 StockMoneyFlowDB 转换为资金净流入数据
+func (m StockMoneyFlowDB) ToNetInflow() zszx.NetInflow {
+	return zszx.NetInflow{
+		TrdDt:        m.Date,
+		ClsPrc:       m.Close,
+		MainMnyNetIn: m.MainNetIn,
+		HugeNetIn:    m.HugeNetIn,
+		BigNetIn:     m.BigNetIn,
+		MidNetIn:     m.MidNetIn,
+		SmallNetIn:   m.SmallNetIn,
+		TTLMnyNetIn:  m.TotalNetIn,
+	}
+}
+
+// BondYieldDB 债券收益率曲线数据库模型，每条记录为一条曲线在某日某期限的收益率
+type BondYieldDB struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CurveName string    `gorm:"column:curve_name;uniqueIndex:idx_bond_yield" json:"curve_name"`
+	Date      string    `gorm:"column:date;uniqueIndex:idx_bond_yield;index" json:"date"`
+	Tenor     float64   `gorm:"column:tenor;uniqueIndex:idx_bond_yield" json:"tenor"`
+	Yield     float64   `gorm:"column:yield" json:"yield"`
+	CurveID   string    `gorm:"column:curve_id" json:"curve_id"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (BondYieldDB) TableName() string {
+	return "bond_yields"
+}
+
+// ToBondYieldsDB 将收益率曲线转换为 BondYieldDB 列表
+func ToBondYieldsDB(curve chinabond.YieldCurve) []BondYieldDB {
+	result := make([]BondYieldDB, 0, len(curve.Points))
+	for _, p := range curve.Points {
+		result = append(result, BondYieldDB{
+			CurveName: curve.Name,
+			Date:      curve.Date,
+			Tenor:     p.Tenor,
+			Yield:     p.Yield,
+			CurveID:   curve.ID,
+			UpdatedAt: time.Now(),
+		})
+	}
+	return result
+}
+
+// MarketTemperatureDB 指数估值温度数据库模型，每个指数每日一条
+type MarketTemperatureDB struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	IndexCode     string    `gorm:"column:index_code;uniqueIndex:idx_market_temperature" json:"index_code"`
+	Date          string    `gorm:"column:date;uniqueIndex:idx_market_temperature;index" json:"date"`
+	IndexName     string    `gorm:"column:index_name" json:"index_name"`
+	PE            float64   `gorm:"column:pe" json:"pe"`
+	PB            float64   `gorm:"column:pb" json:"pb"`
+	PEPercentile  float64   `gorm:"column:pe_percentile" json:"pe_percentile"`
+	PBPercentile  float64   `gorm:"column:pb_percentile" json:"pb_percentile"`
+	RiskFreeRate  float64   `gorm:"column:risk_free_rate" json:"risk_free_rate"`
+	ERP           float64   `gorm:"column:erp" json:"erp"`
+	ERPPercentile float64   `gorm:"column:erp_percentile" json:"erp_percentile"`
+	Temperature   float64   `gorm:"column:temperature" json:"temperature"`
+	UpdatedAt     time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MarketTemperatureDB) TableName() string {
+	return "market_temperatures"
+}
+
+// ToMarketTemperatureDB 将指数温度转换为 MarketTemperatureDB
+func ToMarketTemperatureDB(t IndexTemperature) MarketTemperatureDB {
+	return MarketTemperatureDB{
+		IndexCode:     t.IndexCode,
+		Date:          t.Date,
+		IndexName:     t.IndexName,
+		PE:            t.PE,
+		PB:            t.PB,
+		PEPercentile:  t.PEPercentile,
+		PBPercentile:  t.PBPercentile,
+		RiskFreeRate:  t.RiskFreeRate,
+		ERP:           t.ERP,
+		ERPPercentile: t.ERPPercentile,
+		Temperature:   t.Temperature,
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// ToIndexTemperature 将 MarketTemperatureDB 转换为指数温度
+func (m MarketTemperatureDB) ToIndexTemperature() IndexTemperature {
+	return IndexTemperature{
+		Date:          m.Date,
+		IndexCode:     m.IndexCode,
+		IndexName:     m.IndexName,
+		PE:            m.PE,
+		PB:            m.PB,
+		PEPercentile:  m.PEPercentile,
+		PBPercentile:  m.PBPercentile,
+		RiskFreeRate:  m.RiskFreeRate,
+		ERP:           m.ERP,
+		ERPPercentile: m.ERPPercentile,
+		Temperature:   m.Temperature,
+		Level:         TemperatureLevel(m.Temperature),
+	}
+}
+
+// IndexValuationDB 指数每日估值数据库模型
+type IndexValuationDB struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	IndexCode    string    `gorm:"column:index_code;uniqueIndex:idx_index_valuation" json:"index_code"`
+	Date         string    `gorm:"column:date;uniqueIndex:idx_index_valuation" json:"date"`
+	IndexName    string    `gorm:"column:index_name" json:"index_name"`
+	Price        float64   `gorm:"column:price" json:"price"`
+	PE           float64   `gorm:"column:pe" json:"pe"`
+	PEPercentile float64   `gorm:"column:pe_percentile" json:"pe_percentile"`
+	PB           float64   `gorm:"column:pb" json:"pb"`
+	PBPercentile float64   `gorm:"column:pb_percentile" json:"pb_percentile"`
+	Valuation    int       `gorm:"column:valuation" json:"valuation"`
+	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (IndexValuationDB) TableName() string {
+	return "index_valuations"
+}
+
+// IndexConstituentDB 指数成分股快照数据库模型
+type IndexConstituentDB struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	IndexCode string    `gorm:"column:index_code;uniqueIndex:idx_index_constituent" json:"index_code"`
+	Date      string    `gorm:"column:date;uniqueIndex:idx_index_constituent" json:"date"`
+	StockCode string    `gorm:"column:stock_code;uniqueIndex:idx_index_constituent" json:"stock_code"`
+	IndexName string    `gorm:"column:index_name" json:"index_name"`
+	StockName string    `gorm:"column:stock_name" json:"stock_name"`
+	Weight    float64   `gorm:"column:weight" json:"weight"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (IndexConstituentDB) TableName() string {
+	return "index_constituents"
+}
+
+// ToIndexConstituentsDB 将成分股列表转换为 date 日的快照记录
+func ToIndexConstituentsDB(date string, list []eastmoney.IndexConstituent) []IndexConstituentDB {
+	rows := make([]IndexConstituentDB, 0, len(list))
+	now := time.Now()
+	for _, c := range list {
+		rows = append(rows, IndexConstituentDB{
+			IndexCode: c.IndexCode,
+			Date:      date,
+			StockCode: c.StockCode,
+			IndexName: c.IndexName,
+			StockName: c.StockName,
+			Weight:    c.Weight,
+			CreatedAt: now,
+		})
+	}
+	return rows
+}
+
+// ToIndexConstituent 转换为成分股
+func (c IndexConstituentDB) ToIndexConstituent() eastmoney.IndexConstituent {
+	return eastmoney.IndexConstituent{
+		IndexCode: c.IndexCode,
+		IndexName: c.IndexName,
+		StockCode: c.StockCode,
+		StockName: c.StockName,
+		Weight:    c.Weight,
+	}
+}
+
+// IndustryDailyDB 行业板块每日行情及估值数据库模型
+type IndustryDailyDB struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BoardCode      string    `gorm:"column:board_code;uniqueIndex:idx_industry_daily" json:"board_code"`
+	Date           string    `gorm:"column:date;uniqueIndex:idx_industry_daily;index" json:"date"`
+	Name           string    `gorm:"column:name;index" json:"name"`
+	Close          float64   `gorm:"column:close" json:"close"`
+	ChangePct      float64   `gorm:"column:change_pct" json:"change_pct"`
+	PE             float64   `gorm:"column:pe" json:"pe"`
+	PB             float64   `gorm:"column:pb" json:"pb"`
+	TotalMarketCap float64   `gorm:"column:total_market_cap" json:"total_market_cap"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (IndustryDailyDB) TableName() string {
+	return "industry_dailies"
+}
+
+// ToIndustryDailiesDB 将行业板块行情转换为 date 日的记录
+func ToIndustryDailiesDB(date string, boards []eastmoney.IndustryBoard) []IndustryDailyDB {
+	rows := make([]IndustryDailyDB, 0, len(boards))
+	now := time.Now()
+	for _, b := range boards {
+		rows = append(rows, IndustryDailyDB{
+			BoardCode:      b.Code,
+			Date:           date,
+			Name:           b.Name,
+			Close:          b.Price,
+			ChangePct:      b.ChangePct,
+			PE:             b.PE,
+			PB:             b.PB,
+			TotalMarketCap: b.TotalMarketCap,
+			UpdatedAt:      now,
+		})
+	}
+	return rows
+}
+
+// FundStyleDB 基金每个报告期的风格箱
+type FundStyleDB struct {
+	ID         uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	FundCode   string  `gorm:"column:fund_code;uniqueIndex:idx_fund_style" json:"fund_code"`
+	ReportDate string  `gorm:"column:report_date;uniqueIndex:idx_fund_style" json:"report_date"`
+	Size       string  `gorm:"column:size" json:"size"`
+	Style      string  `gorm:"column:style" json:"style"`
+	Box        string  `gorm:"column:box;index" json:"box"`
+	SizeScore  float64 `gorm:"column:size_score" json:"size_score"`
+	StyleScore float64 `gorm:"column:style_score" json:"style_score"`
+	MarketCap  float64 `gorm:"column:market_cap" json:"market_cap"`
+	PE         float64 `gorm:"column:pe" json:"pe"`
+	PB         float64 `gorm:"column:pb" json:"pb"`
+	Coverage   float64 `gorm:"column:coverage" json:"coverage"`
+	// 截至该报告期的风格漂移度
+	Drift     float64   `gorm:"column:drift;index" json:"drift"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FundStyleDB) TableName() string {
+	return "fund_styles"
+}
+
+// ToFundStyleDB 转换为数据库记录，drift 为截至该报告期的风格漂移度
+func ToFundStyleDB(s FundStyle, drift float64) FundStyleDB {
+	return FundStyleDB{
+		FundCode:   s.FundCode,
+		ReportDate: s.ReportDate,
+		Size:       s.Size,
+		Style:      s.Style,
+		Box:        s.Box,
+		SizeScore:  s.SizeScore,
+		StyleScore: s.StyleScore,
+		MarketCap:  s.MarketCap,
+		PE:         s.PE,
+		PB:         s.PB,
+		Coverage:   s.Coverage,
+		Drift:      drift,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// ToFundStyle 转换为基金风格
+func (s FundStyleDB) ToFundStyle() FundStyle {
+	return FundStyle{
+		FundCode:   s.FundCode,
+		ReportDate: s.ReportDate,
+		Size:       s.Size,
+		Style:      s.Style,
+		Box:        s.Box,
+		SizeScore:  s.SizeScore,
+		StyleScore: s.StyleScore,
+		MarketCap:  s.MarketCap,
+		PE:         s.PE,
+		PB:         s.PB,
+		Coverage:   s.Coverage,
+	}
+}
+
+// FundScaleDB 基金各报告期的净资产规模
+type FundScaleDB struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	FundCode  string    `gorm:"column:fund_code;uniqueIndex:idx_fund_scale" json:"fund_code"`
+	Date      string    `gorm:"column:date;uniqueIndex:idx_fund_scale" json:"date"`
+	NetAssets float64   `gorm:"column:net_assets" json:"net_assets"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FundScaleDB) TableName() string {
+	return "fund_scales"
+}
+
+// FundNavDB 基金历史净值
+type FundNavDB struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	FundCode   string    `gorm:"column:fund_code;uniqueIndex:idx_fund_nav" json:"fund_code"`
+	Date       string    `gorm:"column:date;uniqueIndex:idx_fund_nav" json:"date"`
+	NAV        float64   `gorm:"column:nav" json:"nav"`
+	AccNAV     float64   `gorm:"column:acc_nav" json:"acc_nav"`
+	GrowthRate float64   `gorm:"column:growth_rate" json:"growth_rate"`
+	UpdatedAt  time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (FundNavDB) TableName() string {
+	return "fund_navs"
+}
+
+// ToFundNavsDB 将基金历史净值转换为数据库记录，跳过增长率缺失的记录
+func ToFundNavsDB(fundCode string, navs eastmoney.FundNetValueList) []FundNavDB {
+	rows := make([]FundNavDB, 0, len(navs))
+	now := time.Now()
+	for _, v := range navs {
+		rate, err := strconv.ParseFloat(v.GrowthRate, 64)
+		if err != nil {
+			continue
+		}
+		nav, _ := strconv.ParseFloat(v.NAV, 64)
+		accNAV, _ := strconv.ParseFloat(v.AccNAV, 64)
+		rows = append(rows, FundNavDB{
+			FundCode:   fundCode,
+			Date:       v.Date,
+			NAV:        nav,
+			AccNAV:     accNAV,
+			GrowthRate: rate,
+			UpdatedAt:  now,
+		})
+	}
+	return rows
+}
+
+// ToFundNetValue 转换为基金单日净值
+func (n FundNavDB) ToFundNetValue() eastmoney.FundNetValue {
+	return eastmoney.FundNetValue{
+		Date:       n.Date,
+		NAV:        strconv.FormatFloat(n.NAV, 'f', -1, 64),
+		AccNAV:     strconv.FormatFloat(n.AccNAV, 'f', -1, 64),
+		GrowthRate: strconv.FormatFloat(n.GrowthRate, 'f', -1, 64),
+	}
+}
+
+// WatchlistDB 自选列表
+type WatchlistDB struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	// 所属用户，未开启认证和命令行创建的列表为 0
+	UserID uint   `gorm:"column:user_id;uniqueIndex:idx_watchlist_user_name" json:"user_id"`
+	Name   string `gorm:"column:name;uniqueIndex:idx_watchlist_user_name" json:"name"`
+	// 列表类型：fund/stock
+	Kind string `gorm:"column:kind" json:"kind"`
+	Note string `gorm:"column:note" json:"note"`
+	// 标签，逗号分隔
+	Tags      string    `gorm:"column:tags" json:"tags"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WatchlistDB) TableName() string {
+	return "watchlists"
+}
+
+// WatchlistItemDB 自选列表中的基金或股票
+type WatchlistItemDB struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	WatchlistID uint   `gorm:"column:watchlist_id;uniqueIndex:idx_watchlist_item" json:"watchlist_id"`
+	Code        string `gorm:"column:code;uniqueIndex:idx_watchlist_item" json:"code"`
+	Note        string `gorm:"column:note" json:"note"`
+	// 标签，逗号分隔
+	Tags      string    `gorm:"column:tags" json:"tags"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (WatchlistItemDB) TableName() string {
+	return "watchlist_items"
+}
+
+// ScreenPresetDB 保存的筛选预设，筛选和检测参数以 JSON 保存
+type ScreenPresetDB struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name           string    `gorm:"column:name;uniqueIndex" json:"name"`
+	Desc           string    `gorm:"column:desc" json:"desc"`
+	FundFilter     string    `gorm:"column:fund_filter;type:jsonb" json:"fund_filter"`
+	StockFilter    string    `gorm:"column:stock_filter;type:jsonb" json:"stock_filter"`
+	CheckerOptions string    `gorm:"column:checker_options;type:jsonb" json:"checker_options"`
+	CreatedAt      time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ScreenPresetDB) TableName() string {
+	return "screen_presets"
+}
+
+// AlertRuleDB 告警规则数据库模型
+type AlertRuleDB struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	// 所属用户，未开启认证时为 0
+	UserID uint   `gorm:"column:user_id;index" json:"user_id"`
+	Name   string `gorm:"column:name" json:"name"`
+	// 规则类型，见 AlertType 常量
+	Type string `gorm:"column:type;index" json:"type"`
+	// 监控对象，基金、股票或指数代码，逗号分隔，支持 watchlist:<name>
+	Targets string `gorm:"column:targets" json:"targets"`
+	// 阈值：规模（亿）、合理价差（%）、PE 百分位
+	Threshold float64 `gorm:"column:threshold" json:"threshold"`
+	// 合理价差穿越方向：above/below
+	Direction string `gorm:"column:direction" json:"direction"`
+	// 基本面检测使用的预设名称，为空时使用默认检测条件
+	Preset    string    `gorm:"column:preset" json:"preset"`
+	Enabled   bool      `gorm:"column:enabled;default:true" json:"enabled"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AlertRuleDB) TableName() string {
+	return "alert_rules"
+}
+
+// AlertStateDB 告警规则在每个监控对象上的最近一次评估状态，用于避免重复触发
+type AlertStateDB struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	RuleID uint   `gorm:"column:rule_id;uniqueIndex:idx_alert_state" json:"rule_id"`
+	Target string `gorm:"column:target;uniqueIndex:idx_alert_state" json:"target"`
+	// 告警条件是否成立
+	Active bool `gorm:"column:active" json:"active"`
+	// 最近一次观察值
+	Value       string    `gorm:"column:value" json:"value"`
+	EvaluatedAt time.Time `gorm:"column:evaluated_at" json:"evaluated_at"`
+}
+
+// TableName 指定表名
+func (AlertStateDB) TableName() string {
+	return "alert_states"
+}
+
+// AlertDB 告警历史数据库模型
+type AlertDB struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint      `gorm:"column:user_id;index" json:"user_id"`
+	RuleID     uint      `gorm:"column:rule_id;index" json:"rule_id"`
+	RuleName   string    `gorm:"column:rule_name" json:"rule_name"`
+	Type       string    `gorm:"column:type;index" json:"type"`
+	Target     string    `gorm:"column:target;index" json:"target"`
+	TargetName string    `gorm:"column:target_name" json:"target_name"`
+	Value      string    `gorm:"column:value" json:"value"`
+	Message    string    `gorm:"column:message" json:"message"`
+	CreatedAt  time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AlertDB) TableName() string {
+	return "alerts"
+}
+
+// ReportDB 定时报告存档
+type ReportDB struct {
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	// 报告配置名称
+	Name string `gorm:"column:name;index" json:"name"`
+	// 报告类型，见 Report 常量
+	Kind   string `gorm:"column:kind" json:"kind"`
+	Preset string `gorm:"column:preset" json:"preset"`
+	Title  string `gorm:"column:title" json:"title"`
+	// 纯文本摘要
+	Summary string `gorm:"column:summary;type:text" json:"summary"`
+	// HTML 正文
+	HTML string `gorm:"column:html;type:text" json:"-"`
+	// XLSX 文件名和内容
+	Filename string `gorm:"column:filename" json:"filename"`
+	Content  []byte `gorm:"column:content" json:"-"`
+	// 报告中的基金或股票代码，逗号分隔，用于和下一期报告比较
+	Codes      string `gorm:"column:codes;type:text" json:"-"`
+	Recipients string `gorm:"column:recipients" json:"recipients"`
+	// 是否已发送，发送失败时记录错误
+	Delivered bool      `gorm:"column:delivered" json:"delivered"`
+	Error     string    `gorm:"column:error" json:"error"`
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ReportDB) TableName() string {
+	return "reports"
+}
+
+// UserDB 用户
+type UserDB struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username string `gorm:"column:username;uniqueIndex" json:"username"`
+	// bcrypt 密码哈希
+	PasswordHash string `gorm:"column:password_hash" json:"-"`
+	// 角色：admin/user/viewer
+	Role        string     `gorm:"column:role" json:"role"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserDB) TableName() string {
+	return "users"
+}
+
+// APITokenDB 用户的个人 API token，只保存 token 的哈希
+type APITokenDB struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint   `gorm:"column:user_id;index" json:"user_id"`
+	Name   string `gorm:"column:name" json:"name"`
+	// token 的 SHA-256 哈希
+	TokenHash string `gorm:"column:token_hash;uniqueIndex" json:"-"`
+	// token 前缀，用于展示和区分
+	Prefix     string     `gorm:"column:prefix" json:"prefix"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (APITokenDB) TableName() string {
+	return "api_tokens"
+}
@@ -0,0 +1,128 @@
+// 股票分红可持续性分析
+
+package models
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// DividendAnalysis 分红分析结果
+type DividendAnalysis struct {
+	// 最新已分红年度
+	LastYear int `json:"last_year"`
+	// 连续分红年数
+	ConsecutiveYears int `json:"consecutive_years"`
+	// 每股分红复合增长率（%）
+	CAGR float64 `json:"cagr"`
+	// 历年股利支付率（%），最新的在最前面
+	PayoutRatioList []float64 `json:"payout_ratio_list"`
+	// 股利支付率标准差
+	PayoutRatioStdDev float64 `json:"payout_ratio_std_dev"`
+	// 自由现金流分红覆盖倍数：年度自由现金流 / 年度分红总额
+	FCFCoverage float64 `json:"fcf_coverage"`
+}
+
+// NewDividendAnalysis 根据历史分红、年报和现金流量表计算分红可持续性指标
+func NewDividendAnalysis(
+	ctx context.Context,
+	dividends eastmoney.StockDividendList,
+	hf eastmoney.HistoricalFinaMainData,
+	cashflows eastmoney.CashflowDataList,
+	years int,
+) DividendAnalysis {
+	a := DividendAnalysis{}
+	yearly := dividends.YearlyCashPerShare()
+	for y := range yearly {
+		if y > a.LastYear {
+			a.LastYear = y
+		}
+	}
+	if a.LastYear == 0 {
+		return a
+	}
+	a.ConsecutiveYears = dividends.ConsecutiveYears(a.LastYear)
+	cagrYears := years
+	if a.ConsecutiveYears-1 < cagrYears {
+		cagrYears = a.ConsecutiveYears - 1
+	}
+	if cagr, err := dividends.CAGR(a.LastYear, cagrYears); err == nil {
+		a.CAGR = cagr
+	}
+	a.PayoutRatioList = dividends.PayoutRatioList(a.LastYear, years)
+	if len(a.PayoutRatioList) > 1 {
+		sd, err := goutils.StdDeviationFloat64(a.PayoutRatioList)
+		if err != nil {
+			logrus.WithContext(ctx).Warn("NewDividendAnalysis StdDeviationFloat64 error:" + err.Error())
+		}
+		a.PayoutRatioStdDev = sd
+	}
+
+	// 分红总额 = 股利支付率 * 归属净利润
+	payout := dividends.YearlyPayoutRatio()[a.LastYear]
+	report := hf.GetReport(ctx, a.LastYear, eastmoney.FinaReportTypeYear)
+	var fcf float64
+	fcfFound := false
+	lastYearStr := strconv.Itoa(a.LastYear)
+	for _, cf := range cashflows {
+		if cf.ReportType == eastmoney.FinaReportTypeYear && len(cf.ReportDate) >= 4 && cf.ReportDate[:4] == lastYearStr {
+			fcf = cf.NetcashOperate - cf.ConstructLongAsset
+			fcfFound = true
+			break
+		}
+	}
+	if report != nil && fcfFound && payout > 0 {
+		total := payout / 100 * report.Parentnetprofit
+		if total > 0 {
+			a.FCFCoverage = fcf / total
+		}
+	}
+	return a
+}
+
+// SaveStockDividends 批量保存股票分红记录到数据库，已存在的报告期更新记录
+func SaveStockDividends(ctx context.Context, dividends eastmoney.StockDividendList) error {
+	if DB == nil || len(dividends) == 0 {
+		return nil
+	}
+	// 同一批数据中报告期重复时保留第一条，避免同一条语句多次更新同一行
+	rows := []StockDividendDB{}
+	seen := map[string]bool{}
+	for _, d := range ToStockDividendsDB(dividends) {
+		key := d.SecurityCode + "_" + d.ReportDate
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		rows = append(rows, d)
+	}
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "security_code"}, {Name: "report_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"plan_notice_date", "ex_dividend_date", "pretax_bonus_rmb", "bonus_it_ratio",
+			"assign_progress", "basic_eps", "divident_ratio", "updated_at",
+		}),
+	}).CreateInBatches(rows, 500).Error
+}
+
+// LoadStockDividends 从数据库加载股票分红记录，最新的在最前面
+func LoadStockDividends(ctx context.Context, securityCode string) (eastmoney.StockDividendList, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []StockDividendDB{}
+	if err := DB.WithContext(ctx).Where("security_code = ?", securityCode).Order("report_date DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := eastmoney.StockDividendList{}
+	for _, r := range rows {
+		result = append(result, r.ToStockDividend())
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDividendAnalysis(t *testing.T) {
+	ctx := context.TODO()
+	dividends := eastmoney.StockDividendList{
+		{ReportDate: "2022-12-31 00:00:00", PretaxBonusRMB: 12.1, AssignProgress: "实施分配", BasicEps: 2.42},
+		{ReportDate: "2021-12-31 00:00:00", PretaxBonusRMB: 11, AssignProgress: "实施分配", BasicEps: 2.2},
+		{ReportDate: "2020-12-31 00:00:00", PretaxBonusRMB: 10, AssignProgress: "实施分配", BasicEps: 2.0},
+	}
+	hf := eastmoney.HistoricalFinaMainData{
+		{ReportYear: "2022", ReportType: eastmoney.FinaReportTypeYear, Parentnetprofit: 1000},
+	}
+	cashflows := eastmoney.CashflowDataList{
+		{ReportDate: "2022-12-31 00:00:00", ReportType: eastmoney.FinaReportTypeYear, NetcashOperate: 1200, ConstructLongAsset: 200},
+	}
+	a := NewDividendAnalysis(ctx, dividends, hf, cashflows, 5)
+	require.Equal(t, 2022, a.LastYear)
+	require.Equal(t, 3, a.ConsecutiveYears)
+	require.InDelta(t, 10.0, a.CAGR, 1e-9)
+	require.Len(t, a.PayoutRatioList, 3)
+	require.InDelta(t, 0.0, a.PayoutRatioStdDev, 1e-9)
+	require.InDelta(t, 2.0, a.FCFCoverage, 1e-9)
+}
@@ -76,7 +76,7 @@ type ExportorData struct {
 	RightPrice interface{} `json:"right_price"               csv:"估算合理价格"`
 	// 合理价格与当时价的价格差(%)
 	PriceSpace interface{} `json:"price_space"               csv:"合理价差"`
-	// 历史波动率
+	// 历史波动率，与检测时使用的窗口一致，K线数据不足时为 0
 	HV float64 `json:"hv"                        csv:"历史波动率"`
 	// 最新负债率 (%)
 	ZXFZL float64 `json:"zxfzl"                     csv:"最新负债率 (%)"`
@@ -128,8 +128,27 @@ type ExportorData struct {
 	FreeHoldersTop10 string `json:"free_holders_top_10"       csv:"十大流通股东"`
 	// 主力净流入
 	MainMoneyNetInflows string `json:"main_money_net_inflows"    csv:"主力资金净流入"`
+	// 近5日主力净流入（万元）
+	MainNetIn5D float64 `json:"main_net_in_5d"            csv:"近5日主力净流入(万元)"`
+	// 近20日主力净流入（万元）
+	MainNetIn20D float64 `json:"main_net_in_20d"           csv:"近20日主力净流入(万元)"`
+	// 近60日主力净流入（万元）
+	MainNetIn60D float64 `json:"main_net_in_60d"           csv:"近60日主力净流入(万元)"`
+	// 近20日资金与股价背离
+	MoneyFlowDivergence string `json:"money_flow_divergence"     csv:"资金股价背离(20日)"`
 }
 
+// MoneyFlowHeaders 资金流向相关的可选导出列
+var MoneyFlowHeaders = []string{
+	"近5日主力净流入(万元)",
+	"近20日主力净流入(万元)",
+	"近60日主力净流入(万元)",
+	"资金股价背离(20日)",
+}
+
+// MoneyFlowDivergenceMinPriceChgPct 导出时判断资金与股价背离的最小区间涨跌幅（%）
+const MoneyFlowDivergenceMinPriceChgPct = 5.0
+
 // GetHeaderValueMap 获取以 csv tag 为 key 的 Data map
 func (d ExportorData) GetHeaderValueMap() map[string]interface{} {
 	return goutils.StructToMap(&d, "csv")
@@ -140,8 +159,8 @@ func (d ExportorData) GetHeaders() []string {
 	return goutils.StructTagList(&d, "csv")
 }
 
-// NewExportorData 创建 ExportotData 对象
-func NewExportorData(ctx context.Context, stock Stock) ExportorData {
+// NewExportorData 创建 ExportotData 对象，hvWindow 为计算历史波动率的窗口（交易日）
+func NewExportorData(ctx context.Context, stock Stock, hvWindow int) ExportorData {
 	var rightPrice interface{} = "--"
 	var priceSpace interface{} = "--"
 	var reportOpinion interface{} = "--"
@@ -152,6 +171,7 @@ func NewExportorData(ctx context.Context, stock Stock) ExportorData {
 	if stock.FinaReportOpinion != "" {
 		reportOpinion = stock.FinaReportOpinion
 	}
+	hv, _ := stock.Volatility(hvWindow)
 	frd := strings.Fields(stock.FinaReportDate)
 	reportDate := "--"
 	if len(frd) > 0 {
@@ -217,7 +237,7 @@ func NewExportorData(ctx context.Context, stock Stock) ExportorData {
 		Price:                  stock.GetPrice(),
 		RightPrice:             rightPrice,
 		PriceSpace:             priceSpace,
-		HV:                     stock.HistoricalVolatility,
+		HV:                     hv,
 		ListingVolatilityYear:  stock.BaseInfo.ListingVolatilityYear,
 		ZXFZL:                  fina.Zcfzl,
 		NetprofitGrowthrate3Y:  stock.BaseInfo.NetprofitGrowthrate3Y,
@@ -248,6 +268,10 @@ func NewExportorData(ctx context.Context, stock Stock) ExportorData {
 		NetcashFree:         goutils.YiWanString(stock.NetcashFree),
 		FreeHoldersTop10:    stock.FreeHoldersTop10.String(),
 		MainMoneyNetInflows: stock.MainMoneyNetInflows.String(),
+		MainNetIn5D:         stock.MainMoneyNetInflows.RollingSum(5).Main,
+		MainNetIn20D:        stock.MainMoneyNetInflows.RollingSum(20).Main,
+		MainNetIn60D:        stock.MainMoneyNetInflows.RollingSum(60).Main,
+		MoneyFlowDivergence: stock.MainMoneyNetInflows.Divergence(20, MoneyFlowDivergenceMinPriceChgPct).Desc,
 	}
 }
 
@@ -282,6 +306,13 @@ func (d ExportorDataList) SortByHV() {
 	})
 }
 
+// SortByMainNetIn5D 股票列表按近5日主力净流入排序
+func (d ExportorDataList) SortByMainNetIn5D() {
+	sort.Slice(d, func(i, j int) bool {
+		return d[i].MainNetIn5D > d[j].MainNetIn5D
+	})
+}
+
 // GetIndustryList 获取行业分类列表
 func (d ExportorDataList) GetIndustryList() []string {
 	result := []string{}
@@ -307,10 +338,10 @@ func (d ExportorDataList) ChunkedBySize(chunkSize int) []ExportorDataList {
 	return result
 }
 
-// NewExportorDataList 创建要导出的数据列表
-func NewExportorDataList(ctx context.Context, stocks StockList) (result ExportorDataList) {
+// NewExportorDataList 创建要导出的数据列表，hvWindow 为计算历史波动率的窗口（交易日）
+func NewExportorDataList(ctx context.Context, stocks StockList, hvWindow int) (result ExportorDataList) {
 	for _, s := range stocks {
-		result = append(result, NewExportorData(ctx, s))
+		result = append(result, NewExportorData(ctx, s, hvWindow))
 	}
 	return
 }
@@ -0,0 +1,283 @@
+// 银行、保险等金融股专项分析
+
+package models
+
+import (
+	"context"
+	"sync"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// BankAnalysis 银行股专项指标
+type BankAnalysis struct {
+	// 最新年报名称
+	ReportDateName string `json:"report_date_name"`
+	// 净息差（%）：利息净收入 / 平均总资产
+	NIM float64 `json:"nim"`
+	// 成本收入比（%）：业务及管理费 / 营业收入
+	CostIncomeRatio float64 `json:"cost_income_ratio"`
+	// 贷款同比增速（%）
+	LoanGrowth float64 `json:"loan_growth"`
+	// 存款同比增速（%）
+	DepositGrowth float64 `json:"deposit_growth"`
+	// 拨贷比（%）：不良贷款率 * 拨备覆盖率
+	ProvisionLoanRatio float64 `json:"provision_loan_ratio"`
+	// 历年不良贷款率（年报），最新的在最前面
+	NPLList []float64 `json:"npl_list"`
+}
+
+// IsNPLImproving 不良贷款率是否没有恶化（最新一年不高于最早一年且没有连续上升）
+func (b BankAnalysis) IsNPLImproving() bool {
+	n := len(b.NPLList)
+	if n < 2 {
+		return true
+	}
+	if b.NPLList[0] > b.NPLList[n-1] {
+		return false
+	}
+	// 最近两年不能连续上升
+	if n >= 3 && b.NPLList[0] > b.NPLList[1] && b.NPLList[1] > b.NPLList[2] {
+		return false
+	}
+	return true
+}
+
+// InsurerAnalysis 保险股专项指标
+type InsurerAnalysis struct {
+	// 最新年报名称
+	ReportDateName string `json:"report_date_name"`
+	// 内含价值同比增速（%）
+	EVGrowth float64 `json:"ev_growth"`
+	// 综合成本率（%）：(赔付支出 + 提取责任准备金 + 手续费及佣金 + 业务及管理费) / 已赚保费
+	CombinedRatio float64 `json:"combined_ratio"`
+	// 偿付能力充足率（%）
+	SolvencyRatio float64 `json:"solvency_ratio"`
+	// 内含价值
+	EV float64 `json:"ev"`
+}
+
+// FinancialAnalysis 金融股分析结果
+type FinancialAnalysis struct {
+	// 银行专项
+	Bank *BankAnalysis `json:"bank,omitempty"`
+	// 保险专项
+	Insurer *InsurerAnalysis `json:"insurer,omitempty"`
+	// 最新市净率
+	PB float64 `json:"pb"`
+	// 最新年报 ROE（%）
+	ROE float64 `json:"roe"`
+	// PEV：总市值 / 内含价值，仅保险股有值
+	PEV float64 `json:"pev"`
+}
+
+// PBROE 返回 PB / 合理PB，合理PB = ROE / 要求回报率，小于 1 表示低估
+func (f FinancialAnalysis) PBROE(requiredReturn float64) float64 {
+	if f.ROE <= 0 || requiredReturn <= 0 {
+		return -1
+	}
+	return f.PB / (f.ROE / requiredReturn)
+}
+
+// IsFinancialOrgType 判断机构类型是否为金融机构（银行、保险）
+func IsFinancialOrgType(orgType string) bool {
+	return orgType == "银行" || orgType == "保险"
+}
+
+// growthRatio 同比增速（%）
+func growthRatio(cur, prev float64) float64 {
+	if prev == 0 {
+		return 0
+	}
+	return (cur - prev) / prev * 100
+}
+
+// NewBankAnalysis 根据年报数据计算银行专项指标
+func NewBankAnalysis(
+	ctx context.Context,
+	hf eastmoney.HistoricalFinaMainData,
+	incomes eastmoney.BankIncomeDataList,
+	balances eastmoney.BankBalanceDataList,
+	years int,
+) BankAnalysis {
+	b := BankAnalysis{}
+	yearReports := hf.FilterByReportType(ctx, eastmoney.FinaReportTypeYear)
+	if len(yearReports) > 0 {
+		cur := yearReports[0]
+		b.ReportDateName = cur.ReportDateName
+		b.ProvisionLoanRatio = cur.NonPerLoan * cur.Bldkbbl / 100
+		if len(yearReports) > 1 {
+			prev := yearReports[1]
+			b.LoanGrowth = growthRatio(cur.GrossLoans, prev.GrossLoans)
+			b.DepositGrowth = growthRatio(cur.TotalDeposits, prev.TotalDeposits)
+		}
+	}
+	b.NPLList = hf.ValueList(ctx, eastmoney.ValueListTypeNPL, years, eastmoney.FinaReportTypeYear)
+
+	yearIncomes := eastmoney.BankIncomeDataList{}
+	for _, i := range incomes {
+		if i.ReportType == eastmoney.FinaReportTypeYear {
+			yearIncomes = append(yearIncomes, i)
+		}
+	}
+	yearBalances := eastmoney.BankBalanceDataList{}
+	for _, i := range balances {
+		if i.ReportType == eastmoney.FinaReportTypeYear {
+			yearBalances = append(yearBalances, i)
+		}
+	}
+	if len(yearIncomes) > 0 {
+		income := yearIncomes[0]
+		if income.OperateIncome != 0 {
+			b.CostIncomeRatio = income.BusinessManage / income.OperateIncome * 100
+		}
+		if len(yearBalances) > 0 {
+			avgAssets := yearBalances[0].TotalAssets
+			if len(yearBalances) > 1 {
+				avgAssets = (yearBalances[0].TotalAssets + yearBalances[1].TotalAssets) / 2
+			}
+			if avgAssets != 0 {
+				b.NIM = income.InterestNi / avgAssets * 100
+			}
+		}
+		// 主要指标没有存贷款数据时使用资产负债表数据
+		if len(yearReports) < 2 && len(yearBalances) > 1 {
+			b.LoanGrowth = growthRatio(yearBalances[0].LoanAdvance, yearBalances[1].LoanAdvance)
+			b.DepositGrowth = growthRatio(yearBalances[0].AcceptDeposit, yearBalances[1].AcceptDeposit)
+		}
+	}
+	return b
+}
+
+// NewInsurerAnalysis 根据年报数据计算保险专项指标
+func NewInsurerAnalysis(
+	ctx context.Context,
+	hf eastmoney.HistoricalFinaMainData,
+	incomes eastmoney.InsuranceIncomeDataList,
+) InsurerAnalysis {
+	a := InsurerAnalysis{}
+	yearReports := hf.FilterByReportType(ctx, eastmoney.FinaReportTypeYear)
+	if len(yearReports) > 0 {
+		cur := yearReports[0]
+		a.ReportDateName = cur.ReportDateName
+		a.SolvencyRatio = cur.SolvencyAr
+		a.EV = cur.NhjzCurrentAmt
+		if len(yearReports) > 1 {
+			a.EVGrowth = growthRatio(cur.NhjzCurrentAmt, yearReports[1].NhjzCurrentAmt)
+		}
+	}
+	for _, i := range incomes {
+		if i.ReportType != eastmoney.FinaReportTypeYear {
+			continue
+		}
+		if i.EarnedPremium != 0 {
+			cost := i.CompensateExpense - i.AmortizeCompensateExpense +
+				i.ExtractInsuranceReserve - i.AmortizeInsuranceReserve +
+				i.FeeCommissionExpense + i.BusinessManageExpense
+			a.CombinedRatio = cost / i.EarnedPremium * 100
+		}
+		break
+	}
+	return a
+}
+
+// FinancialStatements 金融股专项报表数据
+type FinancialStatements struct {
+	BankIncomes      eastmoney.BankIncomeDataList
+	BankBalances     eastmoney.BankBalanceDataList
+	InsuranceIncomes eastmoney.InsuranceIncomeDataList
+}
+
+// QueryFinancialStatements 按机构类型获取金融股专项报表，非金融股返回空数据
+func QueryFinancialStatements(ctx context.Context, secucode, orgType string) FinancialStatements {
+	fs := FinancialStatements{}
+	switch orgType {
+	case "银行":
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var err error
+			fs.BankIncomes, err = datacenter.EastMoney.QueryFinaBankIncomeData(ctx, secucode)
+			if err != nil {
+				logrus.WithContext(ctx).Error("QueryFinancialStatements QueryFinaBankIncomeData err:" + err.Error())
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var err error
+			fs.BankBalances, err = datacenter.EastMoney.QueryFinaBankBalanceData(ctx, secucode)
+			if err != nil {
+				logrus.WithContext(ctx).Error("QueryFinancialStatements QueryFinaBankBalanceData err:" + err.Error())
+			}
+		}()
+		wg.Wait()
+	case "保险":
+		var err error
+		fs.InsuranceIncomes, err = datacenter.EastMoney.QueryFinaInsuranceIncomeData(ctx, secucode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("QueryFinancialStatements QueryFinaInsuranceIncomeData err:" + err.Error())
+		}
+	}
+	return fs
+}
+
+// FilterByReportDates 只保留报告期在 reportDates 中的报表
+func (fs FinancialStatements) FilterByReportDates(reportDates map[string]bool) FinancialStatements {
+	result := FinancialStatements{}
+	for _, i := range fs.BankIncomes {
+		if reportDates[i.ReportDate] {
+			result.BankIncomes = append(result.BankIncomes, i)
+		}
+	}
+	for _, i := range fs.BankBalances {
+		if reportDates[i.ReportDate] {
+			result.BankBalances = append(result.BankBalances, i)
+		}
+	}
+	for _, i := range fs.InsuranceIncomes {
+		if reportDates[i.ReportDate] {
+			result.InsuranceIncomes = append(result.InsuranceIncomes, i)
+		}
+	}
+	return result
+}
+
+// NewFinancialAnalysis 获取金融股专项数据并进行分析，非金融股返回 nil
+func NewFinancialAnalysis(ctx context.Context, s Stock, years int) *FinancialAnalysis {
+	orgType := s.GetOrgType()
+	if !IsFinancialOrgType(orgType) {
+		return nil
+	}
+	fs := QueryFinancialStatements(ctx, s.BaseInfo.Secucode, orgType)
+	return NewFinancialAnalysisWithStatements(ctx, s, fs, years)
+}
+
+// NewFinancialAnalysisWithStatements 使用给定的专项报表进行金融股分析，非金融股返回 nil
+func NewFinancialAnalysisWithStatements(ctx context.Context, s Stock, fs FinancialStatements, years int) *FinancialAnalysis {
+	orgType := s.GetOrgType()
+	if !IsFinancialOrgType(orgType) {
+		return nil
+	}
+	fa := &FinancialAnalysis{
+		PB: s.BaseInfo.PBNewMRQ,
+	}
+	if r := s.HistoricalFinaMainData.FilterByReportType(ctx, eastmoney.FinaReportTypeYear); len(r) > 0 {
+		fa.ROE = r[0].Roejq
+	}
+
+	switch orgType {
+	case "银行":
+		bank := NewBankAnalysis(ctx, s.HistoricalFinaMainData, fs.BankIncomes, fs.BankBalances, years)
+		fa.Bank = &bank
+	case "保险":
+		insurer := NewInsurerAnalysis(ctx, s.HistoricalFinaMainData, fs.InsuranceIncomes)
+		fa.Insurer = &insurer
+		if insurer.EV > 0 {
+			fa.PEV = s.BaseInfo.TotalMarketCap / insurer.EV
+		}
+	}
+	return fa
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBankAnalysis(t *testing.T) {
+	ctx := context.TODO()
+	hf := eastmoney.HistoricalFinaMainData{
+		{ReportType: eastmoney.FinaReportTypeYear, ReportDateName: "2022年报", GrossLoans: 110, TotalDeposits: 120, NonPerLoan: 1.0, Bldkbbl: 300},
+		{ReportType: eastmoney.FinaReportTypeYear, ReportDateName: "2021年报", GrossLoans: 100, TotalDeposits: 100, NonPerLoan: 1.2},
+		{ReportType: eastmoney.FinaReportTypeYear, ReportDateName: "2020年报", GrossLoans: 90, TotalDeposits: 90, NonPerLoan: 1.5},
+	}
+	incomes := eastmoney.BankIncomeDataList{
+		{ReportType: eastmoney.FinaReportTypeYear, OperateIncome: 100, BusinessManage: 30, InterestNi: 20},
+	}
+	balances := eastmoney.BankBalanceDataList{
+		{ReportType: eastmoney.FinaReportTypeYear, TotalAssets: 1100},
+		{ReportType: eastmoney.FinaReportTypeYear, TotalAssets: 900},
+	}
+	b := NewBankAnalysis(ctx, hf, incomes, balances, 3)
+	require.InDelta(t, 2.0, b.NIM, 1e-9)
+	require.InDelta(t, 30.0, b.CostIncomeRatio, 1e-9)
+	require.InDelta(t, 10.0, b.LoanGrowth, 1e-9)
+	require.InDelta(t, 20.0, b.DepositGrowth, 1e-9)
+	require.InDelta(t, 3.0, b.ProvisionLoanRatio, 1e-9)
+	require.Equal(t, []float64{1.0, 1.2, 1.5}, b.NPLList)
+	require.True(t, b.IsNPLImproving())
+
+	b.NPLList = []float64{1.5, 1.2, 1.0}
+	require.False(t, b.IsNPLImproving())
+}
+
+func TestNewInsurerAnalysis(t *testing.T) {
+	ctx := context.TODO()
+	hf := eastmoney.HistoricalFinaMainData{
+		{ReportType: eastmoney.FinaReportTypeYear, NhjzCurrentAmt: 110, SolvencyAr: 200},
+		{ReportType: eastmoney.FinaReportTypeYear, NhjzCurrentAmt: 100},
+	}
+	incomes := eastmoney.InsuranceIncomeDataList{
+		{ReportType: eastmoney.FinaReportTypeQ3, EarnedPremium: 1},
+		{ReportType: eastmoney.FinaReportTypeYear, EarnedPremium: 100, CompensateExpense: 60, FeeCommissionExpense: 10, BusinessManageExpense: 20},
+	}
+	a := NewInsurerAnalysis(ctx, hf, incomes)
+	require.InDelta(t, 10.0, a.EVGrowth, 1e-9)
+	require.InDelta(t, 90.0, a.CombinedRatio, 1e-9)
+	require.Equal(t, 200.0, a.SolvencyRatio)
+
+	fa := FinancialAnalysis{PB: 0.8, ROE: 10}
+	require.InDelta(t, 0.8, fa.PBROE(10), 1e-9)
+}
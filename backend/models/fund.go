@@ -44,6 +44,8 @@ type Fund struct {
 	Performance fundPerformance `json:"performance"`
 	// 持仓股票
 	Stocks []fundStock `json:"stocks"`
+	// 持仓股票的报告期
+	StocksReportDate string `json:"stocks_report_date"`
 	// 基金经理
 	Manager fundManager `json:"manager"`
 	// 历史分红送配
@@ -52,6 +54,8 @@ type Fund struct {
 	AssetsProportion fundAssetsProportion `json:"assets_proportion"`
 	// 行业占比
 	IndustryProportions []fundIndustryProportion `json:"industry_proportions"`
+	// 历史各报告期的净资产规模，按日期升序
+	ScaleHistory []FundScale `json:"-"`
 }
 
 // fundIndustryProportion 行业占比
@@ -379,6 +383,14 @@ func NewFund(ctx context.Context, efund *eastmoney.RespFundInfo) *Fund {
 	// 基金规模
 	if len(efund.Jjgm.Datas) > 0 {
 		fund.NetAssetsScale = interfaceToFloat64(ctx, efund.Jjgm.Datas[0].Netnav)
+		for _, d := range efund.Jjgm.Datas {
+			if scale := interfaceToFloat64(ctx, d.Netnav); d.Fsrq != "" && scale > 0 {
+				fund.ScaleHistory = append(fund.ScaleHistory, FundScale{Date: d.Fsrq, NetAssets: scale})
+			}
+		}
+		sort.Slice(fund.ScaleHistory, func(i, j int) bool {
+			return fund.ScaleHistory[i].Date < fund.ScaleHistory[j].Date
+		})
 	} else {
 		logrus.WithContext(ctx).Debugf("code:%v jjgm no data", fund.Code)
 	}
@@ -482,6 +494,7 @@ func NewFund(ctx context.Context, efund *eastmoney.RespFundInfo) *Fund {
 		stocks = append(stocks, stock)
 	}
 	fund.Stocks = stocks
+	fund.StocksReportDate = efund.Jjcc.ReportDate()
 
 	// 基金经理
 	manager := fundManager{}
@@ -694,6 +707,10 @@ type ParamFundListFilter struct {
 	Max135AvgRetr float64 `json:"max_135_avg_retr"         form:"max_135_avg_retr"`
 	// 最低成立年限
 	MinEstabYears float64 `json:"min_estab_years"          form:"min_estab_years"`
+	// 最新报告期的风格箱，如 large_growth，可指定多个，依赖数据库中的风格数据
+	StyleBoxes []string `json:"style_boxes"              form:"style_boxes"`
+	// 风格漂移度最大值，依赖数据库中的风格数据
+	MaxStyleDrift float64 `json:"max_style_drift"          form:"max_style_drift"`
 }
 
 // Filter 按参数过滤
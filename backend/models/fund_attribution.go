@@ -0,0 +1,554 @@
+// 基金归因：基于净值的风格分析（RBSA）和基于行业配置的 Brinson 归因
+
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrStyleReturnsNotEnough 基金与风格指数共同的交易日不足
+var ErrStyleReturnsNotEnough = errors.New("style returns not enough")
+
+// StyleBenchmark 风格分析使用的指数
+type StyleBenchmark struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// DefaultStyleBenchmarks 默认风格指数：大盘、中盘、小盘、债券、成长
+var DefaultStyleBenchmarks = []StyleBenchmark{
+	{Code: "000300", Name: "沪深300"},
+	{Code: "000905", Name: "中证500"},
+	{Code: "000852", Name: "中证1000"},
+	{Code: "H11001", Name: "中证全债"},
+	{Code: "399006", Name: "创业板指"},
+}
+
+// projectSimplex 将向量投影到 {w >= 0, sum(w) = 1}
+func projectSimplex(v []float64) []float64 {
+	u := append([]float64{}, v...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(u)))
+	sum, theta := 0.0, 0.0
+	for i, x := range u {
+		sum += x
+		if t := (sum - 1) / float64(i+1); x-t > 0 {
+			theta = t
+		}
+	}
+	w := make([]float64, len(v))
+	for i, x := range v {
+		w[i] = math.Max(x-theta, 0)
+	}
+	return w
+}
+
+// SolveStyleWeights 带约束的回归：权重非负且和为 1，最小化基金收益与指数组合收益之差的方差
+// y 为基金收益序列，xs[j] 为第 j 个指数的收益序列，返回权重及拟合优度 R²
+func SolveStyleWeights(y []float64, xs [][]float64) ([]float64, float64) {
+	k, n := len(xs), len(y)
+	if k == 0 || n == 0 {
+		return nil, 0
+	}
+	// 中心化后的协方差矩阵
+	mean := func(s []float64) float64 {
+		sum := 0.0
+		for _, v := range s {
+			sum += v
+		}
+		return sum / float64(len(s))
+	}
+	my := mean(y)
+	mx := make([]float64, k)
+	for j := range xs {
+		mx[j] = mean(xs[j])
+	}
+	q := make([][]float64, k)
+	c := make([]float64, k)
+	trace := 0.0
+	for i := 0; i < k; i++ {
+		q[i] = make([]float64, k)
+		for j := 0; j < k; j++ {
+			for t := 0; t < n; t++ {
+				q[i][j] += (xs[i][t] - mx[i]) * (xs[j][t] - mx[j])
+			}
+			q[i][j] /= float64(n)
+		}
+		for t := 0; t < n; t++ {
+			c[i] += (xs[i][t] - mx[i]) * (y[t] - my)
+		}
+		c[i] /= float64(n)
+		trace += q[i][i]
+	}
+
+	// 投影梯度下降，步长取 1/(2*trace) 保证收敛
+	w := make([]float64, k)
+	for i := range w {
+		w[i] = 1 / float64(k)
+	}
+	if trace > 0 {
+		step := 1 / (2 * trace)
+		for iter := 0; iter < 20000; iter++ {
+			next := make([]float64, k)
+			for i := 0; i < k; i++ {
+				grad := -c[i]
+				for j := 0; j < k; j++ {
+					grad += q[i][j] * w[j]
+				}
+				next[i] = w[i] - step*2*grad
+			}
+			next = projectSimplex(next)
+			delta := 0.0
+			for i := range next {
+				delta = math.Max(delta, math.Abs(next[i]-w[i]))
+			}
+			w = next
+			if delta < 1e-12 {
+				break
+			}
+		}
+	}
+
+	varY, varE := 0.0, 0.0
+	residuals := make([]float64, n)
+	for t := 0; t < n; t++ {
+		fit := 0.0
+		for j := 0; j < k; j++ {
+			fit += w[j] * xs[j][t]
+		}
+		residuals[t] = y[t] - fit
+	}
+	me := mean(residuals)
+	for t := 0; t < n; t++ {
+		varY += (y[t] - my) * (y[t] - my)
+		varE += (residuals[t] - me) * (residuals[t] - me)
+	}
+	r2 := 0.0
+	if varY > 0 {
+		r2 = 1 - varE/varY
+	}
+	return w, r2
+}
+
+// AlignStyleReturns 按日期对齐基金和各指数的日收益，只保留全部序列都有数据的交易日，按日期升序
+func AlignStyleReturns(fundReturns map[string]float64, benchmarkReturns []map[string]float64) ([]string, []float64, [][]float64) {
+	dates := []string{}
+	for date := range fundReturns {
+		ok := true
+		for _, b := range benchmarkReturns {
+			if _, exists := b[date]; !exists {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+	y := make([]float64, len(dates))
+	xs := make([][]float64, len(benchmarkReturns))
+	for j := range xs {
+		xs[j] = make([]float64, len(dates))
+	}
+	for t, date := range dates {
+		y[t] = fundReturns[date]
+		for j, b := range benchmarkReturns {
+			xs[j][t] = b[date]
+		}
+	}
+	return dates, y, xs
+}
+
+// StyleWeight 指数的有效暴露
+type StyleWeight struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// 权重（%）
+	Weight float64 `json:"weight"`
+}
+
+// StyleExposure 一个窗口内的风格分析结果
+type StyleExposure struct {
+	Start   string        `json:"start"`
+	End     string        `json:"end"`
+	Days    int           `json:"days"`
+	Weights []StyleWeight `json:"weights"`
+	// 拟合优度（%），越低说明基金收益越难用这些指数解释
+	RSquared float64 `json:"r_squared"`
+}
+
+// newStyleExposure 对 [begin, end) 区间做风格分析
+func newStyleExposure(benchmarks []StyleBenchmark, dates []string, y []float64, xs [][]float64, begin, end int) StyleExposure {
+	sub := make([][]float64, len(xs))
+	for j := range xs {
+		sub[j] = xs[j][begin:end]
+	}
+	w, r2 := SolveStyleWeights(y[begin:end], sub)
+	exposure := StyleExposure{Start: dates[begin], End: dates[end-1], Days: end - begin, RSquared: r2 * 100}
+	for j, b := range benchmarks {
+		exposure.Weights = append(exposure.Weights, StyleWeight{Code: b.Code, Name: b.Name, Weight: w[j] * 100})
+	}
+	return exposure
+}
+
+// RollingStyleAnalysis 滚动窗口风格分析，最后一个窗口以最新交易日结束，按窗口结束日期升序
+func RollingStyleAnalysis(benchmarks []StyleBenchmark, dates []string, y []float64, xs [][]float64, window, step int) []StyleExposure {
+	result := []StyleExposure{}
+	if window <= 0 || step <= 0 {
+		return result
+	}
+	for end := len(dates); end-window >= 0; end -= step {
+		result = append(result, newStyleExposure(benchmarks, dates, y, xs, end-window, end))
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// csrcIndustryBoards 证监会行业与东方财富行业板块的对应关系，制造业等无法对应的行业使用沪深300收益
+var csrcIndustryBoards = map[string][]string{
+	"金融业":         {"银行", "证券", "保险", "多元金融"},
+	"房地产业":        {"房地产开发", "房地产服务"},
+	"采矿业":         {"煤炭行业", "石油行业", "有色金属", "采掘行业"},
+	"建筑业":         {"工程建设", "装修装饰"},
+	"批发和零售业":      {"商业百货", "贸易行业", "医药商业"},
+	"交通运输、仓储和邮政业": {"航运港口", "物流行业", "铁路公路", "航空机场"},
+	"信息传输、软件和信息技术服务业":  {"软件开发", "互联网服务", "通信服务"},
+	"电力、热力、燃气及水生产和供应业": {"电力行业", "燃气", "公用事业"},
+	"农、林、牧、渔业":         {"农牧饲渔"},
+	"卫生和社会工作":          {"医疗服务"},
+	"文化、体育和娱乐业":        {"文化传媒", "游戏"},
+	"住宿和餐饮业":           {"旅游酒店"},
+	"水利、环境和公共设施管理业":    {"环保行业"},
+	"教育":         {"教育"},
+	"租赁和商务服务业":   {"专业服务"},
+	"科学研究和技术服务业": {"专业服务"},
+}
+
+// NonEquityBenchmarkCode Brinson 归因中非股票资产使用的基准指数：中证全债
+const NonEquityBenchmarkCode = "H11001"
+
+// BrinsonSegment 单个行业的配置效应
+type BrinsonSegment struct {
+	Industry string `json:"industry"`
+	// 基金行业权重（%），按行业配置合计归一化
+	FundWeight float64 `json:"fund_weight"`
+	// 基准行业权重（%），基准为全部基金的平均行业配置
+	BenchmarkWeight float64 `json:"benchmark_weight"`
+	// 行业区间收益率（%）
+	Return float64 `json:"return"`
+	// 配置效应（%），已按股票仓位折算
+	Allocation float64 `json:"allocation"`
+}
+
+// BrinsonResult Brinson 归因结果，收益率单位均为 %
+// 基金只披露行业配置比例，行业内的实际收益不可得，选股效应为基金收益扣除按行业收益计算的股票部分收益和非股票部分收益后的剩余，包含交互效应
+// 股票仓位取行业配置占净值比例之和，基金和基准都按该仓位计算股票部分，非股票部分按中证全债收益计算，大类资产配置不计入配置效应
+type BrinsonResult struct {
+	Start           string  `json:"start"`
+	End             string  `json:"end"`
+	FundReturn      float64 `json:"fund_return"`
+	BenchmarkReturn float64 `json:"benchmark_return"`
+	ActiveReturn    float64 `json:"active_return"`
+	Allocation      float64 `json:"allocation"`
+	Selection       float64 `json:"selection"`
+	// 股票仓位（%）
+	StockRatio float64 `json:"stock_ratio"`
+	// 非股票部分基准收益率（%）
+	NonEquityReturn float64 `json:"non_equity_return"`
+	// 非股票部分对基金收益的贡献（%）
+	NonEquity float64          `json:"non_equity"`
+	Segments  []BrinsonSegment `json:"segments"`
+}
+
+// normalizeWeights 将权重归一化为和为 1，忽略非正值
+func normalizeWeights(weights map[string]float64) map[string]float64 {
+	sum := 0.0
+	for _, w := range weights {
+		if w > 0 {
+			sum += w
+		}
+	}
+	result := map[string]float64{}
+	for k, w := range weights {
+		if w > 0 && sum > 0 {
+			result[k] = w / sum
+		}
+	}
+	return result
+}
+
+// BrinsonAttribution 按行业计算 Brinson 归因，stockRatio 为股票仓位（0~1），其余部分按 nonEquityReturn 计算
+// industryReturns 中缺失的行业使用 defaultReturn，收益率单位均为 %
+func BrinsonAttribution(fundWeights, benchmarkWeights, industryReturns map[string]float64, defaultReturn, fundReturn, stockRatio, nonEquityReturn float64) BrinsonResult {
+	stockRatio = math.Min(math.Max(stockRatio, 0), 1)
+	wp := normalizeWeights(fundWeights)
+	wb := normalizeWeights(benchmarkWeights)
+	industries := map[string]bool{}
+	for i := range wp {
+		industries[i] = true
+	}
+	for i := range wb {
+		industries[i] = true
+	}
+	ret := func(industry string) float64 {
+		if r, ok := industryReturns[industry]; ok {
+			return r
+		}
+		return defaultReturn
+	}
+
+	result := BrinsonResult{
+		FundReturn:      fundReturn,
+		StockRatio:      stockRatio * 100,
+		NonEquityReturn: nonEquityReturn,
+		NonEquity:       (1 - stockRatio) * nonEquityReturn,
+		Segments:        []BrinsonSegment{},
+	}
+	benchmarkIndustryReturn, fundIndustryReturn := 0.0, 0.0
+	for i := range industries {
+		benchmarkIndustryReturn += wb[i] * ret(i)
+		fundIndustryReturn += wp[i] * ret(i)
+	}
+	for i := range industries {
+		s := BrinsonSegment{
+			Industry:        i,
+			FundWeight:      wp[i] * 100,
+			BenchmarkWeight: wb[i] * 100,
+			Return:          ret(i),
+			Allocation:      stockRatio * (wp[i] - wb[i]) * (ret(i) - benchmarkIndustryReturn),
+		}
+		result.Allocation += s.Allocation
+		result.Segments = append(result.Segments, s)
+	}
+	result.BenchmarkReturn = stockRatio*benchmarkIndustryReturn + result.NonEquity
+	result.ActiveReturn = fundReturn - result.BenchmarkReturn
+	result.Selection = fundReturn - stockRatio*fundIndustryReturn - result.NonEquity
+	sort.Slice(result.Segments, func(i, j int) bool {
+		if result.Segments[i].Allocation != result.Segments[j].Allocation {
+			return result.Segments[i].Allocation > result.Segments[j].Allocation
+		}
+		return result.Segments[i].Industry < result.Segments[j].Industry
+	})
+	return result
+}
+
+// PeriodReturn 按升序 K 线计算 (start, end] 区间的收益率（%），数据不覆盖区间时返回 false
+func PeriodReturn(klines eastmoney.KlineList, start, end string) (float64, bool) {
+	begin, last := 0.0, 0.0
+	for _, k := range klines {
+		if k.Date <= start {
+			begin = k.Close
+		}
+		if k.Date <= end {
+			last = k.Close
+		}
+	}
+	if begin <= 0 || last <= 0 || len(klines) == 0 || klines[len(klines)-1].Date < end {
+		return 0, false
+	}
+	return (last/begin - 1) * 100, true
+}
+
+// navPeriodReturn 按日增长率复合计算 (start, end] 区间的基金收益率（%）
+func navPeriodReturn(navs eastmoney.FundNetValueList, start, end string) (float64, bool) {
+	value, days := 1.0, 0
+	for date, r := range navs.DailyReturns() {
+		if date > start && date <= end {
+			value *= 1 + r
+			days++
+		}
+	}
+	return (value - 1) * 100, days > 0
+}
+
+// GetFundBrinson 以最近两个行业配置报告期为区间计算 Brinson 归因，benchmarks 提供市场基准、行业板块和债券指数行情
+func GetFundBrinson(ctx context.Context, fundCode string, navs eastmoney.FundNetValueList, benchmarks *AttributionBenchmarks) (*BrinsonResult, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	dates := []string{}
+	if err := DB.WithContext(ctx).Model(&FundIndustryProportionDB{}).Where("fund_code = ?", fundCode).
+		Distinct("pub_date").Order("pub_date DESC").Limit(2).Pluck("pub_date", &dates).Error; err != nil {
+		return nil, err
+	}
+	if len(dates) < 2 {
+		return nil, errors.New("fund industry proportion periods not enough")
+	}
+	start, end := dates[1], dates[0]
+	fundReturn, ok := navPeriodReturn(navs, start, end)
+	if !ok {
+		return nil, errors.New("fund nav not cover " + start + " ~ " + end)
+	}
+
+	rows := []FundIndustryProportionDB{}
+	if err := DB.WithContext(ctx).Where("pub_date = ?", start).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	// 行业配置比例为占净值比例，合计即股票仓位
+	fundWeights := map[string]float64{}
+	stockRatio := 0.0
+	for _, r := range rows {
+		if r.FundCode == fundCode {
+			fundWeights[r.Industry] = ParseFundRate(r.Prop)
+			stockRatio += fundWeights[r.Industry] / 100
+		}
+	}
+	benchmarkWeights := map[string]float64{}
+	for _, a := range AggregateFundIndustryAllocation(rows) {
+		benchmarkWeights[a.Industry] = a.AvgProp
+	}
+
+	marketReturn, ok := PeriodReturn(benchmarks.Market, start, end)
+	if !ok {
+		return nil, errors.New("benchmark kline not cover " + start + " ~ " + end)
+	}
+	nonEquityReturn, ok := PeriodReturn(benchmarks.NonEquity, start, end)
+	if !ok && stockRatio < 1 {
+		return nil, errors.New("non-equity benchmark kline not cover " + start + " ~ " + end)
+	}
+	industryReturns := map[string]float64{}
+	for industry := range normalizeWeights(benchmarkWeights) {
+		sum, count := 0.0, 0
+		for _, name := range csrcIndustryBoards[industry] {
+			if r, ok := PeriodReturn(benchmarks.Boards[name], start, end); ok {
+				sum += r
+				count++
+			}
+		}
+		if count > 0 {
+			industryReturns[industry] = sum / float64(count)
+		}
+	}
+
+	result := BrinsonAttribution(fundWeights, benchmarkWeights, industryReturns, marketReturn, fundReturn, stockRatio, nonEquityReturn)
+	result.Start, result.End = start, end
+	return &result, nil
+}
+
+// FundAttribution 基金归因
+type FundAttribution struct {
+	FundCode   string           `json:"fund_code"`
+	Benchmarks []StyleBenchmark `json:"benchmarks"`
+	// 全区间风格分析
+	Overall StyleExposure `json:"overall"`
+	// 滚动窗口风格分析
+	Rolling []StyleExposure `json:"rolling"`
+	// 行业 Brinson 归因，数据不足时为空
+	Brinson *BrinsonResult `json:"brinson"`
+}
+
+// AttributionBenchmarks 基金归因使用的指数行情，多只基金归因时只获取一次
+type AttributionBenchmarks struct {
+	// DefaultStyleBenchmarks 中各指数的日收益率
+	StyleReturns []map[string]float64
+	// Brinson 归因的市场基准行情，获取失败时为空
+	Market eastmoney.KlineList
+	// Brinson 归因的非股票资产基准行情，获取失败时为空
+	NonEquity eastmoney.KlineList
+	// Brinson 归因的行业板块行情，key 为板块名称
+	Boards map[string]eastmoney.KlineList
+}
+
+// GetAttributionBenchmarks 获取最近 days 个交易日的风格指数行情和 Brinson 归因的市场基准行情
+func GetAttributionBenchmarks(ctx context.Context, days int) (*AttributionBenchmarks, error) {
+	result := &AttributionBenchmarks{}
+	for _, b := range DefaultStyleBenchmarks {
+		klines, err := GetIndexPrices(ctx, b.Code, days)
+		if err != nil {
+			return nil, err
+		}
+		returns := map[string]float64{}
+		for _, k := range klines {
+			returns[k.Date] = k.ChangePct / 100
+		}
+		result.StyleReturns = append(result.StyleReturns, returns)
+	}
+	market, err := GetIndexPrices(ctx, IndustryBenchmarkCode, 500)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetAttributionBenchmarks GetIndexPrices market err:" + err.Error())
+	}
+	result.Market = market
+	nonEquity, err := GetIndexPrices(ctx, NonEquityBenchmarkCode, 500)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetAttributionBenchmarks GetIndexPrices non-equity err:" + err.Error())
+	}
+	result.NonEquity = nonEquity
+	result.Boards = queryBrinsonBoards(ctx)
+	return result, nil
+}
+
+// queryBrinsonBoards 获取 csrcIndustryBoards 中全部行业板块的日 K 线，key 为板块名称
+func queryBrinsonBoards(ctx context.Context) map[string]eastmoney.KlineList {
+	result := map[string]eastmoney.KlineList{}
+	boards, err := datacenter.EastMoney.QueryIndustryBoards(ctx)
+	if err != nil {
+		logrus.WithContext(ctx).Error("queryBrinsonBoards QueryIndustryBoards err:" + err.Error())
+		return result
+	}
+	boardCodes := map[string]string{}
+	for _, b := range boards {
+		boardCodes[b.Name] = b.Code
+	}
+	for _, names := range csrcIndustryBoards {
+		for _, name := range names {
+			code, ok := boardCodes[name]
+			if _, loaded := result[name]; !ok || loaded {
+				continue
+			}
+			klines, err := datacenter.EastMoney.QueryKlineBySecID(ctx, eastmoney.IndustrySecID(code), eastmoney.KlineTypeDay, eastmoney.KlineFQNone, 500)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("queryBrinsonBoards QueryKline board:%s err:%v", code, err)
+				continue
+			}
+			result[name] = klines
+		}
+	}
+	return result
+}
+
+// GetFundAttribution 按最近 days 个交易日的净值做风格分析，窗口长度 window，每 step 个交易日滚动一次，并计算行业 Brinson 归因
+func GetFundAttribution(ctx context.Context, fundCode string, days, window, step int) (*FundAttribution, error) {
+	benchmarks, err := GetAttributionBenchmarks(ctx, days)
+	if err != nil {
+		return nil, err
+	}
+	return GetFundAttributionWithBenchmarks(ctx, fundCode, days, window, step, benchmarks)
+}
+
+// GetFundAttributionWithBenchmarks 使用已获取的指数行情计算基金归因，benchmarks 需要覆盖最近 days 个交易日
+func GetFundAttributionWithBenchmarks(ctx context.Context, fundCode string, days, window, step int, benchmarks *AttributionBenchmarks) (*FundAttribution, error) {
+	navs, err := GetFundNavs(ctx, fundCode, days)
+	if err != nil {
+		return nil, err
+	}
+	dates, y, xs := AlignStyleReturns(navs.DailyReturns(), benchmarks.StyleReturns)
+	// 样本数至少是指数个数的数倍才有意义
+	if len(dates) < 4*len(DefaultStyleBenchmarks) {
+		return nil, ErrStyleReturnsNotEnough
+	}
+	if window > len(dates) {
+		window = len(dates)
+	}
+
+	result := &FundAttribution{
+		FundCode:   fundCode,
+		Benchmarks: DefaultStyleBenchmarks,
+		Overall:    newStyleExposure(DefaultStyleBenchmarks, dates, y, xs, 0, len(dates)),
+		Rolling:    RollingStyleAnalysis(DefaultStyleBenchmarks, dates, y, xs, window, step),
+	}
+	if brinson, err := GetFundBrinson(ctx, fundCode, navs, benchmarks); err != nil {
+		logrus.WithContext(ctx).Debug("GetFundAttribution GetFundBrinson err:" + err.Error())
+	} else {
+		result.Brinson = brinson
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveStyleWeights(t *testing.T) {
+	n := 200
+	x1, x2, x3, y := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		x1[i] = math.Sin(float64(i)) / 100
+		x2[i] = math.Cos(float64(i)*0.7) / 100
+		x3[i] = math.Sin(float64(i)*1.3+1) / 200
+		y[i] = 0.6*x1[i] + 0.4*x2[i]
+	}
+	w, r2 := SolveStyleWeights(y, [][]float64{x1, x2, x3})
+	require.InDelta(t, 0.6, w[0], 1e-4)
+	require.InDelta(t, 0.4, w[1], 1e-4)
+	require.InDelta(t, 0.0, w[2], 1e-4)
+	require.InDelta(t, 1.0, r2, 1e-6)
+
+	// 负暴露被约束为 0，权重和为 1
+	for i := 0; i < n; i++ {
+		y[i] = 1.5*x1[i] - 0.5*x2[i]
+	}
+	w, _ = SolveStyleWeights(y, [][]float64{x1, x2, x3})
+	sum := 0.0
+	for _, v := range w {
+		require.GreaterOrEqual(t, v, 0.0)
+		sum += v
+	}
+	require.InDelta(t, 1.0, sum, 1e-9)
+}
+
+func TestRollingStyleAnalysis(t *testing.T) {
+	fund := map[string]float64{"2024-01-02": 0.01, "2024-01-03": -0.02, "2024-01-04": 0.015, "2024-01-05": 0.005, "2024-01-08": 0.01}
+	index := map[string]float64{"2024-01-02": 0.01, "2024-01-03": -0.02, "2024-01-04": 0.015, "2024-01-05": 0.005}
+	dates, y, xs := AlignStyleReturns(fund, []map[string]float64{index})
+	require.Equal(t, []string{"2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"}, dates)
+	require.Equal(t, 0.005, y[3])
+
+	benchmarks := []StyleBenchmark{{Code: "000300", Name: "沪深300"}}
+	rolling := RollingStyleAnalysis(benchmarks, dates, y, xs, 3, 1)
+	require.Len(t, rolling, 2)
+	require.Equal(t, "2024-01-02", rolling[0].Start)
+	require.Equal(t, "2024-01-05", rolling[1].End)
+	require.InDelta(t, 100.0, rolling[1].Weights[0].Weight, 1e-9)
+}
+
+func TestBrinsonAttribution(t *testing.T) {
+	fund := map[string]float64{"金融业": 60, "制造业": 20}
+	benchmark := map[string]float64{"金融业": 20, "制造业": 60}
+	returns := map[string]float64{"金融业": 10}
+	result := BrinsonAttribution(fund, benchmark, returns, 2, 8, 1, 0)
+	// 基准收益 0.25*10 + 0.75*2 = 4
+	require.InDelta(t, 4.0, result.BenchmarkReturn, 1e-9)
+	require.InDelta(t, 4.0, result.ActiveReturn, 1e-9)
+	// 配置效应 0.5*(10-4) + (-0.5)*(2-4) = 4
+	require.InDelta(t, 4.0, result.Allocation, 1e-9)
+	require.InDelta(t, result.ActiveReturn, result.Allocation+result.Selection, 1e-9)
+	require.Equal(t, "金融业", result.Segments[0].Industry)
+	require.InDelta(t, 75.0, result.Segments[0].FundWeight, 1e-9)
+
+	// 股票仓位 80%，非股票部分收益 1：基准收益 0.8*4 + 0.2*1 = 3.4
+	result = BrinsonAttribution(fund, benchmark, returns, 2, 8, 0.8, 1)
+	require.InDelta(t, 0.2, result.NonEquity, 1e-9)
+	require.InDelta(t, 3.4, result.BenchmarkReturn, 1e-9)
+	require.InDelta(t, 3.2, result.Allocation, 1e-9)
+	// 选股效应 8 - 0.8*8 - 0.2 = 1.4
+	require.InDelta(t, 1.4, result.Selection, 1e-9)
+	require.InDelta(t, result.ActiveReturn, result.Allocation+result.Selection, 1e-9)
+}
+
+func TestPeriodReturn(t *testing.T) {
+	klines := eastmoney.KlineList{
+		{Date: "2024-03-28", Close: 10},
+		{Date: "2024-03-29", Close: 11},
+		{Date: "2024-06-28", Close: 12.1},
+		{Date: "2024-07-01", Close: 13},
+	}
+	r, ok := PeriodReturn(klines, "2024-03-31", "2024-06-30")
+	require.True(t, ok)
+	require.InDelta(t, 10.0, r, 1e-9)
+	_, ok = PeriodReturn(klines, "2024-03-31", "2024-09-30")
+	require.False(t, ok)
+}
@@ -0,0 +1,742 @@
+// 基金选基规则回测：定期调仓到满足规则的前 N 只基金，计算收益曲线、年化收益、回撤、换手及与基准的比较
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/goutils"
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// 选基规则
+const (
+	// BacktestRule4433 4433法则
+	BacktestRule4433 = "4433"
+	// BacktestRuleFilter 按 ParamFundListFilter 筛选
+	BacktestRuleFilter = "filter"
+)
+
+// 调仓周期
+const (
+	RebalanceMonthly   = "monthly"
+	RebalanceQuarterly = "quarterly"
+	RebalanceAnnual    = "annual"
+)
+
+// ErrInvalidBacktestParams 回测参数错误
+var ErrInvalidBacktestParams = errors.New("invalid backtest params")
+
+// 计算排名使用的交易日数
+const (
+	tradingDaysMonth3 = 63
+	tradingDaysMonth6 = 125
+	tradingDaysYear1  = 250
+	tradingDaysYear2  = 500
+	tradingDaysYear3  = 750
+	tradingDaysYear5  = 1250
+)
+
+// RedemptionFee 赎回费率档位：持有天数小于 MaxDays 时按 Rate（%）收取，MaxDays 为 0 表示不限天数
+type RedemptionFee struct {
+	MaxDays int     `json:"max_days"`
+	Rate    float64 `json:"rate"`
+}
+
+// DefaultRedemptionFees 常见的股票型、混合型基金赎回费率
+var DefaultRedemptionFees = []RedemptionFee{
+	{MaxDays: 7, Rate: 1.5},
+	{MaxDays: 365, Rate: 0.5},
+	{MaxDays: 730, Rate: 0.25},
+	{MaxDays: 0, Rate: 0},
+}
+
+// daysBetween 两个日期之间的自然日天数，日期无法解析时返回 0
+func daysBetween(from, to string) int {
+	f, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return 0
+	}
+	t, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return 0
+	}
+	return int(t.Sub(f).Hours() / 24)
+}
+
+// redemptionRate 按持有天数返回赎回费率（小数）
+func redemptionRate(fees []RedemptionFee, days int) float64 {
+	for _, f := range fees {
+		if f.MaxDays == 0 || days < f.MaxDays {
+			return f.Rate / 100
+		}
+	}
+	return 0
+}
+
+// FundBacktestParams 回测参数
+type FundBacktestParams struct {
+	// 选基规则：4433/filter
+	Rule string `json:"rule"`
+	// 规则为 filter 时的筛选参数，排名比例和成立年限按调仓日的净值计算，规模使用调仓日已公布的最近一期规模，
+	// 类型使用最新数据，基金经理年限和波动率等指标不参与回测
+	Filter ParamFundListFilter `json:"filter"`
+	// 基金池，为空时使用数据库中的全部股票型和混合型基金
+	Codes []string `json:"codes"`
+	// 每次调仓持有的基金数量
+	TopN int `json:"top_n"`
+	// 调仓周期：monthly/quarterly
+	Rebalance string `json:"rebalance"`
+	// 回测开始日期
+	Start string `json:"start"`
+	// 回测结束日期，为空时到最新交易日
+	End string `json:"end"`
+	// 初始资金
+	InitialCash float64 `json:"initial_cash"`
+	// 基准指数代码
+	Benchmark string `json:"benchmark"`
+	// 赎回费率档位，为空时使用 DefaultRedemptionFees
+	RedemptionFees []RedemptionFee `json:"redemption_fees"`
+}
+
+// Validate 检查参数
+func (p FundBacktestParams) Validate() error {
+	switch {
+	case p.Rule != BacktestRule4433 && p.Rule != BacktestRuleFilter:
+		return fmt.Errorf("%w: rule %s", ErrInvalidBacktestParams, p.Rule)
+	case p.Rebalance != RebalanceMonthly && p.Rebalance != RebalanceQuarterly:
+		return fmt.Errorf("%w: rebalance %s", ErrInvalidBacktestParams, p.Rebalance)
+	case p.TopN <= 0:
+		return fmt.Errorf("%w: top_n must be positive", ErrInvalidBacktestParams)
+	case p.InitialCash <= 0:
+		return fmt.Errorf("%w: initial_cash must be positive", ErrInvalidBacktestParams)
+	case p.Benchmark == "":
+		return fmt.Errorf("%w: benchmark is required", ErrInvalidBacktestParams)
+	}
+	if _, err := time.Parse("2006-01-02", p.Start); err != nil {
+		return fmt.Errorf("%w: start %s", ErrInvalidBacktestParams, p.Start)
+	}
+	if p.End != "" && p.End < p.Start {
+		return fmt.Errorf("%w: end before start", ErrInvalidBacktestParams)
+	}
+	return nil
+}
+
+// BacktestFund 参与回测的基金及其复权净值
+type BacktestFund struct {
+	Info *Fund
+	// 按日期升序的交易日
+	Dates []string
+	// 按日增长率复合得到的复权净值，首日为 1
+	Values []float64
+	// 各报告期的规模，按日期升序，规模条件按调仓日已公布的规模判断
+	Scales []FundScale
+}
+
+// NewBacktestFund 由历史净值构造复权净值序列
+func NewBacktestFund(info *Fund, navs eastmoney.FundNetValueList) *BacktestFund {
+	returns := navs.DailyReturns()
+	f := &BacktestFund{Info: info, Scales: info.ScaleHistory}
+	for date := range returns {
+		f.Dates = append(f.Dates, date)
+	}
+	sort.Strings(f.Dates)
+	value := 1.0
+	for i, date := range f.Dates {
+		if i > 0 {
+			value *= 1 + returns[date]
+		}
+		f.Values = append(f.Values, value)
+	}
+	return f
+}
+
+// indexAt 返回 date 当日或之前最近一个交易日的下标，没有时返回 -1
+func (f *BacktestFund) indexAt(date string) int {
+	return sort.SearchStrings(f.Dates, date+"~") - 1
+}
+
+// valueAt 返回 date 当日或之前最近的复权净值
+func (f *BacktestFund) valueAt(date string) (float64, bool) {
+	i := f.indexAt(date)
+	if i < 0 {
+		return 0, false
+	}
+	return f.Values[i], true
+}
+
+// trailingReturn 截至 date 最近 days 个交易日的收益率（%）
+func (f *BacktestFund) trailingReturn(date string, days int) (float64, bool) {
+	i := f.indexAt(date)
+	if i-days < 0 {
+		return 0, false
+	}
+	return (f.Values[i]/f.Values[i-days] - 1) * 100, true
+}
+
+// thisYearReturn 截至 date 的今年以来收益率（%）
+func (f *BacktestFund) thisYearReturn(date string) (float64, bool) {
+	i := f.indexAt(date)
+	j := f.indexAt(date[:4] + "-01-01")
+	if i < 0 || j < 0 {
+		return 0, false
+	}
+	return (f.Values[i]/f.Values[j] - 1) * 100, true
+}
+
+// backtestRanks 调仓日各基金的收益率排名比例（%），排名越靠前越小
+type backtestRanks map[string]map[string]float64
+
+// 排名指标
+const (
+	rankMonth3   = "month_3"
+	rankMonth6   = "month_6"
+	rankYear1    = "year_1"
+	rankYear2    = "year_2"
+	rankYear3    = "year_3"
+	rankYear5    = "year_5"
+	rankThisYear = "this_year"
+)
+
+// computeBacktestRanks 计算调仓日基金池内的收益率排名比例，同时返回近一年收益率用于排序
+func computeBacktestRanks(funds []*BacktestFund, date string) (backtestRanks, map[string]float64) {
+	windows := map[string]int{
+		rankMonth3: tradingDaysMonth3,
+		rankMonth6: tradingDaysMonth6,
+		rankYear1:  tradingDaysYear1,
+		rankYear2:  tradingDaysYear2,
+		rankYear3:  tradingDaysYear3,
+		rankYear5:  tradingDaysYear5,
+	}
+	returns := map[string]map[string]float64{}
+	for name := range windows {
+		returns[name] = map[string]float64{}
+	}
+	returns[rankThisYear] = map[string]float64{}
+	for _, f := range funds {
+		// 净值停止更新超过一个月的基金不参与
+		i := f.indexAt(date)
+		if i < 0 || daysBetween(f.Dates[i], date) > 30 {
+			continue
+		}
+		for name, days := range windows {
+			if r, ok := f.trailingReturn(date, days); ok {
+				returns[name][f.Info.Code] = r
+			}
+		}
+		if r, ok := f.thisYearReturn(date); ok {
+			returns[rankThisYear][f.Info.Code] = r
+		}
+	}
+
+	ranks := backtestRanks{}
+	for name, values := range returns {
+		codes := make([]string, 0, len(values))
+		for code := range values {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool {
+			if values[codes[i]] != values[codes[j]] {
+				return values[codes[i]] > values[codes[j]]
+			}
+			return codes[i] < codes[j]
+		})
+		for i, code := range codes {
+			if ranks[code] == nil {
+				ranks[code] = map[string]float64{}
+			}
+			ranks[code][name] = float64(i+1) / float64(len(codes)) * 100
+		}
+	}
+	return ranks, returns[rankYear1]
+}
+
+// passBacktestRule 判断基金在调仓日是否满足规则，缺少排名数据时不满足
+// 规模使用调仓日已公布的最近一期规模，没有当时的规模数据时不使用规模条件，scaleUnknown 返回 true
+func passBacktestRule(p FundBacktestParams, f *BacktestFund, ranks map[string]float64, date string) (pass bool, scaleUnknown bool) {
+	rankLE := func(name string, max float64) bool {
+		r, ok := ranks[name]
+		return ok && r <= max
+	}
+	scale, scaleKnown := FundScaleAt(f.Scales, date)
+	if p.Rule == BacktestRule4433 {
+		quarter, oneThird := 25.0, 100.0/3
+		return (!scaleKnown || scale >= 10*100000000) &&
+			rankLE(rankYear1, quarter) && rankLE(rankYear2, quarter) && rankLE(rankYear3, quarter) &&
+			rankLE(rankYear5, quarter) && rankLE(rankThisYear, quarter) &&
+			rankLE(rankMonth6, oneThird) && rankLE(rankMonth3, oneThird), !scaleKnown
+	}
+
+	filter := p.Filter
+	useScale := filter.MinScale > 0 || filter.MaxScale > 0
+	scaleUnknown = useScale && !scaleKnown
+	switch {
+	case len(filter.Types) > 0 && !goutils.IsStrInSlice(f.Info.Type, filter.Types):
+		return false, scaleUnknown
+	case scaleKnown && filter.MinScale > 0 && scale < filter.MinScale*100000000:
+		return false, scaleUnknown
+	case scaleKnown && filter.MaxScale > 0 && scale > filter.MaxScale*100000000:
+		return false, scaleUnknown
+	case filter.MinEstabYears > 0 && daysBetween(f.Info.EstablishedDate, date) > 0 &&
+		daysBetween(f.Info.EstablishedDate, date) < int(filter.MinEstabYears*365):
+		// 与 FundList.Filter 一致，成立日期未知时不排除
+		return false, scaleUnknown
+	case filter.Year1RankRatio > 0 && !rankLE(rankYear1, filter.Year1RankRatio):
+		return false, scaleUnknown
+	case filter.ThisYear235RankRatio > 0 && !(rankLE(rankThisYear, filter.ThisYear235RankRatio) &&
+		rankLE(rankYear2, filter.ThisYear235RankRatio) && rankLE(rankYear3, filter.ThisYear235RankRatio) &&
+		rankLE(rankYear5, filter.ThisYear235RankRatio)):
+		return false, scaleUnknown
+	case filter.Month6RankRatio > 0 && !rankLE(rankMonth6, filter.Month6RankRatio):
+		return false, scaleUnknown
+	case filter.Month3RankRatio > 0 && !rankLE(rankMonth3, filter.Month3RankRatio):
+		return false, scaleUnknown
+	}
+	return true, scaleUnknown
+}
+
+// SelectBacktestFunds 返回调仓日满足规则的基金，按近一年收益率降序取前 TopN 只，
+// scaleUnknown 为没有当时规模数据、未使用规模条件判断的基金数量
+func SelectBacktestFunds(p FundBacktestParams, funds []*BacktestFund, date string) (selected []string, scaleUnknown int) {
+	ranks, year1 := computeBacktestRanks(funds, date)
+	selected = []string{}
+	for _, f := range funds {
+		pass, unknown := passBacktestRule(p, f, ranks[f.Info.Code], date)
+		if unknown {
+			scaleUnknown++
+		}
+		if pass {
+			selected = append(selected, f.Info.Code)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return year1[selected[i]] > year1[selected[j]]
+	})
+	if len(selected) > p.TopN {
+		selected = selected[:p.TopN]
+	}
+	sort.Strings(selected)
+	return selected, scaleUnknown
+}
+
+// BacktestPoint 收益曲线上的一个点
+type BacktestPoint struct {
+	Date      string  `json:"date"`
+	Value     float64 `json:"value"`
+	Benchmark float64 `json:"benchmark"`
+}
+
+// BacktestRebalance 一次调仓
+type BacktestRebalance struct {
+	Date  string   `json:"date"`
+	Funds []string `json:"funds"`
+	// 换手率（%）：买卖金额之和的一半占组合市值的比例
+	Turnover float64 `json:"turnover"`
+	// 申购费和赎回费
+	Fee float64 `json:"fee"`
+}
+
+// BacktestMetrics 收益曲线的统计指标，单位均为 %
+type BacktestMetrics struct {
+	TotalReturn float64 `json:"total_return"`
+	CAGR        float64 `json:"cagr"`
+	MaxDrawdown float64 `json:"max_drawdown"`
+	// 年化波动率
+	Volatility float64 `json:"volatility"`
+}
+
+// NewBacktestMetrics 计算按日期升序的净值序列的统计指标
+func NewBacktestMetrics(dates []string, values []float64) BacktestMetrics {
+	m := BacktestMetrics{}
+	if len(values) < 2 || values[0] <= 0 {
+		return m
+	}
+	last := values[len(values)-1]
+	m.TotalReturn = (last/values[0] - 1) * 100
+	if days := daysBetween(dates[0], dates[len(dates)-1]); days > 0 && last > 0 {
+		m.CAGR = (math.Pow(last/values[0], 365/float64(days)) - 1) * 100
+	}
+	peak := values[0]
+	returns := []float64{}
+	for i, v := range values {
+		if v > peak {
+			peak = v
+		}
+		if dd := (1 - v/peak) * 100; dd > m.MaxDrawdown {
+			m.MaxDrawdown = dd
+		}
+		if i > 0 && values[i-1] > 0 {
+			returns = append(returns, v/values[i-1]-1)
+		}
+	}
+	if len(returns) > 1 {
+		mean := 0.0
+		for _, r := range returns {
+			mean += r
+		}
+		mean /= float64(len(returns))
+		variance := 0.0
+		for _, r := range returns {
+			variance += (r - mean) * (r - mean)
+		}
+		m.Volatility = math.Sqrt(variance/float64(len(returns)-1)*250) * 100
+	}
+	return m
+}
+
+// FundBacktestResult 回测结果
+type FundBacktestResult struct {
+	Params    FundBacktestParams `json:"params"`
+	Start     string             `json:"start"`
+	End       string             `json:"end"`
+	Strategy  BacktestMetrics    `json:"strategy"`
+	Benchmark BacktestMetrics    `json:"benchmark"`
+	// 年化超额收益（%）
+	ExcessCAGR float64 `json:"excess_cagr"`
+	// 年化换手率（%）
+	Turnover float64 `json:"turnover"`
+	// 累计交易费用
+	Fees       float64             `json:"fees"`
+	Series     []BacktestPoint     `json:"series"`
+	Rebalances []BacktestRebalance `json:"rebalances"`
+	// 各次调仓中没有当时规模数据、未使用规模条件判断的基金次数
+	ScaleUnknown int `json:"scale_unknown"`
+	// 回测结果的局限性说明
+	Limitations []string `json:"limitations"`
+}
+
+// backtestLot 一笔申购的份额
+type backtestLot struct {
+	units float64
+	date  string
+}
+
+// isRebalanceDay 判断相邻两个交易日之间是否进入新的调仓周期
+func isRebalanceDay(rebalance, prev, date string) bool {
+	if prev == "" {
+		return true
+	}
+	if rebalance == RebalanceQuarterly {
+		quarter := func(d string) string {
+			month := (int(d[5]-'0')*10 + int(d[6]-'0') - 1) / 3
+			return d[:4] + string(rune('1'+month))
+		}
+		return quarter(prev) != quarter(date)
+	}
+	return prev[:7] != date[:7]
+}
+
+// RunFundBacktest 在基准指数的交易日上按规则定期调仓回测
+// 申购费按 Fund.Rate 收取，赎回费按份额的持有天数分档收取，净值缺失时沿用最近的净值
+func RunFundBacktest(p FundBacktestParams, funds []*BacktestFund, benchmark eastmoney.KlineList) (*FundBacktestResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	fees := p.RedemptionFees
+	if len(fees) == 0 {
+		fees = DefaultRedemptionFees
+	}
+	fundMap := map[string]*BacktestFund{}
+	for _, f := range funds {
+		fundMap[f.Info.Code] = f
+	}
+	dates, closes := []string{}, []float64{}
+	for _, k := range benchmark {
+		if k.Date >= p.Start && (p.End == "" || k.Date <= p.End) && k.Close > 0 {
+			dates = append(dates, k.Date)
+			closes = append(closes, k.Close)
+		}
+	}
+	if len(dates) < 2 {
+		return nil, fmt.Errorf("%w: no benchmark data between %s and %s", ErrInvalidBacktestParams, p.Start, p.End)
+	}
+
+	result := &FundBacktestResult{
+		Params:     p,
+		Start:      dates[0],
+		End:        dates[len(dates)-1],
+		Series:     []BacktestPoint{},
+		Rebalances: []BacktestRebalance{},
+	}
+	cash := p.InitialCash
+	holdings := map[string][]backtestLot{}
+	units := func(code string) float64 {
+		sum := 0.0
+		for _, l := range holdings[code] {
+			sum += l.units
+		}
+		return sum
+	}
+	value := func(date string) float64 {
+		total := cash
+		for code := range holdings {
+			if nav, ok := fundMap[code].valueAt(date); ok {
+				total += units(code) * nav
+			}
+		}
+		return total
+	}
+	// sell 按先进先出赎回 amount 市值的份额，返回到账金额和赎回费
+	sell := func(code, date string, amount float64) (float64, float64) {
+		nav, ok := fundMap[code].valueAt(date)
+		if !ok || nav <= 0 {
+			return 0, 0
+		}
+		remain := amount / nav
+		received, fee := 0.0, 0.0
+		lots := holdings[code]
+		for len(lots) > 0 && remain > 1e-12 {
+			u := math.Min(lots[0].units, remain)
+			gross := u * nav
+			f := gross * redemptionRate(fees, daysBetween(lots[0].date, date))
+			received += gross - f
+			fee += f
+			remain -= u
+			lots[0].units -= u
+			if lots[0].units <= 1e-12 {
+				lots = lots[1:]
+			}
+		}
+		if len(lots) == 0 {
+			delete(holdings, code)
+		} else {
+			holdings[code] = lots
+		}
+		return received, fee
+	}
+
+	prev := ""
+	turnoverSum := 0.0
+	for i, date := range dates {
+		if isRebalanceDay(p.Rebalance, prev, date) {
+			targets, scaleUnknown := SelectBacktestFunds(p, funds, date)
+			result.ScaleUnknown += scaleUnknown
+			total := value(date)
+			rb := BacktestRebalance{Date: date, Funds: targets}
+			traded := 0.0
+			target := 0.0
+			if len(targets) > 0 {
+				target = total / float64(len(targets))
+			}
+			// 先卖出不在目标中的和超配的基金
+			for code := range holdings {
+				nav, _ := fundMap[code].valueAt(date)
+				current := units(code) * nav
+				sellAmount := current
+				if goutils.IsStrInSlice(code, targets) {
+					sellAmount = current - target
+				}
+				if sellAmount > 1e-6 {
+					received, fee := sell(code, date, sellAmount)
+					cash += received
+					rb.Fee += fee
+					traded += sellAmount
+				}
+			}
+			// 再用现金买入低配的基金，申购费按外扣法计算
+			for _, code := range targets {
+				nav, ok := fundMap[code].valueAt(date)
+				if !ok || nav <= 0 {
+					continue
+				}
+				buyAmount := math.Min(target-units(code)*nav, cash)
+				if buyAmount <= 1e-6 {
+					continue
+				}
+				rate := ParseFundRate(fundMap[code].Info.Rate) / 100
+				net := buyAmount / (1 + rate)
+				holdings[code] = append(holdings[code], backtestLot{units: net / nav, date: date})
+				cash -= buyAmount
+				rb.Fee += buyAmount - net
+				traded += buyAmount
+			}
+			if total > 0 {
+				rb.Turnover = traded / 2 / total * 100
+			}
+			turnoverSum += rb.Turnover
+			result.Fees += rb.Fee
+			result.Rebalances = append(result.Rebalances, rb)
+		}
+		result.Series = append(result.Series, BacktestPoint{
+			Date:      date,
+			Value:     value(date),
+			Benchmark: p.InitialCash * closes[i] / closes[0],
+		})
+		prev = date
+	}
+
+	values, benchValues := make([]float64, len(result.Series)), make([]float64, len(result.Series))
+	for i, pt := range result.Series {
+		values[i], benchValues[i] = pt.Value, pt.Benchmark
+	}
+	result.Strategy = NewBacktestMetrics(dates, values)
+	result.Benchmark = NewBacktestMetrics(dates, benchValues)
+	result.ExcessCAGR = result.Strategy.CAGR - result.Benchmark.CAGR
+	if days := daysBetween(result.Start, result.End); days > 0 {
+		result.Turnover = turnoverSum / (float64(days) / 365)
+	}
+	result.Limitations = backtestLimitations(p, result.ScaleUnknown)
+	return result, nil
+}
+
+// backtestLimitations 回测结果的局限性说明
+func backtestLimitations(p FundBacktestParams, scaleUnknown int) []string {
+	limitations := []string{}
+	if len(p.Codes) == 0 {
+		limitations = append(limitations, "基金池为数据库中当前存续的基金，回测期间已清盘或转型的基金不在基金池中，结果存在幸存者偏差")
+	}
+	if scaleUnknown > 0 {
+		limitations = append(limitations, fmt.Sprintf("有 %d 次基金筛选缺少调仓日已公布的规模数据，未使用规模条件", scaleUnknown))
+	}
+	if p.Rule == BacktestRuleFilter && len(p.Filter.Types) > 0 {
+		limitations = append(limitations, "基金类型使用最新数据")
+	}
+	return limitations
+}
+
+// backtestFundPool 回测基金池：指定代码时使用数据库或接口中的基金信息，否则使用数据库中的全部股票型和混合型基金
+func backtestFundPool(ctx context.Context, codes []string) ([]*Fund, error) {
+	funds := []*Fund{}
+	found := map[string]bool{}
+	if DB != nil {
+		fundDBs := []FundDB{}
+		query := DB.Model(&FundDB{})
+		if len(codes) > 0 {
+			query = query.Where("code IN ?", codes)
+		} else {
+			query = query.Where("type LIKE ? OR type LIKE ?", "%股票%", "%混合%")
+		}
+		if err := query.Find(&fundDBs).Error; err != nil {
+			return nil, err
+		}
+		funds = append(funds, ToFunds(fundDBs)...)
+		for i := range fundDBs {
+			found[fundDBs[i].Code] = true
+		}
+	} else if len(codes) == 0 {
+		return nil, errors.New("database not initialized, fund codes are required")
+	}
+	for _, code := range codes {
+		if found[code] {
+			continue
+		}
+		efund, err := datacenter.EastMoney.QueryFundInfo(ctx, code)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("backtestFundPool QueryFundInfo code:%s err:%v", code, err)
+			continue
+		}
+		funds = append(funds, NewFund(ctx, efund))
+	}
+	return funds, nil
+}
+
+// backtestNavDays 覆盖回测区间及其前五年排名窗口需要的交易日数
+func backtestNavDays(start string) int {
+	return daysBetween(start, time.Now().Format("2006-01-02"))*250/365 + tradingDaysYear5 + 20
+}
+
+// LoadFundBacktestData 加载回测需要的基金净值和基准行情，fetch 为 true 时从接口获取并保存净值，否则只使用数据库中的净值
+func LoadFundBacktestData(ctx context.Context, p FundBacktestParams, fetch bool) ([]*BacktestFund, eastmoney.KlineList, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	pool, err := backtestFundPool(ctx, p.Codes)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 数据库中保存的历史规模，接口获取的基金使用接口返回的历史规模
+	codes := make([]string, 0, len(pool))
+	for _, fund := range pool {
+		codes = append(codes, fund.Code)
+	}
+	scales := map[string][]FundScale{}
+	if DB != nil {
+		if scales, err = LoadFundScales(ctx, codes); err != nil {
+			logrus.WithContext(ctx).Errorf("LoadFundBacktestData LoadFundScales err:%v", err)
+			scales = map[string][]FundScale{}
+		}
+	}
+	days := backtestNavDays(p.Start)
+	benchmark, err := GetIndexPrices(ctx, p.Benchmark, days)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 限制并发，避免被接口限流
+	semaphore := make(chan struct{}, 4)
+	list := make([]*BacktestFund, len(pool))
+	var wg sync.WaitGroup
+	for i, fund := range pool {
+		wg.Add(1)
+		go func(i int, fund *Fund) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+			}()
+			semaphore <- struct{}{}
+			var navs eastmoney.FundNetValueList
+			var err error
+			if fetch {
+				navs, err = GetFundNavs(ctx, fund.Code, days)
+			} else {
+				navs, err = LoadFundNavs(ctx, fund.Code, days)
+			}
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("LoadFundBacktestData load navs code:%s err:%v", fund.Code, err)
+				return
+			}
+			if len(navs) > 0 {
+				list[i] = NewBacktestFund(fund, navs)
+				if s, ok := scales[fund.Code]; ok && len(s) >= len(list[i].Scales) {
+					list[i].Scales = s
+				}
+			}
+		}(i, fund)
+	}
+	wg.Wait()
+
+	funds := []*BacktestFund{}
+	for _, f := range list {
+		if f != nil {
+			funds = append(funds, f)
+		}
+	}
+	if len(funds) == 0 {
+		return nil, nil, errors.New("no fund nav for backtest")
+	}
+	return funds, benchmark, nil
+}
+
+// BacktestFunds 加载数据并运行回测
+func BacktestFunds(ctx context.Context, p FundBacktestParams, fetch bool) (*FundBacktestResult, error) {
+	funds, benchmark, err := LoadFundBacktestData(ctx, p, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return RunFundBacktest(p, funds, benchmark)
+}
+
+// SyncFundNavs 获取并保存数据库中全部股票型和混合型基金最近 days 个交易日的净值，返回成功的基金数量
+func SyncFundNavs(ctx context.Context, days int) (int, error) {
+	pool, err := backtestFundPool(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, fund := range pool {
+		if _, err := GetFundNavs(ctx, fund.Code, days); err != nil {
+			logrus.WithContext(ctx).Errorf("SyncFundNavs code:%s err:%v", fund.Code, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
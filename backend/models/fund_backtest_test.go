@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+// mockBacktestData 生成工作日净值：每只基金按固定日增长率增长
+func mockBacktestData(days int, rates map[string]float64) ([]*BacktestFund, eastmoney.KlineList) {
+	dates := []string{}
+	for d := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC); len(dates) < days; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+	}
+	funds := []*BacktestFund{}
+	for code, rate := range rates {
+		navs := eastmoney.FundNetValueList{}
+		for i := len(dates) - 1; i >= 0; i-- {
+			navs = append(navs, eastmoney.FundNetValue{Date: dates[i], GrowthRate: fmt.Sprint(rate * 100)})
+		}
+		funds = append(funds, NewBacktestFund(&Fund{Code: code, Rate: "0.15%", NetAssetsScale: 20e8}, navs))
+	}
+	benchmark := eastmoney.KlineList{}
+	for i, date := range dates {
+		benchmark = append(benchmark, eastmoney.Kline{Date: date, Close: 1000 + float64(i)})
+	}
+	return funds, benchmark
+}
+
+func TestRunFundBacktest(t *testing.T) {
+	funds, benchmark := mockBacktestData(600, map[string]float64{"A": 0.001, "B": 0.0005, "C": -0.0001})
+	p := FundBacktestParams{
+		Rule:        BacktestRuleFilter,
+		Filter:      ParamFundListFilter{Year1RankRatio: 34},
+		TopN:        2,
+		Rebalance:   RebalanceMonthly,
+		Start:       "2020-01-01",
+		InitialCash: 100000,
+		Benchmark:   "000300",
+	}
+	result, err := RunFundBacktest(p, funds, benchmark)
+	require.Nil(t, err)
+	require.Equal(t, []string{"A"}, result.Rebalances[0].Funds)
+	require.Equal(t, "2020-01-01", result.Start)
+	// 首次申购费
+	require.InDelta(t, 100000-100000/1.0015, result.Fees, 1e-6)
+	// 持仓不变时后续调仓没有交易
+	require.Equal(t, 0.0, result.Rebalances[1].Turnover)
+	require.InDelta(t, 50.0, result.Rebalances[0].Turnover, 1e-9)
+	require.Greater(t, result.Strategy.TotalReturn, 0.0)
+	require.Equal(t, 0.0, result.Strategy.MaxDrawdown)
+	require.Equal(t, 100000.0, result.Series[0].Benchmark)
+
+	// 规则不满足时空仓
+	p.Filter.Year1RankRatio = 1
+	result, err = RunFundBacktest(p, funds, benchmark)
+	require.Nil(t, err)
+	require.Empty(t, result.Rebalances[0].Funds)
+	require.Equal(t, 100000.0, result.Series[len(result.Series)-1].Value)
+
+	p.Rule = "unknown"
+	_, err = RunFundBacktest(p, funds, benchmark)
+	require.ErrorIs(t, err, ErrInvalidBacktestParams)
+}
+
+func TestRedemptionRate(t *testing.T) {
+	require.Equal(t, 0.015, redemptionRate(DefaultRedemptionFees, 3))
+	require.Equal(t, 0.005, redemptionRate(DefaultRedemptionFees, 30))
+	require.Equal(t, 0.0, redemptionRate(DefaultRedemptionFees, 800))
+}
+
+func TestIsRebalanceDay(t *testing.T) {
+	require.True(t, isRebalanceDay(RebalanceMonthly, "", "2024-01-02"))
+	require.True(t, isRebalanceDay(RebalanceMonthly, "2024-01-31", "2024-02-01"))
+	require.False(t, isRebalanceDay(RebalanceQuarterly, "2024-01-31", "2024-02-01"))
+	require.True(t, isRebalanceDay(RebalanceQuarterly, "2024-03-29", "2024-04-01"))
+	require.True(t, isRebalanceDay(RebalanceQuarterly, "2023-12-29", "2024-01-02"))
+}
+
+func TestNewBacktestMetrics(t *testing.T) {
+	m := NewBacktestMetrics([]string{"2023-01-01", "2023-06-01", "2023-09-01", "2024-01-01"}, []float64{100, 120, 90, 108})
+	require.InDelta(t, 8.0, m.TotalReturn, 1e-9)
+	require.InDelta(t, 8.0, m.CAGR, 1e-9)
+	require.InDelta(t, 25.0, m.MaxDrawdown, 1e-9)
+}
+
+func TestFundScaleAt(t *testing.T) {
+	scales := []FundScale{{Date: "2023-12-31", NetAssets: 5e8}, {Date: "2024-03-31", NetAssets: 12e8}}
+	_, ok := FundScaleAt(scales, "2024-01-15")
+	require.False(t, ok)
+	// 报告期结束后规模尚未公布时使用上一期规模
+	scale, ok := FundScaleAt(scales, "2024-04-10")
+	require.True(t, ok)
+	require.Equal(t, 5e8, scale)
+	scale, ok = FundScaleAt(scales, "2024-05-10")
+	require.True(t, ok)
+	require.Equal(t, 12e8, scale)
+}
+
+func TestRunFundBacktestHistoricalScale(t *testing.T) {
+	funds, benchmark := mockBacktestData(600, map[string]float64{"A": 0.001, "B": 0.0005})
+	for _, f := range funds {
+		if f.Info.Code == "A" {
+			// 当前规模满足条件，但回测开始时的规模不满足
+			f.Scales = []FundScale{{Date: "2019-09-30", NetAssets: 1e8}, {Date: "2020-06-30", NetAssets: 20e8}}
+		}
+	}
+	p := FundBacktestParams{
+		Rule:        BacktestRuleFilter,
+		Filter:      ParamFundListFilter{MinScale: 10},
+		TopN:        1,
+		Rebalance:   RebalanceQuarterly,
+		Start:       "2020-01-01",
+		InitialCash: 100000,
+		Benchmark:   "000300",
+	}
+	result, err := RunFundBacktest(p, funds, benchmark)
+	require.Nil(t, err)
+	// B 没有历史规模，不使用规模条件
+	require.Equal(t, []string{"B"}, result.Rebalances[0].Funds)
+	require.Equal(t, "2020-07-01", result.Rebalances[2].Date)
+	require.Equal(t, []string{"B"}, result.Rebalances[2].Funds)
+	require.Equal(t, "2020-10-01", result.Rebalances[3].Date)
+	require.Equal(t, []string{"A"}, result.Rebalances[3].Funds)
+	require.Equal(t, len(result.Rebalances), result.ScaleUnknown)
+	require.Len(t, result.Limitations, 2)
+}
@@ -0,0 +1,543 @@
+// 基金定投模拟：按固定频率扣款，支持估值百分位加权和目标止盈，计算现金流、份额、成本、XIRR 和最大回撤，并与一次性投入比较
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// 定投频率
+const (
+	DCAWeekly   = "weekly"
+	DCABiweekly = "biweekly"
+	DCAMonthly  = "monthly"
+)
+
+// 定投流水类型
+const (
+	// DCAFlowBuy 定投扣款
+	DCAFlowBuy = "buy"
+	// DCAFlowSkip 估值过高暂停扣款
+	DCAFlowSkip = "skip"
+	// DCAFlowDividend 现金分红
+	DCAFlowDividend = "dividend"
+	// DCAFlowReinvest 红利再投资
+	DCAFlowReinvest = "reinvest"
+	// DCAFlowTakeProfit 止盈赎回
+	DCAFlowTakeProfit = "take_profit"
+)
+
+// ErrInvalidDCAParams 定投参数错误
+var ErrInvalidDCAParams = errors.New("invalid dca params")
+
+// DCAValuationWeight 估值加权档位：PE 百分位小于 MaxPercentile 时按基础金额的 Multiple 倍扣款，MaxPercentile 为 0 表示不限
+type DCAValuationWeight struct {
+	MaxPercentile float64 `json:"max_percentile"`
+	Multiple      float64 `json:"multiple"`
+}
+
+// DefaultDCAValuationWeights 低估多投、高估少投，极度高估时暂停扣款
+var DefaultDCAValuationWeights = []DCAValuationWeight{
+	{MaxPercentile: 20, Multiple: 2},
+	{MaxPercentile: 40, Multiple: 1.5},
+	{MaxPercentile: 60, Multiple: 1},
+	{MaxPercentile: 80, Multiple: 0.5},
+	{MaxPercentile: 0, Multiple: 0},
+}
+
+// dcaMultiple 按估值百分位返回扣款倍数
+func dcaMultiple(weights []DCAValuationWeight, percentile float64) float64 {
+	for _, w := range weights {
+		if w.MaxPercentile == 0 || percentile < w.MaxPercentile {
+			return w.Multiple
+		}
+	}
+	return 1
+}
+
+// FundDCAParams 定投参数
+type FundDCAParams struct {
+	// 基金代码
+	Code string `json:"code"`
+	// 开始日期
+	Start string `json:"start"`
+	// 结束日期，为空时到最新净值日期
+	End string `json:"end"`
+	// 每期基础扣款金额
+	Amount float64 `json:"amount"`
+	// 扣款频率：weekly/biweekly/monthly，计划扣款日不是交易日时顺延到下一个交易日
+	Frequency string `json:"frequency"`
+	// 是否按跟踪指数的 PE 百分位调整每期扣款金额
+	SmartValuation bool `json:"smart_valuation"`
+	// 估值指数代码，为空时使用基金的跟踪标的
+	ValuationIndex string `json:"valuation_index"`
+	// 估值加权档位，为空时使用 DefaultDCAValuationWeights
+	ValuationWeights []DCAValuationWeight `json:"valuation_weights"`
+	// 目标止盈收益率（%）：持仓收益率达到该值时全部赎回，之后继续定投，为 0 时不止盈
+	TakeProfit float64 `json:"take_profit"`
+	// 分红方式：true 红利再投资，false 现金分红
+	ReinvestDividends bool `json:"reinvest_dividends"`
+	// 赎回费率档位，为空时使用 DefaultRedemptionFees
+	RedemptionFees []RedemptionFee `json:"redemption_fees"`
+}
+
+// Validate 检查参数
+func (p FundDCAParams) Validate() error {
+	switch {
+	case p.Code == "":
+		return fmt.Errorf("%w: code is required", ErrInvalidDCAParams)
+	case p.Amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidDCAParams)
+	case p.Frequency != DCAWeekly && p.Frequency != DCABiweekly && p.Frequency != DCAMonthly:
+		return fmt.Errorf("%w: frequency %s", ErrInvalidDCAParams, p.Frequency)
+	case p.TakeProfit < 0:
+		return fmt.Errorf("%w: take_profit must not be negative", ErrInvalidDCAParams)
+	}
+	for _, w := range p.ValuationWeights {
+		if w.Multiple < 0 {
+			return fmt.Errorf("%w: valuation weight multiple must not be negative", ErrInvalidDCAParams)
+		}
+	}
+	if _, err := time.Parse("2006-01-02", p.Start); err != nil {
+		return fmt.Errorf("%w: start %s", ErrInvalidDCAParams, p.Start)
+	}
+	if p.End != "" && p.End < p.Start {
+		return fmt.Errorf("%w: end before start", ErrInvalidDCAParams)
+	}
+	return nil
+}
+
+// dcaDueDate 第 n 期的计划扣款日，按月扣款时当月没有开始日期对应的日子则取月末
+func dcaDueDate(start time.Time, frequency string, n int) string {
+	switch frequency {
+	case DCAWeekly:
+		return start.AddDate(0, 0, 7*n).Format("2006-01-02")
+	case DCABiweekly:
+		return start.AddDate(0, 0, 14*n).Format("2006-01-02")
+	}
+	first := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	day := start.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1).Format("2006-01-02")
+}
+
+// DCANav 一个交易日的单位净值
+type DCANav struct {
+	Date string  `json:"date"`
+	NAV  float64 `json:"nav"`
+}
+
+// NewDCANavs 由接口返回的历史净值生成 start 到 end 之间按日期升序的单位净值，end 为空时不限
+func NewDCANavs(navs eastmoney.FundNetValueList, start, end string) []DCANav {
+	result := []DCANav{}
+	for _, n := range navs {
+		if n.Date < start || (end != "" && n.Date > end) {
+			continue
+		}
+		nav, err := strconv.ParseFloat(n.NAV, 64)
+		if err != nil || nav <= 0 {
+			continue
+		}
+		result = append(result, DCANav{Date: n.Date, NAV: nav})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// FundDCAFlow 一笔定投流水
+type FundDCAFlow struct {
+	Date string `json:"date"`
+	// 类型：buy/skip/dividend/reinvest/take_profit
+	Type string `json:"type"`
+	// 现金流：扣款为负，现金分红和赎回到账为正
+	Amount float64 `json:"amount"`
+	// 成交净值
+	NAV float64 `json:"nav"`
+	// 份额变动
+	Units float64 `json:"units"`
+	// 申购费或赎回费
+	Fee float64 `json:"fee"`
+	// 扣款时参考的估值百分位（%），没有估值数据时为 -1
+	Percentile float64 `json:"percentile"`
+	// 扣款倍数
+	Multiple float64 `json:"multiple"`
+}
+
+// FundDCAPoint 每个交易日的账户状态
+type FundDCAPoint struct {
+	Date string `json:"date"`
+	// 累计投入
+	Invested float64 `json:"invested"`
+	// 账户价值：持仓市值加累计取回的现金
+	Value float64 `json:"value"`
+	// 持有份额
+	Units float64 `json:"units"`
+	// 持仓平均成本
+	AvgCost float64 `json:"avg_cost"`
+	// 同期一次性投入相同总金额的账户价值
+	LumpSumValue float64 `json:"lump_sum_value"`
+}
+
+// FundDCASummary 投资结果汇总
+type FundDCASummary struct {
+	// 扣款次数
+	Periods int `json:"periods"`
+	// 累计投入
+	TotalInvested float64 `json:"total_invested"`
+	// 累计取回：现金分红和止盈赎回到账金额
+	Received float64 `json:"received"`
+	// 期末持仓市值，未扣除赎回费
+	MarketValue float64 `json:"market_value"`
+	// 期末持有份额
+	Units float64 `json:"units"`
+	// 期末持仓平均成本（含申购费）
+	AvgCost float64 `json:"avg_cost"`
+	// 收益金额
+	Profit float64 `json:"profit"`
+	// 收益率（%）：收益金额 / 累计投入
+	ReturnRate float64 `json:"return_rate"`
+	// 年化内部收益率（%）
+	XIRR float64 `json:"xirr"`
+	// 时间加权净值的最大回撤（%），剔除了扣款资金流入的影响
+	MaxDrawdown float64 `json:"max_drawdown"`
+	// 申购费和赎回费
+	Fees float64 `json:"fees"`
+	// 止盈次数
+	TakeProfits int `json:"take_profits"`
+}
+
+// FundDCAResult 定投模拟结果
+type FundDCAResult struct {
+	Params   FundDCAParams `json:"params"`
+	FundName string        `json:"fund_name"`
+	// 基金当前的定投状态
+	FixedInvestmentStatus string `json:"fixed_investment_status"`
+	Start                 string `json:"start"`
+	End                   string `json:"end"`
+	// 定投结果
+	DCA FundDCASummary `json:"dca"`
+	// 在第一个交易日一次性投入定投累计投入金额的结果
+	LumpSum FundDCASummary `json:"lump_sum"`
+	Flows   []FundDCAFlow  `json:"flows"`
+	Series  []FundDCAPoint `json:"series"`
+}
+
+// dcaOrder 一次计划扣款
+type dcaOrder struct {
+	amount     float64
+	percentile float64
+	multiple   float64
+}
+
+// dcaAccount 模拟账户，份额按申购批次记录以便按持有天数计算赎回费
+type dcaAccount struct {
+	purchaseRate float64
+	fees         []RedemptionFee
+	lots         []backtestLot
+	// 当前持仓成本
+	cost     float64
+	invested float64
+	received float64
+	summary  FundDCASummary
+	flows    []FundDCAFlow
+	// 时间加权净值
+	twr       float64
+	lastValue float64
+	dates     []string
+	twrs      []float64
+	points    []FundDCAPoint
+}
+
+func (a *dcaAccount) units() float64 {
+	sum := 0.0
+	for _, l := range a.lots {
+		sum += l.units
+	}
+	return sum
+}
+
+// buy 按外扣法扣除申购费后买入
+func (a *dcaAccount) buy(date string, nav float64, order dcaOrder) {
+	net := order.amount / (1 + a.purchaseRate)
+	units := net / nav
+	a.lots = append(a.lots, backtestLot{units: units, date: date})
+	a.cost += order.amount
+	a.invested += order.amount
+	a.summary.Periods++
+	a.summary.Fees += order.amount - net
+	a.flows = append(a.flows, FundDCAFlow{
+		Date:       date,
+		Type:       DCAFlowBuy,
+		Amount:     -order.amount,
+		NAV:        nav,
+		Units:      units,
+		Fee:        order.amount - net,
+		Percentile: order.percentile,
+		Multiple:   order.multiple,
+	})
+}
+
+// dividend 按持有份额分红，再投资时按除息日净值折算份额且不收申购费
+func (a *dcaAccount) dividend(date string, nav, perUnit float64, reinvest bool) {
+	units := a.units()
+	if units <= 0 || perUnit <= 0 {
+		return
+	}
+	cash := units * perUnit
+	if reinvest {
+		a.lots = append(a.lots, backtestLot{units: cash / nav, date: date})
+		a.flows = append(a.flows, FundDCAFlow{Date: date, Type: DCAFlowReinvest, NAV: nav, Units: cash / nav, Percentile: -1})
+		return
+	}
+	a.received += cash
+	a.flows = append(a.flows, FundDCAFlow{Date: date, Type: DCAFlowDividend, Amount: cash, NAV: nav, Percentile: -1})
+}
+
+// takeProfit 持仓收益率达到 threshold（%）时按先进先出全部赎回
+func (a *dcaAccount) takeProfit(date string, nav, threshold float64) {
+	units := a.units()
+	if threshold <= 0 || units <= 0 || a.cost <= 0 || (units*nav/a.cost-1)*100 < threshold {
+		return
+	}
+	received, fee := 0.0, 0.0
+	for _, l := range a.lots {
+		gross := l.units * nav
+		f := gross * redemptionRate(a.fees, daysBetween(l.date, date))
+		received += gross - f
+		fee += f
+	}
+	a.lots = nil
+	a.cost = 0
+	a.received += received
+	a.summary.Fees += fee
+	a.summary.TakeProfits++
+	a.flows = append(a.flows, FundDCAFlow{Date: date, Type: DCAFlowTakeProfit, Amount: received, NAV: nav, Units: -units, Fee: fee, Percentile: -1})
+}
+
+// value 持仓市值加累计取回的现金
+func (a *dcaAccount) value(nav float64) float64 {
+	return a.units()*nav + a.received
+}
+
+// mark 记录当日的时间加权净值，preValue 为当日扣款前的账户价值
+func (a *dcaAccount) mark(date string, preValue, value float64) {
+	if a.lastValue > 0 {
+		a.twr *= preValue / a.lastValue
+	}
+	a.lastValue = value
+	a.dates = append(a.dates, date)
+	a.twrs = append(a.twrs, a.twr)
+}
+
+// finish 计算期末汇总
+func (a *dcaAccount) finish(ctx context.Context, date string, nav float64) FundDCASummary {
+	s := a.summary
+	s.TotalInvested = a.invested
+	s.Received = a.received
+	s.Units = a.units()
+	s.MarketValue = s.Units * nav
+	if s.Units > 0 {
+		s.AvgCost = a.cost / s.Units
+	}
+	s.Profit = s.MarketValue + s.Received - s.TotalInvested
+	if s.TotalInvested > 0 {
+		s.ReturnRate = s.Profit / s.TotalInvested * 100
+	}
+	s.MaxDrawdown = NewBacktestMetrics(a.dates, a.twrs).MaxDrawdown
+
+	flows := []CashFlow{}
+	for _, f := range a.flows {
+		if f.Amount != 0 {
+			flows = append(flows, CashFlow{Date: f.Date, Amount: f.Amount})
+		}
+	}
+	flows = append(flows, CashFlow{Date: date, Amount: s.MarketValue})
+	if xirr, err := XIRR(flows); err == nil {
+		s.XIRR = xirr * 100
+	} else {
+		logrus.WithContext(ctx).Debugf("dcaAccount finish XIRR err:%v", err)
+	}
+	return s
+}
+
+// simulateDCA 在 navs 的交易日上模拟投资，orderAt 返回第 i 个交易日的计划扣款，没有扣款时返回 false
+func simulateDCA(
+	ctx context.Context,
+	p FundDCAParams,
+	purchaseRate float64,
+	navs []DCANav,
+	dividends []FundDividendDB,
+	takeProfit float64,
+	orderAt func(i int) (dcaOrder, bool),
+) (*dcaAccount, FundDCASummary) {
+	fees := p.RedemptionFees
+	if len(fees) == 0 {
+		fees = DefaultRedemptionFees
+	}
+	a := &dcaAccount{purchaseRate: purchaseRate, fees: fees, twr: 1}
+	divIdx := 0
+	for i, n := range navs {
+		// 权益登记日之后的第一个交易日除息，按登记日持有的份额分红
+		for divIdx < len(dividends) && dividends[divIdx].RegDate < n.Date {
+			if i > 0 && dividends[divIdx].RegDate >= navs[i-1].Date {
+				a.dividend(n.Date, n.NAV, dividends[divIdx].Value, p.ReinvestDividends)
+			}
+			divIdx++
+		}
+		a.takeProfit(n.Date, n.NAV, takeProfit)
+		preValue := a.value(n.NAV)
+		if order, ok := orderAt(i); ok {
+			if order.amount > 0 {
+				a.buy(n.Date, n.NAV, order)
+			} else {
+				a.flows = append(a.flows, FundDCAFlow{Date: n.Date, Type: DCAFlowSkip, NAV: n.NAV, Percentile: order.percentile, Multiple: order.multiple})
+			}
+		}
+		a.mark(n.Date, preValue, a.value(n.NAV))
+		pt := FundDCAPoint{Date: n.Date, Invested: a.invested, Value: a.value(n.NAV), Units: a.units()}
+		if pt.Units > 0 {
+			pt.AvgCost = a.cost / pt.Units
+		}
+		a.points = append(a.points, pt)
+	}
+	last := navs[len(navs)-1]
+	return a, a.finish(ctx, last.Date, last.NAV)
+}
+
+// RunFundDCA 使用单位净值、分红记录和按日期升序的指数估值模拟定投，并与首个交易日一次性投入相同总金额比较。
+// 申购费按 Fund.Rate 外扣，止盈赎回按份额持有天数收取赎回费，估值只使用扣款日之前的数据
+func RunFundDCA(
+	ctx context.Context,
+	p FundDCAParams,
+	info *Fund,
+	navs []DCANav,
+	dividends []FundDividendDB,
+	valuations []IndexValuationDB,
+) (*FundDCAResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if len(navs) == 0 {
+		return nil, fmt.Errorf("%w: no nav between %s and %s", ErrInvalidDCAParams, p.Start, p.End)
+	}
+	weights := p.ValuationWeights
+	if len(weights) == 0 {
+		weights = DefaultDCAValuationWeights
+	}
+	start, _ := time.Parse("2006-01-02", p.Start)
+	divs := make([]FundDividendDB, 0, len(dividends))
+	for _, d := range dividends {
+		if len(d.RegDate) >= 10 {
+			d.RegDate = d.RegDate[:10]
+			divs = append(divs, d)
+		}
+	}
+	sort.Slice(divs, func(i, j int) bool { return divs[i].RegDate < divs[j].RegDate })
+	purchaseRate := ParseFundRate(info.Rate) / 100
+
+	period := 0
+	valIdx := 0
+	dcaOrderAt := func(i int) (dcaOrder, bool) {
+		date := navs[i].Date
+		if date < dcaDueDate(start, p.Frequency, period) {
+			return dcaOrder{}, false
+		}
+		for dcaDueDate(start, p.Frequency, period) <= date {
+			period++
+		}
+		order := dcaOrder{amount: p.Amount, percentile: -1, multiple: 1}
+		if p.SmartValuation {
+			for valIdx < len(valuations) && valuations[valIdx].Date < date {
+				valIdx++
+			}
+			if valIdx > 0 {
+				order.percentile = valuations[valIdx-1].PEPercentile
+				order.multiple = dcaMultiple(weights, order.percentile)
+				order.amount = p.Amount * order.multiple
+			}
+		}
+		return order, true
+	}
+	dca, dcaSummary := simulateDCA(ctx, p, purchaseRate, navs, divs, p.TakeProfit, dcaOrderAt)
+
+	result := &FundDCAResult{
+		Params:                p,
+		FundName:              info.Name,
+		FixedInvestmentStatus: info.FixedInvestmentStatus,
+		Start:                 navs[0].Date,
+		End:                   navs[len(navs)-1].Date,
+		DCA:                   dcaSummary,
+		Flows:                 dca.flows,
+		Series:                dca.points,
+	}
+	lumpSumOrderAt := func(i int) (dcaOrder, bool) {
+		return dcaOrder{amount: dcaSummary.TotalInvested, percentile: -1, multiple: 1}, i == 0 && dcaSummary.TotalInvested > 0
+	}
+	lumpSum, lumpSumSummary := simulateDCA(ctx, p, purchaseRate, navs, divs, 0, lumpSumOrderAt)
+	result.LumpSum = lumpSumSummary
+	for i := range result.Series {
+		result.Series[i].LumpSumValue = lumpSum.points[i].Value
+	}
+	return result, nil
+}
+
+// LoadFundDividends 从数据库加载基金分红记录，没有时使用基金详情中的最近分红
+func LoadFundDividends(ctx context.Context, fund *Fund) []FundDividendDB {
+	if DB != nil {
+		rows := []FundDividendDB{}
+		if err := DB.Where("fund_code = ?", fund.Code).Order("reg_date").Find(&rows).Error; err != nil {
+			logrus.WithContext(ctx).Error("LoadFundDividends err:" + err.Error())
+		} else if len(rows) > 0 {
+			return rows
+		}
+	}
+	return fund.ToFundDividends()
+}
+
+// SimulateFundDCA 加载基金净值、分红和估值数据并模拟定投
+func SimulateFundDCA(ctx context.Context, p FundDCAParams) (*FundDCAResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	funds, err := backtestFundPool(ctx, []string{p.Code})
+	if err != nil {
+		return nil, err
+	}
+	if len(funds) == 0 {
+		return nil, fmt.Errorf("%w: fund %s not found", ErrInvalidDCAParams, p.Code)
+	}
+	fund := funds[0]
+	days := daysBetween(p.Start, time.Now().Format("2006-01-02"))*250/365 + 20
+	navList, err := GetFundNavs(ctx, p.Code, days)
+	if err != nil {
+		return nil, err
+	}
+
+	valuations := []IndexValuationDB{}
+	if p.SmartValuation {
+		indexCode := p.ValuationIndex
+		if indexCode == "" {
+			indexCode = fund.IndexCode
+		}
+		if indexCode == "" {
+			return nil, fmt.Errorf("%w: valuation_index is required for fund %s without index", ErrInvalidDCAParams, p.Code)
+		}
+		if valuations, err = LoadIndexValuations(ctx, indexCode, "", p.End); err != nil {
+			return nil, err
+		}
+		if len(valuations) == 0 {
+			logrus.WithContext(ctx).Warnf("SimulateFundDCA index %s has no valuation history", indexCode)
+		}
+	}
+	return RunFundDCA(ctx, p, fund, NewDCANavs(navList, p.Start, p.End), LoadFundDividends(ctx, fund), valuations)
+}
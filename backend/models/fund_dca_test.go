@@ -0,0 +1,117 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXIRR(t *testing.T) {
+	rate, err := XIRR([]CashFlow{{Date: "2021-01-01", Amount: -1000}, {Date: "2022-01-01", Amount: 1100}})
+	require.Nil(t, err)
+	require.InDelta(t, 0.1, rate, 1e-6)
+
+	rate, err = XIRR([]CashFlow{{Date: "2021-01-01", Amount: -1000}, {Date: "2021-07-01", Amount: -1000}, {Date: "2022-01-01", Amount: 1800}})
+	require.Nil(t, err)
+	require.Less(t, rate, 0.0)
+
+	_, err = XIRR([]CashFlow{{Date: "2021-01-01", Amount: -1000}, {Date: "2022-01-01", Amount: -1000}})
+	require.ErrorIs(t, err, ErrXIRRNotConverge)
+}
+
+func TestDCADueDate(t *testing.T) {
+	start := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, "2021-02-28", dcaDueDate(start, DCAMonthly, 1))
+	require.Equal(t, "2021-03-31", dcaDueDate(start, DCAMonthly, 2))
+	require.Equal(t, "2021-02-14", dcaDueDate(start, DCABiweekly, 1))
+	require.Equal(t, "2021-02-07", dcaDueDate(start, DCAWeekly, 1))
+}
+
+// mockDCANavs 2021 年每个工作日的净值，从 1 开始每日变化 step
+func mockDCANavs(step float64) []DCANav {
+	navs := []DCANav{}
+	nav := 1.0
+	for d := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() == 2021; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		navs = append(navs, DCANav{Date: d.Format("2006-01-02"), NAV: nav})
+		nav += step
+	}
+	return navs
+}
+
+func TestRunFundDCA(t *testing.T) {
+	ctx := context.Background()
+	info := &Fund{Code: "000001", Name: "test", Rate: "0.00%"}
+	p := FundDCAParams{Code: "000001", Start: "2021-01-01", Amount: 1000, Frequency: DCAMonthly}
+
+	// 净值不变时没有收益
+	result, err := RunFundDCA(ctx, p, info, mockDCANavs(0), nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, 12, result.DCA.Periods)
+	require.Equal(t, 12000.0, result.DCA.TotalInvested)
+	require.InDelta(t, 0, result.DCA.Profit, 1e-6)
+	require.InDelta(t, 0, result.DCA.XIRR, 1e-4)
+	require.Equal(t, 0.0, result.DCA.MaxDrawdown)
+	require.Equal(t, 1.0, result.DCA.AvgCost)
+	require.Equal(t, 12000.0, result.LumpSum.TotalInvested)
+	require.Equal(t, 1, result.LumpSum.Periods)
+	require.Len(t, result.Series, len(mockDCANavs(0)))
+	require.Equal(t, 12000.0, result.Series[0].LumpSumValue)
+	require.Equal(t, 1000.0, result.Series[0].Invested)
+
+	// 单边上涨时一次性投入收益更高，定投的平均成本低于期末净值
+	navs := mockDCANavs(0.002)
+	dividends := []FundDividendDB{{FundCode: "000001", RegDate: "2021-06-30", Value: 0.1}}
+	result, err = RunFundDCA(ctx, p, info, navs, dividends, nil)
+	require.Nil(t, err)
+	require.Greater(t, result.LumpSum.Profit, result.DCA.Profit)
+	require.Less(t, result.DCA.AvgCost, navs[len(navs)-1].NAV)
+	require.Greater(t, result.DCA.XIRR, 0.0)
+	require.Greater(t, result.DCA.Received, 0.0)
+	found := false
+	for _, f := range result.Flows {
+		if f.Type == DCAFlowDividend {
+			found = true
+			require.Equal(t, "2021-07-01", f.Date)
+		}
+	}
+	require.True(t, found)
+
+	// 红利再投资增加份额
+	p.ReinvestDividends = true
+	reinvest, err := RunFundDCA(ctx, p, info, navs, dividends, nil)
+	require.Nil(t, err)
+	require.Equal(t, 0.0, reinvest.DCA.Received)
+	require.Greater(t, reinvest.DCA.Units, result.DCA.Units)
+
+	// 止盈后清仓并继续定投
+	p.TakeProfit = 20
+	result, err = RunFundDCA(ctx, p, info, navs, nil, nil)
+	require.Nil(t, err)
+	require.Greater(t, result.DCA.TakeProfits, 0)
+	require.Greater(t, result.DCA.Received, 0.0)
+	require.Greater(t, result.DCA.Fees, 0.0)
+	require.Equal(t, 0, result.LumpSum.TakeProfits)
+
+	// 估值加权：低估时加倍，高估时暂停
+	p.TakeProfit = 0
+	p.SmartValuation = true
+	valuations := []IndexValuationDB{
+		{Date: "2020-12-31", PEPercentile: 10},
+		{Date: "2021-03-15", PEPercentile: 90},
+	}
+	result, err = RunFundDCA(ctx, p, info, mockDCANavs(0), nil, valuations)
+	require.Nil(t, err)
+	require.Equal(t, 3, result.DCA.Periods)
+	require.Equal(t, 6000.0, result.DCA.TotalInvested)
+	require.Equal(t, DCAFlowSkip, result.Flows[len(result.Flows)-1].Type)
+	require.Equal(t, 90.0, result.Flows[len(result.Flows)-1].Percentile)
+
+	p.Frequency = "daily"
+	_, err = RunFundDCA(ctx, p, info, navs, nil, nil)
+	require.ErrorIs(t, err, ErrInvalidDCAParams)
+}
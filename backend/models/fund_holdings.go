@@ -0,0 +1,365 @@
+// 基金持仓按报告期保存、跨期对比及4433基金调仓汇总
+
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 持仓变动类型
+const (
+	FundHoldingNew       = "new"
+	FundHoldingExit      = "exit"
+	FundHoldingIncrease  = "increase"
+	FundHoldingDecrease  = "decrease"
+	FundHoldingUnchanged = "unchanged"
+)
+
+// ErrFundHoldingPeriodsNotEnough 持仓报告期不足两期
+var ErrFundHoldingPeriodsNotEnough = errors.New("fund holding periods not enough")
+
+// LatestFundStocksReportDate 基金已保存持仓的最新报告期，没有报告期数据时返回空字符串
+func LatestFundStocksReportDate(db *gorm.DB, fundCode string) string {
+	dates := []string{}
+	db.Model(&FundStockDB{}).Where("fund_code = ? AND report_date <> ''", fundCode).
+		Order("report_date DESC").Limit(1).Pluck("report_date", &dates)
+	if len(dates) == 0 {
+		return ""
+	}
+	return dates[0]
+}
+
+// LatestFundStocksReportDates 多只基金已保存持仓的最新报告期，按基金代码分组一次查询，没有报告期数据的基金不在结果中
+func LatestFundStocksReportDates(db *gorm.DB, fundCodes []string) map[string]string {
+	rows := []struct {
+		FundCode   string
+		ReportDate string
+	}{}
+	if err := db.Model(&FundStockDB{}).Select("fund_code, MAX(report_date) AS report_date").
+		Where("fund_code IN ? AND report_date <> ''", fundCodes).Group("fund_code").Scan(&rows).Error; err != nil {
+		logrus.Error("LatestFundStocksReportDates error:" + err.Error())
+	}
+	result := map[string]string{}
+	for _, r := range rows {
+		result[r.FundCode] = r.ReportDate
+	}
+	return result
+}
+
+// SaveFundStocks 保存基金某报告期的持仓，只覆盖同一报告期及没有报告期的旧记录，保留历史报告期
+func SaveFundStocks(db *gorm.DB, fundCode, reportDate string, stocks []FundStockDB) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+	if err := db.Where("fund_code = ? AND (report_date = ? OR report_date = '' OR report_date IS NULL)", fundCode, reportDate).
+		Delete(&FundStockDB{}).Error; err != nil {
+		return err
+	}
+	return db.CreateInBatches(stocks, 100).Error
+}
+
+// LoadFundHoldingPeriods 加载基金已保存持仓的报告期，按日期降序
+func LoadFundHoldingPeriods(ctx context.Context, fundCode string) ([]string, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	dates := []string{}
+	err := DB.Model(&FundStockDB{}).Where("fund_code = ? AND report_date <> ''", fundCode).
+		Distinct("report_date").Order("report_date DESC").Pluck("report_date", &dates).Error
+	return dates, err
+}
+
+// LoadFundHoldings 加载基金某报告期的持仓
+func LoadFundHoldings(ctx context.Context, fundCode, reportDate string) ([]FundStockDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []FundStockDB{}
+	err := DB.Where("fund_code = ? AND report_date = ?", fundCode, reportDate).Order("hold_ratio DESC").Find(&rows).Error
+	return rows, err
+}
+
+// FundHoldingChange 单只股票的持仓变动
+type FundHoldingChange struct {
+	StockCode string `json:"stock_code"`
+	StockName string `json:"stock_name"`
+	Industry  string `json:"industry"`
+	// 变动类型：new/exit/increase/decrease/unchanged
+	Type string `json:"type"`
+	// 起始报告期占净值比例（%）
+	FromRatio float64 `json:"from_ratio"`
+	// 结束报告期占净值比例（%）
+	ToRatio float64 `json:"to_ratio"`
+	// 占比变化（百分点）
+	Change float64 `json:"change"`
+}
+
+// FundHoldingDiff 基金两个报告期之间的持仓变动
+// 基金只披露前十大重仓股，调出可能只是跌出前十
+type FundHoldingDiff struct {
+	FundCode  string              `json:"fund_code"`
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	New       []FundHoldingChange `json:"new"`
+	Exited    []FundHoldingChange `json:"exited"`
+	Increased []FundHoldingChange `json:"increased"`
+	Decreased []FundHoldingChange `json:"decreased"`
+	Unchanged []FundHoldingChange `json:"unchanged"`
+}
+
+// DiffFundHoldings 对比基金两个报告期的持仓，各类变动按占比变化绝对值降序
+func DiffFundHoldings(fundCode, from, to string, fromRows, toRows []FundStockDB) FundHoldingDiff {
+	diff := FundHoldingDiff{
+		FundCode:  fundCode,
+		From:      from,
+		To:        to,
+		New:       []FundHoldingChange{},
+		Exited:    []FundHoldingChange{},
+		Increased: []FundHoldingChange{},
+		Decreased: []FundHoldingChange{},
+		Unchanged: []FundHoldingChange{},
+	}
+	fromMap := map[string]FundStockDB{}
+	for _, r := range fromRows {
+		fromMap[r.StockCode] = r
+	}
+	toMap := map[string]bool{}
+	for _, r := range toRows {
+		toMap[r.StockCode] = true
+		c := FundHoldingChange{StockCode: r.StockCode, StockName: r.StockName, Industry: r.Industry, ToRatio: r.HoldRatio}
+		old, ok := fromMap[r.StockCode]
+		if !ok {
+			c.Type = FundHoldingNew
+			c.Change = r.HoldRatio
+			diff.New = append(diff.New, c)
+			continue
+		}
+		c.FromRatio = old.HoldRatio
+		c.Change = r.HoldRatio - old.HoldRatio
+		switch {
+		case c.Change > 0:
+			c.Type = FundHoldingIncrease
+			diff.Increased = append(diff.Increased, c)
+		case c.Change < 0:
+			c.Type = FundHoldingDecrease
+			diff.Decreased = append(diff.Decreased, c)
+		default:
+			c.Type = FundHoldingUnchanged
+			diff.Unchanged = append(diff.Unchanged, c)
+		}
+	}
+	for _, r := range fromRows {
+		if toMap[r.StockCode] {
+			continue
+		}
+		diff.Exited = append(diff.Exited, FundHoldingChange{
+			StockCode: r.StockCode,
+			StockName: r.StockName,
+			Industry:  r.Industry,
+			Type:      FundHoldingExit,
+			FromRatio: r.HoldRatio,
+			Change:    -r.HoldRatio,
+		})
+	}
+	for _, list := range [][]FundHoldingChange{diff.New, diff.Exited, diff.Increased, diff.Decreased} {
+		sort.SliceStable(list, func(i, j int) bool {
+			return math.Abs(list[i].Change) > math.Abs(list[j].Change)
+		})
+	}
+	return diff
+}
+
+// GetFundHoldingDiff 对比基金两个报告期的持仓，未指定时使用最近两个报告期
+func GetFundHoldingDiff(ctx context.Context, fundCode, from, to string) (FundHoldingDiff, error) {
+	if from == "" || to == "" {
+		periods, err := LoadFundHoldingPeriods(ctx, fundCode)
+		if err != nil {
+			return FundHoldingDiff{}, err
+		}
+		if to == "" {
+			if len(periods) == 0 {
+				return FundHoldingDiff{}, ErrFundHoldingPeriodsNotEnough
+			}
+			to = periods[0]
+		}
+		if from == "" {
+			for _, p := range periods {
+				if p < to {
+					from = p
+					break
+				}
+			}
+			if from == "" {
+				return FundHoldingDiff{}, ErrFundHoldingPeriodsNotEnough
+			}
+		}
+	}
+	fromRows, err := LoadFundHoldings(ctx, fundCode, from)
+	if err != nil {
+		return FundHoldingDiff{}, err
+	}
+	toRows, err := LoadFundHoldings(ctx, fundCode, to)
+	if err != nil {
+		return FundHoldingDiff{}, err
+	}
+	return DiffFundHoldings(fundCode, from, to, fromRows, toRows), nil
+}
+
+// SmartMoneyStock 多只基金对同一股票的调仓汇总
+type SmartMoneyStock struct {
+	StockCode string `json:"stock_code"`
+	StockName string `json:"stock_name"`
+	Industry  string `json:"industry"`
+	// 新进的基金数
+	NewFunds int `json:"new_funds"`
+	// 增持的基金数
+	IncreasedFunds int `json:"increased_funds"`
+	// 减持的基金数
+	DecreasedFunds int `json:"decreased_funds"`
+	// 调出的基金数
+	ExitedFunds int `json:"exited_funds"`
+	// 净加仓基金数：新进+增持-减持-调出
+	NetFunds int `json:"net_funds"`
+	// 本期持有的基金数
+	HoldFunds int `json:"hold_funds"`
+	// 占净值比例变化之和（百分点）
+	TotalChange float64 `json:"total_change"`
+}
+
+// SmartMoneyReport 4433基金最新报告期调仓汇总
+type SmartMoneyReport struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// 参与统计的基金数
+	FundCount int `json:"fund_count"`
+	// 按净加仓基金数降序
+	MostAdded []SmartMoneyStock `json:"most_added"`
+	// 按净减仓基金数降序
+	MostRemoved []SmartMoneyStock `json:"most_removed"`
+}
+
+// AggregateSmartMoney 汇总多只基金的持仓变动，返回加仓最多和减仓最多的前 limit 只股票
+func AggregateSmartMoney(diffs []FundHoldingDiff, limit int) (mostAdded, mostRemoved []SmartMoneyStock) {
+	stocks := map[string]*SmartMoneyStock{}
+	get := func(c FundHoldingChange) *SmartMoneyStock {
+		s, ok := stocks[c.StockCode]
+		if !ok {
+			s = &SmartMoneyStock{StockCode: c.StockCode, StockName: c.StockName, Industry: c.Industry}
+			stocks[c.StockCode] = s
+		}
+		s.TotalChange += c.Change
+		return s
+	}
+	for _, d := range diffs {
+		for _, c := range d.New {
+			s := get(c)
+			s.NewFunds++
+			s.HoldFunds++
+		}
+		for _, c := range d.Increased {
+			s := get(c)
+			s.IncreasedFunds++
+			s.HoldFunds++
+		}
+		for _, c := range d.Decreased {
+			s := get(c)
+			s.DecreasedFunds++
+			s.HoldFunds++
+		}
+		for _, c := range d.Unchanged {
+			get(c).HoldFunds++
+		}
+		for _, c := range d.Exited {
+			get(c).ExitedFunds++
+		}
+	}
+
+	all := []SmartMoneyStock{}
+	for _, s := range stocks {
+		s.NetFunds = s.NewFunds + s.IncreasedFunds - s.DecreasedFunds - s.ExitedFunds
+		all = append(all, *s)
+	}
+	mostAdded = []SmartMoneyStock{}
+	mostRemoved = []SmartMoneyStock{}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].NetFunds != all[j].NetFunds {
+			return all[i].NetFunds > all[j].NetFunds
+		}
+		if all[i].TotalChange != all[j].TotalChange {
+			return all[i].TotalChange > all[j].TotalChange
+		}
+		return all[i].StockCode < all[j].StockCode
+	})
+	for _, s := range all {
+		if s.NetFunds <= 0 || len(mostAdded) >= limit {
+			break
+		}
+		mostAdded = append(mostAdded, s)
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].NetFunds >= 0 || len(mostRemoved) >= limit {
+			break
+		}
+		mostRemoved = append(mostRemoved, all[i])
+	}
+	return
+}
+
+// QuerySmartMoney 汇总全部4433基金在最新报告期相对上一报告期的调仓
+func QuerySmartMoney(ctx context.Context, limit int) (*SmartMoneyReport, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	codes := []string{}
+	if err := DB.Model(&FundDB{}).Where("is_4433 = ?", true).Pluck("code", &codes).Error; err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("no 4433 fund")
+	}
+	periods := []string{}
+	if err := DB.Model(&FundStockDB{}).Where("fund_code IN ? AND report_date <> ''", codes).
+		Distinct("report_date").Order("report_date DESC").Limit(2).Pluck("report_date", &periods).Error; err != nil {
+		return nil, err
+	}
+	if len(periods) < 2 {
+		return nil, ErrFundHoldingPeriodsNotEnough
+	}
+	to, from := periods[0], periods[1]
+	rows := []FundStockDB{}
+	if err := DB.Where("fund_code IN ? AND report_date IN ?", codes, periods).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	fromRows := map[string][]FundStockDB{}
+	toRows := map[string][]FundStockDB{}
+	for _, r := range rows {
+		if r.ReportDate == to {
+			toRows[r.FundCode] = append(toRows[r.FundCode], r)
+		} else {
+			fromRows[r.FundCode] = append(fromRows[r.FundCode], r)
+		}
+	}
+	// 只统计两个报告期都有持仓数据的基金
+	diffs := []FundHoldingDiff{}
+	for code, t := range toRows {
+		f, ok := fromRows[code]
+		if !ok {
+			continue
+		}
+		diffs = append(diffs, DiffFundHoldings(code, from, to, f, t))
+	}
+	mostAdded, mostRemoved := AggregateSmartMoney(diffs, limit)
+	return &SmartMoneyReport{
+		From:        from,
+		To:          to,
+		FundCount:   len(diffs),
+		MostAdded:   mostAdded,
+		MostRemoved: mostRemoved,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFundHoldings(t *testing.T) {
+	from := []FundStockDB{
+		{StockCode: "600519", StockName: "贵州茅台", HoldRatio: 9},
+		{StockCode: "000858", StockName: "五粮液", HoldRatio: 8},
+		{StockCode: "601318", StockName: "中国平安", HoldRatio: 5},
+		{StockCode: "600036", StockName: "招商银行", HoldRatio: 4},
+	}
+	to := []FundStockDB{
+		{StockCode: "600519", StockName: "贵州茅台", HoldRatio: 10},
+		{StockCode: "000858", StockName: "五粮液", HoldRatio: 6},
+		{StockCode: "600036", StockName: "招商银行", HoldRatio: 4},
+		{StockCode: "300750", StockName: "宁德时代", HoldRatio: 7},
+	}
+	diff := DiffFundHoldings("000001", "2023-06-30", "2023-09-30", from, to)
+	require.Len(t, diff.New, 1)
+	require.Equal(t, "300750", diff.New[0].StockCode)
+	require.Len(t, diff.Exited, 1)
+	require.Equal(t, -5.0, diff.Exited[0].Change)
+	require.Len(t, diff.Increased, 1)
+	require.Equal(t, 1.0, diff.Increased[0].Change)
+	require.Len(t, diff.Decreased, 1)
+	require.Equal(t, FundHoldingDecrease, diff.Decreased[0].Type)
+	require.Len(t, diff.Unchanged, 1)
+}
+
+func TestAggregateSmartMoney(t *testing.T) {
+	d1 := DiffFundHoldings("1", "p", "q",
+		[]FundStockDB{{StockCode: "A", HoldRatio: 5}, {StockCode: "B", HoldRatio: 5}},
+		[]FundStockDB{{StockCode: "A", HoldRatio: 6}, {StockCode: "C", HoldRatio: 3}},
+	)
+	d2 := DiffFundHoldings("2", "p", "q",
+		[]FundStockDB{{StockCode: "B", HoldRatio: 4}},
+		[]FundStockDB{{StockCode: "B", HoldRatio: 2}, {StockCode: "C", HoldRatio: 2}},
+	)
+	added, removed := AggregateSmartMoney([]FundHoldingDiff{d1, d2}, 10)
+	require.Len(t, added, 2)
+	require.Equal(t, "C", added[0].StockCode)
+	require.Equal(t, 2, added[0].NetFunds)
+	require.Equal(t, 2, added[0].NewFunds)
+	require.Equal(t, "A", added[1].StockCode)
+	require.Len(t, removed, 1)
+	require.Equal(t, "B", removed[0].StockCode)
+	require.Equal(t, -2, removed[0].NetFunds)
+	require.Equal(t, 1, removed[0].HoldFunds)
+
+	added, _ = AggregateSmartMoney([]FundHoldingDiff{d1, d2}, 1)
+	require.Len(t, added, 1)
+}
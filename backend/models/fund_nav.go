@@ -0,0 +1,66 @@
+// 基金历史净值获取与存储
+
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// SaveFundNavs 保存基金历史净值，覆盖相同日期区间内的旧数据
+func SaveFundNavs(ctx context.Context, fundCode string, navs eastmoney.FundNetValueList) error {
+	rows := ToFundNavsDB(fundCode, navs)
+	if DB == nil || len(rows) == 0 {
+		return nil
+	}
+	begin, end := rows[0].Date, rows[0].Date
+	for _, r := range rows {
+		if r.Date < begin {
+			begin = r.Date
+		}
+		if r.Date > end {
+			end = r.Date
+		}
+	}
+	if err := DB.Where("fund_code = ? AND date >= ? AND date <= ?", fundCode, begin, end).Delete(&FundNavDB{}).Error; err != nil {
+		return err
+	}
+	return DB.CreateInBatches(rows, 500).Error
+}
+
+// LoadFundNavs 从数据库加载最近 limit 个交易日的净值，与接口一致按日期降序返回
+func LoadFundNavs(ctx context.Context, fundCode string, limit int) (eastmoney.FundNetValueList, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []FundNavDB{}
+	query := DB.Where("fund_code = ?", fundCode).Order("date DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(eastmoney.FundNetValueList, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.ToFundNetValue())
+	}
+	return result, nil
+}
+
+// GetFundNavs 获取基金最近 limit 个交易日的净值并保存到数据库，接口失败时使用数据库中的数据
+func GetFundNavs(ctx context.Context, fundCode string, limit int) (eastmoney.FundNetValueList, error) {
+	navs, err := datacenter.EastMoney.QueryFundNetHistory(ctx, fundCode, limit)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetFundNavs QueryFundNetHistory err:" + err.Error())
+		return LoadFundNavs(ctx, fundCode, limit)
+	}
+	if err := SaveFundNavs(ctx, fundCode, navs); err != nil {
+		logrus.WithContext(ctx).Error("GetFundNavs SaveFundNavs err:" + err.Error())
+	}
+	return navs, nil
+}
@@ -0,0 +1,69 @@
+// 基金历史规模
+
+package models
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// FundScale 基金在报告期的净资产规模
+type FundScale struct {
+	// 报告期
+	Date string `json:"date"`
+	// 净资产规模（元）
+	NetAssets float64 `json:"net_assets"`
+}
+
+// fundScalePublishLag 报告期的规模在季报中公布，季报在季度结束后 15 个工作日内发布，按 30 个自然日计算
+const fundScalePublishLag = 30
+
+// FundScaleAt 返回 date 当日已经公布的最近一期规模，scales 按日期升序，没有时返回 false
+func FundScaleAt(scales []FundScale, date string) (float64, bool) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, false
+	}
+	published := t.AddDate(0, 0, -fundScalePublishLag).Format("2006-01-02")
+	i := sort.Search(len(scales), func(i int) bool { return scales[i].Date > published })
+	if i == 0 {
+		return 0, false
+	}
+	return scales[i-1].NetAssets, true
+}
+
+// SaveFundScales 保存基金各报告期的规模，已存在的报告期更新规模
+func SaveFundScales(ctx context.Context, fundCode string, scales []FundScale) error {
+	if DB == nil || len(scales) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]FundScaleDB, 0, len(scales))
+	for _, s := range scales {
+		rows = append(rows, FundScaleDB{FundCode: fundCode, Date: s.Date, NetAssets: s.NetAssets, UpdatedAt: now})
+	}
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "fund_code"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"net_assets", "updated_at"}),
+	}).CreateInBatches(rows, 500).Error
+}
+
+// LoadFundScales 从数据库加载基金各报告期的规模，按基金代码分组，每组按日期升序
+func LoadFundScales(ctx context.Context, fundCodes []string) (map[string][]FundScale, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []FundScaleDB{}
+	if err := DB.WithContext(ctx).Where("fund_code IN ?", fundCodes).Order("fund_code, date").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := map[string][]FundScale{}
+	for _, r := range rows {
+		result[r.FundCode] = append(result[r.FundCode], FundScale{Date: r.Date, NetAssets: r.NetAssets})
+	}
+	return result, nil
+}
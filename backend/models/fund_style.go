@@ -0,0 +1,362 @@
+// 基金风格箱：按持仓股票的市值和估值划分大中小盘 × 价值平衡成长，并计算风格漂移
+
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 市值规模
+const (
+	FundSizeLarge = "large"
+	FundSizeMid   = "mid"
+	FundSizeSmall = "small"
+)
+
+// 投资风格
+const (
+	FundStyleValue  = "value"
+	FundStyleBlend  = "blend"
+	FundStyleGrowth = "growth"
+)
+
+// FundStyleBoxes 全部风格箱，按 规模_风格 命名
+var FundStyleBoxes = []string{
+	FundSizeLarge + "_" + FundStyleValue, FundSizeLarge + "_" + FundStyleBlend, FundSizeLarge + "_" + FundStyleGrowth,
+	FundSizeMid + "_" + FundStyleValue, FundSizeMid + "_" + FundStyleBlend, FundSizeMid + "_" + FundStyleGrowth,
+	FundSizeSmall + "_" + FundStyleValue, FundSizeSmall + "_" + FundStyleBlend, FundSizeSmall + "_" + FundStyleGrowth,
+}
+
+// StyleThresholds 股票规模和风格的划分阈值
+type StyleThresholds struct {
+	// 大盘股最低总市值（亿）
+	LargeCapMin float64 `json:"large_cap_min"`
+	// 中盘股最低总市值（亿），低于该值为小盘股
+	MidCapMin float64 `json:"mid_cap_min"`
+	// 价值股最高市盈率
+	ValueMaxPE float64 `json:"value_max_pe"`
+	// 价值股最高市净率
+	ValueMaxPB float64 `json:"value_max_pb"`
+	// 成长股最低市盈率
+	GrowthMinPE float64 `json:"growth_min_pe"`
+	// 成长股最低市净率
+	GrowthMinPB float64 `json:"growth_min_pb"`
+}
+
+// DefaultStyleThresholds 默认划分阈值
+var DefaultStyleThresholds = StyleThresholds{
+	LargeCapMin: 500,
+	MidCapMin:   100,
+	ValueMaxPE:  15,
+	ValueMaxPB:  2,
+	GrowthMinPE: 30,
+	GrowthMinPB: 4,
+}
+
+// stockSizeScore 股票规模得分：大盘 1，中盘 0，小盘 -1
+func (t StyleThresholds) stockSizeScore(marketCap float64) float64 {
+	switch {
+	case marketCap >= t.LargeCapMin*100000000:
+		return 1
+	case marketCap >= t.MidCapMin*100000000:
+		return 0
+	}
+	return -1
+}
+
+// stockStyleScore 股票风格得分：成长 1，平衡 0，价值 -1，亏损股只看市净率
+func (t StyleThresholds) stockStyleScore(pe, pb float64) float64 {
+	switch {
+	case pe >= t.GrowthMinPE || pb >= t.GrowthMinPB:
+		return 1
+	case pe > 0 && pe <= t.ValueMaxPE && pb > 0 && pb <= t.ValueMaxPB:
+		return -1
+	}
+	return 0
+}
+
+// FundStyle 基金某报告期的风格
+// 持仓为报告期披露的持仓，市值和市盈率、市净率使用计算时的最新数据，历史报告期的风格反映的是当时持仓在当前估值下的风格
+type FundStyle struct {
+	FundCode   string `json:"fund_code"`
+	ReportDate string `json:"report_date"`
+	// 规模：large/mid/small
+	Size string `json:"size"`
+	// 风格：value/blend/growth
+	Style string `json:"style"`
+	// 风格箱：规模_风格
+	Box string `json:"box"`
+	// 按持仓占比加权的规模得分，[-1, 1]，越大越偏大盘
+	SizeScore float64 `json:"size_score"`
+	// 按持仓占比加权的风格得分，[-1, 1]，越大越偏成长
+	StyleScore float64 `json:"style_score"`
+	// 加权平均总市值（元）
+	MarketCap float64 `json:"market_cap"`
+	// 加权平均市盈率，只统计盈利的股票
+	PE float64 `json:"pe"`
+	// 加权平均市净率
+	PB float64 `json:"pb"`
+	// 有行情数据的持仓占全部披露持仓的比例（%），港股等无数据的持仓不参与计算
+	Coverage float64 `json:"coverage"`
+}
+
+// scoreLevel 将 [-1, 1] 的得分三等分
+func scoreLevel(score float64, high, mid, low string) string {
+	switch {
+	case score >= 1.0/3:
+		return high
+	case score <= -1.0/3:
+		return low
+	}
+	return mid
+}
+
+// ClassifyFundStyle 按持仓股票的市值和估值计算基金风格箱
+// stocks 为以股票代码为 key 的行情数据，接口只提供最新市值和估值，历史报告期的风格是按当前数据的近似
+func ClassifyFundStyle(fundCode, reportDate string, holdings []FundStockDB, stocks map[string]eastmoney.StockInfo, t StyleThresholds) FundStyle {
+	style := FundStyle{FundCode: fundCode, ReportDate: reportDate}
+	totalRatio, coveredRatio, peRatio := 0.0, 0.0, 0.0
+	sizeScore, styleScore := 0.0, 0.0
+	for _, h := range holdings {
+		if h.HoldRatio <= 0 {
+			continue
+		}
+		totalRatio += h.HoldRatio
+		stock, ok := stocks[h.StockCode]
+		if !ok || stock.TotalMarketCap <= 0 {
+			continue
+		}
+		coveredRatio += h.HoldRatio
+		sizeScore += h.HoldRatio * t.stockSizeScore(stock.TotalMarketCap)
+		styleScore += h.HoldRatio * t.stockStyleScore(stock.PE, stock.PBNewMRQ)
+		style.MarketCap += h.HoldRatio * stock.TotalMarketCap
+		style.PB += h.HoldRatio * stock.PBNewMRQ
+		if stock.PE > 0 {
+			peRatio += h.HoldRatio
+			style.PE += h.HoldRatio * stock.PE
+		}
+	}
+	if coveredRatio > 0 {
+		style.SizeScore = sizeScore / coveredRatio
+		style.StyleScore = styleScore / coveredRatio
+		style.MarketCap /= coveredRatio
+		style.PB /= coveredRatio
+		style.Coverage = coveredRatio / totalRatio * 100
+	}
+	if peRatio > 0 {
+		style.PE /= peRatio
+	}
+	style.Size = scoreLevel(style.SizeScore, FundSizeLarge, FundSizeMid, FundSizeSmall)
+	style.Style = scoreLevel(style.StyleScore, FundStyleGrowth, FundStyleBlend, FundStyleValue)
+	style.Box = style.Size + "_" + style.Style
+	return style
+}
+
+// FundStyleDrift 风格漂移度（0~100）：相邻报告期在规模、风格得分平面上移动距离的平均值，
+// 以平面对角线长度为 100，按报告期升序传入，少于两期时为 0
+func FundStyleDrift(styles []FundStyle) float64 {
+	if len(styles) < 2 {
+		return 0
+	}
+	sum := 0.0
+	for i := 1; i < len(styles); i++ {
+		sum += math.Hypot(styles[i].SizeScore-styles[i-1].SizeScore, styles[i].StyleScore-styles[i-1].StyleScore)
+	}
+	return sum / float64(len(styles)-1) / (2 * math.Sqrt2) * 100
+}
+
+// FundStyleHistory 基金风格历史
+type FundStyleHistory struct {
+	FundCode string `json:"fund_code"`
+	// 各报告期风格，按报告期升序
+	Styles []FundStyle `json:"styles"`
+	// 风格漂移度（0~100）
+	Drift float64 `json:"drift"`
+	// 风格箱变化次数
+	BoxChanges int `json:"box_changes"`
+	// 风格计算的局限性说明
+	Limitations []string `json:"limitations"`
+}
+
+// fundStyleLimitations 风格计算的局限性说明
+var fundStyleLimitations = []string{
+	"各报告期使用当时披露的持仓，但市值、市盈率和市净率为计算时的最新数据，不是报告期当时的数据",
+	"同一只股票在各报告期使用相同的市值和估值，风格漂移只反映持仓变化，不反映持仓股票自身市值和估值的变化",
+}
+
+// NewFundStyleHistory 按报告期排序并计算漂移度和风格箱变化次数
+func NewFundStyleHistory(fundCode string, styles []FundStyle) FundStyleHistory {
+	sort.SliceStable(styles, func(i, j int) bool {
+		return styles[i].ReportDate < styles[j].ReportDate
+	})
+	history := FundStyleHistory{FundCode: fundCode, Styles: styles, Drift: FundStyleDrift(styles), Limitations: fundStyleLimitations}
+	for i := 1; i < len(styles); i++ {
+		if styles[i].Box != styles[i-1].Box {
+			history.BoxChanges++
+		}
+	}
+	return history
+}
+
+// QueryStockStyleData 批量获取股票的市值和估值，以股票代码为 key
+func QueryStockStyleData(ctx context.Context, codes []string) (map[string]eastmoney.StockInfo, error) {
+	result := map[string]eastmoney.StockInfo{}
+	// 分批查询，避免请求参数过长
+	batch := 100
+	for i := 0; i < len(codes); i += batch {
+		end := i + batch
+		if end > len(codes) {
+			end = len(codes)
+		}
+		filter := eastmoney.Filter{SpecialSecurityCodeList: codes[i:end]}
+		stocks, err := datacenter.EastMoney.QuerySelectedStocksWithFilter(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stocks {
+			result[s.SecurityCode] = s
+		}
+	}
+	return result, nil
+}
+
+// SaveFundStyles 保存基金各报告期风格，每期记录截至该期的漂移度
+func SaveFundStyles(ctx context.Context, history FundStyleHistory) error {
+	if DB == nil || len(history.Styles) == 0 {
+		return nil
+	}
+	rows := make([]FundStyleDB, 0, len(history.Styles))
+	for i, s := range history.Styles {
+		rows = append(rows, ToFundStyleDB(s, FundStyleDrift(history.Styles[:i+1])))
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("fund_code = ?", history.FundCode).Delete(&FundStyleDB{}).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// LoadFundStyleHistory 从数据库加载基金风格历史
+func LoadFundStyleHistory(ctx context.Context, fundCode string) (FundStyleHistory, error) {
+	if DB == nil {
+		return FundStyleHistory{}, errors.New("database not initialized")
+	}
+	rows := []FundStyleDB{}
+	if err := DB.WithContext(ctx).Where("fund_code = ?", fundCode).Order("report_date").Find(&rows).Error; err != nil {
+		return FundStyleHistory{}, err
+	}
+	styles := make([]FundStyle, 0, len(rows))
+	for _, r := range rows {
+		styles = append(styles, r.ToFundStyle())
+	}
+	return NewFundStyleHistory(fundCode, styles), nil
+}
+
+// loadFundHoldingsByPeriod 加载基金全部报告期的持仓，以报告期为 key
+func loadFundHoldingsByPeriod(ctx context.Context, fundCodes []string) (map[string]map[string][]FundStockDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []FundStockDB{}
+	if err := DB.Where("fund_code IN ? AND report_date <> ''", fundCodes).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := map[string]map[string][]FundStockDB{}
+	for _, r := range rows {
+		if result[r.FundCode] == nil {
+			result[r.FundCode] = map[string][]FundStockDB{}
+		}
+		result[r.FundCode][r.ReportDate] = append(result[r.FundCode][r.ReportDate], r)
+	}
+	return result, nil
+}
+
+// computeFundStyles 计算多只基金的风格历史并保存
+func computeFundStyles(ctx context.Context, fundCodes []string) ([]FundStyleHistory, error) {
+	holdings, err := loadFundHoldingsByPeriod(ctx, fundCodes)
+	if err != nil {
+		return nil, err
+	}
+	codes := []string{}
+	seen := map[string]bool{}
+	for _, periods := range holdings {
+		for _, rows := range periods {
+			for _, r := range rows {
+				if !seen[r.StockCode] {
+					seen[r.StockCode] = true
+					codes = append(codes, r.StockCode)
+				}
+			}
+		}
+	}
+	stocks, err := QueryStockStyleData(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []FundStyleHistory{}
+	for _, fundCode := range fundCodes {
+		periods := holdings[fundCode]
+		if len(periods) == 0 {
+			continue
+		}
+		styles := []FundStyle{}
+		for period, rows := range periods {
+			styles = append(styles, ClassifyFundStyle(fundCode, period, rows, stocks, DefaultStyleThresholds))
+		}
+		history := NewFundStyleHistory(fundCode, styles)
+		if err := SaveFundStyles(ctx, history); err != nil {
+			logrus.WithContext(ctx).Errorf("computeFundStyles SaveFundStyles fund:%s err:%v", fundCode, err)
+		}
+		result = append(result, history)
+	}
+	return result, nil
+}
+
+// GetFundStyleHistory 按数据库中的历史持仓计算基金风格历史并保存
+func GetFundStyleHistory(ctx context.Context, fundCode string) (FundStyleHistory, error) {
+	histories, err := computeFundStyles(ctx, []string{fundCode})
+	if err != nil {
+		return FundStyleHistory{}, err
+	}
+	if len(histories) == 0 {
+		return FundStyleHistory{}, ErrFundHoldingPeriodsNotEnough
+	}
+	return histories[0], nil
+}
+
+// SyncFundStyles 计算全部股票型和混合型基金的风格历史并保存，返回基金数量
+func SyncFundStyles(ctx context.Context) (int, error) {
+	if DB == nil {
+		return 0, errors.New("database not initialized")
+	}
+	fundCodes := []string{}
+	if err := DB.Model(&FundDB{}).Where("type LIKE ? OR type LIKE ?", "%股票%", "%混合%").
+		Pluck("code", &fundCodes).Error; err != nil {
+		return 0, err
+	}
+	count := 0
+	// 分批计算，控制单次加载的持仓数量
+	batch := 500
+	for i := 0; i < len(fundCodes); i += batch {
+		end := i + batch
+		if end > len(fundCodes) {
+			end = len(fundCodes)
+		}
+		histories, err := computeFundStyles(ctx, fundCodes[i:end])
+		if err != nil {
+			return count, err
+		}
+		count += len(histories)
+	}
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyFundStyle(t *testing.T) {
+	stocks := map[string]eastmoney.StockInfo{
+		// 大盘价值
+		"601398": {SecurityCode: "601398", TotalMarketCap: 2000e8, PE: 5, PBNewMRQ: 0.6},
+		"600036": {SecurityCode: "600036", TotalMarketCap: 800e8, PE: 6, PBNewMRQ: 0.9},
+		// 小盘成长
+		"300999": {SecurityCode: "300999", TotalMarketCap: 50e8, PE: 80, PBNewMRQ: 6},
+		// 中盘亏损股
+		"002001": {SecurityCode: "002001", TotalMarketCap: 200e8, PE: -10, PBNewMRQ: 1.5},
+	}
+	holdings := []FundStockDB{
+		{StockCode: "601398", HoldRatio: 10},
+		{StockCode: "600036", HoldRatio: 10},
+		{StockCode: "00700", HoldRatio: 5},
+	}
+	style := ClassifyFundStyle("000001", "2023-12-31", holdings, stocks, DefaultStyleThresholds)
+	require.Equal(t, FundSizeLarge, style.Size)
+	require.Equal(t, FundStyleValue, style.Style)
+	require.Equal(t, "large_value", style.Box)
+	require.Equal(t, 80.0, style.Coverage)
+	require.InDelta(t, 1400e8, style.MarketCap, 1)
+	require.InDelta(t, 5.5, style.PE, 1e-9)
+
+	holdings = []FundStockDB{
+		{StockCode: "300999", HoldRatio: 10},
+		{StockCode: "002001", HoldRatio: 10},
+	}
+	style = ClassifyFundStyle("000001", "2024-06-30", holdings, stocks, DefaultStyleThresholds)
+	require.Equal(t, FundSizeSmall, style.Size)
+	require.Equal(t, FundStyleGrowth, style.Style)
+	require.Equal(t, -0.5, style.SizeScore)
+	require.Equal(t, 0.5, style.StyleScore)
+	// 亏损股不计入平均市盈率
+	require.Equal(t, 80.0, style.PE)
+
+	style = ClassifyFundStyle("000001", "2024-06-30", nil, stocks, DefaultStyleThresholds)
+	require.Equal(t, "mid_blend", style.Box)
+	require.Equal(t, 0.0, style.Coverage)
+}
+
+func TestNewFundStyleHistory(t *testing.T) {
+	styles := []FundStyle{
+		{ReportDate: "2024-06-30", Box: "small_growth", SizeScore: -1, StyleScore: 1},
+		{ReportDate: "2023-12-31", Box: "large_value", SizeScore: 1, StyleScore: -1},
+		{ReportDate: "2024-12-31", Box: "small_growth", SizeScore: -1, StyleScore: 1},
+	}
+	history := NewFundStyleHistory("000001", styles)
+	require.Equal(t, "2023-12-31", history.Styles[0].ReportDate)
+	require.Equal(t, 1, history.BoxChanges)
+	// 一次对角线移动 + 一次不动
+	require.InDelta(t, 50.0, history.Drift, 1e-9)
+	require.NotEmpty(t, history.Limitations)
+
+	require.Equal(t, 0.0, FundStyleDrift(styles[:1]))
+	require.Len(t, FundStyleBoxes, 9)
+}
@@ -6,6 +6,7 @@ package models
 import (
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -37,12 +38,47 @@ var (
 	// StockIndustryList 东方财富股票行业列表
 	StockIndustryList []string
 	SyncFundTime      = time.Now()
-	// AAACompanyBondSyl AAA公司债当期收益率
-	AAACompanyBondSyl = -1.0 // datacenter.ChinaBond.QueryAAACompanyBondSyl(context.Background())
 	// DB 全局数据库连接
 	DB *gorm.DB
 )
 
+// 债券收益率，由 cron.SyncBond 更新，定时任务和请求并发读写，通过 bondYieldMu 保护
+var (
+	bondYieldMu sync.RWMutex
+	// aaaCompanyBondSyl AAA公司债当期收益率
+	aaaCompanyBondSyl = -1.0
+	// treasuryYield10Y 10年期国债收益率，用作无风险利率
+	treasuryYield10Y = -1.0
+)
+
+// AAACompanyBondSyl 返回AAA公司债当期收益率，未同步时为 -1
+func AAACompanyBondSyl() float64 {
+	bondYieldMu.RLock()
+	defer bondYieldMu.RUnlock()
+	return aaaCompanyBondSyl
+}
+
+// SetAAACompanyBondSyl 更新AAA公司债当期收益率
+func SetAAACompanyBondSyl(y float64) {
+	bondYieldMu.Lock()
+	defer bondYieldMu.Unlock()
+	aaaCompanyBondSyl = y
+}
+
+// TreasuryYield10Y 返回10年期国债收益率，未同步时为 -1
+func TreasuryYield10Y() float64 {
+	bondYieldMu.RLock()
+	defer bondYieldMu.RUnlock()
+	return treasuryYield10Y
+}
+
+// SetTreasuryYield10Y 更新10年期国债收益率
+func SetTreasuryYield10Y(y float64) {
+	bondYieldMu.Lock()
+	defer bondYieldMu.Unlock()
+	treasuryYield10Y = y
+}
+
 // LoadDatabaseConfig 从配置文件加载数据库配置
 func LoadDatabaseConfig(configFile string) error {
 	if configFile == "" {
@@ -117,9 +153,45 @@ func InitDatabase() error {
 	logrus.Info("database connected successfully")
 
 	// 自动迁移数据库表结构
-	if err := DB.AutoMigrate(&FundDB{}, &FundStockDB{}, &FundManagerRelationDB{}, &IndustryDB{}, &FundManagerDB{}, &FundManagerFundsDB{}, &FundDividendDB{}, &FundAssetsProportionDB{}, &FundIndustryProportionDB{}); err != nil {
+	if err := DB.AutoMigrate(
+		&FundDB{},
+		&FundStockDB{},
+		&FundManagerRelationDB{},
+		&IndustryDB{},
+		&FundManagerDB{},
+		&FundManagerFundsDB{},
+		&FundDividendDB{},
+		&FundAssetsProportionDB{},
+		&FundIndustryProportionDB{},
+		&StockDividendDB{},
+		&StockPriceDB{},
+		&StockMoneyFlowDB{},
+		&BondYieldDB{},
+		&MarketTemperatureDB{},
+		&IndexValuationDB{},
+		&IndexConstituentDB{},
+		&IndustryDailyDB{},
+		&FundStyleDB{},
+		&FundNavDB{},
+		&FundScaleDB{},
+		&WatchlistDB{},
+		&WatchlistItemDB{},
+		&ScreenPresetDB{},
+		&AlertRuleDB{},
+		&AlertStateDB{},
+		&AlertDB{},
+		&ReportDB{},
+		&UserDB{},
+		&APITokenDB{},
+	); err != nil {
 		return fmt.Errorf("failed to auto migrate database: %w", err)
 	}
+	// 自选列表改为按用户唯一，删除旧的名称唯一索引
+	if DB.Migrator().HasIndex(&WatchlistDB{}, "idx_watchlists_name") {
+		if err := DB.Migrator().DropIndex(&WatchlistDB{}, "idx_watchlists_name"); err != nil {
+			return fmt.Errorf("failed to drop watchlist name index: %w", err)
+		}
+	}
 	logrus.Info("database tables migrated successfully")
 
 	return nil
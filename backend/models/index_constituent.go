@@ -0,0 +1,233 @@
+// 指数成分股快照、快照对比及多指数集合运算
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// 成分股集合运算
+const (
+	IndexSetOpUnion      = "union"
+	IndexSetOpIntersect  = "intersect"
+	IndexSetOpDifference = "difference"
+)
+
+// ErrInvalidIndexSetOp 不支持的集合运算
+var ErrInvalidIndexSetOp = errors.New("invalid index set op")
+
+// IndexConstituentSnapshot 指数某日的成分股快照
+type IndexConstituentSnapshot struct {
+	IndexCode    string                       `json:"index_code"`
+	Date         string                       `json:"date"`
+	Constituents []eastmoney.IndexConstituent `json:"constituents"`
+}
+
+// Codes 返回快照中的股票代码
+func (s IndexConstituentSnapshot) Codes() []string {
+	codes := make([]string, 0, len(s.Constituents))
+	for _, c := range s.Constituents {
+		codes = append(codes, c.StockCode)
+	}
+	return codes
+}
+
+// SaveIndexConstituents 保存指数成分股快照，覆盖同一指数同一日期的旧快照
+func SaveIndexConstituents(ctx context.Context, indexCode, date string, list []eastmoney.IndexConstituent) error {
+	if DB == nil || len(list) == 0 {
+		return nil
+	}
+	if err := DB.Where("index_code = ? AND date = ?", indexCode, date).Delete(&IndexConstituentDB{}).Error; err != nil {
+		return err
+	}
+	return DB.CreateInBatches(ToIndexConstituentsDB(date, list), 500).Error
+}
+
+// LoadIndexConstituents 加载指数在 date 当日或之前最近一次的成分股快照，date 为空时返回最新快照
+func LoadIndexConstituents(ctx context.Context, indexCode, date string) (IndexConstituentSnapshot, error) {
+	snapshot := IndexConstituentSnapshot{IndexCode: indexCode, Constituents: []eastmoney.IndexConstituent{}}
+	if DB == nil {
+		return snapshot, errors.New("database not initialized")
+	}
+	latest := IndexConstituentDB{}
+	query := DB.Where("index_code = ?", indexCode)
+	if date != "" {
+		query = query.Where("date <= ?", date)
+	}
+	if err := query.Order("date DESC").Limit(1).Find(&latest).Error; err != nil {
+		return snapshot, err
+	}
+	if latest.ID == 0 {
+		return snapshot, fmt.Errorf("no constituent snapshot of %s before %s", indexCode, date)
+	}
+	rows := []IndexConstituentDB{}
+	if err := DB.Where("index_code = ? AND date = ?", indexCode, latest.Date).Order("weight DESC").Find(&rows).Error; err != nil {
+		return snapshot, err
+	}
+	snapshot.Date = latest.Date
+	for _, r := range rows {
+		snapshot.Constituents = append(snapshot.Constituents, r.ToIndexConstituent())
+	}
+	return snapshot, nil
+}
+
+// LoadIndexConstituentDates 加载指数已保存快照的日期，按日期升序
+func LoadIndexConstituentDates(ctx context.Context, indexCode string) ([]string, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	dates := []string{}
+	err := DB.Model(&IndexConstituentDB{}).Where("index_code = ?", indexCode).
+		Distinct("date").Order("date").Pluck("date", &dates).Error
+	return dates, err
+}
+
+// GetIndexConstituents 获取指数当前成分股并保存当日快照，接口失败时返回最新保存的快照
+func GetIndexConstituents(ctx context.Context, indexCode string) (IndexConstituentSnapshot, error) {
+	list, err := datacenter.EastMoney.QueryIndexConstituents(ctx, indexCode)
+	if err != nil || len(list) == 0 {
+		if err != nil {
+			logrus.WithContext(ctx).Error("GetIndexConstituents QueryIndexConstituents err:" + err.Error())
+		} else {
+			err = fmt.Errorf("no constituents of index %s", indexCode)
+		}
+		if snapshot, lerr := LoadIndexConstituents(ctx, indexCode, ""); lerr == nil {
+			return snapshot, nil
+		}
+		return IndexConstituentSnapshot{IndexCode: indexCode}, err
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Weight > list[j].Weight
+	})
+	date := time.Now().Format("2006-01-02")
+	if err := SaveIndexConstituents(ctx, indexCode, date, list); err != nil {
+		logrus.WithContext(ctx).Error("GetIndexConstituents SaveIndexConstituents err:" + err.Error())
+	}
+	return IndexConstituentSnapshot{IndexCode: indexCode, Date: date, Constituents: list}, nil
+}
+
+// IndexWeightChange 成分股权重变化
+type IndexWeightChange struct {
+	StockCode  string  `json:"stock_code"`
+	StockName  string  `json:"stock_name"`
+	FromWeight float64 `json:"from_weight"`
+	ToWeight   float64 `json:"to_weight"`
+	// 权重变化（百分点）
+	Change float64 `json:"change"`
+}
+
+// IndexConstituentDiff 两个快照之间的成分股变化
+type IndexConstituentDiff struct {
+	IndexCode string `json:"index_code"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	// 调入
+	Added []eastmoney.IndexConstituent `json:"added"`
+	// 调出
+	Removed []eastmoney.IndexConstituent `json:"removed"`
+	// 保留成分股的权重变化，按变化绝对值降序
+	WeightChanges []IndexWeightChange `json:"weight_changes"`
+}
+
+// DiffIndexConstituents 对比两个快照的成分股调入、调出和权重变化
+func DiffIndexConstituents(from, to IndexConstituentSnapshot) IndexConstituentDiff {
+	diff := IndexConstituentDiff{
+		IndexCode:     to.IndexCode,
+		From:          from.Date,
+		To:            to.Date,
+		Added:         []eastmoney.IndexConstituent{},
+		Removed:       []eastmoney.IndexConstituent{},
+		WeightChanges: []IndexWeightChange{},
+	}
+	fromMap := map[string]eastmoney.IndexConstituent{}
+	for _, c := range from.Constituents {
+		fromMap[c.StockCode] = c
+	}
+	toMap := map[string]bool{}
+	for _, c := range to.Constituents {
+		toMap[c.StockCode] = true
+		old, ok := fromMap[c.StockCode]
+		if !ok {
+			diff.Added = append(diff.Added, c)
+			continue
+		}
+		if c.Weight != old.Weight {
+			diff.WeightChanges = append(diff.WeightChanges, IndexWeightChange{
+				StockCode:  c.StockCode,
+				StockName:  c.StockName,
+				FromWeight: old.Weight,
+				ToWeight:   c.Weight,
+				Change:     c.Weight - old.Weight,
+			})
+		}
+	}
+	for _, c := range from.Constituents {
+		if !toMap[c.StockCode] {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+	sort.SliceStable(diff.WeightChanges, func(i, j int) bool {
+		return math.Abs(diff.WeightChanges[i].Change) > math.Abs(diff.WeightChanges[j].Change)
+	})
+	return diff
+}
+
+// IndexSetMember 集合运算结果中的股票
+type IndexSetMember struct {
+	StockCode string `json:"stock_code"`
+	StockName string `json:"stock_name"`
+	// 股票所在的参与运算的指数及权重
+	Weights map[string]float64 `json:"weights"`
+}
+
+// IndexConstituentSet 对多个指数的成分股做集合运算，按股票代码排序
+// union 并集，intersect 交集，difference 在第一个指数中但不在其余指数中
+func IndexConstituentSet(op string, snapshots []IndexConstituentSnapshot) ([]IndexSetMember, error) {
+	if op != IndexSetOpUnion && op != IndexSetOpIntersect && op != IndexSetOpDifference {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIndexSetOp, op)
+	}
+	result := []IndexSetMember{}
+	if len(snapshots) == 0 {
+		return result, nil
+	}
+	members := map[string]*IndexSetMember{}
+	for _, s := range snapshots {
+		for _, c := range s.Constituents {
+			m, ok := members[c.StockCode]
+			if !ok {
+				m = &IndexSetMember{StockCode: c.StockCode, StockName: c.StockName, Weights: map[string]float64{}}
+				members[c.StockCode] = m
+			}
+			m.Weights[s.IndexCode] = c.Weight
+		}
+	}
+
+	for _, m := range members {
+		keep := false
+		switch op {
+		case IndexSetOpUnion:
+			keep = true
+		case IndexSetOpIntersect:
+			keep = len(m.Weights) == len(snapshots)
+		case IndexSetOpDifference:
+			_, inFirst := m.Weights[snapshots[0].IndexCode]
+			keep = inFirst && len(m.Weights) == 1
+		}
+		if keep {
+			result = append(result, *m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StockCode < result[j].StockCode
+	})
+	return result, nil
+}
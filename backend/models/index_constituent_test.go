@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffIndexConstituents(t *testing.T) {
+	from := IndexConstituentSnapshot{IndexCode: "000016", Date: "2023-06-01", Constituents: []eastmoney.IndexConstituent{
+		{StockCode: "600519", Weight: 15},
+		{StockCode: "601318", Weight: 8},
+		{StockCode: "600036", Weight: 6},
+	}}
+	to := IndexConstituentSnapshot{IndexCode: "000016", Date: "2023-12-11", Constituents: []eastmoney.IndexConstituent{
+		{StockCode: "600519", Weight: 14},
+		{StockCode: "601318", Weight: 5},
+		{StockCode: "601012", Weight: 3},
+	}}
+	diff := DiffIndexConstituents(from, to)
+	require.Equal(t, "2023-06-01", diff.From)
+	require.Len(t, diff.Added, 1)
+	require.Equal(t, "601012", diff.Added[0].StockCode)
+	require.Len(t, diff.Removed, 1)
+	require.Equal(t, "600036", diff.Removed[0].StockCode)
+	require.Len(t, diff.WeightChanges, 2)
+	require.Equal(t, "601318", diff.WeightChanges[0].StockCode)
+	require.Equal(t, -3.0, diff.WeightChanges[0].Change)
+}
+
+func TestIndexConstituentSet(t *testing.T) {
+	snapshots := []IndexConstituentSnapshot{
+		{IndexCode: "A", Constituents: []eastmoney.IndexConstituent{{StockCode: "1", Weight: 1}, {StockCode: "2", Weight: 2}}},
+		{IndexCode: "B", Constituents: []eastmoney.IndexConstituent{{StockCode: "2", Weight: 3}, {StockCode: "3"}}},
+		{IndexCode: "C", Constituents: []eastmoney.IndexConstituent{{StockCode: "2"}, {StockCode: "4"}}},
+	}
+	union, err := IndexConstituentSet(IndexSetOpUnion, snapshots)
+	require.Nil(t, err)
+	require.Len(t, union, 4)
+
+	intersect, err := IndexConstituentSet(IndexSetOpIntersect, snapshots)
+	require.Nil(t, err)
+	require.Len(t, intersect, 1)
+	require.Equal(t, "2", intersect[0].StockCode)
+	require.Equal(t, 3.0, intersect[0].Weights["B"])
+
+	diff, err := IndexConstituentSet(IndexSetOpDifference, snapshots)
+	require.Nil(t, err)
+	require.Len(t, diff, 1)
+	require.Equal(t, "1", diff[0].StockCode)
+
+	_, err = IndexConstituentSet("xor", snapshots)
+	require.ErrorIs(t, err, ErrInvalidIndexSetOp)
+}
@@ -0,0 +1,184 @@
+// 指数基金跟踪质量：跟踪误差、跟踪差、费率、规模和流动性
+
+package models
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/axiaoxin-com/investool/indicators"
+)
+
+// 指数基金类别
+const (
+	IndexFundCategoryETF     = "ETF"
+	IndexFundCategoryETFLink = "ETF联接"
+	IndexFundCategoryLOF     = "LOF"
+	IndexFundCategoryOTC     = "场外指数"
+)
+
+// MinTrackingDays 计算跟踪误差需要的最少共同交易日数
+const MinTrackingDays = 20
+
+// IndexFundTracking 指数基金跟踪质量
+type IndexFundTracking struct {
+	// 基金代码
+	Code string `json:"code"`
+	// 基金名称
+	Name string `json:"name"`
+	// 类别：ETF/ETF联接/LOF/场外指数
+	Category string `json:"category"`
+	// 基金规模（元）
+	Scale float64 `json:"scale"`
+	// 购买费率（%）
+	Fee float64 `json:"fee"`
+	// 参与计算的共同交易日数
+	Days int `json:"days"`
+	// 年化跟踪误差（%）
+	TrackingError float64 `json:"tracking_error"`
+	// 年化跟踪差（%）：基金年化收益 - 指数年化收益
+	TrackingDiff float64 `json:"tracking_diff"`
+	// 场内近20日日均成交额（元），场外基金为0
+	AvgAmount float64 `json:"avg_amount"`
+	// 综合得分（0-100）
+	Score float64 `json:"score"`
+}
+
+// IsExchangeTraded 是否为场内交易基金
+func (t IndexFundTracking) IsExchangeTraded() bool {
+	return t.Category == IndexFundCategoryETF || t.Category == IndexFundCategoryLOF
+}
+
+// IndexFundCategory 按基金名称判断指数基金类别
+func IndexFundCategory(name string) string {
+	upper := strings.ToUpper(name)
+	switch {
+	case strings.Contains(upper, "联接"):
+		return IndexFundCategoryETFLink
+	case strings.Contains(upper, "ETF"):
+		return IndexFundCategoryETF
+	case strings.Contains(upper, "LOF"):
+		return IndexFundCategoryLOF
+	}
+	return IndexFundCategoryOTC
+}
+
+// ParseFundRate 解析基金费率字符串，如 "0.12%"，无法解析时返回 0
+func ParseFundRate(rate string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(rate), "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// TrackingStats 按日期对齐基金与指数的日收益率（小数），返回共同交易日数、年化跟踪误差（%）和年化跟踪差（%）
+func TrackingStats(fundReturns, indexReturns map[string]float64) (days int, trackingError, trackingDiff float64) {
+	dates := []string{}
+	for d := range fundReturns {
+		if _, ok := indexReturns[d]; ok {
+			dates = append(dates, d)
+		}
+	}
+	days = len(dates)
+	if days < 2 {
+		return
+	}
+	sort.Strings(dates)
+	diffs := make([]float64, 0, days)
+	fundNav, indexNav := 1.0, 1.0
+	for _, d := range dates {
+		diffs = append(diffs, fundReturns[d]-indexReturns[d])
+		fundNav *= 1 + fundReturns[d]
+		indexNav *= 1 + indexReturns[d]
+	}
+	mean := 0.0
+	for _, v := range diffs {
+		mean += v
+	}
+	mean /= float64(days)
+	variance := 0.0
+	for _, v := range diffs {
+		variance += (v - mean) * (v - mean)
+	}
+	trackingError = math.Sqrt(variance/float64(days-1)) * math.Sqrt(indicators.TradingDaysPerYear) * 100
+	years := float64(days) / indicators.TradingDaysPerYear
+	trackingDiff = (math.Pow(fundNav, 1/years) - math.Pow(indexNav, 1/years)) * 100
+	return
+}
+
+// ScoreIndexFunds 按跟踪差（40%）、跟踪误差（30%）、费率（15%）、规模（15%）在同指数基金中的排名计算得分，并按得分降序排列
+// 数据不足 MinTrackingDays 的基金得分为 0
+func ScoreIndexFunds(list []IndexFundTracking) {
+	valid := []int{}
+	for i, t := range list {
+		if t.Days >= MinTrackingDays {
+			valid = append(valid, i)
+		}
+	}
+	tds, tes, fees, scales := []float64{}, []float64{}, []float64{}, []float64{}
+	for _, i := range valid {
+		tds = append(tds, list[i].TrackingDiff)
+		tes = append(tes, list[i].TrackingError)
+		fees = append(fees, list[i].Fee)
+		scales = append(scales, list[i].Scale)
+	}
+	for _, i := range valid {
+		t := &list[i]
+		t.Score = 0.4*indicators.PercentileRank(tds, t.TrackingDiff) +
+			0.3*lowerIsBetterRank(tes, t.TrackingError) +
+			0.15*lowerIsBetterRank(fees, t.Fee) +
+			0.15*indicators.PercentileRank(scales, t.Scale)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Score > list[j].Score
+	})
+}
+
+// lowerIsBetterRank 数值越小排名越高的百分位（0-100）
+func lowerIsBetterRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	count := 0
+	for _, i := range values {
+		if i >= v {
+			count++
+		}
+	}
+	return float64(count) / float64(len(values)) * 100
+}
+
+// IndexFundRecommendation 指数基金推荐结果
+type IndexFundRecommendation struct {
+	// 综合最优
+	Best *IndexFundTracking `json:"best"`
+	// 场内（ETF/LOF）最优，要求有成交
+	BestExchange *IndexFundTracking `json:"best_exchange"`
+	// 场外（联接/场外指数）最优
+	BestOTC *IndexFundTracking `json:"best_otc"`
+}
+
+// RecommendIndexFund 从已按得分排序的列表中选出推荐基金
+func RecommendIndexFund(list []IndexFundTracking) IndexFundRecommendation {
+	r := IndexFundRecommendation{}
+	for i := range list {
+		t := &list[i]
+		if t.Score <= 0 {
+			continue
+		}
+		if r.Best == nil {
+			r.Best = t
+		}
+		if t.IsExchangeTraded() {
+			if r.BestExchange == nil && t.AvgAmount > 0 {
+				r.BestExchange = t
+			}
+		} else if r.BestOTC == nil {
+			r.BestOTC = t
+		}
+	}
+	return r
+}
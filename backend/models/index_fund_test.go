@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexFundCategory(t *testing.T) {
+	require.Equal(t, IndexFundCategoryETFLink, IndexFundCategory("华泰柏瑞沪深300ETF联接A"))
+	require.Equal(t, IndexFundCategoryETF, IndexFundCategory("华泰柏瑞沪深300ETF"))
+	require.Equal(t, IndexFundCategoryLOF, IndexFundCategory("某某沪深300指数(LOF)A"))
+	require.Equal(t, IndexFundCategoryOTC, IndexFundCategory("易方达沪深300指数增强A"))
+	require.Equal(t, 0.12, ParseFundRate("0.12%"))
+	require.Equal(t, 0.0, ParseFundRate("--"))
+}
+
+func TestTrackingStats(t *testing.T) {
+	fund := map[string]float64{"2023-01-03": 0.011, "2023-01-04": -0.009, "2023-01-05": 0.002, "2023-01-06": 0.01}
+	index := map[string]float64{"2023-01-03": 0.01, "2023-01-04": -0.01, "2023-01-05": 0.002}
+	days, te, td := TrackingStats(fund, index)
+	require.Equal(t, 3, days)
+	require.Greater(t, te, 0.0)
+	require.Greater(t, td, 0.0)
+
+	// 完全复制指数时跟踪误差和跟踪差为 0
+	days, te, td = TrackingStats(index, index)
+	require.Equal(t, 3, days)
+	require.InDelta(t, 0.0, te, 1e-9)
+	require.InDelta(t, 0.0, td, 1e-9)
+}
+
+func TestScoreIndexFunds(t *testing.T) {
+	list := []IndexFundTracking{
+		{Code: "a", Category: IndexFundCategoryETFLink, Days: 250, TrackingDiff: -1, TrackingError: 1, Fee: 0.12, Scale: 1e9},
+		{Code: "b", Category: IndexFundCategoryETF, Days: 250, TrackingDiff: 1, TrackingError: 0.5, Fee: 0, Scale: 1e10, AvgAmount: 1e8},
+		{Code: "c", Category: IndexFundCategoryOTC, Days: 10, TrackingDiff: 5, TrackingError: 0.1, Fee: 0, Scale: 1e11},
+	}
+	ScoreIndexFunds(list)
+	require.Equal(t, "b", list[0].Code)
+	require.Equal(t, "a", list[1].Code)
+	require.Equal(t, 0.0, list[2].Score)
+
+	r := RecommendIndexFund(list)
+	require.Equal(t, "b", r.Best.Code)
+	require.Equal(t, "b", r.BestExchange.Code)
+	require.Equal(t, "a", r.BestOTC.Code)
+}
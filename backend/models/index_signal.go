@@ -0,0 +1,215 @@
+// 指数定投信号：按估值百分位区间给出加倍、正常、暂停、止盈建议，并在已保存的估值历史上回测
+
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 定投信号
+const (
+	InvestSignalIncrease   = "increase"
+	InvestSignalNormal     = "normal"
+	InvestSignalPause      = "pause"
+	InvestSignalTakeProfit = "take_profit"
+)
+
+// 估值百分位指标
+const (
+	SignalMetricPE = "pe"
+	SignalMetricPB = "pb"
+)
+
+// ErrInvalidSignalBands 信号区间配置不合法
+var ErrInvalidSignalBands = errors.New("invalid signal bands")
+
+// InvestSignalDesc 定投信号说明
+func InvestSignalDesc(signal string) string {
+	switch signal {
+	case InvestSignalIncrease:
+		return "加倍定投"
+	case InvestSignalNormal:
+		return "正常定投"
+	case InvestSignalPause:
+		return "暂停定投"
+	case InvestSignalTakeProfit:
+		return "分批止盈"
+	}
+	return "--"
+}
+
+// SignalBands 定投信号的百分位区间
+// 百分位 < IncreaseBelow 加倍定投，< NormalBelow 正常定投，< PauseBelow 暂停定投，其余止盈
+type SignalBands struct {
+	// 使用的百分位指标：pe/pb
+	Metric string `json:"metric"`
+	// 加倍定投的百分位上限
+	IncreaseBelow float64 `json:"increase_below"`
+	// 正常定投的百分位上限
+	NormalBelow float64 `json:"normal_below"`
+	// 暂停定投的百分位上限
+	PauseBelow float64 `json:"pause_below"`
+	// 加倍定投时的金额倍数
+	IncreaseMultiple float64 `json:"increase_multiple"`
+	// 每次止盈卖出的持仓比例（0-1）
+	TakeProfitRatio float64 `json:"take_profit_ratio"`
+}
+
+// DefaultSignalBands 默认信号区间：PE 百分位 30/70/90
+func DefaultSignalBands() SignalBands {
+	return SignalBands{
+		Metric:           SignalMetricPE,
+		IncreaseBelow:    30,
+		NormalBelow:      70,
+		PauseBelow:       90,
+		IncreaseMultiple: 2,
+		TakeProfitRatio:  0.5,
+	}
+}
+
+// Validate 检查区间配置
+func (b SignalBands) Validate() error {
+	if b.Metric != SignalMetricPE && b.Metric != SignalMetricPB {
+		return fmt.Errorf("%w: metric %q", ErrInvalidSignalBands, b.Metric)
+	}
+	if !(0 <= b.IncreaseBelow && b.IncreaseBelow <= b.NormalBelow && b.NormalBelow <= b.PauseBelow && b.PauseBelow <= 100) {
+		return fmt.Errorf("%w: bands must be ascending within 0-100", ErrInvalidSignalBands)
+	}
+	if b.IncreaseMultiple < 1 {
+		return fmt.Errorf("%w: increase multiple must >= 1", ErrInvalidSignalBands)
+	}
+	if b.TakeProfitRatio < 0 || b.TakeProfitRatio > 1 {
+		return fmt.Errorf("%w: take profit ratio must within 0-1", ErrInvalidSignalBands)
+	}
+	return nil
+}
+
+// Percentile 返回估值记录中所选指标的百分位
+func (b SignalBands) Percentile(v IndexValuationDB) float64 {
+	if b.Metric == SignalMetricPB {
+		return v.PBPercentile
+	}
+	return v.PEPercentile
+}
+
+// Signal 按百分位返回定投信号及本期定投金额倍数
+func (b SignalBands) Signal(percentile float64) (string, float64) {
+	switch {
+	case percentile < b.IncreaseBelow:
+		return InvestSignalIncrease, b.IncreaseMultiple
+	case percentile < b.NormalBelow:
+		return InvestSignalNormal, 1
+	case percentile < b.PauseBelow:
+		return InvestSignalPause, 0
+	}
+	return InvestSignalTakeProfit, 0
+}
+
+// IndexSignal 指数当前定投信号
+type IndexSignal struct {
+	IndexCode string `json:"index_code"`
+	IndexName string `json:"index_name"`
+	// 估值日期
+	Date       string  `json:"date"`
+	Metric     string  `json:"metric"`
+	Percentile float64 `json:"percentile"`
+	Signal     string  `json:"signal"`
+	SignalDesc string  `json:"signal_desc"`
+	// 本期定投金额倍数
+	Multiple float64 `json:"multiple"`
+}
+
+// NewIndexSignal 根据估值记录生成定投信号
+func NewIndexSignal(v IndexValuationDB, bands SignalBands) IndexSignal {
+	p := bands.Percentile(v)
+	signal, multiple := bands.Signal(p)
+	return IndexSignal{
+		IndexCode:  v.IndexCode,
+		IndexName:  v.IndexName,
+		Date:       v.Date,
+		Metric:     bands.Metric,
+		Percentile: p,
+		Signal:     signal,
+		SignalDesc: InvestSignalDesc(signal),
+		Multiple:   multiple,
+	}
+}
+
+// SignalBacktestResult 定投信号回测结果
+type SignalBacktestResult struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// 定投期数（月）
+	Periods int `json:"periods"`
+	// 每期基础定投金额
+	Amount float64 `json:"amount"`
+	// 按信号定投：累计投入、期末市值（含止盈现金）、收益率（%）
+	Invested float64 `json:"invested"`
+	Value    float64 `json:"value"`
+	Return   float64 `json:"return"`
+	// 止盈累计取回的现金
+	TakeProfitCash float64 `json:"take_profit_cash"`
+	// 普通定投：累计投入、期末市值、收益率（%）
+	PlainInvested float64 `json:"plain_invested"`
+	PlainValue    float64 `json:"plain_value"`
+	PlainReturn   float64 `json:"plain_return"`
+	// 各信号出现次数
+	SignalCounts map[string]int `json:"signal_counts"`
+}
+
+// BacktestIndexSignal 在按日期升序的估值历史上回测定投信号
+// 每月第一条估值记录按收盘点位定投一次 amount，与不看信号的普通定投比较
+func BacktestIndexSignal(history []IndexValuationDB, bands SignalBands, amount float64) (SignalBacktestResult, error) {
+	result := SignalBacktestResult{Amount: amount, SignalCounts: map[string]int{}}
+	if err := bands.Validate(); err != nil {
+		return result, err
+	}
+	if amount <= 0 {
+		return result, errors.New("amount must > 0")
+	}
+
+	shares, plainShares, lastPrice := 0.0, 0.0, 0.0
+	lastMonth := ""
+	for _, v := range history {
+		if v.Price <= 0 || len(v.Date) < 7 {
+			continue
+		}
+		lastPrice = v.Price
+		result.End = v.Date
+		if v.Date[:7] == lastMonth {
+			continue
+		}
+		lastMonth = v.Date[:7]
+		if result.Start == "" {
+			result.Start = v.Date
+		}
+		result.Periods++
+
+		plainShares += amount / v.Price
+		result.PlainInvested += amount
+
+		signal, multiple := bands.Signal(bands.Percentile(v))
+		result.SignalCounts[signal]++
+		if multiple > 0 {
+			shares += amount * multiple / v.Price
+			result.Invested += amount * multiple
+		}
+		if signal == InvestSignalTakeProfit && shares > 0 {
+			sell := shares * bands.TakeProfitRatio
+			shares -= sell
+			result.TakeProfitCash += sell * v.Price
+		}
+	}
+	if result.Periods == 0 {
+		return result, errors.New("no valuation history")
+	}
+
+	result.Value = shares*lastPrice + result.TakeProfitCash
+	result.PlainValue = plainShares * lastPrice
+	if result.Invested > 0 {
+		result.Return = (result.Value - result.Invested) / result.Invested * 100
+	}
+	result.PlainReturn = (result.PlainValue - result.PlainInvested) / result.PlainInvested * 100
+	return result, nil
+}
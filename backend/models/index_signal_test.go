@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalBands(t *testing.T) {
+	b := DefaultSignalBands()
+	require.Nil(t, b.Validate())
+	s, m := b.Signal(10)
+	require.Equal(t, InvestSignalIncrease, s)
+	require.Equal(t, 2.0, m)
+	s, _ = b.Signal(50)
+	require.Equal(t, InvestSignalNormal, s)
+	s, m = b.Signal(80)
+	require.Equal(t, InvestSignalPause, s)
+	require.Equal(t, 0.0, m)
+	s, _ = b.Signal(90)
+	require.Equal(t, InvestSignalTakeProfit, s)
+
+	b.NormalBelow = 20
+	require.ErrorIs(t, b.Validate(), ErrInvalidSignalBands)
+
+	b = DefaultSignalBands()
+	b.Metric = SignalMetricPB
+	signal := NewIndexSignal(IndexValuationDB{IndexCode: "000300", PEPercentile: 95, PBPercentile: 15}, b)
+	require.Equal(t, InvestSignalIncrease, signal.Signal)
+	require.Equal(t, 15.0, signal.Percentile)
+}
+
+func TestBacktestIndexSignal(t *testing.T) {
+	history := []IndexValuationDB{
+		{Date: "2023-01-03", Price: 1000, PEPercentile: 20},
+		// 同月只在第一条记录定投
+		{Date: "2023-01-04", Price: 1100, PEPercentile: 95},
+		{Date: "2023-02-01", Price: 800, PEPercentile: 50},
+		{Date: "2023-03-01", Price: 1000, PEPercentile: 95},
+	}
+	r, err := BacktestIndexSignal(history, DefaultSignalBands(), 1000)
+	require.Nil(t, err)
+	require.Equal(t, 3, r.Periods)
+	require.Equal(t, "2023-01-03", r.Start)
+	require.Equal(t, "2023-03-01", r.End)
+	require.InDelta(t, 3000.0, r.PlainInvested, 1e-9)
+	require.InDelta(t, 3250.0, r.PlainValue, 1e-9)
+	// 1月加倍买入2份，2月买入1.25份，3月止盈卖出一半
+	require.InDelta(t, 3000.0, r.Invested, 1e-9)
+	require.InDelta(t, 1625.0, r.TakeProfitCash, 1e-9)
+	require.InDelta(t, 3250.0, r.Value, 1e-9)
+	require.InDelta(t, 25.0/3, r.Return, 1e-9)
+	require.Equal(t, 1, r.SignalCounts[InvestSignalTakeProfit])
+
+	_, err = BacktestIndexSignal(nil, DefaultSignalBands(), 1000)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,94 @@
+// 指数每日估值存储，用于积累自有的估值历史
+
+package models
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// ToIndexValuationDB 将指数信息转换为 IndexValuationDB，估值日期缺失时使用当天
+func ToIndexValuationDB(data *eastmoney.IndexData) IndexValuationDB {
+	date := time.Now().Format("2006-01-02")
+	if len(data.PDate) >= 10 {
+		date = data.PDate[:10]
+	}
+	valuation, _ := strconv.Atoi(data.IndexvaluaCN)
+	return IndexValuationDB{
+		IndexCode:    data.IndexCode,
+		Date:         date,
+		IndexName:    data.IndexName,
+		Price:        parseIndexFloat(data.NewPrice),
+		PE:           parseIndexFloat(data.Petim),
+		PEPercentile: parseIndexFloat(data.Pep100),
+		PB:           parseIndexFloat(data.Pb),
+		PBPercentile: parseIndexFloat(data.Pbp100),
+		Valuation:    valuation,
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// SaveIndexValuation 保存指数估值，同一指数同一日期只保留一条
+func SaveIndexValuation(ctx context.Context, v IndexValuationDB) error {
+	if DB == nil {
+		return nil
+	}
+	return DB.Where(IndexValuationDB{IndexCode: v.IndexCode, Date: v.Date}).Assign(v).FirstOrCreate(&IndexValuationDB{}).Error
+}
+
+// QueryIndexValuation 获取指数当前估值并保存
+func QueryIndexValuation(ctx context.Context, indexCode string) (IndexValuationDB, error) {
+	data, err := datacenter.EastMoney.Index(ctx, indexCode)
+	if err != nil {
+		return IndexValuationDB{}, err
+	}
+	v := ToIndexValuationDB(data)
+	if v.IndexCode == "" {
+		v.IndexCode = indexCode
+	}
+	if err := SaveIndexValuation(ctx, v); err != nil {
+		logrus.WithContext(ctx).Error("QueryIndexValuation SaveIndexValuation err:" + err.Error())
+	}
+	return v, nil
+}
+
+// LoadIndexValuations 加载指数估值历史，按日期升序
+func LoadIndexValuations(ctx context.Context, indexCode, start, end string) ([]IndexValuationDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []IndexValuationDB{}
+	query := DB.Where("index_code = ?", indexCode)
+	if start != "" {
+		query = query.Where("date >= ?", start)
+	}
+	if end != "" {
+		query = query.Where("date <= ?", end)
+	}
+	err := query.Order("date").Find(&rows).Error
+	return rows, err
+}
+
+// SyncIndexValuations 同步指数列表的当前估值，返回成功保存的数量
+func SyncIndexValuations(ctx context.Context, indexCodes []string) (int, error) {
+	count := 0
+	var lastErr error
+	for _, code := range indexCodes {
+		if _, err := QueryIndexValuation(ctx, code); err != nil {
+			logrus.WithContext(ctx).Errorf("SyncIndexValuations code:%s err:%v", code, err)
+			lastErr = err
+			continue
+		}
+		count++
+	}
+	if count == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return count, nil
+}
@@ -0,0 +1,327 @@
+// 行业轮动：行业板块相对强弱、估值序列及全市场基金行业配置变化
+
+package models
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+// IndustryBenchmarkCode 计算行业相对强弱的基准指数：沪深300
+const IndustryBenchmarkCode = "000300"
+
+// SaveIndustryDailies 保存 date 日的行业板块行情，覆盖当日旧数据
+func SaveIndustryDailies(ctx context.Context, date string, boards []eastmoney.IndustryBoard) error {
+	if DB == nil || len(boards) == 0 {
+		return nil
+	}
+	if err := DB.Where("date = ?", date).Delete(&IndustryDailyDB{}).Error; err != nil {
+		return err
+	}
+	return DB.CreateInBatches(ToIndustryDailiesDB(date, boards), 500).Error
+}
+
+// SyncIndustryDailies 获取当前行业板块行情并保存，返回板块数量
+func SyncIndustryDailies(ctx context.Context) (int, error) {
+	boards, err := datacenter.EastMoney.QueryIndustryBoards(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := SaveIndustryDailies(ctx, time.Now().Format("2006-01-02"), boards); err != nil {
+		return 0, err
+	}
+	return len(boards), nil
+}
+
+// LoadIndustryDailies 加载行业板块的每日行情及估值，board 可以是板块代码或名称，按日期升序
+func LoadIndustryDailies(ctx context.Context, board, start, end string) ([]IndustryDailyDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []IndustryDailyDB{}
+	query := DB.Where("board_code = ? OR name = ?", board, board)
+	if start != "" {
+		query = query.Where("date >= ?", start)
+	}
+	if end != "" {
+		query = query.Where("date <= ?", end)
+	}
+	err := query.Order("date").Find(&rows).Error
+	return rows, err
+}
+
+// WindowReturn 按升序收盘价计算最近 window 个交易日的收益率（%），数据不足时返回 false
+func WindowReturn(closes []float64, window int) (float64, bool) {
+	n := len(closes)
+	if window <= 0 || n <= window || closes[n-1-window] <= 0 {
+		return 0, false
+	}
+	return (closes[n-1]/closes[n-1-window] - 1) * 100, true
+}
+
+// IndustryMomentum 行业动量
+type IndustryMomentum struct {
+	Code string  `json:"code"`
+	Name string  `json:"name"`
+	PE   float64 `json:"pe"`
+	PB   float64 `json:"pb"`
+	// 区间收益率（%）
+	Return float64 `json:"return"`
+	// 相对基准的强弱（%）：(1+行业收益)/(1+基准收益)-1
+	RelativeStrength float64 `json:"relative_strength"`
+	// 按相对强弱的排名，从 1 开始
+	Rank int `json:"rank"`
+}
+
+// RelativeStrength 行业相对基准的强弱（%）
+func RelativeStrength(ret, benchmarkRet float64) float64 {
+	if benchmarkRet <= -100 {
+		return 0
+	}
+	return ((1+ret/100)/(1+benchmarkRet/100) - 1) * 100
+}
+
+// RankIndustryMomentum 按相对强弱降序排列并设置排名
+func RankIndustryMomentum(list []IndustryMomentum) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].RelativeStrength > list[j].RelativeStrength
+	})
+	for i := range list {
+		list[i].Rank = i + 1
+	}
+}
+
+// IndustryAllocation 某报告期全部基金在某行业上的配置
+type IndustryAllocation struct {
+	PubDate  string `json:"pub_date"`
+	Industry string `json:"industry"`
+	// 配置该行业的基金数
+	FundCount int `json:"fund_count"`
+	// 该行业占基金净值比例的平均值（%），分母为该报告期披露行业配置的全部基金
+	AvgProp float64 `json:"avg_prop"`
+}
+
+// AggregateFundIndustryAllocation 按报告期和行业汇总基金行业配置，按报告期升序、平均占比降序
+func AggregateFundIndustryAllocation(rows []FundIndustryProportionDB) []IndustryAllocation {
+	type key struct{ date, industry string }
+	sums := map[key]*IndustryAllocation{}
+	props := map[key]float64{}
+	funds := map[string]map[string]bool{}
+	for _, r := range rows {
+		prop := ParseFundRate(r.Prop)
+		if prop <= 0 {
+			continue
+		}
+		if funds[r.PubDate] == nil {
+			funds[r.PubDate] = map[string]bool{}
+		}
+		funds[r.PubDate][r.FundCode] = true
+		k := key{r.PubDate, r.Industry}
+		if sums[k] == nil {
+			sums[k] = &IndustryAllocation{PubDate: r.PubDate, Industry: r.Industry}
+		}
+		sums[k].FundCount++
+		props[k] += prop
+	}
+	result := []IndustryAllocation{}
+	for k, a := range sums {
+		a.AvgProp = props[k] / float64(len(funds[k.date]))
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PubDate != result[j].PubDate {
+			return result[i].PubDate < result[j].PubDate
+		}
+		if result[i].AvgProp != result[j].AvgProp {
+			return result[i].AvgProp > result[j].AvgProp
+		}
+		return result[i].Industry < result[j].Industry
+	})
+	return result
+}
+
+// IndustryAllocationChange 基金行业配置在最近两个报告期之间的变化
+type IndustryAllocationChange struct {
+	Industry    string  `json:"industry"`
+	PrevPubDate string  `json:"prev_pub_date"`
+	PubDate     string  `json:"pub_date"`
+	PrevAvgProp float64 `json:"prev_avg_prop"`
+	AvgProp     float64 `json:"avg_prop"`
+	// 平均占比变化（百分点）
+	Change float64 `json:"change"`
+	// 配置基金数变化
+	FundCountChange int `json:"fund_count_change"`
+}
+
+// IndustryAllocationChanges 计算最近两个报告期的行业配置变化，按变化降序
+func IndustryAllocationChanges(allocs []IndustryAllocation) []IndustryAllocationChange {
+	dates := []string{}
+	seen := map[string]bool{}
+	for _, a := range allocs {
+		if !seen[a.PubDate] {
+			seen[a.PubDate] = true
+			dates = append(dates, a.PubDate)
+		}
+	}
+	result := []IndustryAllocationChange{}
+	if len(dates) < 2 {
+		return result
+	}
+	sort.Strings(dates)
+	prevDate, lastDate := dates[len(dates)-2], dates[len(dates)-1]
+	prev := map[string]IndustryAllocation{}
+	last := map[string]IndustryAllocation{}
+	for _, a := range allocs {
+		switch a.PubDate {
+		case prevDate:
+			prev[a.Industry] = a
+		case lastDate:
+			last[a.Industry] = a
+		}
+	}
+	industries := map[string]bool{}
+	for i := range prev {
+		industries[i] = true
+	}
+	for i := range last {
+		industries[i] = true
+	}
+	for industry := range industries {
+		p, l := prev[industry], last[industry]
+		result = append(result, IndustryAllocationChange{
+			Industry:        industry,
+			PrevPubDate:     prevDate,
+			PubDate:         lastDate,
+			PrevAvgProp:     p.AvgProp,
+			AvgProp:         l.AvgProp,
+			Change:          l.AvgProp - p.AvgProp,
+			FundCountChange: l.FundCount - p.FundCount,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Change != result[j].Change {
+			return result[i].Change > result[j].Change
+		}
+		return result[i].Industry < result[j].Industry
+	})
+	return result
+}
+
+// LoadFundIndustryAllocation 从数据库汇总最近 periods 个报告期的基金行业配置
+func LoadFundIndustryAllocation(ctx context.Context, periods int) ([]IndustryAllocation, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	dates := []string{}
+	if err := DB.Model(&FundIndustryProportionDB{}).Distinct("pub_date").
+		Order("pub_date DESC").Limit(periods).Pluck("pub_date", &dates).Error; err != nil {
+		return nil, err
+	}
+	if len(dates) == 0 {
+		return []IndustryAllocation{}, nil
+	}
+	rows := []FundIndustryProportionDB{}
+	if err := DB.Where("pub_date IN ?", dates).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return AggregateFundIndustryAllocation(rows), nil
+}
+
+// IndustryRotationReport 行业轮动报告
+type IndustryRotationReport struct {
+	Date string `json:"date"`
+	// 计算收益的交易日窗口
+	Window int `json:"window"`
+	// 基准（沪深300）区间收益率（%）
+	BenchmarkReturn float64 `json:"benchmark_return"`
+	// 按相对强弱排名的行业
+	Momentum []IndustryMomentum `json:"momentum"`
+	// 按基金配置变化排名的行业，数据库不可用时为空
+	Allocation []IndustryAllocationChange `json:"allocation"`
+}
+
+// QueryIndustryRotation 计算最近 window 个交易日的行业轮动报告，同时保存当日行业行情
+func QueryIndustryRotation(ctx context.Context, window int) (*IndustryRotationReport, error) {
+	boards, err := datacenter.EastMoney.QueryIndustryBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	date := time.Now().Format("2006-01-02")
+	if err := SaveIndustryDailies(ctx, date, boards); err != nil {
+		logrus.WithContext(ctx).Error("QueryIndustryRotation SaveIndustryDailies err:" + err.Error())
+	}
+
+	benchmark, err := datacenter.EastMoney.QueryKlineBySecID(ctx, eastmoney.IndexSecID(IndustryBenchmarkCode), eastmoney.KlineTypeDay, eastmoney.KlineFQNone, window+1)
+	if err != nil {
+		return nil, err
+	}
+	benchmarkRet, ok := WindowReturn(benchmark.Closes(), window)
+	if !ok {
+		return nil, errors.New("benchmark kline not enough")
+	}
+	if len(benchmark) > 0 {
+		date = benchmark[len(benchmark)-1].Date
+	}
+
+	// 限制并发，避免被接口限流
+	semaphore := make(chan struct{}, 4)
+	list := make([]*IndustryMomentum, len(boards))
+	var wg sync.WaitGroup
+	for i, board := range boards {
+		wg.Add(1)
+		go func(i int, board eastmoney.IndustryBoard) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+			}()
+			semaphore <- struct{}{}
+			klines, err := datacenter.EastMoney.QueryKlineBySecID(ctx, eastmoney.IndustrySecID(board.Code), eastmoney.KlineTypeDay, eastmoney.KlineFQNone, window+1)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("QueryIndustryRotation QueryKline board:%s err:%v", board.Code, err)
+				return
+			}
+			ret, ok := WindowReturn(klines.Closes(), window)
+			if !ok {
+				return
+			}
+			list[i] = &IndustryMomentum{
+				Code:             board.Code,
+				Name:             board.Name,
+				PE:               board.PE,
+				PB:               board.PB,
+				Return:           ret,
+				RelativeStrength: RelativeStrength(ret, benchmarkRet),
+			}
+		}(i, board)
+	}
+	wg.Wait()
+
+	momentum := []IndustryMomentum{}
+	for _, m := range list {
+		if m != nil {
+			momentum = append(momentum, *m)
+		}
+	}
+	RankIndustryMomentum(momentum)
+
+	report := &IndustryRotationReport{
+		Date:            date,
+		Window:          window,
+		BenchmarkReturn: benchmarkRet,
+		Momentum:        momentum,
+		Allocation:      []IndustryAllocationChange{},
+	}
+	if allocs, err := LoadFundIndustryAllocation(ctx, 2); err != nil {
+		logrus.WithContext(ctx).Debug("QueryIndustryRotation LoadFundIndustryAllocation err:" + err.Error())
+	} else {
+		report.Allocation = IndustryAllocationChanges(allocs)
+	}
+	return report, nil
+}
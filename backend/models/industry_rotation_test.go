@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowReturn(t *testing.T) {
+	ret, ok := WindowReturn([]float64{100, 110, 121}, 2)
+	require.True(t, ok)
+	require.InDelta(t, 21.0, ret, 1e-9)
+	_, ok = WindowReturn([]float64{100, 110}, 2)
+	require.False(t, ok)
+	require.InDelta(t, 10.0, RelativeStrength(21, 10), 1e-9)
+}
+
+func TestRankIndustryMomentum(t *testing.T) {
+	list := []IndustryMomentum{{Name: "a", RelativeStrength: -1}, {Name: "b", RelativeStrength: 5}, {Name: "c", RelativeStrength: 2}}
+	RankIndustryMomentum(list)
+	require.Equal(t, "b", list[0].Name)
+	require.Equal(t, 1, list[0].Rank)
+	require.Equal(t, 3, list[2].Rank)
+}
+
+func TestIndustryAllocation(t *testing.T) {
+	rows := []FundIndustryProportionDB{
+		{FundCode: "1", PubDate: "2023-06-30", Industry: "制造业", Prop: "60"},
+		{FundCode: "1", PubDate: "2023-06-30", Industry: "金融业", Prop: "10"},
+		{FundCode: "2", PubDate: "2023-06-30", Industry: "制造业", Prop: "40"},
+		{FundCode: "1", PubDate: "2023-09-30", Industry: "制造业", Prop: "50"},
+		{FundCode: "2", PubDate: "2023-09-30", Industry: "金融业", Prop: "30"},
+		{FundCode: "2", PubDate: "2023-09-30", Industry: "采矿业", Prop: "--"},
+	}
+	allocs := AggregateFundIndustryAllocation(rows)
+	require.Len(t, allocs, 4)
+	require.Equal(t, "2023-06-30", allocs[0].PubDate)
+	require.Equal(t, "制造业", allocs[0].Industry)
+	require.Equal(t, 2, allocs[0].FundCount)
+	require.InDelta(t, 50.0, allocs[0].AvgProp, 1e-9)
+
+	changes := IndustryAllocationChanges(allocs)
+	require.Len(t, changes, 2)
+	require.Equal(t, "金融业", changes[0].Industry)
+	require.InDelta(t, 10.0, changes[0].Change, 1e-9)
+	require.Equal(t, "制造业", changes[1].Industry)
+	require.InDelta(t, -25.0, changes[1].Change, 1e-9)
+	require.Equal(t, -1, changes[1].FundCountChange)
+}
@@ -0,0 +1,302 @@
+// 市场温度：股权风险溢价（ERP）、指数估值百分位及综合温度
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/indicators"
+	"github.com/sirupsen/logrus"
+)
+
+// MarketIndex 用于衡量市场温度的宽基指数
+type MarketIndex struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// MarketIndexes 参与市场温度计算的指数
+var MarketIndexes = []MarketIndex{
+	{Code: "000300", Name: "沪深300"},
+	{Code: "000905", Name: "中证500"},
+	{Code: "000985", Name: "中证全指"},
+}
+
+// IndexTemperature 单个指数的估值温度
+type IndexTemperature struct {
+	Date      string  `json:"date"`
+	IndexCode string  `json:"index_code"`
+	IndexName string  `json:"index_name"`
+	PE        float64 `json:"pe"`
+	PB        float64 `json:"pb"`
+	// PE 历史百分位（0-100）
+	PEPercentile float64 `json:"pe_percentile"`
+	// PB 历史百分位（0-100）
+	PBPercentile float64 `json:"pb_percentile"`
+	// 无风险利率（%）
+	RiskFreeRate float64 `json:"risk_free_rate"`
+	// 股权风险溢价（%）：1/PE - 无风险利率
+	ERP float64 `json:"erp"`
+	// ERP 在已保存历史中的百分位（0-100），历史不足时为 -1
+	ERPPercentile float64 `json:"erp_percentile"`
+	// 综合温度（0-100），越高越贵
+	Temperature float64 `json:"temperature"`
+	// 温度等级
+	Level string `json:"level"`
+}
+
+// MarketTemperature 市场整体温度
+type MarketTemperature struct {
+	Date         string  `json:"date"`
+	RiskFreeRate float64 `json:"risk_free_rate"`
+	Temperature  float64 `json:"temperature"`
+	Level        string  `json:"level"`
+	// 参考股票仓位（%）
+	SuggestedEquityRatio float64            `json:"suggested_equity_ratio"`
+	Indexes              []IndexTemperature `json:"indexes"`
+}
+
+// MinERPHistoryDays 计算 ERP 百分位需要的最少历史天数
+const MinERPHistoryDays = 20
+
+// ERP 计算股权风险溢价（%）
+func ERP(pe, riskFreeRate float64) float64 {
+	if pe <= 0 {
+		return 0
+	}
+	return 100/pe - riskFreeRate
+}
+
+// TemperatureScore 综合温度：PE、PB 百分位与 ERP 反向百分位的均值，ERP 百分位小于 0 时不参与
+func TemperatureScore(pePercentile, pbPercentile, erpPercentile float64) float64 {
+	values := []float64{pePercentile, pbPercentile}
+	if erpPercentile >= 0 {
+		// ERP 越高越便宜
+		values = append(values, 100-erpPercentile)
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// TemperatureLevel 温度等级
+func TemperatureLevel(t float64) string {
+	switch {
+	case t < 20:
+		return "冰点"
+	case t < 40:
+		return "偏冷"
+	case t < 60:
+		return "适中"
+	case t < 80:
+		return "偏热"
+	}
+	return "过热"
+}
+
+// SuggestedEquityRatio 按温度线性给出参考股票仓位：0度对应80%，100度对应20%
+func SuggestedEquityRatio(t float64) float64 {
+	return 80 - 0.6*t
+}
+
+// NewIndexTemperature 计算单个指数的估值温度，erpHistory 为该指数已保存的历史 ERP
+func NewIndexTemperature(index MarketIndex, date string, pe, pb, pePercentile, pbPercentile, riskFreeRate float64, erpHistory []float64) IndexTemperature {
+	t := IndexTemperature{
+		Date:          date,
+		IndexCode:     index.Code,
+		IndexName:     index.Name,
+		PE:            pe,
+		PB:            pb,
+		PEPercentile:  pePercentile,
+		PBPercentile:  pbPercentile,
+		RiskFreeRate:  riskFreeRate,
+		ERP:           ERP(pe, riskFreeRate),
+		ERPPercentile: -1,
+	}
+	if len(erpHistory) >= MinERPHistoryDays {
+		t.ERPPercentile = indicators.PercentileRank(append(append([]float64{}, erpHistory...), t.ERP), t.ERP)
+	}
+	t.Temperature = TemperatureScore(t.PEPercentile, t.PBPercentile, t.ERPPercentile)
+	t.Level = TemperatureLevel(t.Temperature)
+	return t
+}
+
+// NewMarketTemperature 汇总各指数温度
+func NewMarketTemperature(date string, riskFreeRate float64, indexes []IndexTemperature) MarketTemperature {
+	m := MarketTemperature{
+		Date:         date,
+		RiskFreeRate: riskFreeRate,
+		Indexes:      indexes,
+	}
+	if len(indexes) == 0 {
+		return m
+	}
+	sum := 0.0
+	for _, i := range indexes {
+		sum += i.Temperature
+	}
+	m.Temperature = sum / float64(len(indexes))
+	m.Level = TemperatureLevel(m.Temperature)
+	m.SuggestedEquityRatio = SuggestedEquityRatio(m.Temperature)
+	return m
+}
+
+// parseIndexFloat 解析指数接口返回的数值
+func parseIndexFloat(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// QueryMarketTemperature 实时计算当前市场温度并保存到数据库
+func QueryMarketTemperature(ctx context.Context) (MarketTemperature, error) {
+	rf := RiskFreeRate(ctx)
+	date := time.Now().Format("2006-01-02")
+	temps := make([]*IndexTemperature, len(MarketIndexes))
+	var wg sync.WaitGroup
+	for i, index := range MarketIndexes {
+		wg.Add(1)
+		go func(i int, index MarketIndex) {
+			defer wg.Done()
+			data, err := datacenter.EastMoney.Index(ctx, index.Code)
+			if err != nil {
+				logrus.WithContext(ctx).Errorf("QueryMarketTemperature Index code:%s err:%v", index.Code, err)
+				return
+			}
+			pe := parseIndexFloat(data.Petim)
+			if pe <= 0 {
+				logrus.WithContext(ctx).Warnf("QueryMarketTemperature index %s has no pe:%v", index.Code, data.Petim)
+				return
+			}
+			// 优先使用指数数据的估值日期
+			idate := date
+			if d := data.PDate; len(d) >= 10 {
+				idate = d[:10]
+			}
+			history, err := LoadERPHistory(ctx, index.Code, idate)
+			if err != nil {
+				logrus.WithContext(ctx).Debug("QueryMarketTemperature LoadERPHistory err:" + err.Error())
+			}
+			t := NewIndexTemperature(
+				index, idate, pe, parseIndexFloat(data.Pb),
+				parseIndexFloat(data.Pep100), parseIndexFloat(data.Pbp100),
+				rf, history,
+			)
+			temps[i] = &t
+		}(i, index)
+	}
+	wg.Wait()
+
+	indexes := []IndexTemperature{}
+	for _, t := range temps {
+		if t == nil {
+			continue
+		}
+		indexes = append(indexes, *t)
+	}
+	if len(indexes) == 0 {
+		return MarketTemperature{}, errors.New("no index valuation data")
+	}
+	date = indexes[0].Date
+	if err := SaveIndexTemperatures(ctx, indexes); err != nil {
+		logrus.WithContext(ctx).Error("QueryMarketTemperature SaveIndexTemperatures err:" + err.Error())
+	}
+	return NewMarketTemperature(date, rf, indexes), nil
+}
+
+// SaveIndexTemperatures 保存指数温度，同一指数同一日期只保留一条
+func SaveIndexTemperatures(ctx context.Context, temps []IndexTemperature) error {
+	if DB == nil {
+		return nil
+	}
+	for _, t := range temps {
+		row := ToMarketTemperatureDB(t)
+		if err := DB.Where(MarketTemperatureDB{IndexCode: t.IndexCode, Date: t.Date}).
+			Assign(row).FirstOrCreate(&MarketTemperatureDB{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadERPHistory 加载指数在 before 之前已保存的历史 ERP
+func LoadERPHistory(ctx context.Context, indexCode, before string) ([]float64, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	values := []float64{}
+	err := DB.Model(&MarketTemperatureDB{}).Where("index_code = ? AND date < ?", indexCode, before).
+		Order("date").Pluck("erp", &values).Error
+	return values, err
+}
+
+// LoadMarketTemperature 加载指定日期（或之前最近一日）保存的市场温度
+func LoadMarketTemperature(ctx context.Context, date string) (MarketTemperature, error) {
+	if DB == nil {
+		return MarketTemperature{}, errors.New("database not initialized")
+	}
+	latest := MarketTemperatureDB{}
+	if err := DB.Where("date <= ?", date).Order("date DESC").Limit(1).Find(&latest).Error; err != nil {
+		return MarketTemperature{}, err
+	}
+	if latest.ID == 0 {
+		return MarketTemperature{}, fmt.Errorf("no market temperature before %s", date)
+	}
+	rows := []MarketTemperatureDB{}
+	if err := DB.Where("date = ?", latest.Date).Order("index_code").Find(&rows).Error; err != nil {
+		return MarketTemperature{}, err
+	}
+	indexes := []IndexTemperature{}
+	for _, r := range rows {
+		indexes = append(indexes, r.ToIndexTemperature())
+	}
+	return NewMarketTemperature(latest.Date, latest.RiskFreeRate, indexes), nil
+}
+
+// GetMarketTemperature 返回当天已计算保存的市场温度，当天还没有计算过时实时计算并保存
+func GetMarketTemperature(ctx context.Context) (MarketTemperature, error) {
+	if DB != nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		latest := MarketTemperatureDB{}
+		if err := DB.WithContext(ctx).Where("updated_at >= ?", today).Order("date DESC").Limit(1).Find(&latest).Error; err != nil {
+			logrus.WithContext(ctx).Error("GetMarketTemperature load today's temperature err:" + err.Error())
+		} else if latest.ID != 0 {
+			return LoadMarketTemperature(ctx, latest.Date)
+		}
+	}
+	return QueryMarketTemperature(ctx)
+}
+
+// LoadIndexTemperatureHistory 加载指数温度历史，按日期升序
+func LoadIndexTemperatureHistory(ctx context.Context, indexCode, start, end string) ([]IndexTemperature, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []MarketTemperatureDB{}
+	query := DB.Where("index_code = ?", indexCode)
+	if start != "" {
+		query = query.Where("date >= ?", start)
+	}
+	if end != "" {
+		query = query.Where("date <= ?", end)
+	}
+	if err := query.Order("date").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := []IndexTemperature{}
+	for _, r := range rows {
+		result = append(result, r.ToIndexTemperature())
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIndexTemperature(t *testing.T) {
+	index := MarketIndexes[0]
+	// 历史不足时 ERP 百分位不参与温度计算
+	temp := NewIndexTemperature(index, "2023-01-03", 12.5, 1.4, 30, 10, 3.0, nil)
+	require.InDelta(t, 5.0, temp.ERP, 1e-9)
+	require.Equal(t, -1.0, temp.ERPPercentile)
+	require.InDelta(t, 20.0, temp.Temperature, 1e-9)
+	require.Equal(t, "适中", TemperatureLevel(50))
+	require.Equal(t, "偏冷", temp.Level)
+
+	history := []float64{}
+	for i := 0; i < MinERPHistoryDays; i++ {
+		history = append(history, float64(i)*0.1)
+	}
+	// 当前 ERP 为历史最高，百分位 100，反向计入 0
+	temp = NewIndexTemperature(index, "2023-01-03", 12.5, 1.4, 30, 30, 3.0, history)
+	require.Equal(t, 100.0, temp.ERPPercentile)
+	require.InDelta(t, 20.0, temp.Temperature, 1e-9)
+
+	m := NewMarketTemperature("2023-01-03", 3.0, []IndexTemperature{{Temperature: 20}, {Temperature: 40}})
+	require.InDelta(t, 30.0, m.Temperature, 1e-9)
+	require.InDelta(t, 62.0, m.SuggestedEquityRatio, 1e-9)
+	require.Equal(t, 0.0, ERP(0, 3))
+}
@@ -0,0 +1,91 @@
+// 个股资金流向获取与存储
+
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/zszx"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// MoneyFlowProviderDays 资金流向接口单次查询的自然日范围
+const MoneyFlowProviderDays = 60
+
+// SaveStockMoneyFlows 保存资金净流入数据到数据库，相同日期的数据覆盖更新
+func SaveStockMoneyFlows(ctx context.Context, secucode string, inflows zszx.NetInflowList) error {
+	if DB == nil || len(inflows) == 0 {
+		return nil
+	}
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "secucode"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"close", "main_net_in", "huge_net_in", "big_net_in", "mid_net_in", "small_net_in", "total_net_in", "updated_at"}),
+	}).CreateInBatches(ToStockMoneyFlowsDB(secucode, inflows), 500).Error
+}
+
+// LoadStockMoneyFlows 从数据库加载最近 limit 个交易日的资金净流入数据，按日期降序返回
+func LoadStockMoneyFlows(ctx context.Context, secucode string, limit int) (zszx.NetInflowList, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []StockMoneyFlowDB{}
+	query := DB.WithContext(ctx).Where("secucode = ?", secucode).Order("date DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(zszx.NetInflowList, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, r.ToNetInflow())
+	}
+	return result, nil
+}
+
+// queryStockMoneyFlows 从接口获取最近 MoneyFlowProviderDays 天的资金净流入数据，按日期降序返回
+func queryStockMoneyFlows(ctx context.Context, secucode string) (zszx.NetInflowList, error) {
+	now := time.Now()
+	end := now.Format("2006-01-02")
+	start := now.AddDate(0, 0, -MoneyFlowProviderDays).Format("2006-01-02")
+	return datacenter.Zszx.QueryMainMoneyNetInflows(ctx, secucode, start, end)
+}
+
+// SyncStockMoneyFlows 从接口获取最近的资金净流入数据并保存到数据库，由定时任务调用积累历史
+func SyncStockMoneyFlows(ctx context.Context, secucode string) (int, error) {
+	inflows, err := queryStockMoneyFlows(ctx, secucode)
+	if err != nil {
+		return 0, err
+	}
+	return len(inflows), SaveStockMoneyFlows(ctx, secucode, inflows)
+}
+
+// GetStockMoneyFlows 获取最近 limit 个交易日的资金净流入数据，按日期降序返回
+// 接口只返回最近 MoneyFlowProviderDays 天的数据，更早的历史从定时任务保存的数据库数据补充
+func GetStockMoneyFlows(ctx context.Context, secucode string, limit int) (zszx.NetInflowList, error) {
+	inflows, err := queryStockMoneyFlows(ctx, secucode)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetStockMoneyFlows QueryMainMoneyNetInflows err:" + err.Error())
+		return LoadStockMoneyFlows(ctx, secucode, limit)
+	}
+	if DB != nil && len(inflows) > 0 && (limit <= 0 || len(inflows) < limit) {
+		history, err := LoadStockMoneyFlows(ctx, secucode, limit)
+		if err != nil {
+			logrus.WithContext(ctx).Error("GetStockMoneyFlows LoadStockMoneyFlows err:" + err.Error())
+		}
+		oldest := inflows[len(inflows)-1].TrdDt
+		for _, h := range history {
+			if h.TrdDt < oldest {
+				inflows = append(inflows, h)
+			}
+		}
+	}
+	if limit > 0 && len(inflows) > limit {
+		inflows = inflows[:limit]
+	}
+	return inflows, nil
+}
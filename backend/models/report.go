@@ -0,0 +1,113 @@
+// 定时报告存档
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrReportNotFound 报告不存在
+var ErrReportNotFound = errors.New("report not found")
+
+// SaveReport 保存报告
+func SaveReport(ctx context.Context, report *ReportDB) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Save(report).Error
+}
+
+// UpdateReportDelivery 更新报告的发送结果
+func UpdateReportDelivery(ctx context.Context, report *ReportDB) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Model(report).Select("delivered", "error").Updates(report).Error
+}
+
+// GetReport 按 ID 获取报告，包含正文和文件内容
+func GetReport(ctx context.Context, id uint) (*ReportDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	report := ReportDB{}
+	if err := DB.WithContext(ctx).First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrReportNotFound, id)
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// LatestReport 获取指定名称的最近一期报告，不存在时返回 nil
+func LatestReport(ctx context.Context, name string) (*ReportDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	reports := []ReportDB{}
+	err := DB.WithContext(ctx).
+		Select("id", "name", "kind", "codes", "created_at").
+		Where("name = ?", name).
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Find(&reports).Error
+	if err != nil || len(reports) == 0 {
+		return nil, err
+	}
+	return &reports[0], nil
+}
+
+// ParamReportList 报告列表查询参数
+type ParamReportList struct {
+	Name     string `json:"name"      form:"name"`
+	PageNum  int    `json:"page_num"  form:"page_num"  binding:"min=0"`
+	PageSize int    `json:"page_size" form:"page_size" binding:"min=0,max=100"`
+}
+
+// ListReports 分页查询报告，不返回正文和文件内容
+func ListReports(ctx context.Context, p ParamReportList) ([]ReportDB, int64, error) {
+	if DB == nil {
+		return nil, 0, errors.New("database not initialized")
+	}
+	query := DB.WithContext(ctx).Model(&ReportDB{})
+	if p.Name != "" {
+		query = query.Where("name = ?", p.Name)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	reports := []ReportDB{}
+	err := query.Omit("html", "content", "codes").
+		Order("created_at DESC, id DESC").
+		Offset((p.PageNum - 1) * p.PageSize).
+		Limit(p.PageSize).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+// DiffCodes 比较两期报告的代码列表，返回新增和移出的代码，保持各自列表中的顺序
+func DiffCodes(prev, curr []string) (added, removed []string) {
+	prevSet := make(map[string]bool, len(prev))
+	for _, code := range prev {
+		prevSet[code] = true
+	}
+	currSet := make(map[string]bool, len(curr))
+	for _, code := range curr {
+		currSet[code] = true
+		if !prevSet[code] {
+			added = append(added, code)
+		}
+	}
+	for _, code := range prev {
+		if !currSet[code] {
+			removed = append(removed, code)
+		}
+	}
+	return
+}
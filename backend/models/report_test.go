@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffCodes(t *testing.T) {
+	added, removed := DiffCodes([]string{"000001", "000002", "000003"}, []string{"000003", "000004", "000001"})
+	require.Equal(t, []string{"000004"}, added)
+	require.Equal(t, []string{"000002"}, removed)
+
+	// 首期报告全部视为新增
+	added, removed = DiffCodes(nil, []string{"000001"})
+	require.Equal(t, []string{"000001"}, added)
+	require.Nil(t, removed)
+}
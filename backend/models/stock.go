@@ -4,6 +4,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
 	"github.com/axiaoxin-com/investool/datacenter/eniu"
 	"github.com/axiaoxin-com/investool/datacenter/zszx"
+	"github.com/axiaoxin-com/investool/indicators"
 	"github.com/sirupsen/logrus"
 )
 
@@ -71,6 +73,14 @@ type Stock struct {
 	FreeHoldersTop10 eastmoney.FreeHolderList `json:"free_holders_top_10"`
 	// 主力资金净流入
 	MainMoneyNetInflows zszx.NetInflowList `json:"main_money_net_inflows"`
+	// 最近的日K线（前复权）
+	HistoricalKlines eastmoney.KlineList `json:"historical_klines"`
+	// 历史分红送配
+	HistoricalDividends eastmoney.StockDividendList `json:"historical_dividends"`
+	// 分红可持续性分析
+	DividendAnalysis DividendAnalysis `json:"dividend_analysis"`
+	// 金融股专项分析，非金融股为空
+	FinancialAnalysis *FinancialAnalysis `json:"financial_analysis,omitempty"`
 }
 
 // GetPrice 返回股价，没开盘时可能是字符串“-”，此时返回最近历史股价，无历史价则返回 -1
@@ -93,6 +103,24 @@ func (s Stock) GetOrgType() string {
 	return s.HistoricalFinaMainData[0].OrgType
 }
 
+// IsFinancial 是否为银行、保险等金融股
+func (s Stock) IsFinancial() bool {
+	return IsFinancialOrgType(s.GetOrgType())
+}
+
+// Volatility 按日K线计算最近 window 个交易日的年化已实现波动率，K线数据不足时返回 false
+// window 为 0 时使用上市以来的历史波动率
+func (s Stock) Volatility(window int) (float64, bool) {
+	if window == 0 {
+		return s.HistoricalVolatility, true
+	}
+	closes := s.HistoricalKlines.Closes()
+	if window < 2 || len(closes) <= window {
+		return 0, false
+	}
+	return indicators.Last(indicators.RealizedVolatility(closes, window)), true
+}
+
 // StockList 股票列表
 type StockList []Stock
 
@@ -110,8 +138,76 @@ func (s StockList) SortByPriceSpace() {
 	})
 }
 
-// NewStock 创建 Stock 对象
-func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo) (Stock, error) {
+// SortByMainNetInflow 股票列表按最近 days 个交易日主力资金净流入排序
+func (s StockList) SortByMainNetInflow(days int) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].MainMoneyNetInflows.RollingSum(days).Main > s[j].MainMoneyNetInflows.RollingSum(days).Main
+	})
+}
+
+// setGincomeList 设置利润表及由最新一期利润表计算的指标
+func (s *Stock) setGincomeList(gincomeList eastmoney.GincomeDataList) {
+	s.HistoricalGincomeList = gincomeList
+	if len(s.HistoricalGincomeList) > 0 {
+		// 本业营收比
+		gincome := s.HistoricalGincomeList[0]
+		s.BYYSRatio = gincome.OperateProfit / (gincome.OperateProfit + gincome.NonbusinessIncome)
+		// 审计意见
+		s.FinaReportOpinion = gincome.OpinionType
+	}
+}
+
+// setCashflowList 设置现金流量表及由最新一期现金流量表计算的指标
+func (s *Stock) setCashflowList(cashflow eastmoney.CashflowDataList) {
+	s.HistoricalCashflowList = cashflow
+	if len(s.HistoricalCashflowList) > 0 {
+		cf := s.HistoricalCashflowList[0]
+		s.NetcashOperate = cf.NetcashOperate
+		s.NetcashInvest = cf.NetcashInvest
+		s.NetcashFinance = cf.NetcashFinance
+		if cf.NetcashInvest < 0 {
+			s.NetcashFree = s.NetcashOperate + s.NetcashInvest
+		} else {
+			s.NetcashFree = s.NetcashOperate - s.NetcashInvest
+		}
+	}
+}
+
+// calcRightPrice 计算 thisYear 年的合理价格和上一年的合理价格
+func calcRightPrice(
+	ctx context.Context,
+	hf eastmoney.HistoricalFinaMainData,
+	peList eastmoney.HistoricalPEList,
+	thisYear int,
+) (rightPrice, lastYearRightPrice float64, err error) {
+	// 去年年报
+	lastYearReport := hf.GetReport(ctx, thisYear-1, eastmoney.FinaReportTypeYear)
+	beforeLastYearReport := hf.GetReport(ctx, thisYear-2, eastmoney.FinaReportTypeYear)
+	thisYearAvgRevIncrRatio := hf.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear)
+	lastYearAvgRevIncrRatio := hf.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-1)
+	// nil fix: 新的一年刚开始时，上一年的年报还没披露，年份数据全部-1，保证有数据返回
+	if lastYearReport == nil {
+		logrus.WithContext(ctx).Debug("calcRightPrice get last year report nil, use before last year report")
+		lastYearReport = beforeLastYearReport
+		beforeLastYearReport = hf.GetReport(ctx, thisYear-3, eastmoney.FinaReportTypeYear)
+		thisYearAvgRevIncrRatio = hf.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-1)
+		lastYearAvgRevIncrRatio = hf.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-2)
+	}
+	if lastYearReport == nil || beforeLastYearReport == nil {
+		return 0, 0, errors.New("year report not found")
+	}
+	// pe 中位数
+	peMidVal, err := peList.GetMidValue(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	rightPrice = peMidVal * (lastYearReport.Epsjb * (1 + thisYearAvgRevIncrRatio/100.0))
+	lastYearRightPrice = peMidVal * (beforeLastYearReport.Epsjb * (1 + lastYearAvgRevIncrRatio/100.0))
+	return rightPrice, lastYearRightPrice, nil
+}
+
+// NewStock 创建 Stock 对象，checkYears 为金融股专项分析使用的年报数量
+func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo, checkYears int) (Stock, error) {
 	s := Stock{
 		BaseInfo: baseInfo,
 	}
@@ -145,29 +241,14 @@ func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo) (Stock, error)
 		s.HistoricalPEList = peList
 
 		// 合理价格判断
-		// 去年年报
-		lastYearReport := s.HistoricalFinaMainData.GetReport(ctx, time.Now().Year()-1, eastmoney.FinaReportTypeYear)
-		beforeLastYearReport := s.HistoricalFinaMainData.GetReport(ctx, time.Now().Year()-2, eastmoney.FinaReportTypeYear)
-		thisYear := time.Now().Year()
-		thisYearAvgRevIncrRatio := s.HistoricalFinaMainData.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear)
-		lastYearAvgRevIncrRatio := s.HistoricalFinaMainData.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-1)
-		// nil fix: 新的一年刚开始时，上一年的年报还没披露，年份数据全部-1，保证有数据返回
-		if lastYearReport == nil {
-			logrus.WithContext(ctx).Debug("NewStock get last year report nil, use before last year report")
-			lastYearReport = beforeLastYearReport
-			beforeLastYearReport = s.HistoricalFinaMainData.GetReport(ctx, time.Now().Year()-3, eastmoney.FinaReportTypeYear)
-			thisYearAvgRevIncrRatio = s.HistoricalFinaMainData.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-1)
-			lastYearAvgRevIncrRatio = s.HistoricalFinaMainData.GetAvgRevenueIncreasingRatioByYear(ctx, thisYear-2)
-		}
-		// pe 中位数
-		peMidVal, err := peList.GetMidValue(ctx)
+		rightPrice, lastYearRightPrice, err := calcRightPrice(ctx, hf, peList, time.Now().Year())
 		if err != nil {
-			logrus.WithContext(ctx).Error("NewStock GetMidValue err:" + err.Error())
+			logrus.WithContext(ctx).Error("NewStock calcRightPrice err:" + err.Error())
 			return
 		}
-		s.RightPrice = peMidVal * (lastYearReport.Epsjb * (1 + thisYearAvgRevIncrRatio/100.0))
+		s.RightPrice = rightPrice
 		s.PriceSpace = (s.RightPrice - price) / price * 100
-		s.LastYearRightPrice = peMidVal * (beforeLastYearReport.Epsjb * (1 + lastYearAvgRevIncrRatio/100.0))
+		s.LastYearRightPrice = lastYearRightPrice
 	}(ctx, &s)
 
 	// 获取综合估值
@@ -275,14 +356,7 @@ func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo) (Stock, error)
 			logrus.WithContext(ctx).Error("NewStock QueryFinaGincomeData err:" + err.Error())
 			return
 		}
-		s.HistoricalGincomeList = gincomeList
-		if len(s.HistoricalGincomeList) > 0 {
-			// 本业营收比
-			gincome := s.HistoricalGincomeList[0]
-			s.BYYSRatio = gincome.OperateProfit / (gincome.OperateProfit + gincome.NonbusinessIncome)
-			// 审计意见
-			s.FinaReportOpinion = gincome.OpinionType
-		}
+		s.setGincomeList(gincomeList)
 	}(ctx, &s)
 
 	// 现金流量表数据
@@ -294,18 +368,7 @@ func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo) (Stock, error)
 			logrus.WithContext(ctx).Error("NewStock QueryFinaCashflowData err:" + err.Error())
 			return
 		}
-		s.HistoricalCashflowList = cashflow
-		if len(s.HistoricalCashflowList) > 0 {
-			cf := s.HistoricalCashflowList[0]
-			s.NetcashOperate = cf.NetcashOperate
-			s.NetcashInvest = cf.NetcashInvest
-			s.NetcashFinance = cf.NetcashFinance
-			if cf.NetcashInvest < 0 {
-				s.NetcashFree = s.NetcashOperate + s.NetcashInvest
-			} else {
-				s.NetcashFree = s.NetcashOperate - s.NetcashInvest
-			}
-		}
+		s.setCashflowList(cashflow)
 	}(ctx, &s)
 
 	// 获取前10大流通股东
@@ -320,23 +383,57 @@ func NewStock(ctx context.Context, baseInfo eastmoney.StockInfo) (Stock, error)
 		s.FreeHoldersTop10 = holders
 	}(ctx, &s)
 
-	// 获取最近60日的主力资金净流入
+	// 获取最近60个交易日的主力资金净流入，接口窗口外的历史从数据库补充
 	wg.Add(1)
 	go func(ctx context.Context, s *Stock) {
 		defer wg.Done()
-		now := time.Now()
-		end := now.Format("2006-01-02")
-		d, _ := time.ParseDuration("-1440h")
-		start := now.Add(d).Format("2006-01-02")
-		inflows, err := datacenter.Zszx.QueryMainMoneyNetInflows(ctx, s.BaseInfo.Secucode, start, end)
+		inflows, err := GetStockMoneyFlows(ctx, s.BaseInfo.Secucode, 60)
 		if err != nil {
-			logrus.WithContext(ctx).Error("NewStock QueryMainMoneyNetInflows err:" + err.Error())
+			logrus.WithContext(ctx).Error("NewStock GetStockMoneyFlows err:" + err.Error())
 			return
 		}
 		s.MainMoneyNetInflows = inflows
 	}(ctx, &s)
 
+	// 日K线（前复权），用于趋势指标
+	wg.Add(1)
+	go func(ctx context.Context, s *Stock) {
+		defer wg.Done()
+		klines, err := GetStockPrices(ctx, s.BaseInfo.Secucode, eastmoney.KlineTypeDay, eastmoney.KlineFQForward, 300)
+		if err != nil {
+			logrus.WithContext(ctx).Error("NewStock GetStockPrices err:" + err.Error())
+			return
+		}
+		s.HistoricalKlines = klines
+	}(ctx, &s)
+
+	// 历史分红，接口失败时使用数据库中保存的记录
+	wg.Add(1)
+	go func(ctx context.Context, s *Stock) {
+		defer wg.Done()
+		dividends, err := datacenter.EastMoney.QueryStockDividendHistory(ctx, s.BaseInfo.SecurityCode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("NewStock QueryStockDividendHistory err:" + err.Error())
+			dividends, err = LoadStockDividends(ctx, s.BaseInfo.SecurityCode)
+			if err != nil {
+				logrus.WithContext(ctx).Debug("NewStock LoadStockDividends err:" + err.Error())
+				return
+			}
+		} else if err := SaveStockDividends(ctx, dividends); err != nil {
+			logrus.WithContext(ctx).Error("NewStock SaveStockDividends err:" + err.Error())
+		}
+		s.HistoricalDividends = dividends
+	}(ctx, &s)
+
 	wg.Wait()
 
+	// 分红分析依赖财报和现金流量表
+	s.DividendAnalysis = NewDividendAnalysis(ctx, s.HistoricalDividends, s.HistoricalFinaMainData, s.HistoricalCashflowList, 5)
+
+	// 金融股专项分析依赖财报中的机构类型
+	if s.IsFinancial() {
+		s.FinancialAnalysis = NewFinancialAnalysis(ctx, s, checkYears)
+	}
+
 	return s, nil
 }
@@ -0,0 +1,268 @@
+// 历史时点的股票信息还原：只使用当时已公告的财报和当时的行情，避免回测中的未来函数
+
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/axiaoxin-com/investool/indicators"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrStockHistoryNotEnough 历史时点没有财报或行情
+	ErrStockHistoryNotEnough = errors.New("历史时点没有可用的财报或行情数据")
+)
+
+// StockHistory 股票的全部历史数据，用于还原任意历史时点的股票信息
+type StockHistory struct {
+	// 股票池接口返回的信息，只使用代码、名称、行业、上市时间等不随时间变化的字段
+	BaseInfo eastmoney.StockInfo `json:"base_info"`
+	// 历史财报
+	FinaMainData eastmoney.HistoricalFinaMainData `json:"fina_main_data"`
+	// 历史市盈率
+	PEList eastmoney.HistoricalPEList `json:"pe_list"`
+	// 历史利润表
+	Gincomes eastmoney.GincomeDataList `json:"gincomes"`
+	// 历史现金流量表
+	Cashflows eastmoney.CashflowDataList `json:"cashflows"`
+	// 历史分红送配
+	Dividends eastmoney.StockDividendList `json:"dividends"`
+	// 金融股专项报表
+	FinancialStatements FinancialStatements `json:"financial_statements"`
+	// 不复权日K线，用于还原当时的股价、市值和估值
+	Klines eastmoney.KlineList `json:"klines"`
+	// 后复权日K线，用于计算收益率、波动率和趋势
+	AdjKlines eastmoney.KlineList `json:"adj_klines"`
+}
+
+// StockKlineLimit 覆盖 since 至今并额外保留 extra 个交易日所需的日K线数量
+func StockKlineLimit(since time.Time, extra int) int {
+	days := time.Since(since).Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	// 一年约 250 个交易日，多取 5% 余量
+	return int(days*250/365*1.05) + extra
+}
+
+// LoadStockHistory 获取股票的历史财报和最近 klineLimit 个交易日的日K线
+func LoadStockHistory(ctx context.Context, baseInfo eastmoney.StockInfo, klineLimit int) (*StockHistory, error) {
+	h := &StockHistory{
+		BaseInfo: baseInfo,
+	}
+	hf, err := datacenter.EastMoney.QueryHistoricalFinaMainData(ctx, baseInfo.Secucode)
+	if err != nil {
+		return nil, err
+	}
+	if len(hf) == 0 {
+		return nil, ErrStockHistoryNotEnough
+	}
+	h.FinaMainData = hf
+
+	var wg sync.WaitGroup
+	wg.Add(6)
+	go func() {
+		defer wg.Done()
+		peList, err := datacenter.EastMoney.QueryHistoricalPEList(ctx, baseInfo.Secucode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory QueryHistoricalPEList err:" + err.Error())
+			return
+		}
+		h.PEList = peList
+	}()
+	go func() {
+		defer wg.Done()
+		gincomes, err := datacenter.EastMoney.QueryFinaGincomeData(ctx, baseInfo.Secucode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory QueryFinaGincomeData err:" + err.Error())
+			return
+		}
+		h.Gincomes = gincomes
+	}()
+	go func() {
+		defer wg.Done()
+		cashflows, err := datacenter.EastMoney.QueryFinaCashflowData(ctx, baseInfo.Secucode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory QueryFinaCashflowData err:" + err.Error())
+			return
+		}
+		h.Cashflows = cashflows
+	}()
+	go func() {
+		defer wg.Done()
+		dividends, err := datacenter.EastMoney.QueryStockDividendHistory(ctx, baseInfo.SecurityCode)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory QueryStockDividendHistory err:" + err.Error())
+			if dividends, err = LoadStockDividends(ctx, baseInfo.SecurityCode); err != nil {
+				return
+			}
+		}
+		h.Dividends = dividends
+	}()
+	go func() {
+		defer wg.Done()
+		klines, err := GetStockPrices(ctx, baseInfo.Secucode, eastmoney.KlineTypeDay, eastmoney.KlineFQNone, klineLimit)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory GetStockPrices err:" + err.Error())
+			return
+		}
+		h.Klines = klines
+	}()
+	go func() {
+		defer wg.Done()
+		klines, err := GetStockPrices(ctx, baseInfo.Secucode, eastmoney.KlineTypeDay, eastmoney.KlineFQBackward, klineLimit)
+		if err != nil {
+			logrus.WithContext(ctx).Error("LoadStockHistory GetStockPrices err:" + err.Error())
+			return
+		}
+		h.AdjKlines = klines
+	}()
+	wg.Wait()
+
+	if IsFinancialOrgType(hf[0].OrgType) {
+		h.FinancialStatements = QueryFinancialStatements(ctx, baseInfo.Secucode, hf[0].OrgType)
+	}
+	return h, nil
+}
+
+// AsOf 还原 asOf 当日开盘前可以获得的股票信息：
+// 财报、利润表、现金流量表只使用公告日期在 asOf 之前的数据，分红只使用已除权除息的记录，
+// 行情只使用 asOf 之前的K线，总市值、市盈率、市净率等指标按当时的股价和财报重新计算。
+// 价值评估、四率估值、机构评级、盈利预测、股东和资金流向等接口只提供最新数据，历史时点不填充。
+// checkYears 为分红和财务分析使用的年数，当时未上市、没有已公告财报或没有行情时返回 false
+func (h *StockHistory) AsOf(ctx context.Context, asOf time.Time, checkYears int) (Stock, bool) {
+	before := asOf.Format("2006-01-02")
+	if h.BaseInfo.ListingDate != "" && !eastmoney.IsPublishedBefore(h.BaseInfo.ListingDate, before) {
+		return Stock{}, false
+	}
+	hf := h.FinaMainData.FilterByNoticeDate(ctx, before)
+	klines := h.Klines.Before(before)
+	if len(hf) == 0 || len(klines) == 0 {
+		return Stock{}, false
+	}
+	price := klines[len(klines)-1].Close
+	cur := hf[0]
+
+	info := eastmoney.StockInfo{
+		Secucode:          h.BaseInfo.Secucode,
+		SecurityCode:      h.BaseInfo.SecurityCode,
+		SecurityNameAbbr:  h.BaseInfo.SecurityNameAbbr,
+		Industry:          h.BaseInfo.Industry,
+		ListingDate:       h.BaseInfo.ListingDate,
+		NewPrice:          price,
+		RoeWeight:         cur.Roejq,
+		NetprofitYoyRatio: cur.Parentnetprofittz,
+		ToiYoyRatio:       cur.Totaloperaterevetz,
+		DebtAssetRatio:    cur.Zcfzl,
+		ROA:               cur.Zzcjll,
+	}
+	if cur.Bps > 0 {
+		info.PBNewMRQ = price / cur.Bps
+	}
+	// 总股本 = 归属净利润 / 基本每股收益
+	if cur.Epsjb != 0 {
+		info.TotalMarketCap = price * cur.Parentnetprofit / cur.Epsjb
+	}
+	yearReports := hf.FilterByReportType(ctx, eastmoney.FinaReportTypeYear)
+	// 静态市盈率
+	if len(yearReports) > 0 && yearReports[0].Epsjb > 0 {
+		info.PE = price / yearReports[0].Epsjb
+	}
+	if len(yearReports) > 3 {
+		info.NetprofitGrowthrate3Y = cagr3Y(yearReports[3].Parentnetprofit, yearReports[0].Parentnetprofit)
+		info.IncomeGrowthrate3Y = cagr3Y(yearReports[3].Totaloperatereve, yearReports[0].Totaloperatereve)
+	}
+	dividends := h.Dividends.FilterByExDividendDate(before)
+	// 股息率：最近一年已除息的每股现金分红 / 股价
+	yearAgo := asOf.AddDate(-1, 0, 0).Format("2006-01-02")
+	cash := 0.0
+	for _, d := range dividends {
+		if !eastmoney.IsPublishedBefore(d.ExDividendDate, yearAgo) {
+			cash += d.CashPerShare()
+		}
+	}
+	if price > 0 {
+		info.Zxgxl = cash / price * 100
+	}
+
+	s := Stock{
+		BaseInfo:               info,
+		HistoricalFinaMainData: hf,
+		HistoricalPEList:       h.PEList.Before(before),
+		HistoricalDividends:    dividends,
+	}
+	s.PEG = s.BaseInfo.PE / s.BaseInfo.NetprofitGrowthrate3Y
+	if rightPrice, lastYearRightPrice, err := calcRightPrice(ctx, hf, s.HistoricalPEList, asOf.Year()); err == nil {
+		s.RightPrice = rightPrice
+		s.PriceSpace = (s.RightPrice - price) / price * 100
+		s.LastYearRightPrice = lastYearRightPrice
+	} else {
+		logrus.WithContext(ctx).Debugf("StockHistory AsOf %s calcRightPrice err:%v", before, err)
+	}
+
+	// 趋势和波动率使用复权价格
+	adj := h.AdjKlines.Before(before)
+	if len(adj) > 300 {
+		adj = adj[len(adj)-300:]
+	}
+	s.HistoricalKlines = adj
+	if closes := adj.Closes(); len(closes) > 2 {
+		window := len(closes) - 1
+		if window > 250 {
+			window = 250
+		}
+		s.HistoricalVolatility = indicators.Last(indicators.RealizedVolatility(closes, window))
+	}
+
+	s.setGincomeList(h.Gincomes.FilterByNoticeDate(before))
+	s.setCashflowList(h.Cashflows.FilterByNoticeDate(before))
+	s.DividendAnalysis = NewDividendAnalysis(ctx, dividends, hf, s.HistoricalCashflowList, checkYears)
+
+	// 金融股专项报表没有公告日期，只使用已公告财报对应的报告期
+	if s.IsFinancial() {
+		reportDates := map[string]bool{}
+		for _, r := range hf {
+			reportDates[r.ReportDate] = true
+		}
+		s.FinancialAnalysis = NewFinancialAnalysisWithStatements(ctx, s, h.FinancialStatements.FilterByReportDates(reportDates), checkYears)
+	}
+	return s, true
+}
+
+// cagr3Y 3 年复合增长率（%），起止值必须为正
+func cagr3Y(begin, end float64) float64 {
+	if begin <= 0 || end <= 0 {
+		return 0
+	}
+	return (math.Pow(end/begin, 1.0/3) - 1) * 100
+}
+
+// NewStockAsOf 创建 asOf 时点的 Stock 对象，checkYears 为分红和财务分析使用的年数
+func NewStockAsOf(ctx context.Context, baseInfo eastmoney.StockInfo, asOf time.Time, checkYears int) (Stock, error) {
+	h, err := LoadStockHistory(ctx, baseInfo, StockKlineLimit(asOf, 300))
+	if err != nil {
+		return Stock{}, err
+	}
+	s, ok := h.AsOf(ctx, asOf, checkYears)
+	if !ok {
+		return Stock{}, ErrStockHistoryNotEnough
+	}
+	return s, nil
+}
+
+// closeOnOrBefore 返回 date 当日或之前最近一个交易日的收盘价
+func closeOnOrBefore(klines eastmoney.KlineList, date string) (float64, bool) {
+	i := sort.Search(len(klines), func(i int) bool { return klines[i].Date > date })
+	if i == 0 {
+		return 0, false
+	}
+	return klines[i-1].Close, true
+}
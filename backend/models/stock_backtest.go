@@ -0,0 +1,240 @@
+// 选股策略历史回测：按调仓日还原当时可获得的股票信息进行选股，等权持有到下一个调仓日
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StockRebalanceAnnual 每年 5 月第一个交易日调仓（年报和一季报已全部披露）
+	StockRebalanceAnnual = "annual"
+	// StockRebalanceQuarterly 每季度第一个交易日调仓
+	StockRebalanceQuarterly = "quarterly"
+)
+
+// StockBacktestParams 选股回测参数
+type StockBacktestParams struct {
+	// 调仓周期：annual/quarterly
+	Rebalance string `json:"rebalance"`
+	// 回测开始日期
+	Start string `json:"start"`
+	// 回测结束日期，为空时到最新交易日
+	End string `json:"end"`
+	// 每次调仓最多持有的股票数，按选股结果的顺序截取，为 0 时持有全部入选股票
+	TopN int `json:"top_n"`
+	// 基准指数代码
+	Benchmark string `json:"benchmark"`
+}
+
+// Validate 检查回测参数
+func (p StockBacktestParams) Validate() error {
+	switch {
+	case p.Rebalance != StockRebalanceAnnual && p.Rebalance != StockRebalanceQuarterly:
+		return fmt.Errorf("%w: rebalance %s", ErrInvalidBacktestParams, p.Rebalance)
+	case p.TopN < 0:
+		return fmt.Errorf("%w: top_n must not be negative", ErrInvalidBacktestParams)
+	case p.Benchmark == "":
+		return fmt.Errorf("%w: benchmark is required", ErrInvalidBacktestParams)
+	}
+	if _, err := time.Parse("2006-01-02", p.Start); err != nil {
+		return fmt.Errorf("%w: start %s", ErrInvalidBacktestParams, p.Start)
+	}
+	if p.End != "" && p.End < p.Start {
+		return fmt.Errorf("%w: end %s before start", ErrInvalidBacktestParams, p.End)
+	}
+	return nil
+}
+
+// StockSelectFunc 历史时点选股函数，stocks 为 StockHistory.AsOf 还原的 asOf 时点股票信息，返回按优先级排序的入选股票
+type StockSelectFunc func(ctx context.Context, asOf time.Time, stocks StockList) StockList
+
+// StockBacktestPeriod 一个持有期的结果
+type StockBacktestPeriod struct {
+	// 调仓日，选股只使用该日之前的数据，按该日收盘价买入
+	Date string `json:"date"`
+	// 持有到该日收盘
+	End string `json:"end"`
+	// 入选股票：名称-代码
+	Stocks []string `json:"stocks"`
+	// 组合收益率（%）
+	Return float64 `json:"return"`
+	// 基准收益率（%）
+	Benchmark float64 `json:"benchmark"`
+	// 超额收益（%）
+	Excess float64 `json:"excess"`
+}
+
+// StockBacktestResult 选股回测结果
+type StockBacktestResult struct {
+	Params    StockBacktestParams `json:"params"`
+	Start     string              `json:"start"`
+	End       string              `json:"end"`
+	Strategy  BacktestMetrics     `json:"strategy"`
+	Benchmark BacktestMetrics     `json:"benchmark"`
+	// 年化超额收益（%）
+	ExcessCAGR float64 `json:"excess_cagr"`
+	// 跑赢基准的持有期占比（%）
+	WinRate float64 `json:"win_rate"`
+	// 每日净值，初始为 1
+	Series  []BacktestPoint       `json:"series"`
+	Periods []StockBacktestPeriod `json:"periods"`
+	// 回测结果的局限性说明
+	Limitations []string `json:"limitations"`
+}
+
+// isStockRebalanceDay 前一交易日 prev 到当前交易日 cur 是否需要调仓
+func isStockRebalanceDay(rebalance, prev, cur string) bool {
+	if rebalance == StockRebalanceAnnual {
+		return prev == "" || (cur[5:7] == "05" && prev[5:7] != "05")
+	}
+	return isRebalanceDay(RebalanceQuarterly, prev, cur)
+}
+
+// stockHolding 持仓：份额按调仓日复权收盘价计算
+type stockHolding struct {
+	history *StockHistory
+	units   float64
+	price   float64
+}
+
+// RunStockBacktest 按调仓日依次还原 histories 在当时的股票信息并调用 selectFn 选股，等权买入持有到下一个调仓日。
+// selectFn 只能拿到 AsOf 还原的数据，不会用到调仓日之后公告的财报和行情，checkYears 为还原时分红和财务分析使用的年数。不计交易费用
+func RunStockBacktest(
+	ctx context.Context,
+	p StockBacktestParams,
+	histories []*StockHistory,
+	benchmark eastmoney.KlineList,
+	checkYears int,
+	selectFn StockSelectFunc,
+) (*StockBacktestResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	dates := []string{}
+	closes := []float64{}
+	for _, k := range benchmark {
+		if k.Date < p.Start || (p.End != "" && k.Date > p.End) {
+			continue
+		}
+		dates = append(dates, k.Date)
+		closes = append(closes, k.Close)
+	}
+	if len(dates) < 2 {
+		return nil, fmt.Errorf("%w: no benchmark data between %s and %s", ErrInvalidBacktestParams, p.Start, p.End)
+	}
+
+	result := &StockBacktestResult{
+		Params: p,
+		Start:  dates[0],
+		End:    dates[len(dates)-1],
+		Limitations: []string{
+			"股票池为当前上市的股票，回测期间已退市的股票不在股票池中，结果存在幸存者偏差",
+			"价值评估、机构评级、盈利预测、股东和资金流向等只有最新数据，历史时点选股不使用这些数据",
+			"按调仓日收盘价成交，不计交易费用和冲击成本",
+		},
+	}
+	byCode := map[string]*StockHistory{}
+	for _, h := range histories {
+		byCode[h.BaseInfo.Secucode] = h
+	}
+	value := 1.0
+	holdings := []*stockHolding{}
+	periodStart := 0
+	periodValue := value
+	closePeriod := func(end int) {
+		period := &result.Periods[len(result.Periods)-1]
+		period.End = dates[end]
+		period.Return = (value/periodValue - 1) * 100
+		period.Benchmark = (closes[end]/closes[periodStart] - 1) * 100
+		period.Excess = period.Return - period.Benchmark
+	}
+
+	for i, date := range dates {
+		// 按当日收盘价更新持仓市值，停牌时沿用最近的收盘价
+		if len(holdings) > 0 {
+			value = 0
+			for _, h := range holdings {
+				if price, ok := closeOnOrBefore(h.history.AdjKlines, date); ok {
+					h.price = price
+				}
+				value += h.units * h.price
+			}
+		}
+
+		prev := ""
+		if i > 0 {
+			prev = dates[i-1]
+		}
+		if isStockRebalanceDay(p.Rebalance, prev, date) {
+			if i > 0 {
+				closePeriod(i)
+			}
+			asOf, _ := time.Parse("2006-01-02", date)
+			stocks := StockList{}
+			for _, h := range histories {
+				if s, ok := h.AsOf(ctx, asOf, checkYears); ok {
+					stocks = append(stocks, s)
+				}
+			}
+			selected := selectFn(ctx, asOf, stocks)
+			if p.TopN > 0 && len(selected) > p.TopN {
+				selected = selected[:p.TopN]
+			}
+
+			// 按调仓日收盘价等权买入，调仓日没有行情的股票不买入
+			buy := []*stockHolding{}
+			names := []string{}
+			for _, s := range selected {
+				h := byCode[s.BaseInfo.Secucode]
+				if h == nil {
+					continue
+				}
+				price, ok := closeOnOrBefore(h.AdjKlines, date)
+				if !ok || price <= 0 {
+					logrus.WithContext(ctx).Debugf("RunStockBacktest %s has no price at %s", s.BaseInfo.Secucode, date)
+					continue
+				}
+				buy = append(buy, &stockHolding{history: h, price: price})
+				names = append(names, fmt.Sprintf("%s-%s", s.BaseInfo.SecurityNameAbbr, s.BaseInfo.Secucode))
+			}
+			for _, h := range buy {
+				h.units = value / float64(len(buy)) / h.price
+			}
+			holdings = buy
+			periodStart = i
+			periodValue = value
+			result.Periods = append(result.Periods, StockBacktestPeriod{Date: date, Stocks: names})
+		}
+
+		result.Series = append(result.Series, BacktestPoint{
+			Date:      date,
+			Value:     value,
+			Benchmark: closes[i] / closes[0],
+		})
+	}
+	closePeriod(len(dates) - 1)
+
+	values := make([]float64, len(result.Series))
+	benchValues := make([]float64, len(result.Series))
+	for i, point := range result.Series {
+		values[i] = point.Value
+		benchValues[i] = point.Benchmark
+	}
+	result.Strategy = NewBacktestMetrics(dates, values)
+	result.Benchmark = NewBacktestMetrics(dates, benchValues)
+	result.ExcessCAGR = result.Strategy.CAGR - result.Benchmark.CAGR
+	wins := 0
+	for _, period := range result.Periods {
+		if period.Excess > 0 {
+			wins++
+		}
+	}
+	result.WinRate = float64(wins) / float64(len(result.Periods)) * 100
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStockHistory 2020 年起每个工作日按 rate 增长的股票，每年 4 月 20 日公告上一年年报
+func mockStockHistory(code string, rate float64) *StockHistory {
+	h := &StockHistory{
+		BaseInfo: eastmoney.StockInfo{Secucode: code, SecurityCode: code[:6], SecurityNameAbbr: code, ListingDate: "2010-01-04 00:00:00"},
+	}
+	price := 10.0
+	for d := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() < 2023; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		price *= 1 + rate
+		k := eastmoney.Kline{Date: d.Format("2006-01-02"), Close: price}
+		h.Klines = append(h.Klines, k)
+		h.AdjKlines = append(h.AdjKlines, k)
+	}
+	for y := 2022; y >= 2016; y-- {
+		h.FinaMainData = append(h.FinaMainData, eastmoney.FinaMainData{
+			ReportYear:      time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006"),
+			ReportDate:      time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC).Format("2006-01-02 15:04:05"),
+			ReportType:      eastmoney.FinaReportTypeYear,
+			NoticeDate:      time.Date(y+1, 4, 20, 0, 0, 0, 0, time.UTC).Format("2006-01-02 15:04:05"),
+			Roejq:           float64(y - 2000),
+			Epsjb:           1,
+			Bps:             5,
+			Parentnetprofit: 1e8 * float64(y-2010),
+		})
+	}
+	h.Dividends = eastmoney.StockDividendList{
+		{ExDividendDate: "2021-06-30 00:00:00", PretaxBonusRMB: 5, AssignProgress: "实施分配", ReportDate: "2020-12-31 00:00:00"},
+	}
+	return h
+}
+
+func TestStockHistoryAsOf(t *testing.T) {
+	ctx := context.Background()
+	h := mockStockHistory("600000.SH", 0.001)
+
+	// 2021 年年报 2022-04-20 公告，之前只能看到 2020 年年报
+	asOf := time.Date(2022, 4, 20, 0, 0, 0, 0, time.UTC)
+	s, ok := h.AsOf(ctx, asOf, 5)
+	require.True(t, ok)
+	require.Equal(t, "2020", s.HistoricalFinaMainData[0].ReportYear)
+	require.Equal(t, 20.0, s.BaseInfo.RoeWeight)
+	require.Equal(t, "2022-04-19", s.HistoricalKlines[len(s.HistoricalKlines)-1].Date)
+	price := s.BaseInfo.NewPrice.(float64)
+	require.InDelta(t, price/5, s.BaseInfo.PBNewMRQ, 1e-9)
+	require.InDelta(t, price*10e8, s.BaseInfo.TotalMarketCap, 1e-3)
+	// 最近一年内 2021-06-30 除息每股 0.5 元
+	require.InDelta(t, 0.5/price*100, s.BaseInfo.Zxgxl, 1e-9)
+
+	s, ok = h.AsOf(ctx, asOf.AddDate(0, 0, 1), 5)
+	require.True(t, ok)
+	require.Equal(t, "2021", s.HistoricalFinaMainData[0].ReportYear)
+
+	// 除息日当天还没有除息
+	s, ok = h.AsOf(ctx, time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC), 5)
+	require.True(t, ok)
+	require.Empty(t, s.HistoricalDividends)
+
+	// 分红已超过一年
+	s, ok = h.AsOf(ctx, time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), 5)
+	require.True(t, ok)
+	require.Len(t, s.HistoricalDividends, 1)
+	require.Equal(t, 0.0, s.BaseInfo.Zxgxl)
+
+	// 没有行情
+	_, ok = h.AsOf(ctx, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), 5)
+	require.False(t, ok)
+}
+
+func TestRunStockBacktest(t *testing.T) {
+	ctx := context.Background()
+	histories := []*StockHistory{
+		mockStockHistory("600000.SH", 0.002),
+		mockStockHistory("600001.SH", -0.001),
+	}
+	benchmark := eastmoney.KlineList{}
+	for _, k := range histories[0].Klines {
+		benchmark = append(benchmark, eastmoney.Kline{Date: k.Date, Close: 100})
+	}
+
+	asOfs := []string{}
+	latestReport := ""
+	selectFn := func(ctx context.Context, asOf time.Time, stocks StockList) StockList {
+		asOfs = append(asOfs, asOf.Format("2006-01-02"))
+		for _, s := range stocks {
+			for _, r := range s.HistoricalFinaMainData {
+				require.Less(t, r.NoticeDate[:10], asOf.Format("2006-01-02"))
+			}
+			require.Less(t, s.HistoricalKlines[len(s.HistoricalKlines)-1].Date, asOf.Format("2006-01-02"))
+		}
+		latestReport = stocks[0].HistoricalFinaMainData[0].ReportYear
+		return stocks[:1]
+	}
+	p := StockBacktestParams{
+		Rebalance: StockRebalanceAnnual,
+		Start:     "2020-06-01",
+		End:       "2022-12-31",
+		Benchmark: "000300",
+	}
+	result, err := RunStockBacktest(ctx, p, histories, benchmark, 5, selectFn)
+	require.Nil(t, err)
+	require.Equal(t, []string{"2020-06-01", "2021-05-03", "2022-05-02"}, asOfs)
+	require.Equal(t, "2021", latestReport)
+	require.Len(t, result.Periods, 3)
+	require.Equal(t, []string{"600000.SH-600000.SH"}, result.Periods[0].Stocks)
+	require.Equal(t, "2021-05-03", result.Periods[0].End)
+	require.Greater(t, result.Periods[0].Excess, 0.0)
+	require.Equal(t, 100.0, result.WinRate)
+	require.Equal(t, 0.0, result.Strategy.MaxDrawdown)
+	require.Equal(t, 0.0, result.Benchmark.TotalReturn)
+	require.NotEmpty(t, result.Limitations)
+
+	p.Rebalance = "monthly"
+	_, err = RunStockBacktest(ctx, p, histories, benchmark, 5, selectFn)
+	require.ErrorIs(t, err, ErrInvalidBacktestParams)
+}
@@ -0,0 +1,98 @@
+// 股票历史行情（K 线）获取与存储
+
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/axiaoxin-com/investool/datacenter"
+	"github.com/axiaoxin-com/investool/datacenter/eastmoney"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveStockPrices 在一个事务中保存 K 线数据，相同日期的数据覆盖更新
+// 复权数据的复权基准随除权除息变化，区间内已保存的数据与新数据不一致时，
+// 删除区间之前按旧基准复权的数据，避免历史数据混用不同的复权基准
+func SaveStockPrices(ctx context.Context, secucode string, klt eastmoney.KlineType, fqt eastmoney.KlineFQ, klines eastmoney.KlineList) error {
+	if DB == nil || len(klines) == 0 {
+		return nil
+	}
+	begin, end := klines[0].Date, klines[len(klines)-1].Date
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if fqt != eastmoney.KlineFQNone {
+			stored := StockPriceDB{}
+			if err := tx.Where("secucode = ? AND kline_type = ? AND fq = ? AND date >= ? AND date <= ?", secucode, int(klt), int(fqt), begin, end).
+				Order("date").Limit(1).Find(&stored).Error; err != nil {
+				return err
+			}
+			for _, k := range klines {
+				if stored.ID == 0 || k.Date != stored.Date {
+					continue
+				}
+				if math.Abs(k.Close-stored.Close) > 1e-6 {
+					logrus.WithContext(ctx).Infof("SaveStockPrices %s fq:%d adjustment changed, delete prices before %s", secucode, fqt, begin)
+					if err := tx.Where("secucode = ? AND kline_type = ? AND fq = ? AND date < ?", secucode, int(klt), int(fqt), begin).
+						Delete(&StockPriceDB{}).Error; err != nil {
+						return err
+					}
+				}
+				break
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "secucode"}, {Name: "kline_type"}, {Name: "fq"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"open", "close", "high", "low", "volume", "amount", "change_pct", "turnover", "updated_at"}),
+		}).CreateInBatches(ToStockPricesDB(secucode, klt, fqt, klines), 500).Error
+	})
+}
+
+// LoadStockPrices 从数据库加载最近 limit 条 K 线数据，按日期升序返回
+func LoadStockPrices(ctx context.Context, secucode string, klt eastmoney.KlineType, fqt eastmoney.KlineFQ, limit int) (eastmoney.KlineList, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []StockPriceDB{}
+	query := DB.WithContext(ctx).Where("secucode = ? AND kline_type = ? AND fq = ?", secucode, int(klt), int(fqt)).Order("date DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(eastmoney.KlineList, len(rows))
+	for i, r := range rows {
+		result[len(rows)-1-i] = r.ToKline()
+	}
+	return result, nil
+}
+
+// GetStockPrices 获取股票 K 线数据并保存到数据库，接口失败时使用数据库中的数据
+func GetStockPrices(ctx context.Context, secucode string, klt eastmoney.KlineType, fqt eastmoney.KlineFQ, limit int) (eastmoney.KlineList, error) {
+	klines, err := datacenter.EastMoney.QueryKline(ctx, secucode, klt, fqt, limit)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetStockPrices QueryKline err:" + err.Error())
+		return LoadStockPrices(ctx, secucode, klt, fqt, limit)
+	}
+	if err := SaveStockPrices(ctx, secucode, klt, fqt, klines); err != nil {
+		logrus.WithContext(ctx).Error("GetStockPrices SaveStockPrices err:" + err.Error())
+	}
+	return klines, nil
+}
+
+// GetIndexPrices 获取指数日 K 线并以 secid 为代码保存到数据库，接口失败时使用数据库中的数据
+func GetIndexPrices(ctx context.Context, indexCode string, limit int) (eastmoney.KlineList, error) {
+	secid := eastmoney.IndexSecID(indexCode)
+	klines, err := datacenter.EastMoney.QueryKlineBySecID(ctx, secid, eastmoney.KlineTypeDay, eastmoney.KlineFQNone, limit)
+	if err != nil {
+		logrus.WithContext(ctx).Error("GetIndexPrices QueryKlineBySecID err:" + err.Error())
+		return LoadStockPrices(ctx, secid, eastmoney.KlineTypeDay, eastmoney.KlineFQNone, limit)
+	}
+	if err := SaveStockPrices(ctx, secid, eastmoney.KlineTypeDay, eastmoney.KlineFQNone, klines); err != nil {
+		logrus.WithContext(ctx).Error("GetIndexPrices SaveStockPrices err:" + err.Error())
+	}
+	return klines, nil
+}
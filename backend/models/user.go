@@ -0,0 +1,229 @@
+// 用户和个人 API token
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 用户角色
+const (
+	// RoleAdmin 管理员：可触发同步和定时任务、管理用户和共享的筛选预设
+	RoleAdmin = "admin"
+	// RoleUser 普通用户：可管理自己的自选列表和告警规则，可调用计算量大的接口
+	RoleUser = "user"
+	// RoleViewer 只读用户
+	RoleViewer = "viewer"
+)
+
+// roleLevels 角色权限等级，等级高的角色拥有等级低的角色的全部权限
+var roleLevels = map[string]int{
+	RoleViewer: 1,
+	RoleUser:   2,
+	RoleAdmin:  3,
+}
+
+var (
+	// ErrInvalidUser 用户参数错误
+	ErrInvalidUser = errors.New("invalid user")
+	// ErrUserExists 用户名已存在
+	ErrUserExists = errors.New("user already exists")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+	// ErrAPITokenNotFound API token 不存在
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+var usernameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// ValidRole 是否为有效角色
+func ValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// HasRole 判断角色是否拥有 required 角色的权限
+func HasRole(role, required string) bool {
+	return roleLevels[role] > 0 && roleLevels[role] >= roleLevels[required]
+}
+
+// Validate 检查用户参数
+func (u UserDB) Validate() error {
+	switch {
+	case !usernameRegexp.MatchString(u.Username):
+		return fmt.Errorf("%w: username must be 3-32 letters, digits or _.-", ErrInvalidUser)
+	case !ValidRole(u.Role):
+		return fmt.Errorf("%w: role %s", ErrInvalidUser, u.Role)
+	case u.PasswordHash == "":
+		return fmt.Errorf("%w: password is required", ErrInvalidUser)
+	}
+	return nil
+}
+
+// CreateUser 创建用户
+func CreateUser(ctx context.Context, user *UserDB) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := user.Validate(); err != nil {
+			return err
+		}
+		var exists int64
+		if err := tx.Model(&UserDB{}).Where("username = ?", user.Username).Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return fmt.Errorf("%w: %s", ErrUserExists, user.Username)
+		}
+		return tx.Create(user).Error
+	})
+}
+
+// GetUser 按 ID 获取用户
+func GetUser(ctx context.Context, id uint) (*UserDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	user := UserDB{}
+	if err := DB.WithContext(ctx).First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByName 按用户名获取用户
+func GetUserByName(ctx context.Context, username string) (*UserDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	user := UserDB{}
+	if err := DB.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers 获取全部用户
+func ListUsers(ctx context.Context) ([]UserDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	users := []UserDB{}
+	err := DB.WithContext(ctx).Order("id").Find(&users).Error
+	return users, err
+}
+
+// UpdateUserRole 修改用户角色
+func UpdateUserRole(ctx context.Context, id uint, role string) (*UserDB, error) {
+	if !ValidRole(role) {
+		return nil, fmt.Errorf("%w: role %s", ErrInvalidUser, role)
+	}
+	user, err := GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+	if err := DB.WithContext(ctx).Model(user).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUserPassword 修改用户密码哈希
+func UpdateUserPassword(ctx context.Context, id uint, passwordHash string) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	result := DB.WithContext(ctx).Model(&UserDB{}).Where("id = ?", id).Update("password_hash", passwordHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+	return nil
+}
+
+// TouchUserLogin 记录用户登录时间
+func TouchUserLogin(ctx context.Context, id uint) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Model(&UserDB{}).Where("id = ?", id).Update("last_login_at", time.Now()).Error
+}
+
+// CreateAPIToken 保存 API token
+func CreateAPIToken(ctx context.Context, token *APITokenDB) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Create(token).Error
+}
+
+// GetAPITokenByHash 按哈希获取未过期的 API token 及其用户
+func GetAPITokenByHash(ctx context.Context, tokenHash string) (*APITokenDB, *UserDB, error) {
+	if DB == nil {
+		return nil, nil, errors.New("database not initialized")
+	}
+	token := APITokenDB{}
+	if err := DB.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAPITokenNotFound
+		}
+		return nil, nil, err
+	}
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: token %s expired", ErrAPITokenNotFound, token.Prefix)
+	}
+	user, err := GetUser(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &token, user, nil
+}
+
+// TouchAPIToken 记录 API token 最近使用时间
+func TouchAPIToken(ctx context.Context, id uint) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Model(&APITokenDB{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+}
+
+// ListAPITokens 获取用户的全部 API token
+func ListAPITokens(ctx context.Context, userID uint) ([]APITokenDB, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	tokens := []APITokenDB{}
+	err := DB.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+// DeleteAPIToken 删除用户的 API token
+func DeleteAPIToken(ctx context.Context, userID, id uint) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	result := DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&APITokenDB{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrAPITokenNotFound, id)
+	}
+	return nil
+}
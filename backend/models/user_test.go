@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasRole(t *testing.T) {
+	require.True(t, HasRole(RoleAdmin, RoleUser))
+	require.True(t, HasRole(RoleUser, RoleUser))
+	require.True(t, HasRole(RoleUser, RoleViewer))
+	require.False(t, HasRole(RoleViewer, RoleUser))
+	require.False(t, HasRole(RoleUser, RoleAdmin))
+	require.False(t, HasRole("", RoleViewer))
+	require.False(t, HasRole("root", RoleViewer))
+}
+
+func TestUserValidate(t *testing.T) {
+	require.Nil(t, UserDB{Username: "axiaoxin", Role: RoleUser, PasswordHash: "x"}.Validate())
+	require.ErrorIs(t, UserDB{Username: "ab", Role: RoleUser, PasswordHash: "x"}.Validate(), ErrInvalidUser)
+	require.ErrorIs(t, UserDB{Username: "a b c", Role: RoleUser, PasswordHash: "x"}.Validate(), ErrInvalidUser)
+	require.ErrorIs(t, UserDB{Username: "axiaoxin", Role: "root", PasswordHash: "x"}.Validate(), ErrInvalidUser)
+	require.ErrorIs(t, UserDB{Username: "axiaoxin", Role: RoleUser}.Validate(), ErrInvalidUser)
+}
@@ -0,0 +1,362 @@
+// 自选列表和保存的筛选预设
+
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 自选列表类型
+const (
+	WatchlistKindFund  = "fund"
+	WatchlistKindStock = "stock"
+)
+
+// WatchlistPrefix 在基金代码或股票关键词列表中以 watchlist:<name> 引用自选列表
+const WatchlistPrefix = "watchlist:"
+
+var (
+	// ErrWatchlistNotFound 自选列表不存在
+	ErrWatchlistNotFound = errors.New("watchlist not found")
+	// ErrInvalidWatchlist 自选列表参数错误
+	ErrInvalidWatchlist = errors.New("invalid watchlist")
+	// ErrScreenPresetNotFound 筛选预设不存在
+	ErrScreenPresetNotFound = errors.New("screen preset not found")
+	// ErrInvalidScreenPreset 筛选预设参数错误
+	ErrInvalidScreenPreset = errors.New("invalid screen preset")
+)
+
+// WatchlistItem 自选列表中的基金或股票
+type WatchlistItem struct {
+	// 基金代码或股票代码、名称
+	Code      string    `json:"code"`
+	Note      string    `json:"note"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Watchlist 自选列表
+type Watchlist struct {
+	Name string `json:"name"`
+	// 列表类型：fund/stock
+	Kind      string          `json:"kind"`
+	Note      string          `json:"note"`
+	Tags      []string        `json:"tags"`
+	Items     []WatchlistItem `json:"items"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Validate 检查自选列表
+func (w Watchlist) Validate() error {
+	switch {
+	case w.Name == "" || strings.ContainsAny(w.Name, ",/: "):
+		return fmt.Errorf("%w: name %q", ErrInvalidWatchlist, w.Name)
+	case w.Kind != WatchlistKindFund && w.Kind != WatchlistKindStock:
+		return fmt.Errorf("%w: kind %s", ErrInvalidWatchlist, w.Kind)
+	}
+	for _, item := range w.Items {
+		if item.Code == "" {
+			return fmt.Errorf("%w: item code is required", ErrInvalidWatchlist)
+		}
+	}
+	return nil
+}
+
+// Codes 自选列表中的全部代码
+func (w Watchlist) Codes() []string {
+	codes := make([]string, 0, len(w.Items))
+	for _, item := range w.Items {
+		codes = append(codes, item.Code)
+	}
+	return codes
+}
+
+// joinTags 标签以逗号分隔保存
+func joinTags(tags []string) string {
+	result := []string{}
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" {
+			result = append(result, t)
+		}
+	}
+	return strings.Join(result, ",")
+}
+
+func splitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
+
+func toWatchlistItemDB(watchlistID uint, item WatchlistItem) WatchlistItemDB {
+	return WatchlistItemDB{
+		WatchlistID: watchlistID,
+		Code:        strings.TrimSpace(item.Code),
+		Note:        item.Note,
+		Tags:        joinTags(item.Tags),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// loadWatchlist 按名称加载自选列表及其条目
+func loadWatchlist(db *gorm.DB, userID uint, name string) (*Watchlist, uint, error) {
+	row := WatchlistDB{}
+	if err := db.Where("user_id = ? AND name = ?", userID, name).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrWatchlistNotFound, name)
+		}
+		return nil, 0, err
+	}
+	items := []WatchlistItemDB{}
+	if err := db.Where("watchlist_id = ?", row.ID).Order("id").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	w := &Watchlist{
+		Name:      row.Name,
+		Kind:      row.Kind,
+		Note:      row.Note,
+		Tags:      splitTags(row.Tags),
+		Items:     make([]WatchlistItem, 0, len(items)),
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	for _, item := range items {
+		w.Items = append(w.Items, WatchlistItem{Code: item.Code, Note: item.Note, Tags: splitTags(item.Tags), CreatedAt: item.CreatedAt})
+	}
+	return w, row.ID, nil
+}
+
+// SaveWatchlist 创建用户的自选列表，同名列表已存在时整体替换
+func SaveWatchlist(ctx context.Context, userID uint, w Watchlist) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		row := WatchlistDB{}
+		err := tx.Where("user_id = ? AND name = ?", userID, w.Name).First(&row).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		row.UserID, row.Name, row.Kind, row.Note, row.Tags = userID, w.Name, w.Kind, w.Note, joinTags(w.Tags)
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("watchlist_id = ?", row.ID).Delete(&WatchlistItemDB{}).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		items := []WatchlistItemDB{}
+		for _, item := range w.Items {
+			dbItem := toWatchlistItemDB(row.ID, item)
+			if !seen[dbItem.Code] {
+				seen[dbItem.Code] = true
+				items = append(items, dbItem)
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(items, 100).Error
+	})
+}
+
+// GetWatchlist 按名称获取用户的自选列表
+func GetWatchlist(ctx context.Context, userID uint, name string) (*Watchlist, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	w, _, err := loadWatchlist(DB.WithContext(ctx), userID, name)
+	return w, err
+}
+
+// ListWatchlists 获取用户的全部自选列表，kind 和 tag 不为空时按类型和标签过滤
+func ListWatchlists(ctx context.Context, userID uint, kind, tag string) ([]Watchlist, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	rows := []WatchlistDB{}
+	if err := DB.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := []Watchlist{}
+	for _, row := range rows {
+		if kind != "" && row.Kind != kind {
+			continue
+		}
+		w, _, err := loadWatchlist(DB.WithContext(ctx), userID, row.Name)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" && !containsTag(w.Tags, tag) {
+			continue
+		}
+		result = append(result, *w)
+	}
+	return result, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteWatchlist 删除用户的自选列表及其条目
+func DeleteWatchlist(ctx context.Context, userID uint, name string) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, id, err := loadWatchlist(tx, userID, name)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("watchlist_id = ?", id).Delete(&WatchlistItemDB{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&WatchlistDB{}, id).Error
+	})
+}
+
+// AddWatchlistItems 向自选列表添加条目，已存在的代码更新备注和标签
+func AddWatchlistItems(ctx context.Context, userID uint, name string, items []WatchlistItem) (*Watchlist, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	_, id, err := loadWatchlist(DB.WithContext(ctx), userID, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if strings.TrimSpace(item.Code) == "" {
+			return nil, fmt.Errorf("%w: item code is required", ErrInvalidWatchlist)
+		}
+		row := toWatchlistItemDB(id, item)
+		err := DB.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "watchlist_id"}, {Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"note", "tags"}),
+		}).Create(&row).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	DB.WithContext(ctx).Model(&WatchlistDB{}).Where("id = ?", id).Update("updated_at", time.Now())
+	return GetWatchlist(ctx, userID, name)
+}
+
+// RemoveWatchlistItems 从自选列表移除代码
+func RemoveWatchlistItems(ctx context.Context, userID uint, name string, codes []string) (*Watchlist, error) {
+	if DB == nil {
+		return nil, errors.New("database not initialized")
+	}
+	_, id, err := loadWatchlist(DB.WithContext(ctx), userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := DB.WithContext(ctx).Where("watchlist_id = ? AND code IN ?", id, codes).Delete(&WatchlistItemDB{}).Error; err != nil {
+		return nil, err
+	}
+	DB.WithContext(ctx).Model(&WatchlistDB{}).Where("id = ?", id).Update("updated_at", time.Now())
+	return GetWatchlist(ctx, userID, name)
+}
+
+// ExpandWatchlistCodes 将代码列表中的 watchlist:<name> 展开为用户自选列表中的代码，按出现顺序去重
+// kind 为期望的自选列表类型，类型不一致时返回 ErrInvalidWatchlist
+func ExpandWatchlistCodes(ctx context.Context, userID uint, kind string, codes []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+	add := func(code string) {
+		if code != "" && !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if !strings.HasPrefix(code, WatchlistPrefix) {
+			add(code)
+			continue
+		}
+		w, err := GetWatchlist(ctx, userID, strings.TrimPrefix(code, WatchlistPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if w.Kind != kind {
+			return nil, fmt.Errorf("%w: %s is a %s watchlist, %s expected", ErrInvalidWatchlist, w.Name, w.Kind, kind)
+		}
+		for _, c := range w.Codes() {
+			add(c)
+		}
+	}
+	return result, nil
+}
+
+// SaveScreenPreset 保存筛选预设，同名预设已存在时覆盖
+func SaveScreenPreset(ctx context.Context, preset ScreenPresetDB) error {
+	if preset.Name == "" || strings.ContainsAny(preset.Name, ",/: ") {
+		return fmt.Errorf("%w: name %q", ErrInvalidScreenPreset, preset.Name)
+	}
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	preset.UpdatedAt = time.Now()
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"desc", "fund_filter", "stock_filter", "checker_options", "updated_at"}),
+	}).Create(&preset).Error
+}
+
+// GetScreenPreset 按名称获取保存的筛选预设
+func GetScreenPreset(ctx context.Context, name string) (*ScreenPresetDB, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("%w: %s, database not initialized", ErrScreenPresetNotFound, name)
+	}
+	row := ScreenPresetDB{}
+	if err := DB.WithContext(ctx).Where("name = ?", name).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrScreenPresetNotFound, name)
+		}
+		return nil, err
+	}
+	return &row, nil
+}
+
+// ListScreenPresets 获取全部保存的筛选预设
+func ListScreenPresets(ctx context.Context) ([]ScreenPresetDB, error) {
+	if DB == nil {
+		return []ScreenPresetDB{}, nil
+	}
+	rows := []ScreenPresetDB{}
+	err := DB.WithContext(ctx).Order("name").Find(&rows).Error
+	return rows, err
+}
+
+// DeleteScreenPreset 删除保存的筛选预设
+func DeleteScreenPreset(ctx context.Context, name string) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	result := DB.WithContext(ctx).Where("name = ?", name).Delete(&ScreenPresetDB{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrScreenPresetNotFound, name)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchlistValidate(t *testing.T) {
+	w := Watchlist{Name: "core", Kind: WatchlistKindFund, Items: []WatchlistItem{{Code: "000001"}}}
+	require.Nil(t, w.Validate())
+	require.Equal(t, []string{"000001"}, w.Codes())
+
+	w.Name = "watchlist:core"
+	require.ErrorIs(t, w.Validate(), ErrInvalidWatchlist)
+	w.Name = "core"
+	w.Kind = "bond"
+	require.ErrorIs(t, w.Validate(), ErrInvalidWatchlist)
+	w.Kind = WatchlistKindStock
+	w.Items = append(w.Items, WatchlistItem{})
+	require.ErrorIs(t, w.Validate(), ErrInvalidWatchlist)
+}
+
+func TestWatchlistTags(t *testing.T) {
+	require.Equal(t, "红利,低波", joinTags([]string{" 红利", "", "低波 "}))
+	require.Equal(t, []string{"红利", "低波"}, splitTags("红利,低波"))
+	require.Equal(t, []string{}, splitTags(""))
+}
+
+func TestExpandWatchlistCodes(t *testing.T) {
+	codes, err := ExpandWatchlistCodes(context.Background(), 0, WatchlistKindFund, []string{"000001", " 000002", "000001", ""})
+	require.Nil(t, err)
+	require.Equal(t, []string{"000001", "000002"}, codes)
+}
@@ -0,0 +1,93 @@
+// 不定期现金流的年化内部收益率
+
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrXIRRNotConverge 现金流没有同时包含投入和取回或无法求解
+var ErrXIRRNotConverge = errors.New("xirr not converge")
+
+// CashFlow 一笔现金流：投入为负，取回为正
+type CashFlow struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"`
+}
+
+// xnpv 以第一笔现金流日期为基准、按 365 天折算的净现值及其对 rate 的导数
+func xnpv(rate float64, flows []CashFlow, years []float64) (float64, float64) {
+	npv, d := 0.0, 0.0
+	for i, f := range flows {
+		base := math.Pow(1+rate, years[i])
+		npv += f.Amount / base
+		d -= years[i] * f.Amount / base / (1 + rate)
+	}
+	return npv, d
+}
+
+// XIRR 计算现金流的年化内部收益率（小数），先用牛顿法迭代，不收敛时在 (-1, 100] 区间二分
+func XIRR(flows []CashFlow) (float64, error) {
+	if len(flows) < 2 {
+		return 0, ErrXIRRNotConverge
+	}
+	first, err := time.Parse("2006-01-02", flows[0].Date)
+	if err != nil {
+		return 0, err
+	}
+	years := make([]float64, len(flows))
+	hasIn, hasOut := false, false
+	for i, f := range flows {
+		t, err := time.Parse("2006-01-02", f.Date)
+		if err != nil {
+			return 0, err
+		}
+		years[i] = t.Sub(first).Hours() / 24 / 365
+		hasIn = hasIn || f.Amount < 0
+		hasOut = hasOut || f.Amount > 0
+	}
+	if !hasIn || !hasOut {
+		return 0, ErrXIRRNotConverge
+	}
+
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		npv, d := xnpv(rate, flows, years)
+		if math.Abs(npv) < 1e-7 {
+			return rate, nil
+		}
+		if d == 0 || math.IsNaN(d) {
+			break
+		}
+		next := rate - npv/d
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		if math.Abs(next-rate) < 1e-10 {
+			return next, nil
+		}
+		rate = next
+	}
+
+	low, high := -0.999999, 100.0
+	npvLow, _ := xnpv(low, flows, years)
+	npvHigh, _ := xnpv(high, flows, years)
+	if npvLow*npvHigh > 0 {
+		return 0, ErrXIRRNotConverge
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		npv, _ := xnpv(mid, flows, years)
+		if math.Abs(npv) < 1e-7 || high-low < 1e-12 {
+			return mid, nil
+		}
+		if npv*npvLow > 0 {
+			low, npvLow = mid, npv
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
@@ -0,0 +1,121 @@
+// SMTP 邮件通知渠道
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Email SMTP 邮件，未配置用户名时不认证
+type Email struct {
+	name     string
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// Name 渠道名称
+func (e *Email) Name() string {
+	return e.name
+}
+
+// recipients 消息指定了收件人时使用消息的收件人
+func (e *Email) recipients(msg Message) []string {
+	if len(msg.To) > 0 {
+		return msg.To
+	}
+	return e.to
+}
+
+// buildMessage 生成邮件内容，标题按 RFC 2047 编码，有 HTML 正文或附件时使用 multipart/mixed
+func (e *Email) buildMessage(msg Message) []byte {
+	headers := []string{
+		"From: " + e.from,
+		"To: " + strings.Join(e.recipients(msg), ", "),
+		"Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title),
+		"Date: " + msg.Time.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+	}
+	if msg.HTML == "" && len(msg.Attachments) == 0 {
+		lines := append(headers,
+			"Content-Type: text/plain; charset=UTF-8",
+			"Content-Transfer-Encoding: 8bit",
+			"",
+			strings.ReplaceAll(msg.Content, "\n", "\r\n"),
+		)
+		return []byte(strings.Join(lines, "\r\n"))
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	contentType, text := "text/plain; charset=UTF-8", msg.Content
+	if msg.HTML != "" {
+		contentType, text = "text/html; charset=UTF-8", msg.HTML
+	}
+	part, _ := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	writeBase64(part, []byte(text))
+	for _, a := range msg.Attachments {
+		ct := a.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		filename := mime.BEncoding.Encode("UTF-8", a.Filename)
+		part, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=\"%s\"", ct, filename)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=\"%s\"", filename)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		writeBase64(part, a.Data)
+	}
+	w.Close()
+
+	headers = append(headers, "Content-Type: multipart/mixed; boundary="+w.Boundary(), "", "")
+	return append([]byte(strings.Join(headers, "\r\n")), body.Bytes()...)
+}
+
+// writeBase64 按每行 76 个字符写入 base64 编码内容
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+// Send 发送邮件
+func (e *Email) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if e.username != "" {
+		auth = smtp.PlainAuth("", e.username, e.password, e.host)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(e.addr, auth, e.from, e.recipients(msg), e.buildMessage(msg))
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp %s: %w", e.addr, err)
+		}
+		return nil
+	}
+}
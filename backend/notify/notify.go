@@ -0,0 +1,446 @@
+// Package notify 告警和任务结果的消息通知，支持 webhook、邮件、企业微信/钉钉/飞书机器人和 Server酱
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// 通知事件
+const (
+	// EventAlert 告警规则触发
+	EventAlert = "alert"
+	// EventJobFailure 定时任务执行失败
+	EventJobFailure = "job_failure"
+	// EventReport 定时报告
+	EventReport = "report"
+)
+
+// 通知渠道类型
+const (
+	ChannelWebhook    = "webhook"
+	ChannelEmail      = "email"
+	ChannelWeCom      = "wecom"
+	ChannelDingTalk   = "dingtalk"
+	ChannelFeishu     = "feishu"
+	ChannelServerChan = "serverchan"
+)
+
+var (
+	// ErrInvalidConfig 通知配置错误
+	ErrInvalidConfig = errors.New("invalid notify config")
+	// ErrQueueFull 异步通知队列已满
+	ErrQueueFull = errors.New("notify queue is full")
+	// ErrNotConfigured 未配置通知渠道
+	ErrNotConfigured = errors.New("notify not configured")
+	// ErrNoChannel 没有订阅该事件的通知渠道
+	ErrNoChannel = errors.New("no notify channel subscribes the event")
+	// ErrDryRun dry run 模式只打印日志不实际发送
+	ErrDryRun = errors.New("notify dry run")
+)
+
+// Message 渲染后的通知消息
+type Message struct {
+	Event   string      `json:"event"`
+	Title   string      `json:"title"`
+	Content string      `json:"content"`
+	Data    interface{} `json:"data"`
+	Time    time.Time   `json:"time"`
+	// HTML 正文，邮件渠道优先使用
+	HTML string `json:"-"`
+	// 邮件收件人，为空时使用渠道配置的收件人
+	To []string `json:"-"`
+	// 邮件附件
+	Attachments []Attachment `json:"-"`
+}
+
+// Attachment 邮件附件
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Channel 通知渠道
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// TemplateConfig 消息模板，使用 text/template 语法，数据为通知事件的数据
+type TemplateConfig struct {
+	Title   string `mapstructure:"title"   json:"title"`
+	Content string `mapstructure:"content" json:"content"`
+}
+
+// ChannelConfig 通知渠道配置
+type ChannelConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+	// 渠道类型：webhook/email/wecom/dingtalk/feishu/serverchan
+	Type string `mapstructure:"type" json:"type"`
+	// webhook 和机器人地址，Server酱为空时按 send_key 生成
+	URL string `mapstructure:"url" json:"url"`
+	// webhook 的 HMAC 签名密钥，钉钉和飞书机器人的加签密钥
+	Secret string `mapstructure:"secret" json:"-"`
+	// Server酱 SendKey
+	SendKey string `mapstructure:"send_key" json:"-"`
+	// 接收的事件，为空时接收全部事件
+	Events []string `mapstructure:"events" json:"events"`
+
+	// 邮件配置
+	SMTPHost string   `mapstructure:"smtp_host" json:"smtp_host"`
+	SMTPPort int      `mapstructure:"smtp_port" json:"smtp_port"`
+	Username string   `mapstructure:"username"  json:"username"`
+	Password string   `mapstructure:"password"  json:"-"`
+	From     string   `mapstructure:"from"      json:"from"`
+	To       []string `mapstructure:"to"        json:"to"`
+}
+
+// Config 通知配置
+type Config struct {
+	// 只打印日志不实际发送
+	DryRun bool `mapstructure:"dry_run" json:"dry_run"`
+	// 发送失败的重试次数
+	Retry int `mapstructure:"retry" json:"retry"`
+	// 首次重试的等待时间，之后每次翻倍
+	Backoff time.Duration `mapstructure:"backoff" json:"backoff"`
+	// 异步通知队列的长度，队列满时丢弃新的通知
+	QueueSize int `mapstructure:"queue_size" json:"queue_size"`
+	// 单次请求超时时间
+	Timeout   time.Duration             `mapstructure:"timeout"   json:"timeout"`
+	Templates map[string]TemplateConfig `mapstructure:"templates" json:"templates"`
+	Channels  []ChannelConfig           `mapstructure:"channels"  json:"channels"`
+}
+
+// DefaultTemplates 默认消息模板
+var DefaultTemplates = map[string]TemplateConfig{
+	EventAlert: {
+		Title:   "[investool] {{.RuleName}}",
+		Content: "{{.Message}}\n\n规则：{{.RuleName}}（{{.Type}}）\n对象：{{.Target}}\n观察值：{{.Value}}\n时间：{{.CreatedAt.Format \"2006-01-02 15:04:05\"}}",
+	},
+	EventJobFailure: {
+		Title:   "[investool] 定时任务 {{.Job}} 执行失败",
+		Content: "任务：{{.Job}}\n错误：{{.Error}}\n时间：{{.Time.Format \"2006-01-02 15:04:05\"}}",
+	},
+}
+
+// JobFailure 定时任务失败通知的数据
+type JobFailure struct {
+	Job   string    `json:"job"`
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+type notifyTemplate struct {
+	title   *template.Template
+	content *template.Template
+}
+
+type notifyChannel struct {
+	Channel
+	events []string
+}
+
+func (c notifyChannel) accept(event string) bool {
+	if len(c.events) == 0 {
+		return true
+	}
+	for _, e := range c.events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier 按事件渲染模板并发送到订阅该事件的渠道
+type Notifier struct {
+	dryRun    bool
+	retry     int
+	backoff   time.Duration
+	timeout   time.Duration
+	templates map[string]notifyTemplate
+	channels  []notifyChannel
+	// 异步发送队列，由 Enqueue 第一次调用时启动的 worker 消费
+	queue      chan Message
+	workerOnce sync.Once
+}
+
+// NewChannel 按配置创建通知渠道
+func NewChannel(cfg ChannelConfig, client *http.Client) (Channel, error) {
+	if cfg.Name == "" {
+		cfg.Name = cfg.Type
+	}
+	switch cfg.Type {
+	case ChannelWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%w: %s url is required", ErrInvalidConfig, cfg.Name)
+		}
+		return &Webhook{name: cfg.Name, url: cfg.URL, secret: cfg.Secret, client: client}, nil
+	case ChannelWeCom, ChannelDingTalk, ChannelFeishu:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%w: %s url is required", ErrInvalidConfig, cfg.Name)
+		}
+		return &Robot{name: cfg.Name, kind: cfg.Type, url: cfg.URL, secret: cfg.Secret, client: client}, nil
+	case ChannelServerChan:
+		url := cfg.URL
+		if url == "" {
+			if cfg.SendKey == "" {
+				return nil, fmt.Errorf("%w: %s send_key is required", ErrInvalidConfig, cfg.Name)
+			}
+			url = fmt.Sprintf("https://sctapi.ftqq.com/%s.send", cfg.SendKey)
+		}
+		return &ServerChan{name: cfg.Name, url: url, client: client}, nil
+	case ChannelEmail:
+		if cfg.SMTPHost == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("%w: %s smtp_host, from and to are required", ErrInvalidConfig, cfg.Name)
+		}
+		port := cfg.SMTPPort
+		if port == 0 {
+			port = 25
+		}
+		return &Email{
+			name:     cfg.Name,
+			addr:     fmt.Sprintf("%s:%d", cfg.SMTPHost, port),
+			host:     cfg.SMTPHost,
+			username: cfg.Username,
+			password: cfg.Password,
+			from:     cfg.From,
+			to:       cfg.To,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s unknown type %s", ErrInvalidConfig, cfg.Name, cfg.Type)
+}
+
+// NewNotifier 按配置创建通知器
+func NewNotifier(cfg Config) (*Notifier, error) {
+	if cfg.Backoff == 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	n := &Notifier{
+		dryRun:    cfg.DryRun,
+		retry:     cfg.Retry,
+		backoff:   cfg.Backoff,
+		timeout:   cfg.Timeout,
+		templates: map[string]notifyTemplate{},
+		queue:     make(chan Message, cfg.QueueSize),
+	}
+
+	// 配置的模板覆盖默认模板中的对应字段
+	templates := map[string]TemplateConfig{}
+	for event, tc := range DefaultTemplates {
+		templates[event] = tc
+	}
+	for event, custom := range cfg.Templates {
+		tc := templates[event]
+		if custom.Title != "" {
+			tc.Title = custom.Title
+		}
+		if custom.Content != "" {
+			tc.Content = custom.Content
+		}
+		templates[event] = tc
+	}
+	for event, tc := range templates {
+		title, err := template.New(event + ".title").Parse(tc.Title)
+		if err != nil {
+			return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidConfig, event, err)
+		}
+		content, err := template.New(event + ".content").Parse(tc.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: template %s: %v", ErrInvalidConfig, event, err)
+		}
+		n.templates[event] = notifyTemplate{title: title, content: content}
+	}
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	for _, cc := range cfg.Channels {
+		ch, err := NewChannel(cc, client)
+		if err != nil {
+			return nil, err
+		}
+		n.channels = append(n.channels, notifyChannel{Channel: ch, events: cc.Events})
+	}
+	return n, nil
+}
+
+// AddChannel 添加通知渠道，events 为空时接收全部事件
+func (n *Notifier) AddChannel(ch Channel, events ...string) {
+	n.channels = append(n.channels, notifyChannel{Channel: ch, events: events})
+}
+
+// Render 按事件模板渲染消息
+func (n *Notifier) Render(event string, data interface{}) (Message, error) {
+	msg := Message{Event: event, Data: data, Time: time.Now()}
+	tpl, ok := n.templates[event]
+	if !ok {
+		msg.Title = event
+		msg.Content = fmt.Sprintf("%+v", data)
+		return msg, nil
+	}
+	var title, content bytes.Buffer
+	if err := tpl.title.Execute(&title, data); err != nil {
+		return msg, err
+	}
+	if err := tpl.content.Execute(&content, data); err != nil {
+		return msg, err
+	}
+	msg.Title = strings.TrimSpace(title.String())
+	msg.Content = strings.TrimSpace(content.String())
+	return msg, nil
+}
+
+// Notify 渲染消息并发送到订阅该事件的全部渠道
+func (n *Notifier) Notify(ctx context.Context, event string, data interface{}) error {
+	msg, err := n.Render(event, data)
+	if err != nil {
+		return err
+	}
+	return n.Send(ctx, msg)
+}
+
+// Enqueue 渲染消息后放入异步发送队列，不等待发送和重试完成，队列已满时丢弃消息并返回 ErrQueueFull
+func (n *Notifier) Enqueue(ctx context.Context, event string, data interface{}) error {
+	msg, err := n.Render(event, data)
+	if err != nil {
+		return err
+	}
+	n.workerOnce.Do(func() {
+		go n.worker()
+	})
+	select {
+	case n.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// worker 按顺序发送队列中的消息
+func (n *Notifier) worker() {
+	for msg := range n.queue {
+		if err := n.Send(context.Background(), msg); err != nil {
+			logrus.Errorf("notify async event:%s error:%v", msg.Event, err)
+		}
+	}
+}
+
+// Deliverable 检查事件是否会实际发送：没有订阅该事件的渠道时返回 ErrNoChannel，dry run 时返回 ErrDryRun
+func (n *Notifier) Deliverable(event string) error {
+	for _, ch := range n.channels {
+		if ch.accept(event) {
+			if n.dryRun {
+				return ErrDryRun
+			}
+			return nil
+		}
+	}
+	return ErrNoChannel
+}
+
+// Send 发送已生成的消息到订阅该事件的全部渠道，各渠道失败时按退避时间重试
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	event := msg.Event
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	errs := []string{}
+	for _, ch := range n.channels {
+		if !ch.accept(event) {
+			continue
+		}
+		if n.dryRun {
+			logrus.WithContext(ctx).Infof("notify dry run channel:%s event:%s title:%s content:%s attachments:%d", ch.Name(), event, msg.Title, msg.Content, len(msg.Attachments))
+			continue
+		}
+		if err := n.send(ctx, ch, msg); err != nil {
+			logrus.WithContext(ctx).Errorf("notify channel:%s event:%s error:%v", ch.Name(), event, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", ch.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, ch Channel, msg Message) (err error) {
+	wait := n.backoff
+	for attempt := 0; attempt <= n.retry; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, n.timeout)
+		err = ch.Send(sendCtx, msg)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		logrus.WithContext(ctx).Warnf("notify channel:%s attempt:%d error:%v", ch.Name(), attempt+1, err)
+	}
+	return err
+}
+
+// Default 全局通知器，未初始化时不发送通知
+var Default *Notifier
+
+// InitFromViper 从 viper 的 notify 配置项初始化全局通知器，未配置渠道时不初始化
+func InitFromViper() error {
+	cfg := Config{}
+	if err := viper.UnmarshalKey("notify", &cfg); err != nil {
+		return err
+	}
+	if len(cfg.Channels) == 0 {
+		return nil
+	}
+	n, err := NewNotifier(cfg)
+	if err != nil {
+		return err
+	}
+	Default = n
+	logrus.Infof("notify initialized with %d channels, dry_run:%v", len(cfg.Channels), cfg.DryRun)
+	return nil
+}
+
+// Notify 使用全局通知器异步发送通知，不阻塞调用方，渲染失败或队列已满时只记录日志
+func Notify(ctx context.Context, event string, data interface{}) {
+	if Default == nil {
+		return
+	}
+	if err := Default.Enqueue(ctx, event, data); err != nil {
+		logrus.WithContext(ctx).Errorf("notify event:%s error:%v", event, err)
+	}
+}
+
+// Send 使用全局通知器发送已生成的消息，消息不会实际发送时返回原因：
+// 未初始化时返回 ErrNotConfigured，没有订阅该事件的渠道时返回 ErrNoChannel，dry run 时返回 ErrDryRun
+func Send(ctx context.Context, msg Message) error {
+	if Default == nil {
+		return ErrNotConfigured
+	}
+	if err := Default.Deliverable(msg.Event); err != nil {
+		return err
+	}
+	return Default.Send(ctx, msg)
+}
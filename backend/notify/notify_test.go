@@ -0,0 +1,338 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAlert struct {
+	RuleName  string
+	Type      string
+	Target    string
+	Value     string
+	Message   string
+	CreatedAt time.Time
+}
+
+var testAlertData = testAlert{
+	RuleName:  "规模预警",
+	Type:      "fund_scale_below",
+	Target:    "000001",
+	Value:     "1.50",
+	Message:   "华夏成长(000001) 规模 1.50 亿，低于 2.00 亿",
+	CreatedAt: time.Date(2021, 6, 1, 20, 0, 0, 0, time.Local),
+}
+
+// recorder 记录测试服务器收到的请求
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *recorder) handler(status int, resp string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, resp)
+	}
+}
+
+func TestRender(t *testing.T) {
+	n, err := NewNotifier(Config{Templates: map[string]TemplateConfig{
+		EventAlert: {Title: "告警：{{.Target}}"},
+	}})
+	require.Nil(t, err)
+	msg, err := n.Render(EventAlert, testAlertData)
+	require.Nil(t, err)
+	require.Equal(t, "告警：000001", msg.Title)
+	require.Contains(t, msg.Content, testAlertData.Message)
+	require.Contains(t, msg.Content, "2021-06-01 20:00:00")
+
+	msg, err = n.Render(EventJobFailure, JobFailure{Job: "SyncFund", Error: "timeout", Time: time.Now()})
+	require.Nil(t, err)
+	require.Equal(t, "[investool] 定时任务 SyncFund 执行失败", msg.Title)
+
+	_, err = NewNotifier(Config{Templates: map[string]TemplateConfig{EventAlert: {Title: "{{.Target"}}})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = NewNotifier(Config{Channels: []ChannelConfig{{Type: "sms"}}})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestWebhook(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec.handler(http.StatusOK, ""))
+	defer srv.Close()
+
+	n, err := NewNotifier(Config{Channels: []ChannelConfig{
+		{Name: "hook", Type: ChannelWebhook, URL: srv.URL, Secret: "s3cret"},
+		{Name: "jobs", Type: ChannelWebhook, URL: srv.URL, Events: []string{EventJobFailure}},
+	}})
+	require.Nil(t, err)
+	require.Nil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+	require.Len(t, rec.requests, 1)
+
+	req, body := rec.requests[0], rec.bodies[0]
+	require.Equal(t, EventAlert, req.Header.Get(HeaderEvent))
+	require.Equal(t, Sign("s3cret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+	msg := Message{}
+	require.Nil(t, json.Unmarshal(body, &msg))
+	require.Equal(t, EventAlert, msg.Event)
+	require.Equal(t, "[investool] 规模预警", msg.Title)
+}
+
+func TestRetryAndDryRun(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := Config{Retry: 2, Backoff: time.Millisecond, Channels: []ChannelConfig{{Type: ChannelWebhook, URL: srv.URL}}}
+	n, err := NewNotifier(cfg)
+	require.Nil(t, err)
+	require.Nil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+	require.Equal(t, 3, calls)
+
+	// 重试次数用完后返回错误
+	calls = -10
+	require.NotNil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+	require.Equal(t, -7, calls)
+
+	// dry run 不发送
+	calls = 0
+	cfg.DryRun = true
+	n, err = NewNotifier(cfg)
+	require.Nil(t, err)
+	require.Nil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+	require.Equal(t, 0, calls)
+	require.ErrorIs(t, n.Deliverable(EventAlert), ErrDryRun)
+}
+
+func TestDeliverable(t *testing.T) {
+	n, err := NewNotifier(Config{Channels: []ChannelConfig{{Type: ChannelWebhook, URL: "http://127.0.0.1", Events: []string{EventJobFailure}}}})
+	require.Nil(t, err)
+	require.Nil(t, n.Deliverable(EventJobFailure))
+	require.ErrorIs(t, n.Deliverable(EventReport), ErrNoChannel)
+
+	// 未初始化全局通知器时不发送并返回原因
+	Default = nil
+	require.ErrorIs(t, Send(context.Background(), Message{Event: EventReport}), ErrNotConfigured)
+}
+
+// blockingChannel 发送时阻塞直到 release 关闭
+type blockingChannel struct {
+	release chan struct{}
+	sent    chan Message
+}
+
+func (c *blockingChannel) Name() string { return "blocking" }
+
+func (c *blockingChannel) Send(ctx context.Context, msg Message) error {
+	<-c.release
+	c.sent <- msg
+	return nil
+}
+
+func TestEnqueue(t *testing.T) {
+	n, err := NewNotifier(Config{QueueSize: 1})
+	require.Nil(t, err)
+	ch := &blockingChannel{release: make(chan struct{}), sent: make(chan Message, 3)}
+	n.AddChannel(ch)
+
+	// 渠道发送阻塞时 Enqueue 立即返回，worker 取走第一条后队列可再放入一条
+	require.Nil(t, n.Enqueue(context.Background(), EventAlert, testAlertData))
+	require.Eventually(t, func() bool { return len(n.queue) == 0 }, time.Second, time.Millisecond)
+	require.Nil(t, n.Enqueue(context.Background(), EventAlert, testAlertData))
+	require.ErrorIs(t, n.Enqueue(context.Background(), EventAlert, testAlertData), ErrQueueFull)
+
+	close(ch.release)
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-ch.sent:
+			require.Equal(t, "[investool] 规模预警", msg.Title)
+		case <-time.After(time.Second):
+			t.Fatal("message not sent")
+		}
+	}
+}
+
+func TestRobots(t *testing.T) {
+	rec := &recorder{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wecom", rec.handler(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`))
+	mux.HandleFunc("/dingtalk", rec.handler(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`))
+	mux.HandleFunc("/feishu", rec.handler(http.StatusOK, `{"code":0,"msg":"success"}`))
+	mux.HandleFunc("/serverchan", rec.handler(http.StatusOK, `{"code":0,"message":""}`))
+	mux.HandleFunc("/fail", rec.handler(http.StatusOK, `{"errcode":310000,"errmsg":"sign not match"}`))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	n, err := NewNotifier(Config{Channels: []ChannelConfig{
+		{Type: ChannelWeCom, URL: srv.URL + "/wecom"},
+		{Type: ChannelDingTalk, URL: srv.URL + "/dingtalk?access_token=x", Secret: "SECx"},
+		{Type: ChannelFeishu, URL: srv.URL + "/feishu", Secret: "fs"},
+		{Type: ChannelServerChan, URL: srv.URL + "/serverchan"},
+	}})
+	require.Nil(t, err)
+	require.Nil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+	require.Len(t, rec.requests, 4)
+
+	wecom := struct {
+		Markdown struct {
+			Content string `json:"content"`
+		} `json:"markdown"`
+	}{}
+	require.Nil(t, json.Unmarshal(rec.bodies[0], &wecom))
+	require.Contains(t, wecom.Markdown.Content, testAlertData.Message)
+
+	q := rec.requests[1].URL.Query()
+	require.Equal(t, "x", q.Get("access_token"))
+	require.Equal(t, robotSign(ChannelDingTalk, "SECx", q.Get("timestamp")), q.Get("sign"))
+
+	feishu := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(rec.bodies[2], &feishu))
+	require.Equal(t, robotSign(ChannelFeishu, "fs", feishu["timestamp"].(string)), feishu["sign"])
+
+	form, err := url.ParseQuery(string(rec.bodies[3]))
+	require.Nil(t, err)
+	require.Equal(t, "[investool] 规模预警", form.Get("title"))
+
+	// 机器人接口返回错误码
+	n, err = NewNotifier(Config{Channels: []ChannelConfig{{Type: ChannelDingTalk, URL: srv.URL + "/fail"}}})
+	require.Nil(t, err)
+	require.Error(t, n.Notify(context.Background(), EventAlert, testAlertData))
+}
+
+// fakeSMTP 最简 SMTP 服务，返回收到的邮件数据
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	received := make(chan string, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ESMTP")
+		data := []string{}
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if inData {
+				if line == "." {
+					inData = false
+					received <- strings.Join(data, "\n")
+					reply("250 OK")
+					continue
+				}
+				data = append(data, line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestEmail(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	n, err := NewNotifier(Config{Channels: []ChannelConfig{{
+		Type:     ChannelEmail,
+		SMTPHost: host,
+		SMTPPort: port,
+		From:     "investool@example.com",
+		To:       []string{"me@example.com"},
+	}}})
+	require.Nil(t, err)
+	require.Nil(t, n.Notify(context.Background(), EventAlert, testAlertData))
+
+	select {
+	case data := <-received:
+		require.Contains(t, data, "To: me@example.com")
+		require.Contains(t, data, "Subject: =?UTF-8?b?")
+		require.Contains(t, data, testAlertData.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("email not received")
+	}
+}
+
+func TestEmailAttachment(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+
+	n, err := NewNotifier(Config{Channels: []ChannelConfig{{
+		Type:     ChannelEmail,
+		SMTPHost: host,
+		SMTPPort: port,
+		From:     "investool@example.com",
+		To:       []string{"me@example.com"},
+		Events:   []string{EventReport},
+	}}})
+	require.Nil(t, err)
+	require.Nil(t, n.Send(context.Background(), Message{
+		Event:       EventReport,
+		Title:       "周报",
+		Content:     "summary",
+		HTML:        "<h1>周报</h1>",
+		To:          []string{"other@example.com"},
+		Attachments: []Attachment{{Filename: "report.xlsx", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Data: []byte("xlsx")}},
+	}))
+
+	select {
+	case data := <-received:
+		require.Contains(t, data, "To: other@example.com")
+		require.Contains(t, data, "Content-Type: multipart/mixed; boundary=")
+		require.Contains(t, data, "Content-Type: text/html; charset=UTF-8")
+		require.Contains(t, data, `filename="report.xlsx"`)
+		require.Contains(t, data, base64.StdEncoding.EncodeToString([]byte("<h1>周报</h1>")))
+	case <-time.After(5 * time.Second):
+		t.Fatal("email not received")
+	}
+}
@@ -0,0 +1,222 @@
+// webhook、机器人和 Server酱 通知渠道
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 通用 webhook 签名请求头
+const (
+	HeaderSignature = "X-Investool-Signature"
+	HeaderTimestamp = "X-Investool-Timestamp"
+	HeaderEvent     = "X-Investool-Event"
+)
+
+// Sign 计算 webhook 请求体的 HMAC-SHA256 签名，签名内容为 时间戳.请求体
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post 发送请求，返回 2xx 以外的状态码时返回错误
+func post(ctx context.Context, client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return body, fmt.Errorf("http status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// Webhook 通用 webhook，POST JSON 消息，配置了密钥时附带 HMAC 签名
+type Webhook struct {
+	name   string
+	url    string
+	secret string
+	client *http.Client
+}
+
+// Name 渠道名称
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+// Send 发送消息
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if w.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(w.secret, timestamp, body))
+	}
+	_, err = post(ctx, w.client, req)
+	return err
+}
+
+// Robot 企业微信、钉钉、飞书群机器人
+type Robot struct {
+	name   string
+	kind   string
+	url    string
+	secret string
+	client *http.Client
+}
+
+// Name 渠道名称
+func (r *Robot) Name() string {
+	return r.name
+}
+
+// robotSign 钉钉和飞书加签：HmacSHA256 后 base64，钉钉以密钥为 key 签名 时间戳\n密钥，飞书以 时间戳\n密钥 为 key 签名空串
+func robotSign(kind, secret string, timestamp string) string {
+	stringToSign := timestamp + "\n" + secret
+	var mac hash.Hash
+	if kind == ChannelFeishu {
+		mac = hmac.New(sha256.New, []byte(stringToSign))
+	} else {
+		mac = hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(stringToSign))
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Send 发送 markdown 或文本消息
+func (r *Robot) Send(ctx context.Context, msg Message) error {
+	target := r.url
+	var payload interface{}
+	switch r.kind {
+	case ChannelWeCom:
+		payload = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"content": fmt.Sprintf("### %s\n%s", msg.Title, msg.Content)},
+		}
+	case ChannelDingTalk:
+		if r.secret != "" {
+			timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			u, err := url.Parse(r.url)
+			if err != nil {
+				return err
+			}
+			q := u.Query()
+			q.Set("timestamp", timestamp)
+			q.Set("sign", robotSign(r.kind, r.secret, timestamp))
+			u.RawQuery = q.Encode()
+			target = u.String()
+		}
+		payload = map[string]interface{}{
+			"msgtype":  "markdown",
+			"markdown": map[string]string{"title": msg.Title, "text": fmt.Sprintf("### %s\n%s", msg.Title, msg.Content)},
+		}
+	case ChannelFeishu:
+		p := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": msg.Title + "\n" + msg.Content},
+		}
+		if r.secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			p["timestamp"] = timestamp
+			p["sign"] = robotSign(r.kind, r.secret, timestamp)
+		}
+		payload = p
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	respBody, err := post(ctx, r.client, req)
+	if err != nil {
+		return err
+	}
+	return checkRobotResponse(respBody)
+}
+
+// checkRobotResponse 机器人接口在 HTTP 200 时通过 errcode 或 code 返回错误
+func checkRobotResponse(body []byte) error {
+	resp := struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		Code    int    `json:"code"`
+		Msg     string `json:"msg"`
+		Message string `json:"message"`
+	}{}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid response: %s", string(body))
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("code %d: %s%s", resp.Code, resp.Msg, resp.Message)
+	}
+	return nil
+}
+
+// ServerChan Server酱推送
+type ServerChan struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// Name 渠道名称
+func (s *ServerChan) Name() string {
+	return s.name
+}
+
+// Send 以表单提交标题和 markdown 正文
+func (s *ServerChan) Send(ctx context.Context, msg Message) error {
+	form := url.Values{}
+	form.Set("title", msg.Title)
+	form.Set("desp", msg.Content)
+	req, err := http.NewRequest(http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := post(ctx, s.client, req)
+	if err != nil {
+		return err
+	}
+	return checkRobotResponse(body)
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/axiaoxin-com/investool/api"
+	"github.com/axiaoxin-com/investool/middleware"
+	"github.com/axiaoxin-com/investool/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,17 +14,116 @@ import (
 func Routes(app *gin.Engine) {
 	// 创建 API 控制器
 	fundController := api.NewFundController()
+	stockController := api.NewStockController()
+	bondController := api.NewBondController()
+	marketController := api.NewMarketController()
+	indexController := api.NewIndexController()
+	industryController := api.NewIndustryController()
+	watchlistController := api.NewWatchlistController()
+	alertController := api.NewAlertController()
+	reportController := api.NewReportController()
+	authController := api.NewAuthController()
+	jobController := api.NewJobController()
+
+	// 权限：viewer 只读，user 可执行计算类接口和管理自己的数据，admin 可管理预设、用户和任务
+	// 未开启认证时不做校验
+	viewer := middleware.RequireRole(models.RoleViewer)
+	user := middleware.RequireRole(models.RoleUser)
+	admin := middleware.RequireRole(models.RoleAdmin)
+	// 限流：高成本接口按请求成本扣减预算，其余计算类接口每个请求成本为 1
+	limit := middleware.RateLimit(nil)
 
 	// API 路由组
 	apiGroup := app.Group("/api")
+	apiGroup.Use(middleware.Authenticate())
 	{
+		// 用户认证 API
+		apiGroup.POST("/auth/register", authController.Register)
+		apiGroup.POST("/auth/login", authController.Login)
+		apiGroup.GET("/auth/me", viewer, authController.Me)
+		apiGroup.GET("/auth/tokens", viewer, authController.ListAPITokens)
+		apiGroup.POST("/auth/tokens", viewer, authController.CreateAPIToken)
+		apiGroup.DELETE("/auth/tokens/:id", viewer, authController.DeleteAPIToken)
+		apiGroup.GET("/users", admin, authController.ListUsers)
+		apiGroup.POST("/users/:id/role", admin, authController.UpdateUserRole)
+
 		// 基金相关 API
 		apiGroup.GET("/fund", fundController.GetFundIndex)
 		apiGroup.GET("/fund/filter", fundController.GetFundFilter)
-		apiGroup.POST("/fund/check", fundController.CheckFund)
+		apiGroup.POST("/fund/check", user, middleware.RateLimit(api.CheckFundCost), fundController.CheckFund)
+		apiGroup.POST("/fund/check/stream", user, middleware.RateLimit(api.CheckFundCost), fundController.CheckFundStream)
 		apiGroup.GET("/fund/managers", fundController.GetFundManagers)
-		apiGroup.GET("/fund/similarity", fundController.GetFundSimilarity)
-		apiGroup.POST("/fund/query_by_stock", fundController.QueryByStock)
+		apiGroup.GET("/fund/similarity", limit, fundController.GetFundSimilarity)
+		apiGroup.GET("/fund/similarity/stream", limit, fundController.GetFundSimilarityStream)
+		apiGroup.POST("/fund/query_by_stock", user, limit, fundController.QueryByStock)
+		apiGroup.GET("/fund/holdings", fundController.GetFundHoldings)
+		apiGroup.GET("/fund/holdings/diff", fundController.GetFundHoldingsDiff)
+		apiGroup.GET("/fund/smart_money", fundController.GetSmartMoney)
+		apiGroup.GET("/fund/style", middleware.RateLimit(api.FundStyleCost), fundController.GetFundStyle)
+		apiGroup.GET("/fund/attribution", limit, fundController.GetFundAttribution)
+		apiGroup.POST("/fund/backtest", user, limit, fundController.BacktestFunds)
+		apiGroup.POST("/fund/dca", user, limit, fundController.SimulateDCA)
+		apiGroup.POST("/fund/allocation/plan", user, limit, fundController.PlanAllocation)
+
+		// 股票相关 API
+		apiGroup.GET("/stock/prices", stockController.GetStockPrices)
+		apiGroup.GET("/stock/volatility", stockController.GetStockVolatility)
+		apiGroup.GET("/stock/money_flow", stockController.GetStockMoneyFlow)
+		apiGroup.GET("/stock/money_flow/rank", stockController.RankStockMoneyFlow)
+		apiGroup.POST("/stock/check", user, middleware.RateLimit(api.CheckStocksCost), stockController.CheckStocks)
+		apiGroup.POST("/stock/check/stream", user, middleware.RateLimit(api.CheckStocksCost), stockController.CheckStocksStream)
+		apiGroup.POST("/stock/select/stream", user, middleware.RateLimit(api.SelectStocksCost), stockController.SelectStocksStream)
+
+		// 债券收益率相关 API
+		apiGroup.GET("/bond/curves", bondController.GetBondCurves)
+		apiGroup.GET("/bond/curve", bondController.GetBondCurve)
+		apiGroup.GET("/bond/yields", bondController.GetBondYields)
+		apiGroup.GET("/bond/spread", bondController.GetBondSpread)
+
+		// 市场温度相关 API
+		apiGroup.GET("/market/temperature", limit, marketController.GetMarketTemperature)
+		apiGroup.GET("/market/temperature/history", marketController.GetMarketTemperatureHistory)
+
+		// 指数相关 API
+		apiGroup.GET("/index/:code/funds", middleware.RateLimit(api.IndexFundsCost), indexController.GetIndexFunds)
+		apiGroup.GET("/index/:code/valuations", indexController.GetIndexValuations)
+		apiGroup.GET("/index/:code/signal", indexController.GetIndexSignal)
+		apiGroup.GET("/index/:code/constituents", indexController.GetIndexConstituents)
+		apiGroup.GET("/index/:code/constituents/diff", indexController.DiffIndexConstituents)
+		apiGroup.GET("/index/constituents/set", indexController.IndexConstituentsSet)
+
+		// 行业相关 API
+		apiGroup.GET("/industry/boards", industryController.GetIndustryBoards)
+		apiGroup.GET("/industry/series", industryController.GetIndustrySeries)
+		apiGroup.GET("/industry/allocation", industryController.GetIndustryAllocation)
+		apiGroup.GET("/industry/rotation", industryController.GetIndustryRotation)
+
+		// 自选列表和筛选预设 API
+		apiGroup.GET("/watchlists", viewer, watchlistController.ListWatchlists)
+		apiGroup.POST("/watchlists", user, watchlistController.SaveWatchlist)
+		apiGroup.GET("/watchlists/:name", viewer, watchlistController.GetWatchlist)
+		apiGroup.DELETE("/watchlists/:name", user, watchlistController.DeleteWatchlist)
+		apiGroup.POST("/watchlists/:name/items", user, watchlistController.AddWatchlistItems)
+		apiGroup.DELETE("/watchlists/:name/items/:code", user, watchlistController.RemoveWatchlistItem)
+		apiGroup.GET("/presets", watchlistController.ListPresets)
+		apiGroup.POST("/presets", admin, watchlistController.SavePreset)
+		apiGroup.GET("/presets/:name", watchlistController.GetPreset)
+		apiGroup.DELETE("/presets/:name", admin, watchlistController.DeletePreset)
+
+		// 告警相关 API
+		apiGroup.GET("/alerts", viewer, alertController.ListAlerts)
+		apiGroup.GET("/alerts/rules", viewer, alertController.ListAlertRules)
+		apiGroup.POST("/alerts/rules", user, alertController.SaveAlertRule)
+		apiGroup.DELETE("/alerts/rules/:id", user, alertController.DeleteAlertRule)
+		apiGroup.POST("/alerts/evaluate", admin, alertController.EvaluateAlerts)
+
+		// 定时报告相关 API
+		apiGroup.GET("/reports", viewer, reportController.ListReports)
+		apiGroup.GET("/reports/:id", viewer, reportController.DownloadReport)
+
+		// 同步任务 API
+		apiGroup.GET("/jobs", admin, jobController.ListJobs)
+		apiGroup.POST("/jobs/:name", admin, jobController.TriggerJob)
 
 		// 健康检查
 		apiGroup.GET("/health", func(c *gin.Context) {
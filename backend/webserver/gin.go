@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/json-iterator/go/extra"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
 func init() {
@@ -28,6 +30,15 @@ func NewGinEngine() *gin.Engine {
 	engine := gin.New()
 	// ///a///b -> /a/b
 	engine.RemoveExtraSlash = true
+	// 只信任配置的反向代理传递的 X-Forwarded-For ，未配置时使用连接的对端地址作为客户端 IP
+	trustedProxies := viper.GetStringSlice("server.trusted_proxies")
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		logrus.Error("SetTrustedProxies error:" + err.Error())
+		engine.SetTrustedProxies(nil)
+	}
 
 	// 添加默认中间件
 	defaultMiddlewares := []gin.HandlerFunc{
@@ -12,6 +12,12 @@ import {
   ApiResponse
 } from '../types/fund';
 
+// SSE 事件回调
+export type StreamEventHandler = (event: string, data: any) => void;
+
+// 登录后的 JWT 保存在 localStorage 中
+const TOKEN_KEY = 'investool_token';
+
 class ApiClient {
   private client: AxiosInstance;
 
@@ -44,6 +50,10 @@ class ApiClient {
     this.client.interceptors.request.use(
       (config) => {
         console.log('API Request:', config.method?.toUpperCase(), config.url);
+        const token = this.getToken();
+        if (token) {
+          config.headers.Authorization = `Bearer ${token}`;
+        }
         return config;
       },
       (error) => {
@@ -60,11 +70,42 @@ class ApiClient {
       },
       (error) => {
         console.error('Response Error:', error.response?.status, error.message);
+        // 凭证过期或无效时清除，需要重新登录
+        if (error.response?.status === 401) {
+          this.setToken(null);
+        }
         return Promise.reject(error);
       }
     );
   }
 
+  // 获取保存的 JWT
+  getToken(): string | null {
+    return localStorage.getItem(TOKEN_KEY);
+  }
+
+  // 保存 JWT，为 null 时清除
+  setToken(token: string | null) {
+    if (token) {
+      localStorage.setItem(TOKEN_KEY, token);
+    } else {
+      localStorage.removeItem(TOKEN_KEY);
+    }
+  }
+
+  // 登录并保存 JWT，之后的请求自动携带
+  async login(username: string, password: string): Promise<any> {
+    const response = await this.client.post('/api/auth/login', { username, password });
+    const data = response.data && response.data.data ? response.data.data : response.data;
+    this.setToken(data.token);
+    return data;
+  }
+
+  // 退出登录
+  logout() {
+    this.setToken(null);
+  }
+
   // 基金首页 - 4433基金列表
   async getFundIndex(params: FundIndexParams = {}): Promise<FundIndexResponse> {
     const response = await this.client.get('/api/fund', { params });
@@ -95,6 +136,75 @@ class ApiClient {
     return response.data;
   }
 
+  // 基金检测（SSE 流式返回），逐个推送查询到的基金和检测结果，不受请求超时限制
+  // 事件：start、progress、fund、stock_check、attribution、done、error
+  async postFundCheckStream(
+    params: FundCheckParams,
+    onEvent: StreamEventHandler,
+    signal?: AbortSignal
+  ): Promise<void> {
+    await this.postStream('/api/fund/check/stream', params, onEvent, signal);
+  }
+
+  // 通用 SSE POST 请求，EventSource 不支持 POST，使用 fetch 读取事件流
+  async postStream(url: string, data: any, onEvent: StreamEventHandler, signal?: AbortSignal): Promise<void> {
+    const headers: Record<string, string> = { 'Content-Type': 'application/json', Accept: 'text/event-stream' };
+    const token = this.getToken();
+    if (token) {
+      headers.Authorization = `Bearer ${token}`;
+    }
+    const response = await fetch(`${this.client.defaults.baseURL || ''}${url}`, {
+      method: 'POST',
+      headers,
+      body: JSON.stringify(data),
+      signal,
+    });
+    if (response.status === 401) {
+      this.setToken(null);
+    }
+    if (!response.ok || !response.body) {
+      const body = await response.json().catch(() => ({}));
+      throw new Error(body.error || body.message || `HTTP ${response.status}`);
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) {
+        break;
+      }
+      buffer += decoder.decode(value, { stream: true });
+      // 事件之间以空行分隔
+      let index = buffer.indexOf('\n\n');
+      while (index >= 0) {
+        const block = buffer.slice(0, index);
+        buffer = buffer.slice(index + 2);
+        let event = 'message';
+        const lines: string[] = [];
+        block.split('\n').forEach((line) => {
+          if (line.startsWith('event:')) {
+            event = line.slice(6).trim();
+          } else if (line.startsWith('data:')) {
+            lines.push(line.slice(5));
+          }
+        });
+        if (lines.length > 0) {
+          const raw = lines.join('\n');
+          let payload: any = raw;
+          try {
+            payload = JSON.parse(raw);
+          } catch (e) {
+            // 非 JSON 数据按字符串返回
+          }
+          onEvent(event, payload);
+        }
+        index = buffer.indexOf('\n\n');
+      }
+    }
+  }
+
   // 基金经理筛选
   async getFundManagers(params: FundManagerParams = {}): Promise<FundManagerResponse> {
     const response = await this.client.get('/api/fund/managers', { params });